@@ -2,19 +2,30 @@ package main
 
 import (
 	"context"
+	"flag"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/events"
+	"cash-flow-financial/internal/managers/brokermanager"
 	"cash-flow-financial/internal/managers/configmanager"
 	"cash-flow-financial/internal/managers/dbmanager"
+	"cash-flow-financial/internal/managers/keymanager"
 	"cash-flow-financial/internal/managers/loggermanager"
-	"cash-flow-financial/internal/managers/rabbitmqmanager"
+	"cash-flow-financial/internal/managers/mailmanager"
+	"cash-flow-financial/internal/managers/ratelimitmanager"
+	"cash-flow-financial/internal/models"
 	accountservice "cash-flow-financial/internal/services/account-service"
 	"cash-flow-financial/internal/services/callback"
 	checkoutservice "cash-flow-financial/internal/services/checkout-service"
+	merchantservice "cash-flow-financial/internal/services/merchant-service"
 	transactionservice "cash-flow-financial/internal/services/transaction-service"
+	userservice "cash-flow-financial/internal/services/user-service"
+	webhookservice "cash-flow-financial/internal/services/webhook-service"
+	"cash-flow-financial/scheduler"
 	"cash-flow-financial/server"
 	"cash-flow-financial/worker"
 
@@ -28,7 +39,21 @@ func main() {
 		panic("Failed to load configuration: " + err.Error())
 	}
 
+	mode := flag.String("mode", cfg.App.Mode, "run mode: api, worker or all")
+	flag.Parse()
+
+	cfg.App.Mode = strings.ToLower(*mode)
+	if !configmanager.IsValidRunMode(cfg.App.Mode) {
+		panic("Invalid run mode '" + *mode + "', must be one of: api, worker, all")
+	}
+
+	keyring, err := keymanager.NewKeyring(&cfg.Keyring)
+	if err != nil {
+		panic("Failed to initialize keyring: " + err.Error())
+	}
+
 	logger := loggermanager.NewLogger(cfg.Logger.Level)
+	logger.Info("Starting cash-flow-financial", zap.String("mode", cfg.App.Mode), zap.String("broker", cfg.Broker.Driver))
 
 	dbManager, err := dbmanager.NewDBManager(&cfg.Database)
 	if err != nil {
@@ -36,35 +61,88 @@ func main() {
 	}
 	defer dbManager.Close()
 
-	rabbitManager, err := rabbitmqmanager.NewRabbitMQManager(&cfg.RabbitMQ)
+	broker, err := brokermanager.NewBroker(cfg, dbManager.GetDB(), logger)
 	if err != nil {
-		panic("Failed to initialize RabbitMQ manager: " + err.Error())
+		panic("Failed to initialize message broker: " + err.Error())
 	}
-	defer rabbitManager.Close()
+	defer broker.Close()
 
 	queries := db.New(dbManager.GetDB())
 
-	checkoutService := checkoutservice.NewCheckoutService(queries, logger, rabbitManager)
-	accountService := accountservice.NewAccountService(queries, logger, cfg)
-	transactionService := transactionservice.NewTransactionService(queries, logger)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	runsAPI := cfg.App.Mode == models.RunModeAPI || cfg.App.Mode == models.RunModeAll
+	runsWorker := cfg.App.Mode == models.RunModeWorker || cfg.App.Mode == models.RunModeAll
 
-	// Initialize callback service
-	callbackService := callback.NewCallbackService(logger, cfg)
+	// Jobs are registered everywhere so the API can list and trigger them, but only worker processes run the schedule
+	jobScheduler := scheduler.NewScheduler(dbManager, queries, logger)
+	if err := jobScheduler.Register(scheduler.NewExpirePaymentIntentsJob(queries, logger, &cfg.Scheduler)); err != nil {
+		panic("Failed to register scheduled job: " + err.Error())
+	}
+	defer jobScheduler.Stop()
 
-	// Initialize worker
-	paymentWorker := worker.NewWorker(queries, rabbitManager, logger, callbackService)
+	mailer := mailmanager.NewMailer(&cfg.Mail, logger)
+	accountService := accountservice.NewAccountService(queries, dbManager, keyring, mailer, logger, cfg)
+	callbackService := callback.NewCallbackService(logger, cfg, accountService)
 
-	srv := server.NewServer(cfg, logger, checkoutService, accountService, transactionService, dbManager, rabbitManager)
+	var paymentWorker worker.IWorker
+	var eventRelay *events.Relay
+	var webhookDispatcher *callback.Dispatcher
+	if runsWorker {
+		eventRelay = events.NewRelay(queries, dbManager, broker, logger)
+		eventRelay.Start()
 
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer cancel()
+		webhookDispatcher = callback.NewDispatcher(queries, dbManager, callbackService, mailer, &cfg.Webhook, logger)
+		webhookDispatcher.Start()
 
-	// Start the payment worker
-	if err := paymentWorker.Start(ctx); err != nil {
-		logger.Fatal("Worker failed to start", zap.Error(err))
+		paymentWorker = worker.NewWorker(queries, dbManager, broker, logger, &cfg.Worker)
+
+		if err := paymentWorker.Start(ctx); err != nil {
+			logger.Fatal("Worker failed to start", zap.Error(err))
+		}
+
+		jobScheduler.Start()
+	}
+
+	if !runsAPI {
+		// Worker-only processes serve health on a plain net/http listener instead of Echo
+		healthServer := worker.NewHealthServer(cfg, logger, dbManager, broker)
+		if err := healthServer.Start(ctx); err != nil {
+			logger.Error("Worker health server failed", zap.Error(err))
+		}
+		stopWorker(logger, paymentWorker)
+		eventRelay.Stop()
+		webhookDispatcher.Stop()
+		return
 	}
 
+	checkoutService := checkoutservice.NewCheckoutService(queries, logger, broker)
+	transactionService := transactionservice.NewTransactionService(queries, logger)
+	webhookService := webhookservice.NewWebhookService(queries, logger, callbackService)
+	merchantService := merchantservice.NewMerchantService(queries, dbManager, checkoutService, logger)
+	userService := userservice.NewUserService(queries, dbManager, keyring, mailer, logger, cfg)
+	// Counts are kept per process, so each API instance enforces the limits on its own
+	rateLimitStore := ratelimitmanager.NewMemoryStore()
+
+	srv := server.NewServer(cfg, logger, checkoutService, accountService, transactionService, webhookService, merchantService, userService, dbManager, broker, rateLimitStore, paymentWorker, jobScheduler)
+
 	if err := srv.Start(ctx); err != nil {
 		logger.Fatal("Server failed to start", zap.Error(err))
 	}
+
+	// The HTTP server is stopped first so no new intents are published while the worker drains
+	if paymentWorker != nil {
+		stopWorker(logger, paymentWorker)
+		eventRelay.Stop()
+		webhookDispatcher.Stop()
+	}
+}
+
+// stopWorker drains the worker before the deferred broker and database closes run;
+// the event relay is stopped after it so events from the final settlements still go out
+func stopWorker(logger *loggermanager.Logger, paymentWorker worker.IWorker) {
+	if err := paymentWorker.Stop(); err != nil {
+		logger.Error("Worker failed to stop cleanly", zap.Error(err))
+	}
 }
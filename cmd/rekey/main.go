@@ -0,0 +1,283 @@
+// Command rekey re-encrypts stored secrets under the primary key in ENCRYPTION_KEYS. Run it after
+// adding a new key to the front of the list; once it reports nothing left to rekey, older keys can
+// be removed from the list.
+//
+// It first gives every merchant without an active webhook secret a new one, encrypted under the
+// primary key. Merchants created before callbacks were signed have none, and callbacks are not
+// sent unsigned. The backfill lives here rather than in a migration because secrets can only be
+// encrypted with the keyring; merchants learn the new secret by rotating it.
+//
+//	rekey [--dry-run] [--batch-size=500]
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/configmanager"
+	"cash-flow-financial/internal/managers/dbmanager"
+	"cash-flow-financial/internal/managers/keymanager"
+	"cash-flow-financial/internal/managers/loggermanager"
+	accountservice "cash-flow-financial/internal/services/account-service"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// backfillStats counts the merchants found without an active webhook secret
+type backfillStats struct {
+	Found   int
+	Created int
+	// Changed counts merchants that got a secret from elsewhere, e.g. a rotation, meanwhile
+	Changed int
+	Failed  int
+}
+
+// rekeyStats counts what happened to the rows of one table
+type rekeyStats struct {
+	Scanned int
+	Rekeyed int
+	// Changed counts rows whose secret was replaced between reading and updating it
+	Changed int
+	Failed  int
+}
+
+type rekeyer struct {
+	queries   *db.Queries
+	keyring   keymanager.IKeyring
+	logger    *loggermanager.Logger
+	dryRun    bool
+	batchSize int32
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "count the secrets that need rekeying or backfilling without changing them")
+	batchSize := flag.Int("batch-size", 500, "rows read per query")
+	flag.Parse()
+
+	cfg, err := configmanager.Load()
+	if err != nil {
+		panic("Failed to load configuration: " + err.Error())
+	}
+	if *batchSize < 1 {
+		panic("--batch-size must be a positive integer")
+	}
+
+	keyring, err := keymanager.NewKeyring(&cfg.Keyring)
+	if err != nil {
+		panic("Failed to initialize keyring: " + err.Error())
+	}
+
+	logger := loggermanager.NewLogger(cfg.Logger.Level)
+
+	dbManager, err := dbmanager.NewDBManager(&cfg.Database)
+	if err != nil {
+		panic("Failed to initialize database manager: " + err.Error())
+	}
+	defer dbManager.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	r := &rekeyer{
+		queries:   db.New(dbManager.GetDB()),
+		keyring:   keyring,
+		logger:    logger,
+		dryRun:    *dryRun,
+		batchSize: int32(*batchSize),
+	}
+
+	logger.Info("Rekeying stored secrets", zap.String("primary_key_id", keyring.PrimaryKeyID()), zap.Bool("dry_run", r.dryRun))
+
+	failed := false
+	backfill, err := r.backfillWebhookSecrets(ctx)
+	if err != nil {
+		logger.Error("Webhook secret backfill stopped", zap.Error(err))
+		failed = true
+	}
+	if backfill.Failed > 0 {
+		failed = true
+	}
+	logger.Info("Backfilled webhook secrets",
+		zap.Int("found", backfill.Found),
+		zap.Int("created", backfill.Created),
+		zap.Int("changed", backfill.Changed),
+		zap.Int("failed", backfill.Failed))
+
+	for _, table := range []struct {
+		name  string
+		rekey func(context.Context) (*rekeyStats, error)
+	}{
+		{"merchant_webhook_secrets", r.rekeyWebhookSecrets},
+		{"merchant_users", r.rekeyTOTPSecrets},
+	} {
+		stats, err := table.rekey(ctx)
+		if err != nil {
+			logger.Error("Rekeying stopped", zap.String("table", table.name), zap.Error(err))
+			failed = true
+		}
+		if stats.Failed > 0 {
+			failed = true
+		}
+		logger.Info("Rekeyed table",
+			zap.String("table", table.name),
+			zap.Int("scanned", stats.Scanned),
+			zap.Int("rekeyed", stats.Rekeyed),
+			zap.Int("changed", stats.Changed),
+			zap.Int("failed", stats.Failed))
+	}
+
+	if failed {
+		dbManager.Close()
+		os.Exit(1)
+	}
+}
+
+func (r *rekeyer) backfillWebhookSecrets(ctx context.Context) (*backfillStats, error) {
+	stats := &backfillStats{}
+	after := uuid.Nil
+	for {
+		merchants, err := r.queries.ListMerchantsWithoutWebhookSecret(ctx, &db.ListMerchantsWithoutWebhookSecretParams{
+			AfterID:  after,
+			RowLimit: r.batchSize,
+		})
+		if err != nil {
+			return stats, err
+		}
+		for _, merchant := range merchants {
+			after = merchant.ID
+			stats.Found++
+			if r.dryRun {
+				stats.Created++
+				continue
+			}
+			secret, err := r.keyring.Encrypt(accountservice.GenerateWebhookSecret())
+			if err != nil {
+				r.logger.Error("Failed to encrypt webhook secret", zap.String("merchant_id", merchant.MerchantID), zap.Error(err))
+				stats.Failed++
+				continue
+			}
+			created, err := r.queries.CreateMissingMerchantWebhookSecret(ctx, &db.CreateMissingMerchantWebhookSecretParams{
+				MerchantID: merchant.ID,
+				Secret:     secret,
+			})
+			if err != nil {
+				return stats, err
+			}
+			if created == 0 {
+				stats.Changed++
+				continue
+			}
+			stats.Created++
+		}
+		if len(merchants) < int(r.batchSize) {
+			return stats, nil
+		}
+	}
+}
+
+func (r *rekeyer) rekeyWebhookSecrets(ctx context.Context) (*rekeyStats, error) {
+	stats := &rekeyStats{}
+	after := uuid.Nil
+	for {
+		rows, err := r.queries.ListWebhookSecretsForRekey(ctx, &db.ListWebhookSecretsForRekeyParams{
+			AfterID:  after,
+			RowLimit: r.batchSize,
+		})
+		if err != nil {
+			return stats, err
+		}
+		for _, row := range rows {
+			after = row.ID
+			stats.Scanned++
+			newSecret, ok := r.reencrypt(row.ID, row.Secret, stats)
+			if !ok {
+				continue
+			}
+			updated, err := r.queries.ReencryptWebhookSecret(ctx, &db.ReencryptWebhookSecretParams{
+				NewSecret: newSecret,
+				ID:        row.ID,
+				OldSecret: row.Secret,
+			})
+			if err != nil {
+				return stats, err
+			}
+			countUpdate(updated, stats)
+		}
+		if len(rows) < int(r.batchSize) {
+			return stats, nil
+		}
+	}
+}
+
+func (r *rekeyer) rekeyTOTPSecrets(ctx context.Context) (*rekeyStats, error) {
+	stats := &rekeyStats{}
+	after := uuid.Nil
+	for {
+		rows, err := r.queries.ListMerchantUserTOTPSecretsForRekey(ctx, &db.ListMerchantUserTOTPSecretsForRekeyParams{
+			AfterID:  after,
+			RowLimit: r.batchSize,
+		})
+		if err != nil {
+			return stats, err
+		}
+		for _, row := range rows {
+			after = row.ID
+			stats.Scanned++
+			newSecret, ok := r.reencrypt(row.ID, row.TotpSecret.String, stats)
+			if !ok {
+				continue
+			}
+			updated, err := r.queries.ReencryptMerchantUserTOTPSecret(ctx, &db.ReencryptMerchantUserTOTPSecretParams{
+				NewSecret: sql.NullString{String: newSecret, Valid: true},
+				ID:        row.ID,
+				OldSecret: row.TotpSecret,
+			})
+			if err != nil {
+				return stats, err
+			}
+			countUpdate(updated, stats)
+		}
+		if len(rows) < int(r.batchSize) {
+			return stats, nil
+		}
+	}
+}
+
+// reencrypt returns the secret encrypted under the primary key, and false when the row is already
+// under it, cannot be decrypted, or this is a dry run
+func (r *rekeyer) reencrypt(id uuid.UUID, ciphertext string, stats *rekeyStats) (string, bool) {
+	if !r.keyring.NeedsRekey(ciphertext) {
+		return "", false
+	}
+	plaintext, err := r.keyring.Decrypt(ciphertext)
+	if err != nil {
+		r.logger.Error("Failed to decrypt secret", zap.String("id", id.String()), zap.Error(err))
+		stats.Failed++
+		return "", false
+	}
+	if r.dryRun {
+		stats.Rekeyed++
+		return "", false
+	}
+	reencrypted, err := r.keyring.Encrypt(plaintext)
+	if err != nil {
+		r.logger.Error("Failed to encrypt secret", zap.String("id", id.String()), zap.Error(err))
+		stats.Failed++
+		return "", false
+	}
+	return reencrypted, true
+}
+
+func countUpdate(updated int64, stats *rekeyStats) {
+	if updated == 0 {
+		stats.Changed++
+		return
+	}
+	stats.Rekeyed++
+}
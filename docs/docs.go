@@ -22,9 +22,54 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/account/create-merchant": {
+        "/account/api-keys": {
+            "get": {
+                "description": "Lists all of the merchant's API keys, newest first, with masked values, status and when each was last used. Revoked and expired keys are included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Merchant"
+                ],
+                "summary": "List API Keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "API keys retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ListAPIKeysResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the keys:read scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Creates a new merchant account with a unique merchant ID and API key for payment processing",
+                "description": "Issues a new API key alongside the merchant's existing ones. The key is limited to the requested scopes, which default to and may not exceed those of the key making the request. The mode defaults to that of the key making the request; a test key can only create test keys, and live keys require a verified email. The full key is only returned in this response; store it securely.",
                 "consumes": [
                     "application/json"
                 ],
@@ -34,27 +79,52 @@ const docTemplate = `{
                 "tags": [
                     "Merchant"
                 ],
-                "summary": "Create Merchant Account",
+                "summary": "Create API Key",
                 "parameters": [
                     {
-                        "description": "Merchant creation request",
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Key details",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateMerchantRequest"
+                            "$ref": "#/definitions/models.CreateAPIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Merchant created successfully",
+                        "description": "API key created successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.CreateMerchantResponse"
+                            "$ref": "#/definitions/models.CreateAPIKeyResponse"
                         }
                     },
                     "400": {
-                        "description": "Validation error or duplicate email",
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the keys:write scope or a requested scope, a test key asked for a live key, or a live key was requested before the email was verified",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -62,43 +132,65 @@ const docTemplate = `{
                 }
             }
         },
-        "/account/merchant": {
-            "get": {
-                "description": "Retrieves merchant information including balances across currencies and recent transactions",
-                "consumes": [
-                    "application/json"
-                ],
+        "/account/api-keys/{id}/revoke": {
+            "post": {
+                "description": "Stops an API key from authenticating immediately. The key making the request must hold all of the key's scopes and have the same mode. A key can only be revoked while another active key of its mode has the keys:write scope; rotate it instead.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Merchant"
                 ],
-                "summary": "Get Merchant Details",
+                "summary": "Revoke API Key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Merchant ID (e.g., CASM-ABC123)",
-                        "name": "merchant_id",
-                        "in": "query",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Merchant details retrieved successfully",
+                        "description": "API key revoked successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.GetMerchantResponse"
+                            "$ref": "#/definitions/models.APIKeyResponse"
                         }
                     },
-                    "400": {
-                        "description": "Missing merchant_id parameter",
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the keys:write scope or a scope of the key being revoked, or the key being revoked has a different mode",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Merchant not found",
+                        "description": "API key not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "API key already revoked or expired, or no other active key of its mode can manage keys",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -106,9 +198,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/checkout/create-intent": {
+        "/account/api-keys/{id}/rotate": {
             "post": {
-                "description": "Creates a payment intent that will be processed asynchronously. The payment gateway charges 1% fee and only accepts ETB and USD currencies.",
+                "description": "Issues a replacement for an API key. Until the grace period ends both keys authenticate, so clients can switch over without downtime. The replacement keeps the key's scopes, so the key making the request must hold all of them and have the same mode. The full new key is only returned in this response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -116,9 +208,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payment"
+                    "Merchant"
                 ],
-                "summary": "Create Payment Intent",
+                "summary": "Rotate API Key",
                 "parameters": [
                     {
                         "type": "string",
@@ -128,20 +220,26 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Payment intent creation request",
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rotation options",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreatePaymentIntentRequest"
+                            "$ref": "#/definitions/models.RotateAPIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Payment intent created successfully",
+                    "200": {
+                        "description": "API key rotated successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.CreatePaymentIntentResponse"
+                            "$ref": "#/definitions/models.RotateAPIKeyResponse"
                         }
                     },
                     "400": {
@@ -156,6 +254,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "API key is missing the keys:write scope or a scope of the key being rotated, or the key being rotated has a different mode",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "API key not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "API key already revoked or expired",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -164,135 +280,3585 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CreateMerchantRequest": {
-            "type": "object",
-            "required": [
-                "email",
-                "name"
-            ],
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "example": "john.doe@example.com"
-                },
-                "name": {
-                    "type": "string",
-                    "maxLength": 100,
-                    "minLength": 2,
-                    "example": "John Doe"
-                }
-            }
         },
-        "models.CreateMerchantResponse": {
-            "type": "object",
-            "properties": {
-                "api_key": {
-                    "type": "string",
-                    "example": "cash_test_abc123def456"
-                },
-                "email": {
-                    "type": "string",
-                    "example": "john.doe@example.com"
-                },
-                "merchant_id": {
-                    "type": "string",
-                    "example": "CASM-ABC123"
-                },
-                "message": {
-                    "type": "string",
-                    "example": "Merchant created successfully"
-                },
-                "name": {
-                    "type": "string",
-                    "example": "John Doe"
-                },
-                "status": {
-                    "type": "boolean",
-                    "example": true
+        "/account/create-merchant": {
+            "post": {
+                "description": "Creates a new merchant account with a unique merchant ID and API key for payment processing. Requires an operator key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Merchant"
+                ],
+                "summary": "Create Merchant Account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operator API Key",
+                        "name": "X-ADMIN-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Merchant creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateMerchantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Merchant created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateMerchantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error or duplicate email",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing admin key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "models.CreatePaymentIntentRequest": {
-            "type": "object",
-            "required": [
-                "amount",
-                "callback_url",
-                "currency",
-                "nonce"
-            ],
-            "properties": {
-                "amount": {
-                    "type": "number",
-                    "maximum": 100000,
-                    "example": 100.5
-                },
-                "callback_url": {
-                    "type": "string",
-                    "example": "https://example.com/callback"
-                },
-                "currency": {
-                    "type": "string",
-                    "enum": [
-                        "ETB",
-                        "USD"
-                    ],
-                    "example": "ETB"
-                },
+        "/account/email/verification": {
+            "post": {
+                "description": "Mails a new verification link to the pending email, or to the current email while it is unverified. Earlier links for the same address keep working until they expire.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Merchant"
+                ],
+                "summary": "Resend Verification Email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verification email sent",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProfileResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the account:write scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email is already verified and no change is pending",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/merchant": {
+            "get": {
+                "description": "Retrieves the authenticated merchant's information including balances across currencies and recent transactions. Only the balances and transactions of the API key's mode (test or live) are returned. merchant_id is optional; when given it must be the merchant the API key belongs to.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Merchant"
+                ],
+                "summary": "Get Merchant Details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Merchant ID (e.g., CASM-ABC123)",
+                        "name": "merchant_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merchant details retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetMerchantResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the balances:read scope, or merchant_id belongs to another merchant",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Merchant not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/profile": {
+            "patch": {
+                "description": "Changes the fields that are set. A new name applies immediately. A new email is kept as pending_email and a verification link is mailed to it; the current email stays in use until the link is followed. Sending the current email cancels a pending change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Merchant"
+                ],
+                "summary": "Update Merchant Profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Profile changes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProfileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the account:write scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email belongs to another merchant",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/verify-email": {
+            "get": {
+                "description": "Opened from the link in a verification email, so it needs no API key; the signed token identifies the merchant and address. A pending address becomes the merchant's email. Links expire after EMAIL_VERIFICATION_TTL hours and stop working once a different address is requested.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Merchant"
+                ],
+                "summary": "Verify Email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email verified successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProfileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing, invalid, superseded or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email was taken by another merchant in the meantime",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/webhook-secret/rotate": {
+            "post": {
+                "description": "Issues a new secret for signing callbacks. Until the grace period ends, callbacks carry a signature from both the new and the previous secret so receivers can switch over without dropping events.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Merchant"
+                ],
+                "summary": "Rotate Webhook Secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Rotation options",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.RotateWebhookSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook secret rotated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.RotateWebhookSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the webhooks:write scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs": {
+            "get": {
+                "description": "Returns every registered scheduler job with its cron schedule, next run time and most recent run",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List Scheduled Jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operator API Key",
+                        "name": "X-ADMIN-KEY",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Scheduled jobs retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduledJobsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing admin key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{name}/run": {
+            "post": {
+                "description": "Starts a run of the named job immediately. The run holds the same lock as scheduled runs, so it is rejected while the job is running on any instance.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Trigger Scheduled Job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operator API Key",
+                        "name": "X-ADMIN-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "expire-payment-intents",
+                        "description": "Job name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Scheduled job run started",
+                        "schema": {
+                            "$ref": "#/definitions/models.TriggerScheduledJobResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing admin key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Scheduled job not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Scheduled job is already running",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/merchants": {
+            "get": {
+                "description": "Lists merchants, newest first, with their status, active API key count and pending intents. q matches the merchant ID, name or email, ignoring case.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List Merchants",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operator API Key",
+                        "name": "X-ADMIN-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "example.com",
+                        "description": "Search text",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "suspended",
+                            "inactive"
+                        ],
+                        "type": "string",
+                        "description": "Merchant status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum merchants to return (1-200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Merchants to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merchants retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ListMerchantsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing admin key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/merchants/{id}": {
+            "get": {
+                "description": "Returns the merchant and every status change made by operators, newest first, with the reason and the admin key that made it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get Merchant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operator API Key",
+                        "name": "X-ADMIN-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "CASM-ABC123",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merchant retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminMerchantResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing admin key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Merchant not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/merchants/{id}/deactivate": {
+            "post": {
+                "description": "Stops every API key of the merchant from authenticating. Pending intents are not processed and expire.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Deactivate Merchant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operator API Key",
+                        "name": "X-ADMIN-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "CASM-ABC123",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeMerchantStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merchant deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeMerchantStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing admin key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Merchant not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Merchant is already inactive",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/merchants/{id}/reactivate": {
+            "post": {
+                "description": "Restores full access and queues the merchant's unexpired pending intents for processing again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Reactivate Merchant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operator API Key",
+                        "name": "X-ADMIN-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "CASM-ABC123",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeMerchantStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merchant reactivated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeMerchantStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing admin key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Merchant not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Merchant is already active",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/merchants/{id}/suspend": {
+            "post": {
+                "description": "Blocks intent creation at once and pauses processing and settlement of the merchant's pending intents until it is reactivated. The merchant can still authenticate and read its account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Suspend Merchant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operator API Key",
+                        "name": "X-ADMIN-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "CASM-ABC123",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeMerchantStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merchant suspended",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeMerchantStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing admin key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Merchant not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Merchant is not active",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/merchants/{id}/tier": {
+            "post": {
+                "description": "Sets the tier that selects the request rate limit of the merchant's API keys and dashboard users. The new limit applies from the merchant's next request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Change Merchant Tier",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operator API Key",
+                        "name": "X-ADMIN-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "CASM-ABC123",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New tier",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeMerchantTierRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merchant tier changed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeMerchantTierResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing admin key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Merchant not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/worker/stats": {
+            "get": {
+                "description": "Returns the payment worker pool configuration together with in-flight, processed and failed delivery counts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get Worker Stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operator API Key",
+                        "name": "X-ADMIN-KEY",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Worker stats retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.WorkerStatsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing admin key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/checkout/create-intent": {
+            "post": {
+                "description": "Creates a payment intent that will be processed asynchronously. The payment gateway charges 1% fee and only accepts ETB and USD currencies. Intents created with a test key are processed by the sandbox, whose outcome is chosen by the account number and the amount's cents.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Create Payment Intent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment intent creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePaymentIntentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Payment intent created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePaymentIntentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the intents:write scope, or the merchant is suspended",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Nonce already used by an intent in the other mode",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/checkout/intents/{id}": {
+            "get": {
+                "description": "Returns the payment intent's status and callback_ack, the acknowledgement state of its callbacks: pending, acknowledged, unacknowledged (2xx without an acknowledgement body), rejected (the merchant answered {\"status\": false}; the callback is being retried) or failed (retries exhausted).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Get Payment Intent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payment intent ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payment intent retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.PaymentIntentResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the intents:read scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment intent not found, or created in the other mode",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/invites/accept": {
+            "post": {
+                "description": "Sets the password of the user an invitation was sent to, using the token from the invitation link, and activates them. The user then signs in with POST /dashboard/login. Invitations expire after DASHBOARD_INVITE_TTL hours and stop working once the user is invited again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Accept Dashboard Invitation",
+                "parameters": [
+                    {
+                        "description": "Invitation token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AcceptInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Invitation accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error, or an invalid, used or expired invitation",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/login": {
+            "post": {
+                "description": "Checks the email and password, and the TOTP code once two-factor authentication is enabled, and returns a session token. Send it as Authorization: Bearer \u003csession_token\u003e; it expires after DASHBOARD_SESSION_TTL hours. A 401 with error \"two-factor code is required\" means the request should be repeated with totp_code. Each TOTP code is accepted once, and after DASHBOARD_LOGIN_MAX_ATTEMPTS failed sign-ins in a row the user is locked out for DASHBOARD_LOGIN_LOCKOUT minutes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Dashboard Login",
+                "parameters": [
+                    {
+                        "description": "Credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Signed in successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials, or a missing or invalid two-factor code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Merchant account is inactive",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/logout": {
+            "post": {
+                "description": "Revokes the session token the request was made with. Other sessions of the user stay signed in.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Dashboard Logout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Signed out"
+                    },
+                    "401": {
+                        "description": "Invalid or missing session",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/me": {
+            "get": {
+                "description": "Returns the user behind the session token, the merchant they belong to and the scopes their role grants.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Get Current Dashboard User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Signed in",
+                        "schema": {
+                            "$ref": "#/definitions/models.DashboardMeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing session",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/me/totp": {
+            "post": {
+                "description": "Generates a TOTP secret and returns it with an otpauth URI for authenticator apps. Sign-in does not ask for a code until it is confirmed with POST /dashboard/me/totp/confirm. Calling this again before confirming replaces the secret.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Start Two-Factor Enrollment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Secret generated",
+                        "schema": {
+                            "$ref": "#/definitions/models.TOTPEnrollmentResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing session",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/me/totp/confirm": {
+            "post": {
+                "description": "Checks a code generated from the secret returned by POST /dashboard/me/totp and turns two-factor authentication on. From then on sign-in requires totp_code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Confirm Two-Factor Enrollment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Code from the authenticator app",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication enabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error or wrong code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing session",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already enabled, or enrollment was not started",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/me/totp/disable": {
+            "post": {
+                "description": "Requires a current code from the authenticator app, so a session token alone cannot remove the second factor.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Disable Two-Factor Authentication",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Code from the authenticator app",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication disabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error or wrong code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing session",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/users": {
+            "get": {
+                "description": "Returns every dashboard user of the merchant, including pending invitations and removed users, oldest first. Accepts an API key or a dashboard session.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "List Dashboard Users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Users retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ListUsersResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key or session",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the users:read scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates the user with the given role and emails them a link to set a password. Inviting an address again, while the invitation is pending or after the user was removed, sends a new link and invalidates the old one. Only owners can invite an owner, except that an API key can invite the first one while the merchant has no active owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Invite Dashboard User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User to invite",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.InviteUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Invitation sent",
+                        "schema": {
+                            "$ref": "#/definitions/models.InviteUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key or session",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the users:write scope, or inviting an owner without being one",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The email already belongs to a dashboard user",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/users/{id}": {
+            "delete": {
+                "description": "Disables the user and signs out all of their sessions, or cancels a pending invitation. The user stays in the list with status disabled and can be invited again. Only owners can remove an owner, and the merchant's last active owner cannot be removed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Remove Dashboard User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User removed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key or session",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the users:write scope, or removing an owner without being one",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The user is the last active owner",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Gives the user a new role, effective from their next request. Only owners can grant or take away the owner role (API keys only while the merchant has no active owner), and the merchant's last active owner cannot be demoted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Change Dashboard User Role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateUserRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User role updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key or session",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the users:write scope, or changing an owner without being one",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The user is the last active owner",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries": {
+            "get": {
+                "description": "Lists callback deliveries, newest first, with every attempt's status code, latency and response excerpt. Filter by payment intent, status or creation time; without from, the last seven days are returned unless payment_intent_id is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List Webhook Deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "PI-ABC123DEF456",
+                        "description": "Payment intent ID",
+                        "name": "payment_intent_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "delivered",
+                            "exhausted"
+                        ],
+                        "type": "string",
+                        "description": "Delivery status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-01-05T00:00:00Z",
+                        "description": "Created at or after (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-01-06T00:00:00Z",
+                        "description": "Created before (RFC 3339); defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum deliveries to return (1-200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook deliveries retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ListWebhookDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the webhooks:read scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{id}": {
+            "get": {
+                "description": "Retrieves a callback delivery of the authenticated merchant, including its payload and every attempt",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get Webhook Delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook delivery retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveryResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the webhooks:read scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{id}/resend": {
+            "post": {
+                "description": "Queues a new delivery with the same payload and URL as an earlier one, in any status. The new delivery references the original through resend_of and gets a fresh retry horizon.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Resend Webhook Delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Webhook delivery queued for resend",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveryResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the webhooks:write scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/endpoints": {
+            "get": {
+                "description": "Returns every webhook endpoint registered by the merchant, enabled or not, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List Webhook Endpoints",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook endpoints retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ListWebhookEndpointsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the webhooks:read scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registers a URL to receive callbacks for the listed event types. Use \"*\" to subscribe to every event type. Events are sent to every enabled endpoint subscribed to them unless the payment intent sets its own callback_url.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Create Webhook Endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook endpoint",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWebhookEndpointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Webhook endpoint created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookEndpointResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the webhooks:write scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/endpoints/{id}": {
+            "delete": {
+                "description": "Deletes the endpoint. Deliveries already queued for it are still attempted, and past deliveries stay in the delivery log.",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete Webhook Endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Webhook endpoint deleted"
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the webhooks:write scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook endpoint not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates only the fields present in the request. Set enabled to false to stop sending events to an endpoint without deleting it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Update Webhook Endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateWebhookEndpointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook endpoint updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookEndpointResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the webhooks:write scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook endpoint not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/test": {
+            "post": {
+                "description": "Sends one signed callback with status \"test\" and payment_intent_id PI-TEST00000000 to the given URL and returns the response code, latency and response excerpt. Test events are not stored or retried.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Send Test Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Merchant API Key",
+                        "name": "X-API-KEY",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Test webhook request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SendTestWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Test webhook sent; see delivered for the outcome",
+                        "schema": {
+                            "$ref": "#/definitions/models.SendTestWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is missing the webhooks:write scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-06T10:30:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9a7b6c5d-4e3f-4a2b-8c1d-0e9f8a7b6c5d"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "Production server"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2024-01-05T11:02:13Z"
+                },
+                "masked_key": {
+                    "type": "string",
+                    "example": "api_live_AbCd1234EfGh_...wxyz"
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "live"
+                },
+                "must_rotate": {
+                    "description": "Legacy key that expires unless rotated",
+                    "type": "boolean"
+                },
+                "revoked_at": {
+                    "type": "string",
+                    "example": "2024-01-06T10:30:00Z"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "intents:read",
+                        "intents:write"
+                    ]
+                },
+                "status": {
+                    "description": "active, expired or revoked",
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "models.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "$ref": "#/definitions/models.APIKey"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "API key revoked successfully"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.AcceptInviteRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Abebe Kebede"
+                },
+                "password": {
+                    "description": "bcrypt only uses the first 72 bytes, so longer passwords are refused rather than truncated",
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 12,
+                    "example": "correct horse battery staple"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "inv_AbCdEfGhIjKlMnOpQrStUvWxYz012345"
+                }
+            }
+        },
+        "models.AdminMerchant": {
+            "type": "object",
+            "properties": {
+                "active_api_keys": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "email_verified": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "merchant_id": {
+                    "type": "string",
+                    "example": "CASM-ABC123"
+                },
+                "merchant_status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "pending_email": {
+                    "type": "string",
+                    "example": "billing@example.com"
+                },
+                "pending_intents": {
+                    "description": "Pending intents across both modes",
+                    "type": "integer",
+                    "example": 0
+                },
+                "status_changed_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                },
+                "tier": {
+                    "type": "string",
+                    "example": "standard"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                }
+            }
+        },
+        "models.AdminMerchantResponse": {
+            "type": "object",
+            "properties": {
+                "merchant": {
+                    "$ref": "#/definitions/models.AdminMerchant"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Merchant retrieved successfully"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "status_history": {
+                    "description": "Newest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MerchantStatusEvent"
+                    }
+                }
+            }
+        },
+        "models.CallbackAcknowledgement": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Order 123 marked as paid"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "acknowledged"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:35:01Z"
+                }
+            }
+        },
+        "models.ChangeMerchantStatusRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "minLength": 3,
+                    "example": "Chargeback ratio above 2%"
+                }
+            }
+        },
+        "models.ChangeMerchantStatusResponse": {
+            "type": "object",
+            "properties": {
+                "event": {
+                    "$ref": "#/definitions/models.MerchantStatusEvent"
+                },
+                "merchant": {
+                    "$ref": "#/definitions/models.AdminMerchant"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Merchant suspended"
+                },
+                "republished_intents": {
+                    "description": "Pending intents queued again on reactivation",
+                    "type": "integer",
+                    "example": 3
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.ChangeMerchantTierRequest": {
+            "type": "object",
+            "required": [
+                "tier"
+            ],
+            "properties": {
+                "tier": {
+                    "type": "string",
+                    "enum": [
+                        "standard",
+                        "growth",
+                        "enterprise"
+                    ],
+                    "example": "growth"
+                }
+            }
+        },
+        "models.ChangeMerchantTierResponse": {
+            "type": "object",
+            "properties": {
+                "merchant": {
+                    "$ref": "#/definitions/models.AdminMerchant"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Merchant tier changed"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "label"
+            ],
+            "properties": {
+                "label": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Production server"
+                },
+                "mode": {
+                    "description": "Defaults to the mode of the key making the request; a test key can only create test keys",
+                    "type": "string",
+                    "enum": [
+                        "test",
+                        "live"
+                    ],
+                    "example": "test"
+                },
+                "scopes": {
+                    "description": "Defaults to the scopes of the key making the request, which is also the most it can grant",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "intents:write"
+                    ]
+                }
+            }
+        },
+        "models.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "type": "string",
+                    "example": "api_live_AbCd1234EfGh_AbCdEfGhIjKlMnOpQrStUvWxYz012345"
+                },
+                "key": {
+                    "$ref": "#/definitions/models.APIKey"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "API key created successfully"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.CreateMerchantRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "john.doe@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "John Doe"
+                }
+            }
+        },
+        "models.CreateMerchantResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "type": "string",
+                    "example": "api_live_AbCd1234EfGh_AbCdEfGhIjKlMnOpQrStUvWxYz012345"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "merchant_id": {
+                    "type": "string",
+                    "example": "CASM-ABC123"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Merchant created successfully"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "test_api_key": {
+                    "description": "Sandbox key; its intents are processed deterministically and never touch live balances",
+                    "type": "string",
+                    "example": "api_test_IjKl5678MnOp_AbCdEfGhIjKlMnOpQrStUvWxYz012345"
+                },
+                "webhook_secret": {
+                    "description": "Shown once; used to verify the Cashflow-Signature header on callbacks",
+                    "type": "string",
+                    "example": "sk_abc123def456"
+                }
+            }
+        },
+        "models.CreatePaymentIntentRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "currency",
+                "nonce"
+            ],
+            "properties": {
+                "account_number": {
+                    "description": "Payer account. In test mode the sandbox account numbers choose the outcome.",
+                    "type": "string",
+                    "maxLength": 15,
+                    "minLength": 9,
+                    "example": "251900000001"
+                },
+                "amount": {
+                    "type": "number",
+                    "maximum": 100000,
+                    "example": 100.5
+                },
+                "callback_url": {
+                    "description": "Overrides registered webhook endpoints",
+                    "type": "string",
+                    "example": "https://example.com/callback"
+                },
+                "currency": {
+                    "type": "string",
+                    "enum": [
+                        "ETB",
+                        "USD"
+                    ],
+                    "example": "ETB"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Payment for order #123"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "nonce": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 16,
+                    "example": "unique_nonce_123456789"
+                }
+            }
+        },
+        "models.CreatePaymentIntentResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 100.5
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "ETB"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Payment for order #123"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:45:00Z"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Payment intent created successfully"
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "live"
+                },
+                "payment_intent_id": {
+                    "type": "string",
+                    "example": "PI-ABC123"
+                },
+                "payment_status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.CreateWebhookEndpointRequest": {
+            "type": "object",
+            "required": [
+                "event_types",
+                "url"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Order service"
+                },
+                "enabled": {
+                    "description": "Defaults to true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "event_types": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "payment.intent.succeeded"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "https://example.com/webhooks/payments"
+                }
+            }
+        },
+        "models.DashboardMeResponse": {
+            "type": "object",
+            "properties": {
+                "merchant_id": {
+                    "type": "string",
+                    "example": "CASM-ABC123DEF456"
+                },
+                "merchant_name": {
+                    "type": "string",
+                    "example": "Acme Store"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Signed in"
+                },
+                "scopes": {
+                    "description": "Granted by the user's role",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "user": {
+                    "$ref": "#/definitions/models.DashboardUser"
+                }
+            }
+        },
+        "models.DashboardUser": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "finance@example.com"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f2b1c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d"
+                },
+                "invited_by": {
+                    "description": "Email of the inviting user, or api_key",
+                    "type": "string",
+                    "example": "owner@example.com"
+                },
+                "last_login_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Abebe Kebede"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "finance"
+                },
+                "status": {
+                    "description": "invited, active or disabled",
+                    "type": "string",
+                    "example": "active"
+                },
+                "totp_enabled": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.GetMerchantResponse": {
+            "type": "object",
+            "properties": {
+                "api_key_created": {
+                    "type": "string"
+                },
+                "api_key_id": {
+                    "type": "string",
+                    "example": "9a7b6c5d-4e3f-4a2b-8c1d-0e9f8a7b6c5d"
+                },
+                "api_key_must_rotate": {
+                    "description": "Legacy key that expires unless rotated",
+                    "type": "boolean"
+                },
+                "api_key_scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "api_key_status": {
+                    "type": "string"
+                },
+                "balances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MerchantBalance"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "description": "Live API keys and live intents require it",
+                    "type": "boolean"
+                },
+                "masked_api_key": {
+                    "type": "string",
+                    "example": "api_live_AbCd1234EfGh_...wxyz"
+                },
+                "merchant_id": {
+                    "type": "string"
+                },
+                "merchant_status": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "mode": {
+                    "description": "Mode of the API key; balances and transactions are limited to it",
+                    "type": "string",
+                    "example": "live"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pending_email": {
+                    "description": "Requested address awaiting verification",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "boolean"
+                },
+                "tier": {
+                    "type": "string",
+                    "example": "standard"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MerchantTransaction"
+                    }
+                }
+            }
+        },
+        "models.InviteUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "finance@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Abebe Kebede"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "admin",
+                        "developer",
+                        "finance",
+                        "read_only"
+                    ],
+                    "example": "finance"
+                }
+            }
+        },
+        "models.InviteUserResponse": {
+            "type": "object",
+            "properties": {
+                "invite_expires_at": {
+                    "type": "string",
+                    "example": "2024-01-08T10:30:00Z"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Invitation sent"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "user": {
+                    "$ref": "#/definitions/models.DashboardUser"
+                }
+            }
+        },
+        "models.ListAPIKeysResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.APIKey"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "API keys retrieved successfully"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.ListMerchantsResponse": {
+            "type": "object",
+            "properties": {
+                "merchants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AdminMerchant"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Merchants retrieved successfully"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.ListUsersResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Users retrieved successfully"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DashboardUser"
+                    }
+                }
+            }
+        },
+        "models.ListWebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookDelivery"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Webhook deliveries retrieved successfully"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.ListWebhookEndpointsResponse": {
+            "type": "object",
+            "properties": {
+                "endpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookEndpoint"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Webhook endpoints retrieved successfully"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "finance@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "example": "correct horse battery staple"
+                },
+                "totp_code": {
+                    "description": "Required once the user has enabled two-factor authentication",
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "models.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-05T22:30:00Z"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Signed in successfully"
+                },
+                "session_token": {
+                    "description": "Sent as Authorization: Bearer \u003csession_token\u003e on dashboard requests; shown only once",
+                    "type": "string",
+                    "example": "sess_AbCdEfGhIjKlMnOpQrStUvWxYz0123456789AbCdEfGh"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "user": {
+                    "$ref": "#/definitions/models.DashboardUser"
+                }
+            }
+        },
+        "models.MerchantBalance": {
+            "type": "object",
+            "properties": {
+                "available_balance": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "last_updated": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "total_deposit": {
+                    "type": "string"
+                },
+                "total_transaction_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MerchantStatusEvent": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                },
+                "from_status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Chargeback ratio above 2%"
+                },
+                "to_status": {
+                    "type": "string",
+                    "example": "suspended"
+                }
+            }
+        },
+        "models.MerchantTransaction": {
+            "type": "object",
+            "properties": {
+                "account_number": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "fee_amount": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "merchant_id": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "payment_intent_id": {
+                    "type": "string"
+                },
+                "payment_method": {
+                    "type": "string"
+                },
+                "processed_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "third_party_reference": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PaymentIntentResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 100.5
+                },
+                "callback_ack": {
+                    "$ref": "#/definitions/models.CallbackAcknowledgement"
+                },
+                "callback_url": {
+                    "type": "string",
+                    "example": "https://example.com/callback"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "ETB"
+                },
                 "description": {
                     "type": "string",
-                    "maxLength": 500,
-                    "example": "Payment for order #123"
+                    "example": "Payment for order #123"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:45:00Z"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Payment intent retrieved successfully"
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "live"
+                },
+                "payment_intent_id": {
+                    "type": "string",
+                    "example": "PI-ABC123"
+                },
+                "payment_status": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.ProfileResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "email_verified": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "merchant_id": {
+                    "type": "string",
+                    "example": "CASM-ABC123"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Profile updated successfully"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe Trading"
+                },
+                "pending_email": {
+                    "type": "string",
+                    "example": "billing@example.com"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "verification_sent": {
+                    "description": "Set when a verification link was mailed by this request",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.RotateAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "grace_period_hours": {
+                    "description": "Hours the rotated key keeps authenticating; defaults to API_KEY_GRACE_PERIOD",
+                    "type": "integer",
+                    "maximum": 168,
+                    "minimum": 0,
+                    "example": 24
+                },
+                "label": {
+                    "description": "Label for the new key; defaults to the rotated key's label",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Production server"
+                }
+            }
+        },
+        "models.RotateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "type": "string",
+                    "example": "api_live_AbCd1234EfGh_AbCdEfGhIjKlMnOpQrStUvWxYz012345"
+                },
+                "key": {
+                    "$ref": "#/definitions/models.APIKey"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "API key rotated successfully"
+                },
+                "previous_key": {
+                    "$ref": "#/definitions/models.APIKey"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.RotateWebhookSecretRequest": {
+            "type": "object",
+            "properties": {
+                "grace_period_hours": {
+                    "description": "Hours the previous secret keeps signing callbacks; defaults to WEBHOOK_SECRET_GRACE_PERIOD",
+                    "type": "integer",
+                    "maximum": 168,
+                    "minimum": 0,
+                    "example": 24
+                }
+            }
+        },
+        "models.RotateWebhookSecretResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Webhook secret rotated successfully"
+                },
+                "previous_secret_expires_at": {
+                    "type": "string",
+                    "example": "2024-01-06T10:30:00Z"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "webhook_secret": {
+                    "type": "string",
+                    "example": "sk_abc123def456"
+                }
+            }
+        },
+        "models.ScheduledJob": {
+            "type": "object",
+            "properties": {
+                "last_run": {
+                    "$ref": "#/definitions/models.ScheduledJobRun"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "expire-payment-intents"
+                },
+                "next_run_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:35:00Z"
+                },
+                "schedule": {
+                    "type": "string",
+                    "example": "*/5 * * * *"
+                }
+            }
+        },
+        "models.ScheduledJobRun": {
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:01Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3b9f5a62-0c1e-4a55-8f3d-2e6b7c8d9e01"
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "worker-1-7"
+                },
+                "job_name": {
+                    "type": "string",
+                    "example": "expire-payment-intents"
+                },
+                "scheduled_for": {
+                    "description": "Schedule slot the run was due for; absent on manual runs",
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "succeeded"
+                },
+                "trigger": {
+                    "type": "string",
+                    "example": "schedule"
+                }
+            }
+        },
+        "models.ScheduledJobsResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScheduledJob"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Scheduled jobs retrieved successfully"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.SendTestWebhookRequest": {
+            "type": "object",
+            "required": [
+                "callback_url"
+            ],
+            "properties": {
+                "callback_url": {
+                    "type": "string",
+                    "example": "https://example.com/callback"
+                }
+            }
+        },
+        "models.SendTestWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "ack_message": {
+                    "type": "string",
+                    "example": "Received"
+                },
+                "acknowledged": {
+                    "description": "Set when the body was an acknowledgement",
+                    "type": "boolean",
+                    "example": true
+                },
+                "delivered": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer",
+                    "example": 184
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Test webhook delivered"
+                },
+                "response_body": {
+                    "type": "string",
+                    "example": "{\"status\":true}"
                 },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": true
+                "response_code": {
+                    "type": "integer",
+                    "example": 200
                 },
-                "nonce": {
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.TOTPCodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
                     "type": "string",
-                    "maxLength": 64,
-                    "minLength": 16,
-                    "example": "unique_nonce_123456789"
+                    "example": "123456"
                 }
             }
         },
-        "models.CreatePaymentIntentResponse": {
+        "models.TOTPEnrollmentResponse": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "number",
-                    "example": 100.5
-                },
-                "created_at": {
+                "message": {
                     "type": "string",
-                    "example": "2024-01-05T10:30:00Z"
+                    "example": "Scan the code, then confirm it with a code from the app"
                 },
-                "currency": {
+                "secret": {
+                    "description": "Base32 secret for authenticator apps that cannot scan the URI",
                     "type": "string",
-                    "example": "ETB"
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
                 },
-                "description": {
-                    "type": "string",
-                    "example": "Payment for order #123"
+                "status": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "expires_at": {
+                "uri": {
                     "type": "string",
-                    "example": "2024-01-05T10:45:00Z"
-                },
+                    "example": "otpauth://totp/Cash%20Flow:finance@example.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP\u0026issuer=Cash%20Flow"
+                }
+            }
+        },
+        "models.TriggerScheduledJobResponse": {
+            "type": "object",
+            "properties": {
                 "message": {
                     "type": "string",
-                    "example": "Payment intent created successfully"
-                },
-                "payment_intent_id": {
-                    "type": "string",
-                    "example": "PI-ABC123"
+                    "example": "Scheduled job run started"
                 },
-                "payment_status": {
-                    "type": "string",
-                    "example": "pending"
+                "run": {
+                    "$ref": "#/definitions/models.ScheduledJobRun"
                 },
                 "status": {
                     "type": "boolean",
@@ -300,131 +3866,317 @@ const docTemplate = `{
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.UpdateProfileRequest": {
             "type": "object",
             "properties": {
-                "details": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "billing@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "John Doe Trading"
+                }
+            }
+        },
+        "models.UpdateUserRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "admin",
+                        "developer",
+                        "finance",
+                        "read_only"
+                    ],
+                    "example": "developer"
+                }
+            }
+        },
+        "models.UpdateWebhookEndpointRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Order service"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "event_types": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
                         "type": "string"
-                    }
-                },
-                "error": {
-                    "type": "string"
+                    },
+                    "example": [
+                        "*"
+                    ]
                 },
-                "status": {
-                    "type": "boolean"
+                "url": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "https://example.com/webhooks/payments"
                 }
             }
         },
-        "models.GetMerchantResponse": {
+        "models.UserResponse": {
             "type": "object",
             "properties": {
-                "api_key": {
-                    "type": "string"
-                },
-                "api_key_created": {
-                    "type": "string"
+                "message": {
+                    "type": "string",
+                    "example": "User updated successfully"
                 },
-                "api_key_status": {
-                    "type": "string"
+                "status": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "balances": {
+                "user": {
+                    "$ref": "#/definitions/models.DashboardUser"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt_log": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.MerchantBalance"
+                        "$ref": "#/definitions/models.WebhookDeliveryAttempt"
                     }
                 },
+                "attempts": {
+                    "type": "integer",
+                    "example": 2
+                },
                 "created_at": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "2024-01-05T10:35:00Z"
                 },
-                "email": {
+                "delivered_at": {
                     "type": "string"
                 },
-                "merchant_id": {
-                    "type": "string"
+                "endpoint_id": {
+                    "type": "string",
+                    "example": "5d2e7c1a-8b4f-4a3e-9c6d-1f0e2a3b4c5d"
                 },
-                "merchant_status": {
-                    "type": "string"
+                "event_type": {
+                    "type": "string",
+                    "example": "payment.intent.succeeded"
                 },
-                "message": {
-                    "type": "string"
+                "id": {
+                    "type": "string",
+                    "example": "0c4a8f5e-6d3b-4e1a-9f2c-7b8d9e0a1b2c"
                 },
-                "name": {
+                "last_error": {
+                    "type": "string",
+                    "example": "callback endpoint returned non-2xx status: 500"
+                },
+                "last_response_code": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "next_attempt_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:36:01Z"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "payment_intent_id": {
+                    "type": "string",
+                    "example": "PI-ABC123DEF456"
+                },
+                "resend_of": {
                     "type": "string"
                 },
                 "status": {
-                    "type": "boolean"
+                    "type": "string",
+                    "example": "pending"
                 },
-                "transactions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.MerchantTransaction"
-                    }
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/callback"
                 }
             }
         },
-        "models.MerchantBalance": {
+        "models.WebhookDeliveryAttempt": {
             "type": "object",
             "properties": {
-                "available_balance": {
-                    "type": "string"
+                "ack_message": {
+                    "type": "string",
+                    "example": "Order not found"
                 },
-                "currency": {
-                    "type": "string"
+                "acknowledged": {
+                    "description": "Set when the body was an acknowledgement",
+                    "type": "boolean",
+                    "example": false
                 },
-                "last_updated": {
-                    "type": "string"
+                "attempt_number": {
+                    "type": "integer",
+                    "example": 1
                 },
-                "total_deposit": {
-                    "type": "string"
+                "attempted_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:35:01Z"
                 },
-                "total_transaction_count": {
-                    "type": "integer"
+                "error": {
+                    "type": "string",
+                    "example": "callback endpoint returned non-2xx status: 500"
+                },
+                "latency_ms": {
+                    "type": "integer",
+                    "example": 184
+                },
+                "response_body": {
+                    "type": "string",
+                    "example": "Internal Server Error"
+                },
+                "response_code": {
+                    "type": "integer",
+                    "example": 500
                 }
             }
         },
-        "models.MerchantTransaction": {
+        "models.WebhookDeliveryResponse": {
             "type": "object",
             "properties": {
-                "account_number": {
-                    "type": "string"
+                "delivery": {
+                    "$ref": "#/definitions/models.WebhookDelivery"
                 },
-                "amount": {
-                    "type": "string"
+                "message": {
+                    "type": "string",
+                    "example": "Webhook delivery retrieved successfully"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.WebhookEndpoint": {
+            "type": "object",
+            "properties": {
+                "circuit_open": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "consecutive_failures": {
+                    "type": "integer",
+                    "example": 0
                 },
                 "created_at": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
                 },
-                "currency": {
-                    "type": "string"
+                "description": {
+                    "type": "string",
+                    "example": "Order service"
                 },
-                "fee_amount": {
-                    "type": "string"
+                "disabled_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
                 },
-                "id": {
-                    "type": "string"
+                "disabled_reason": {
+                    "type": "string",
+                    "example": "25 consecutive failed deliveries since 2024-01-02T10:30:00Z; last error: unexpected status code 503"
                 },
-                "merchant_id": {
-                    "type": "string"
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "payment_intent_id": {
-                    "type": "string"
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "payment.intent.succeeded",
+                        "payment.intent.failed"
+                    ]
                 },
-                "payment_method": {
-                    "type": "string"
+                "failing_since": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
                 },
-                "processed_at": {
-                    "type": "string"
+                "id": {
+                    "type": "string",
+                    "example": "5d2e7c1a-8b4f-4a3e-9c6d-1f0e2a3b4c5d"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-05T10:30:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks/payments"
+                }
+            }
+        },
+        "models.WebhookEndpointResponse": {
+            "type": "object",
+            "properties": {
+                "endpoint": {
+                    "$ref": "#/definitions/models.WebhookEndpoint"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Webhook endpoint created successfully"
                 },
                 "status": {
-                    "type": "string"
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.WorkerStats": {
+            "type": "object",
+            "properties": {
+                "concurrency": {
+                    "type": "integer",
+                    "example": 4
                 },
-                "third_party_reference": {
-                    "type": "string"
+                "failed": {
+                    "type": "integer",
+                    "example": 3
                 },
-                "updated_at": {
-                    "type": "string"
+                "in_flight": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "prefetch": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "processed": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "models.WorkerStatsResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Worker stats retrieved successfully"
+                },
+                "status": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "worker": {
+                    "$ref": "#/definitions/models.WorkerStats"
                 }
             }
         }
@@ -0,0 +1,208 @@
+// Package contracts holds the message contracts shared by queue publishers and consumers.
+// Every contract carries an explicit schema version; consumers decode through this package so
+// older versions still in flight during a rollout are upgraded to the current shape.
+package contracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TypePaymentIntentCreated = "payment.intent.created"
+	ContentTypeJSON          = "application/json"
+	HeaderSchemaVersion      = "schema_version"
+
+	PaymentIntentCreatedV1 = 1
+	PaymentIntentCreatedV2 = 2
+
+	// PaymentIntentCreatedVersion is the version publishers emit
+	PaymentIntentCreatedVersion = PaymentIntentCreatedV2
+)
+
+var (
+	ErrInvalidMessage           = errors.New("invalid message")
+	ErrUnsupportedSchemaVersion = errors.New("unsupported schema version")
+	ErrUnsupportedContentType   = errors.New("unsupported content type")
+)
+
+// PaymentIntentCreated is the payment.intent.created message, published when an intent is
+// accepted and consumed by the payment worker.
+//
+// Version history:
+//   - v1: payment_intent_id, transaction_id (always empty), amount, currency, timestamp; no version field
+//   - v2: drops transaction_id, adds merchant_id and schema_version
+type PaymentIntentCreated struct {
+	SchemaVersion   int    `json:"schema_version"`
+	PaymentIntentID string `json:"payment_intent_id"`
+	MerchantID      string `json:"merchant_id"`
+	Amount          string `json:"amount"`
+	Currency        string `json:"currency"`
+	Timestamp       string `json:"timestamp"`
+}
+
+// paymentIntentCreatedV1 is the legacy shape still accepted from publishers that predate v2
+type paymentIntentCreatedV1 struct {
+	PaymentIntentID string `json:"payment_intent_id"`
+	TransactionID   string `json:"transaction_id"`
+	Amount          string `json:"amount"`
+	Currency        string `json:"currency"`
+	Timestamp       string `json:"timestamp"`
+}
+
+func NewPaymentIntentCreated(paymentIntentID, merchantID, amount, currency string, createdAt time.Time) *PaymentIntentCreated {
+	return &PaymentIntentCreated{
+		SchemaVersion:   PaymentIntentCreatedVersion,
+		PaymentIntentID: paymentIntentID,
+		MerchantID:      merchantID,
+		Amount:          amount,
+		Currency:        currency,
+		Timestamp:       createdAt.Format(time.RFC3339),
+	}
+}
+
+// Encode validates the message and returns its JSON body together with the headers publishers must set
+func (m *PaymentIntentCreated) Encode() ([]byte, map[string]interface{}, error) {
+	if err := m.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	body, err := json.Marshal(m)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal %s message: %w", TypePaymentIntentCreated, err)
+	}
+
+	return body, map[string]interface{}{HeaderSchemaVersion: m.SchemaVersion}, nil
+}
+
+// Validate checks the message against the current schema
+func (m *PaymentIntentCreated) Validate() error {
+	if m.SchemaVersion != PaymentIntentCreatedVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, m.SchemaVersion)
+	}
+	if m.MerchantID == "" {
+		return fmt.Errorf("%w: merchant_id is required", ErrInvalidMessage)
+	}
+	return validatePaymentIntentFields(m.PaymentIntentID, m.Amount, m.Currency, m.Timestamp)
+}
+
+// DecodePaymentIntentCreated parses and validates a payment.intent.created body of any supported
+// version and returns it upgraded to the current version. The schema_version body field wins over
+// the header; a message with neither is treated as v1.
+func DecodePaymentIntentCreated(contentType string, headers map[string]interface{}, body []byte) (*PaymentIntentCreated, error) {
+	if contentType != "" && !strings.HasPrefix(contentType, ContentTypeJSON) {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+
+	var probe struct {
+		SchemaVersion *int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	version := PaymentIntentCreatedV1
+	if probe.SchemaVersion != nil {
+		version = *probe.SchemaVersion
+	} else if headerVersion, ok := schemaVersionHeader(headers); ok {
+		version = headerVersion
+	}
+
+	switch version {
+	case PaymentIntentCreatedV1:
+		return decodePaymentIntentCreatedV1(body)
+	case PaymentIntentCreatedV2:
+		var message PaymentIntentCreated
+		if err := strictUnmarshal(body, &message); err != nil {
+			return nil, err
+		}
+		message.SchemaVersion = PaymentIntentCreatedV2
+		if err := message.Validate(); err != nil {
+			return nil, err
+		}
+		return &message, nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, version)
+	}
+}
+
+func decodePaymentIntentCreatedV1(body []byte) (*PaymentIntentCreated, error) {
+	var legacy paymentIntentCreatedV1
+	if err := strictUnmarshal(body, &legacy); err != nil {
+		return nil, err
+	}
+	if err := validatePaymentIntentFields(legacy.PaymentIntentID, legacy.Amount, legacy.Currency, legacy.Timestamp); err != nil {
+		return nil, err
+	}
+
+	// v1 never carried the merchant; the worker resolves it from the stored intent
+	return &PaymentIntentCreated{
+		SchemaVersion:   PaymentIntentCreatedVersion,
+		PaymentIntentID: legacy.PaymentIntentID,
+		Amount:          legacy.Amount,
+		Currency:        legacy.Currency,
+		Timestamp:       legacy.Timestamp,
+	}, nil
+}
+
+func validatePaymentIntentFields(paymentIntentID, amount, currency, timestamp string) error {
+	if !strings.HasPrefix(paymentIntentID, "PI-") {
+		return fmt.Errorf("%w: payment_intent_id %q must start with PI-", ErrInvalidMessage, paymentIntentID)
+	}
+
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil || value <= 0 {
+		return fmt.Errorf("%w: amount %q must be a positive decimal", ErrInvalidMessage, amount)
+	}
+
+	if currency != "ETB" && currency != "USD" {
+		return fmt.Errorf("%w: currency %q must be ETB or USD", ErrInvalidMessage, currency)
+	}
+
+	if timestamp != "" {
+		if _, err := time.Parse(time.RFC3339, timestamp); err != nil {
+			return fmt.Errorf("%w: timestamp %q must be RFC 3339", ErrInvalidMessage, timestamp)
+		}
+	}
+
+	return nil
+}
+
+// strictUnmarshal rejects fields the schema does not define, which catches a version mismatch
+// between the body and its declared schema_version
+func strictUnmarshal(body []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	return nil
+}
+
+// schemaVersionHeader reads the header as whatever integer type the broker decoded it to
+func schemaVersionHeader(headers map[string]interface{}) (int, bool) {
+	switch v := headers[HeaderSchemaVersion].(type) {
+	case int:
+		return v, true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,94 @@
+package contracts
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files for the current schema version")
+
+func goldenPath(version int) string {
+	return filepath.Join("testdata", fmt.Sprintf("payment_intent_created_v%d.golden.json", version))
+}
+
+func readGolden(t *testing.T, version int) []byte {
+	t.Helper()
+	body, err := os.ReadFile(goldenPath(version))
+	require.NoError(t, err)
+	return body
+}
+
+func sampleMessage() *PaymentIntentCreated {
+	createdAt := time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)
+	return NewPaymentIntentCreated("PI-ABC123DEF456", "CASM-ABC123", "100.50", "ETB", createdAt)
+}
+
+func TestPaymentIntentCreated_EncodeMatchesGolden(t *testing.T) {
+	body, headers, err := sampleMessage().Encode()
+	require.NoError(t, err)
+	assert.Equal(t, PaymentIntentCreatedVersion, headers[HeaderSchemaVersion])
+
+	if *update {
+		require.NoError(t, os.WriteFile(goldenPath(PaymentIntentCreatedVersion), append(body, '\n'), 0o644))
+	}
+
+	assert.JSONEq(t, string(readGolden(t, PaymentIntentCreatedVersion)), string(body))
+}
+
+func TestDecodePaymentIntentCreated_V2Golden(t *testing.T) {
+	message, err := DecodePaymentIntentCreated(ContentTypeJSON, nil, readGolden(t, PaymentIntentCreatedV2))
+	require.NoError(t, err)
+	assert.Equal(t, sampleMessage(), message)
+}
+
+func TestDecodePaymentIntentCreated_V1GoldenIsUpgraded(t *testing.T) {
+	message, err := DecodePaymentIntentCreated(ContentTypeJSON, nil, readGolden(t, PaymentIntentCreatedV1))
+	require.NoError(t, err)
+
+	expected := sampleMessage()
+	expected.MerchantID = ""
+	assert.Equal(t, expected, message)
+}
+
+func TestDecodePaymentIntentCreated_VersionFromHeader(t *testing.T) {
+	body := []byte(`{"payment_intent_id":"PI-ABC123DEF456","merchant_id":"CASM-ABC123","amount":"100.50","currency":"ETB","timestamp":"2024-01-05T10:30:00Z"}`)
+
+	// Brokers hand header integers back as different types
+	for _, header := range []interface{}{2, int32(2), int64(2), float64(2)} {
+		message, err := DecodePaymentIntentCreated("", map[string]interface{}{HeaderSchemaVersion: header}, body)
+		require.NoError(t, err, "header type %T", header)
+		assert.Equal(t, "CASM-ABC123", message.MerchantID)
+	}
+}
+
+func TestDecodePaymentIntentCreated_Rejects(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		err         error
+	}{
+		{"not json", ContentTypeJSON, `not json`, ErrInvalidMessage},
+		{"wrong content type", "text/plain", `{}`, ErrUnsupportedContentType},
+		{"future version", ContentTypeJSON, `{"schema_version":3}`, ErrUnsupportedSchemaVersion},
+		{"missing merchant", ContentTypeJSON, `{"schema_version":2,"payment_intent_id":"PI-1","amount":"1.00","currency":"ETB"}`, ErrInvalidMessage},
+		{"bad intent id", ContentTypeJSON, `{"schema_version":2,"payment_intent_id":"X-1","merchant_id":"CASM-1","amount":"1.00","currency":"ETB"}`, ErrInvalidMessage},
+		{"negative amount", ContentTypeJSON, `{"schema_version":2,"payment_intent_id":"PI-1","merchant_id":"CASM-1","amount":"-1","currency":"ETB"}`, ErrInvalidMessage},
+		{"bad currency", ContentTypeJSON, `{"schema_version":2,"payment_intent_id":"PI-1","merchant_id":"CASM-1","amount":"1.00","currency":"EUR"}`, ErrInvalidMessage},
+		{"v1 body labelled v2", ContentTypeJSON, `{"schema_version":2,"payment_intent_id":"PI-1","transaction_id":"","merchant_id":"CASM-1","amount":"1.00","currency":"ETB"}`, ErrInvalidMessage},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodePaymentIntentCreated(tc.contentType, nil, []byte(tc.body))
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
@@ -13,22 +13,24 @@ import (
 )
 
 const createMerchantBalance = `-- name: CreateMerchantBalance :one
-INSERT INTO merchant_balances (merchant_id, currency)
-VALUES ($1, $2)
-RETURNING id, merchant_id, currency, available_balance, total_deposit, total_transaction_count, last_updated
+INSERT INTO merchant_balances (merchant_id, currency, mode)
+VALUES ($1, $2, $3)
+RETURNING id, merchant_id, mode, currency, available_balance, total_deposit, total_transaction_count, last_updated
 `
 
 type CreateMerchantBalanceParams struct {
 	MerchantID uuid.UUID    `db:"merchant_id" json:"merchant_id"`
 	Currency   CurrencyType `db:"currency" json:"currency"`
+	Mode       ApiMode      `db:"mode" json:"mode"`
 }
 
 func (q *Queries) CreateMerchantBalance(ctx context.Context, arg *CreateMerchantBalanceParams) (*MerchantBalance, error) {
-	row := q.db.QueryRowContext(ctx, createMerchantBalance, arg.MerchantID, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createMerchantBalance, arg.MerchantID, arg.Currency, arg.Mode)
 	var i MerchantBalance
 	err := row.Scan(
 		&i.ID,
 		&i.MerchantID,
+		&i.Mode,
 		&i.Currency,
 		&i.AvailableBalance,
 		&i.TotalDeposit,
@@ -39,22 +41,24 @@ func (q *Queries) CreateMerchantBalance(ctx context.Context, arg *CreateMerchant
 }
 
 const getMerchantBalance = `-- name: GetMerchantBalance :one
-SELECT id, merchant_id, currency, available_balance, total_deposit, total_transaction_count, last_updated
+SELECT id, merchant_id, mode, currency, available_balance, total_deposit, total_transaction_count, last_updated
 FROM merchant_balances
-WHERE merchant_id = $1 AND currency = $2
+WHERE merchant_id = $1 AND currency = $2 AND mode = $3
 `
 
 type GetMerchantBalanceParams struct {
 	MerchantID uuid.UUID    `db:"merchant_id" json:"merchant_id"`
 	Currency   CurrencyType `db:"currency" json:"currency"`
+	Mode       ApiMode      `db:"mode" json:"mode"`
 }
 
 func (q *Queries) GetMerchantBalance(ctx context.Context, arg *GetMerchantBalanceParams) (*MerchantBalance, error) {
-	row := q.db.QueryRowContext(ctx, getMerchantBalance, arg.MerchantID, arg.Currency)
+	row := q.db.QueryRowContext(ctx, getMerchantBalance, arg.MerchantID, arg.Currency, arg.Mode)
 	var i MerchantBalance
 	err := row.Scan(
 		&i.ID,
 		&i.MerchantID,
+		&i.Mode,
 		&i.Currency,
 		&i.AvailableBalance,
 		&i.TotalDeposit,
@@ -65,13 +69,18 @@ func (q *Queries) GetMerchantBalance(ctx context.Context, arg *GetMerchantBalanc
 }
 
 const getMerchantBalances = `-- name: GetMerchantBalances :many
-SELECT id, merchant_id, currency, available_balance, total_deposit, total_transaction_count, last_updated
+SELECT id, merchant_id, mode, currency, available_balance, total_deposit, total_transaction_count, last_updated
 FROM merchant_balances
-WHERE merchant_id = $1
+WHERE merchant_id = $1 AND mode = $2
 `
 
-func (q *Queries) GetMerchantBalances(ctx context.Context, merchantID uuid.UUID) ([]*MerchantBalance, error) {
-	rows, err := q.db.QueryContext(ctx, getMerchantBalances, merchantID)
+type GetMerchantBalancesParams struct {
+	MerchantID uuid.UUID `db:"merchant_id" json:"merchant_id"`
+	Mode       ApiMode   `db:"mode" json:"mode"`
+}
+
+func (q *Queries) GetMerchantBalances(ctx context.Context, arg *GetMerchantBalancesParams) ([]*MerchantBalance, error) {
+	rows, err := q.db.QueryContext(ctx, getMerchantBalances, arg.MerchantID, arg.Mode)
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +91,7 @@ func (q *Queries) GetMerchantBalances(ctx context.Context, merchantID uuid.UUID)
 		if err := rows.Scan(
 			&i.ID,
 			&i.MerchantID,
+			&i.Mode,
 			&i.Currency,
 			&i.AvailableBalance,
 			&i.TotalDeposit,
@@ -102,15 +112,15 @@ func (q *Queries) GetMerchantBalances(ctx context.Context, merchantID uuid.UUID)
 }
 
 const incrementMerchantBalance = `-- name: IncrementMerchantBalance :one
-INSERT INTO merchant_balances (merchant_id, currency, available_balance, total_deposit, total_transaction_count)
-VALUES ($1, $2, $3::decimal - $4::decimal, $3::decimal, 1)
-ON CONFLICT (merchant_id, currency)
+INSERT INTO merchant_balances (merchant_id, currency, available_balance, total_deposit, total_transaction_count, mode)
+VALUES ($1, $2, $3::decimal - $4::decimal, $3::decimal, 1, $5)
+ON CONFLICT (merchant_id, mode, currency)
 DO UPDATE SET
     available_balance = merchant_balances.available_balance + $3::decimal - $4::decimal,
     total_deposit = merchant_balances.total_deposit + $3::decimal,
     total_transaction_count = merchant_balances.total_transaction_count + 1,
     last_updated = NOW()
-RETURNING id, merchant_id, currency, available_balance, total_deposit, total_transaction_count, last_updated
+RETURNING id, merchant_id, mode, currency, available_balance, total_deposit, total_transaction_count, last_updated
 `
 
 type IncrementMerchantBalanceParams struct {
@@ -118,19 +128,23 @@ type IncrementMerchantBalanceParams struct {
 	Currency   CurrencyType `db:"currency" json:"currency"`
 	Column3    string       `db:"column_3" json:"column_3"`
 	Column4    string       `db:"column_4" json:"column_4"`
+	Mode       ApiMode      `db:"mode" json:"mode"`
 }
 
+// Test and live payments credit separate rows, so sandbox traffic never moves a live balance.
 func (q *Queries) IncrementMerchantBalance(ctx context.Context, arg *IncrementMerchantBalanceParams) (*MerchantBalance, error) {
 	row := q.db.QueryRowContext(ctx, incrementMerchantBalance,
 		arg.MerchantID,
 		arg.Currency,
 		arg.Column3,
 		arg.Column4,
+		arg.Mode,
 	)
 	var i MerchantBalance
 	err := row.Scan(
 		&i.ID,
 		&i.MerchantID,
+		&i.Mode,
 		&i.Currency,
 		&i.AvailableBalance,
 		&i.TotalDeposit,
@@ -143,8 +157,8 @@ func (q *Queries) IncrementMerchantBalance(ctx context.Context, arg *IncrementMe
 const updateMerchantBalance = `-- name: UpdateMerchantBalance :one
 UPDATE merchant_balances
 SET available_balance = $3, total_deposit = $4, total_transaction_count = $5, last_updated = NOW()
-WHERE merchant_id = $1 AND currency = $2
-RETURNING id, merchant_id, currency, available_balance, total_deposit, total_transaction_count, last_updated
+WHERE merchant_id = $1 AND currency = $2 AND mode = $6
+RETURNING id, merchant_id, mode, currency, available_balance, total_deposit, total_transaction_count, last_updated
 `
 
 type UpdateMerchantBalanceParams struct {
@@ -153,6 +167,7 @@ type UpdateMerchantBalanceParams struct {
 	AvailableBalance      sql.NullString `db:"available_balance" json:"available_balance"`
 	TotalDeposit          sql.NullString `db:"total_deposit" json:"total_deposit"`
 	TotalTransactionCount sql.NullInt32  `db:"total_transaction_count" json:"total_transaction_count"`
+	Mode                  ApiMode        `db:"mode" json:"mode"`
 }
 
 func (q *Queries) UpdateMerchantBalance(ctx context.Context, arg *UpdateMerchantBalanceParams) (*MerchantBalance, error) {
@@ -162,11 +177,13 @@ func (q *Queries) UpdateMerchantBalance(ctx context.Context, arg *UpdateMerchant
 		arg.AvailableBalance,
 		arg.TotalDeposit,
 		arg.TotalTransactionCount,
+		arg.Mode,
 	)
 	var i MerchantBalance
 	err := row.Scan(
 		&i.ID,
 		&i.MerchantID,
+		&i.Mode,
 		&i.Currency,
 		&i.AvailableBalance,
 		&i.TotalDeposit,
@@ -10,12 +10,49 @@ import (
 	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const confirmMerchantEmail = `-- name: ConfirmMerchantEmail :one
+UPDATE merchants
+SET email = $2,
+    email_verified_at = NOW(),
+    pending_email = CASE WHEN pending_email = $2 THEN NULL ELSE pending_email END,
+    updated_at = NOW()
+WHERE merchant_id = $1
+  AND (pending_email = $2 OR (email = $2 AND email_verified_at IS NULL))
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier
+`
+
+type ConfirmMerchantEmailParams struct {
+	MerchantID string `db:"merchant_id" json:"merchant_id"`
+	Email      string `db:"email" json:"email"`
+}
+
+// Marks email as verified. It succeeds only for the address the token was issued for: either the
+// pending address, which then replaces the current one, or the current address while unverified.
+func (q *Queries) ConfirmMerchantEmail(ctx context.Context, arg *ConfirmMerchantEmailParams) (*Merchant, error) {
+	row := q.db.QueryRowContext(ctx, confirmMerchantEmail, arg.MerchantID, arg.Email)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.MerchantID,
+		&i.Name,
+		&i.Email,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
+	)
+	return &i, err
+}
+
 const createMerchant = `-- name: CreateMerchant :one
 INSERT INTO merchants (merchant_id, name, email)
 VALUES ($1, $2, $3)
-RETURNING id, merchant_id, name, email, status, created_at, updated_at
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier
 `
 
 type CreateMerchantParams struct {
@@ -35,41 +72,58 @@ func (q *Queries) CreateMerchant(ctx context.Context, arg *CreateMerchantParams)
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
 	)
 	return &i, err
 }
 
 const createMerchantAPIKey = `-- name: CreateMerchantAPIKey :one
-INSERT INTO merchant_api_keys (merchant_id, api_key, secret_key)
-VALUES ($1, $2, $3)
-RETURNING id, merchant_id, api_key, status, created_at, expires_at
+INSERT INTO merchant_api_keys (merchant_id, key_prefix, key_hash, key_salt, last_four, label, scopes, mode)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, merchant_id, key_prefix, key_hash, key_salt, last_four, status, created_at, expires_at, label, last_used_at, revoked_at, must_rotate, scopes, mode
 `
 
 type CreateMerchantAPIKeyParams struct {
-	MerchantID uuid.UUID `db:"merchant_id" json:"merchant_id"`
-	ApiKey     string    `db:"api_key" json:"api_key"`
-	SecretKey  string    `db:"secret_key" json:"secret_key"`
+	MerchantID uuid.UUID      `db:"merchant_id" json:"merchant_id"`
+	KeyPrefix  sql.NullString `db:"key_prefix" json:"key_prefix"`
+	KeyHash    string         `db:"key_hash" json:"key_hash"`
+	KeySalt    string         `db:"key_salt" json:"key_salt"`
+	LastFour   string         `db:"last_four" json:"last_four"`
+	Label      sql.NullString `db:"label" json:"label"`
+	Scopes     []string       `db:"scopes" json:"scopes"`
+	Mode       ApiMode        `db:"mode" json:"mode"`
 }
 
-type CreateMerchantAPIKeyRow struct {
-	ID         uuid.UUID        `db:"id" json:"id"`
-	MerchantID uuid.UUID        `db:"merchant_id" json:"merchant_id"`
-	ApiKey     string           `db:"api_key" json:"api_key"`
-	Status     NullApiKeyStatus `db:"status" json:"status"`
-	CreatedAt  sql.NullTime     `db:"created_at" json:"created_at"`
-	ExpiresAt  sql.NullTime     `db:"expires_at" json:"expires_at"`
-}
-
-func (q *Queries) CreateMerchantAPIKey(ctx context.Context, arg *CreateMerchantAPIKeyParams) (*CreateMerchantAPIKeyRow, error) {
-	row := q.db.QueryRowContext(ctx, createMerchantAPIKey, arg.MerchantID, arg.ApiKey, arg.SecretKey)
-	var i CreateMerchantAPIKeyRow
+func (q *Queries) CreateMerchantAPIKey(ctx context.Context, arg *CreateMerchantAPIKeyParams) (*MerchantApiKey, error) {
+	row := q.db.QueryRowContext(ctx, createMerchantAPIKey,
+		arg.MerchantID,
+		arg.KeyPrefix,
+		arg.KeyHash,
+		arg.KeySalt,
+		arg.LastFour,
+		arg.Label,
+		pq.Array(arg.Scopes),
+		arg.Mode,
+	)
+	var i MerchantApiKey
 	err := row.Scan(
 		&i.ID,
 		&i.MerchantID,
-		&i.ApiKey,
+		&i.KeyPrefix,
+		&i.KeyHash,
+		&i.KeySalt,
+		&i.LastFour,
 		&i.Status,
 		&i.CreatedAt,
 		&i.ExpiresAt,
+		&i.Label,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.MustRotate,
+		pq.Array(&i.Scopes),
+		&i.Mode,
 	)
 	return &i, err
 }
@@ -103,45 +157,74 @@ func (q *Queries) GetMerchant(ctx context.Context, id uuid.UUID) (*GetMerchantRo
 	return &i, err
 }
 
-const getMerchantByAPIKey = `-- name: GetMerchantByAPIKey :one
-SELECT m.id, m.name, m.email, m.status, m.created_at, m.updated_at
+const getMerchantByAPIKeyPrefix = `-- name: GetMerchantByAPIKeyPrefix :one
+SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at, m.email_verified_at, m.pending_email, m.tier,
+       mak.id as api_key_id, mak.key_hash, mak.key_salt, mak.must_rotate, mak.scopes, mak.mode, mak.status as api_key_status, mak.created_at as api_key_created_at
 FROM merchants m
 JOIN merchant_api_keys mak ON m.id = mak.merchant_id
-WHERE mak.api_key = $1 AND mak.status = 'active' AND m.status = 'active'
+WHERE mak.key_prefix = $1 AND mak.status = 'active' AND (mak.expires_at IS NULL OR mak.expires_at > NOW())
+  AND m.status IN ('active', 'suspended')
 `
 
-type GetMerchantByAPIKeyRow struct {
-	ID        uuid.UUID          `db:"id" json:"id"`
-	Name      string             `db:"name" json:"name"`
-	Email     string             `db:"email" json:"email"`
-	Status    NullMerchantStatus `db:"status" json:"status"`
-	CreatedAt sql.NullTime       `db:"created_at" json:"created_at"`
-	UpdatedAt sql.NullTime       `db:"updated_at" json:"updated_at"`
+type GetMerchantByAPIKeyPrefixRow struct {
+	ID              uuid.UUID          `db:"id" json:"id"`
+	MerchantID      string             `db:"merchant_id" json:"merchant_id"`
+	Name            string             `db:"name" json:"name"`
+	Email           string             `db:"email" json:"email"`
+	Status          NullMerchantStatus `db:"status" json:"status"`
+	CreatedAt       sql.NullTime       `db:"created_at" json:"created_at"`
+	UpdatedAt       sql.NullTime       `db:"updated_at" json:"updated_at"`
+	EmailVerifiedAt sql.NullTime       `db:"email_verified_at" json:"email_verified_at"`
+	PendingEmail    sql.NullString     `db:"pending_email" json:"pending_email"`
+	Tier            MerchantTier       `db:"tier" json:"tier"`
+	ApiKeyID        uuid.UUID          `db:"api_key_id" json:"api_key_id"`
+	KeyHash         string             `db:"key_hash" json:"key_hash"`
+	KeySalt         string             `db:"key_salt" json:"key_salt"`
+	MustRotate      bool               `db:"must_rotate" json:"must_rotate"`
+	Scopes          []string           `db:"scopes" json:"scopes"`
+	Mode            ApiMode            `db:"mode" json:"mode"`
+	ApiKeyStatus    NullApiKeyStatus   `db:"api_key_status" json:"api_key_status"`
+	ApiKeyCreatedAt sql.NullTime       `db:"api_key_created_at" json:"api_key_created_at"`
 }
 
-func (q *Queries) GetMerchantByAPIKey(ctx context.Context, apiKey string) (*GetMerchantByAPIKeyRow, error) {
-	row := q.db.QueryRowContext(ctx, getMerchantByAPIKey, apiKey)
-	var i GetMerchantByAPIKeyRow
+// The caller verifies the key against key_hash. Suspended merchants authenticate so they can still
+// read their account; inactive ones do not.
+func (q *Queries) GetMerchantByAPIKeyPrefix(ctx context.Context, keyPrefix sql.NullString) (*GetMerchantByAPIKeyPrefixRow, error) {
+	row := q.db.QueryRowContext(ctx, getMerchantByAPIKeyPrefix, keyPrefix)
+	var i GetMerchantByAPIKeyPrefixRow
 	err := row.Scan(
 		&i.ID,
+		&i.MerchantID,
 		&i.Name,
 		&i.Email,
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
+		&i.ApiKeyID,
+		&i.KeyHash,
+		&i.KeySalt,
+		&i.MustRotate,
+		pq.Array(&i.Scopes),
+		&i.Mode,
+		&i.ApiKeyStatus,
+		&i.ApiKeyCreatedAt,
 	)
 	return &i, err
 }
 
-const getMerchantByAPIKeyValue = `-- name: GetMerchantByAPIKeyValue :one
-SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at,
-       mak.api_key, mak.secret_key, mak.status as api_key_status, mak.created_at as api_key_created_at
+const getMerchantByLegacyAPIKey = `-- name: GetMerchantByLegacyAPIKey :one
+SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at, m.email_verified_at, m.pending_email, m.tier,
+       mak.id as api_key_id, mak.key_hash, mak.key_salt, mak.must_rotate, mak.scopes, mak.mode, mak.status as api_key_status, mak.created_at as api_key_created_at
 FROM merchants m
 JOIN merchant_api_keys mak ON m.id = mak.merchant_id
-WHERE mak.api_key = $1 AND mak.status = 'active' AND m.status = 'active'
+WHERE mak.key_hash = $1 AND mak.key_prefix IS NULL AND mak.status = 'active' AND (mak.expires_at IS NULL OR mak.expires_at > NOW())
+  AND m.status IN ('active', 'suspended')
 `
 
-type GetMerchantByAPIKeyValueRow struct {
+type GetMerchantByLegacyAPIKeyRow struct {
 	ID              uuid.UUID          `db:"id" json:"id"`
 	MerchantID      string             `db:"merchant_id" json:"merchant_id"`
 	Name            string             `db:"name" json:"name"`
@@ -149,15 +232,23 @@ type GetMerchantByAPIKeyValueRow struct {
 	Status          NullMerchantStatus `db:"status" json:"status"`
 	CreatedAt       sql.NullTime       `db:"created_at" json:"created_at"`
 	UpdatedAt       sql.NullTime       `db:"updated_at" json:"updated_at"`
-	ApiKey          string             `db:"api_key" json:"api_key"`
-	SecretKey       string             `db:"secret_key" json:"secret_key"`
+	EmailVerifiedAt sql.NullTime       `db:"email_verified_at" json:"email_verified_at"`
+	PendingEmail    sql.NullString     `db:"pending_email" json:"pending_email"`
+	Tier            MerchantTier       `db:"tier" json:"tier"`
+	ApiKeyID        uuid.UUID          `db:"api_key_id" json:"api_key_id"`
+	KeyHash         string             `db:"key_hash" json:"key_hash"`
+	KeySalt         string             `db:"key_salt" json:"key_salt"`
+	MustRotate      bool               `db:"must_rotate" json:"must_rotate"`
+	Scopes          []string           `db:"scopes" json:"scopes"`
+	Mode            ApiMode            `db:"mode" json:"mode"`
 	ApiKeyStatus    NullApiKeyStatus   `db:"api_key_status" json:"api_key_status"`
 	ApiKeyCreatedAt sql.NullTime       `db:"api_key_created_at" json:"api_key_created_at"`
 }
 
-func (q *Queries) GetMerchantByAPIKeyValue(ctx context.Context, apiKey string) (*GetMerchantByAPIKeyValueRow, error) {
-	row := q.db.QueryRowContext(ctx, getMerchantByAPIKeyValue, apiKey)
-	var i GetMerchantByAPIKeyValueRow
+// Keys issued before hash-only storage have no prefix and an unsalted hash.
+func (q *Queries) GetMerchantByLegacyAPIKey(ctx context.Context, keyHash string) (*GetMerchantByLegacyAPIKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, getMerchantByLegacyAPIKey, keyHash)
+	var i GetMerchantByLegacyAPIKeyRow
 	err := row.Scan(
 		&i.ID,
 		&i.MerchantID,
@@ -166,8 +257,15 @@ func (q *Queries) GetMerchantByAPIKeyValue(ctx context.Context, apiKey string) (
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.ApiKey,
-		&i.SecretKey,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
+		&i.ApiKeyID,
+		&i.KeyHash,
+		&i.KeySalt,
+		&i.MustRotate,
+		pq.Array(&i.Scopes),
+		&i.Mode,
 		&i.ApiKeyStatus,
 		&i.ApiKeyCreatedAt,
 	)
@@ -175,7 +273,7 @@ func (q *Queries) GetMerchantByAPIKeyValue(ctx context.Context, apiKey string) (
 }
 
 const getMerchantByMerchantID = `-- name: GetMerchantByMerchantID :one
-SELECT id, merchant_id, name, email, status, created_at, updated_at
+SELECT id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier
 FROM merchants
 WHERE merchant_id = $1
 `
@@ -191,18 +289,75 @@ func (q *Queries) GetMerchantByMerchantID(ctx context.Context, merchantID string
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
+	)
+	return &i, err
+}
+
+const getMerchantSummary = `-- name: GetMerchantSummary :one
+SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at, m.email_verified_at, m.pending_email, m.tier,
+       (SELECT COUNT(*) FROM merchant_api_keys mak
+        WHERE mak.merchant_id = m.id AND mak.status = 'active' AND (mak.expires_at IS NULL OR mak.expires_at > NOW())) AS active_api_keys,
+       (SELECT COUNT(*) FROM payment_intents pi WHERE pi.merchant_id = m.merchant_id AND pi.status = 'pending') AS pending_intents,
+       (SELECT MAX(mse.created_at) FROM merchant_status_events mse WHERE mse.merchant_id = m.id) AS status_changed_at
+FROM merchants m
+WHERE m.merchant_id = $1
+`
+
+type GetMerchantSummaryRow struct {
+	ID              uuid.UUID          `db:"id" json:"id"`
+	MerchantID      string             `db:"merchant_id" json:"merchant_id"`
+	Name            string             `db:"name" json:"name"`
+	Email           string             `db:"email" json:"email"`
+	Status          NullMerchantStatus `db:"status" json:"status"`
+	CreatedAt       sql.NullTime       `db:"created_at" json:"created_at"`
+	UpdatedAt       sql.NullTime       `db:"updated_at" json:"updated_at"`
+	EmailVerifiedAt sql.NullTime       `db:"email_verified_at" json:"email_verified_at"`
+	PendingEmail    sql.NullString     `db:"pending_email" json:"pending_email"`
+	Tier            MerchantTier       `db:"tier" json:"tier"`
+	ActiveApiKeys   int64              `db:"active_api_keys" json:"active_api_keys"`
+	PendingIntents  int64              `db:"pending_intents" json:"pending_intents"`
+	StatusChangedAt sql.NullTime       `db:"status_changed_at" json:"status_changed_at"`
+}
+
+func (q *Queries) GetMerchantSummary(ctx context.Context, merchantID string) (*GetMerchantSummaryRow, error) {
+	row := q.db.QueryRowContext(ctx, getMerchantSummary, merchantID)
+	var i GetMerchantSummaryRow
+	err := row.Scan(
+		&i.ID,
+		&i.MerchantID,
+		&i.Name,
+		&i.Email,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
+		&i.ActiveApiKeys,
+		&i.PendingIntents,
+		&i.StatusChangedAt,
 	)
 	return &i, err
 }
 
 const getMerchantWithAPIKey = `-- name: GetMerchantWithAPIKey :one
-SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at,
-       mak.api_key, mak.secret_key, mak.status as api_key_status, mak.created_at as api_key_created_at
+SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at, m.email_verified_at, m.pending_email, m.tier,
+       mak.key_prefix, mak.last_four, mak.status as api_key_status, mak.created_at as api_key_created_at
 FROM merchants m
 JOIN merchant_api_keys mak ON m.id = mak.merchant_id
-WHERE m.merchant_id = $1 AND mak.status = 'active'
+WHERE m.merchant_id = $1 AND mak.mode = $2 AND mak.status = 'active' AND (mak.expires_at IS NULL OR mak.expires_at > NOW())
+ORDER BY mak.created_at DESC
+LIMIT 1
 `
 
+type GetMerchantWithAPIKeyParams struct {
+	MerchantID string  `db:"merchant_id" json:"merchant_id"`
+	Mode       ApiMode `db:"mode" json:"mode"`
+}
+
 type GetMerchantWithAPIKeyRow struct {
 	ID              uuid.UUID          `db:"id" json:"id"`
 	MerchantID      string             `db:"merchant_id" json:"merchant_id"`
@@ -211,14 +366,17 @@ type GetMerchantWithAPIKeyRow struct {
 	Status          NullMerchantStatus `db:"status" json:"status"`
 	CreatedAt       sql.NullTime       `db:"created_at" json:"created_at"`
 	UpdatedAt       sql.NullTime       `db:"updated_at" json:"updated_at"`
-	ApiKey          string             `db:"api_key" json:"api_key"`
-	SecretKey       string             `db:"secret_key" json:"secret_key"`
+	EmailVerifiedAt sql.NullTime       `db:"email_verified_at" json:"email_verified_at"`
+	PendingEmail    sql.NullString     `db:"pending_email" json:"pending_email"`
+	Tier            MerchantTier       `db:"tier" json:"tier"`
+	KeyPrefix       sql.NullString     `db:"key_prefix" json:"key_prefix"`
+	LastFour        string             `db:"last_four" json:"last_four"`
 	ApiKeyStatus    NullApiKeyStatus   `db:"api_key_status" json:"api_key_status"`
 	ApiKeyCreatedAt sql.NullTime       `db:"api_key_created_at" json:"api_key_created_at"`
 }
 
-func (q *Queries) GetMerchantWithAPIKey(ctx context.Context, merchantID string) (*GetMerchantWithAPIKeyRow, error) {
-	row := q.db.QueryRowContext(ctx, getMerchantWithAPIKey, merchantID)
+func (q *Queries) GetMerchantWithAPIKey(ctx context.Context, arg *GetMerchantWithAPIKeyParams) (*GetMerchantWithAPIKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, getMerchantWithAPIKey, arg.MerchantID, arg.Mode)
 	var i GetMerchantWithAPIKeyRow
 	err := row.Scan(
 		&i.ID,
@@ -228,10 +386,260 @@ func (q *Queries) GetMerchantWithAPIKey(ctx context.Context, merchantID string)
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.ApiKey,
-		&i.SecretKey,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
+		&i.KeyPrefix,
+		&i.LastFour,
 		&i.ApiKeyStatus,
 		&i.ApiKeyCreatedAt,
 	)
 	return &i, err
 }
+
+const listMerchants = `-- name: ListMerchants :many
+SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at, m.email_verified_at, m.pending_email, m.tier,
+       (SELECT COUNT(*) FROM merchant_api_keys mak
+        WHERE mak.merchant_id = m.id AND mak.status = 'active' AND (mak.expires_at IS NULL OR mak.expires_at > NOW())) AS active_api_keys,
+       (SELECT COUNT(*) FROM payment_intents pi WHERE pi.merchant_id = m.merchant_id AND pi.status = 'pending') AS pending_intents,
+       (SELECT MAX(mse.created_at) FROM merchant_status_events mse WHERE mse.merchant_id = m.id) AS status_changed_at
+FROM merchants m
+WHERE ($1::merchant_status IS NULL OR m.status = $1)
+  AND ($2::text IS NULL
+       OR m.merchant_id ILIKE '%' || $2 || '%'
+       OR m.name ILIKE '%' || $2 || '%'
+       OR m.email ILIKE '%' || $2 || '%')
+ORDER BY m.created_at DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListMerchantsParams struct {
+	Status    NullMerchantStatus `db:"status" json:"status"`
+	Query     sql.NullString     `db:"query" json:"query"`
+	RowLimit  int32              `db:"row_limit" json:"row_limit"`
+	RowOffset int32              `db:"row_offset" json:"row_offset"`
+}
+
+type ListMerchantsRow struct {
+	ID              uuid.UUID          `db:"id" json:"id"`
+	MerchantID      string             `db:"merchant_id" json:"merchant_id"`
+	Name            string             `db:"name" json:"name"`
+	Email           string             `db:"email" json:"email"`
+	Status          NullMerchantStatus `db:"status" json:"status"`
+	CreatedAt       sql.NullTime       `db:"created_at" json:"created_at"`
+	UpdatedAt       sql.NullTime       `db:"updated_at" json:"updated_at"`
+	EmailVerifiedAt sql.NullTime       `db:"email_verified_at" json:"email_verified_at"`
+	PendingEmail    sql.NullString     `db:"pending_email" json:"pending_email"`
+	Tier            MerchantTier       `db:"tier" json:"tier"`
+	ActiveApiKeys   int64              `db:"active_api_keys" json:"active_api_keys"`
+	PendingIntents  int64              `db:"pending_intents" json:"pending_intents"`
+	StatusChangedAt sql.NullTime       `db:"status_changed_at" json:"status_changed_at"`
+}
+
+// query matches the merchant ID, name or email, ignoring case.
+func (q *Queries) ListMerchants(ctx context.Context, arg *ListMerchantsParams) ([]*ListMerchantsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMerchants,
+		arg.Status,
+		arg.Query,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListMerchantsRow{}
+	for rows.Next() {
+		var i ListMerchantsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MerchantID,
+			&i.Name,
+			&i.Email,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
+			&i.PendingEmail,
+			&i.Tier,
+			&i.ActiveApiKeys,
+			&i.PendingIntents,
+			&i.StatusChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockMerchantByMerchantID = `-- name: LockMerchantByMerchantID :one
+SELECT id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier
+FROM merchants
+WHERE merchant_id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockMerchantByMerchantID(ctx context.Context, merchantID string) (*Merchant, error) {
+	row := q.db.QueryRowContext(ctx, lockMerchantByMerchantID, merchantID)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.MerchantID,
+		&i.Name,
+		&i.Email,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
+	)
+	return &i, err
+}
+
+const merchantEmailInUse = `-- name: MerchantEmailInUse :one
+SELECT EXISTS (
+    SELECT 1 FROM merchants
+    WHERE email = $1 AND merchant_id <> $2
+) AS in_use
+`
+
+type MerchantEmailInUseParams struct {
+	Email      string `db:"email" json:"email"`
+	MerchantID string `db:"merchant_id" json:"merchant_id"`
+}
+
+func (q *Queries) MerchantEmailInUse(ctx context.Context, arg *MerchantEmailInUseParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, merchantEmailInUse, arg.Email, arg.MerchantID)
+	var in_use bool
+	err := row.Scan(&in_use)
+	return in_use, err
+}
+
+const updateMerchantName = `-- name: UpdateMerchantName :one
+UPDATE merchants
+SET name = $2, updated_at = NOW()
+WHERE merchant_id = $1
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier
+`
+
+type UpdateMerchantNameParams struct {
+	MerchantID string `db:"merchant_id" json:"merchant_id"`
+	Name       string `db:"name" json:"name"`
+}
+
+func (q *Queries) UpdateMerchantName(ctx context.Context, arg *UpdateMerchantNameParams) (*Merchant, error) {
+	row := q.db.QueryRowContext(ctx, updateMerchantName, arg.MerchantID, arg.Name)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.MerchantID,
+		&i.Name,
+		&i.Email,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
+	)
+	return &i, err
+}
+
+const updateMerchantPendingEmail = `-- name: UpdateMerchantPendingEmail :one
+UPDATE merchants
+SET pending_email = $2, updated_at = NOW()
+WHERE merchant_id = $1
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier
+`
+
+type UpdateMerchantPendingEmailParams struct {
+	MerchantID   string         `db:"merchant_id" json:"merchant_id"`
+	PendingEmail sql.NullString `db:"pending_email" json:"pending_email"`
+}
+
+// A NULL pending_email cancels an unconfirmed change
+func (q *Queries) UpdateMerchantPendingEmail(ctx context.Context, arg *UpdateMerchantPendingEmailParams) (*Merchant, error) {
+	row := q.db.QueryRowContext(ctx, updateMerchantPendingEmail, arg.MerchantID, arg.PendingEmail)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.MerchantID,
+		&i.Name,
+		&i.Email,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
+	)
+	return &i, err
+}
+
+const updateMerchantStatus = `-- name: UpdateMerchantStatus :one
+UPDATE merchants
+SET status = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier
+`
+
+type UpdateMerchantStatusParams struct {
+	ID     uuid.UUID          `db:"id" json:"id"`
+	Status NullMerchantStatus `db:"status" json:"status"`
+}
+
+func (q *Queries) UpdateMerchantStatus(ctx context.Context, arg *UpdateMerchantStatusParams) (*Merchant, error) {
+	row := q.db.QueryRowContext(ctx, updateMerchantStatus, arg.ID, arg.Status)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.MerchantID,
+		&i.Name,
+		&i.Email,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
+	)
+	return &i, err
+}
+
+const updateMerchantTier = `-- name: UpdateMerchantTier :one
+UPDATE merchants
+SET tier = $2, updated_at = NOW()
+WHERE merchant_id = $1
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier
+`
+
+type UpdateMerchantTierParams struct {
+	MerchantID string       `db:"merchant_id" json:"merchant_id"`
+	Tier       MerchantTier `db:"tier" json:"tier"`
+}
+
+func (q *Queries) UpdateMerchantTier(ctx context.Context, arg *UpdateMerchantTierParams) (*Merchant, error) {
+	row := q.db.QueryRowContext(ctx, updateMerchantTier, arg.MerchantID, arg.Tier)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.MerchantID,
+		&i.Name,
+		&i.Email,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.Tier,
+	)
+	return &i, err
+}
@@ -0,0 +1,32 @@
+-- Moves merchant_api_keys to hash-only storage on databases created before it. Fresh databases get
+-- the new layout from schema.sql and do not need this.
+--
+-- Existing keys cannot be given a lookup prefix, because the plain key is not stored. They keep
+-- their unsalted hash, which is still accepted, but are marked must_rotate and stop working 30 days
+-- after the migration. Merchants replace them with POST /account/api-keys/{id}/rotate.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/001_hash_only_api_keys.sql
+
+BEGIN;
+
+ALTER TABLE merchant_api_keys
+    ADD COLUMN key_prefix VARCHAR(32) UNIQUE,
+    ADD COLUMN key_hash VARCHAR(255),
+    ADD COLUMN key_salt VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN last_four VARCHAR(4) NOT NULL DEFAULT '',
+    ADD COLUMN must_rotate BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE merchant_api_keys
+SET key_hash = api_key,
+    must_rotate = TRUE,
+    expires_at = LEAST(COALESCE(expires_at, 'infinity'), NOW() + INTERVAL '30 days');
+
+ALTER TABLE merchant_api_keys ALTER COLUMN key_hash SET NOT NULL;
+
+-- Drops the reversibly encrypted copy of every key
+ALTER TABLE merchant_api_keys DROP COLUMN secret_key;
+ALTER TABLE merchant_api_keys DROP COLUMN api_key;
+
+CREATE INDEX idx_merchant_api_keys_legacy_hash ON merchant_api_keys(key_hash) WHERE key_prefix IS NULL;
+
+COMMIT;
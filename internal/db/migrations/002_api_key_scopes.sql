@@ -0,0 +1,21 @@
+-- Adds scopes to merchant_api_keys on databases created before them. Fresh databases get the column
+-- from schema.sql and do not need this.
+--
+-- Existing keys were able to call every route, so they are given every scope and keep working
+-- unchanged. Merchants can replace them with narrower keys from POST /account/api-keys.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/002_api_key_scopes.sql
+
+BEGIN;
+
+ALTER TABLE merchant_api_keys ADD COLUMN scopes TEXT[];
+
+UPDATE merchant_api_keys
+SET scopes = ARRAY[
+    'intents:read', 'intents:write', 'balances:read', 'refunds:write', 'payouts:write',
+    'webhooks:read', 'webhooks:write', 'keys:read', 'keys:write'
+];
+
+ALTER TABLE merchant_api_keys ALTER COLUMN scopes SET NOT NULL;
+
+COMMIT;
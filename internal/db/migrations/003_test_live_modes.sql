@@ -0,0 +1,35 @@
+-- Adds test and live modes on databases created before them. Fresh databases get the columns from
+-- schema.sql and do not need this.
+--
+-- Everything that exists today was created with a live key, so existing keys, intents,
+-- transactions and balances are tagged live. Merchants create test keys from
+-- POST /account/api-keys with "mode": "test".
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/003_test_live_modes.sql
+
+BEGIN;
+
+CREATE TYPE api_mode AS ENUM ('test', 'live');
+
+ALTER TABLE merchant_api_keys ADD COLUMN mode api_mode NOT NULL DEFAULT 'live';
+ALTER TABLE merchant_api_keys ALTER COLUMN mode DROP DEFAULT;
+
+ALTER TABLE payment_intents
+    ADD COLUMN mode api_mode NOT NULL DEFAULT 'live',
+    ADD COLUMN account_number VARCHAR(50);
+ALTER TABLE payment_intents ALTER COLUMN mode DROP DEFAULT;
+
+ALTER TABLE payment_transactions ADD COLUMN mode api_mode NOT NULL DEFAULT 'live';
+ALTER TABLE payment_transactions ALTER COLUMN mode DROP DEFAULT;
+
+ALTER TABLE merchant_balances ADD COLUMN mode api_mode NOT NULL DEFAULT 'live';
+ALTER TABLE merchant_balances ALTER COLUMN mode DROP DEFAULT;
+ALTER TABLE merchant_balances DROP CONSTRAINT merchant_balances_merchant_id_currency_key;
+ALTER TABLE merchant_balances ADD CONSTRAINT merchant_balances_merchant_id_mode_currency_key UNIQUE (merchant_id, mode, currency);
+
+DROP INDEX idx_payment_intents_merchant;
+CREATE INDEX idx_payment_intents_merchant ON payment_intents(merchant_id, mode);
+DROP INDEX idx_payment_transactions_merchant;
+CREATE INDEX idx_payment_transactions_merchant ON payment_transactions(merchant_id, mode);
+
+COMMIT;
@@ -0,0 +1,23 @@
+-- Adds the merchant status history on databases created before it. Fresh databases get the table
+-- from schema.sql and do not need this.
+--
+-- Status changes made before this migration were not recorded, so the history of existing
+-- merchants starts empty.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/004_merchant_status_events.sql
+
+BEGIN;
+
+CREATE TABLE merchant_status_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    from_status merchant_status NOT NULL,
+    to_status merchant_status NOT NULL,
+    reason TEXT NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_merchant_status_events_merchant ON merchant_status_events(merchant_id, created_at DESC);
+
+COMMIT;
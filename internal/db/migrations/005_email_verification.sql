@@ -0,0 +1,20 @@
+-- Adds email verification and pending email changes on databases created before them. Fresh
+-- databases get the columns from schema.sql and do not need this.
+--
+-- Existing merchants are treated as verified so their live keys keep working, and every key that
+-- can manage API keys is also given the new account:write scope so it can edit the profile.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/005_email_verification.sql
+
+BEGIN;
+
+ALTER TABLE merchants ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE merchants ADD COLUMN pending_email VARCHAR(255);
+
+UPDATE merchants SET email_verified_at = COALESCE(created_at, NOW());
+
+UPDATE merchant_api_keys
+SET scopes = array_append(scopes, 'account:write')
+WHERE 'keys:write' = ANY(scopes) AND NOT 'account:write' = ANY(scopes);
+
+COMMIT;
@@ -0,0 +1,51 @@
+-- Adds dashboard users and sessions on databases created before them. Fresh databases get the
+-- tables from schema.sql and do not need this.
+--
+-- Every key that can manage API keys is also given the users:read and users:write scopes, so a
+-- merchant can invite the first owner with the key it already has.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/006_dashboard_users.sql
+
+BEGIN;
+
+CREATE TYPE dashboard_role AS ENUM ('owner', 'admin', 'developer', 'finance', 'read_only');
+CREATE TYPE merchant_user_status AS ENUM ('invited', 'active', 'disabled');
+
+CREATE TABLE merchant_users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    role dashboard_role NOT NULL,
+    status merchant_user_status NOT NULL DEFAULT 'invited',
+    password_hash TEXT,
+    totp_secret TEXT,
+    totp_enabled_at TIMESTAMP WITH TIME ZONE,
+    invite_token_hash VARCHAR(64) UNIQUE,
+    invite_expires_at TIMESTAMP WITH TIME ZONE,
+    invited_by VARCHAR(255) NOT NULL,
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE user_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES merchant_users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(500),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    last_seen_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_merchant_users_merchant ON merchant_users(merchant_id);
+CREATE INDEX idx_user_sessions_user ON user_sessions(user_id);
+
+UPDATE merchant_api_keys
+SET scopes = scopes || ARRAY['users:read', 'users:write']
+WHERE 'keys:write' = ANY(scopes) AND NOT 'users:write' = ANY(scopes);
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Adds merchant tiers, which select the request rate limit, on databases created before them.
+-- Fresh databases get the column from schema.sql and do not need this.
+--
+-- Existing merchants start on the standard tier.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/007_merchant_tiers.sql
+
+BEGIN;
+
+CREATE TYPE merchant_tier AS ENUM ('standard', 'growth', 'enterprise');
+
+ALTER TABLE merchants ADD COLUMN tier merchant_tier NOT NULL DEFAULT 'standard';
+
+COMMIT;
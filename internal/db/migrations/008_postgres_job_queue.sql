@@ -0,0 +1,41 @@
+-- Adds the job queue used by BROKER_DRIVER=postgres on databases created before it. Fresh
+-- databases get the tables from schema.sql and do not need this.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/008_postgres_job_queue.sql
+
+BEGIN;
+
+CREATE TYPE job_status AS ENUM ('queued', 'running', 'done', 'failed');
+
+CREATE TABLE job_bindings (
+    exchange VARCHAR(255) NOT NULL,
+    pattern VARCHAR(255) NOT NULL,
+    queue VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (exchange, pattern, queue)
+);
+
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    queue VARCHAR(255) NOT NULL,
+    exchange VARCHAR(255) NOT NULL,
+    routing_key VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255),
+    message_type VARCHAR(255),
+    content_type VARCHAR(100),
+    headers JSONB,
+    payload BYTEA NOT NULL,
+    status job_status NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 10,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    locked_by VARCHAR(255),
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_jobs_dequeue ON jobs(queue, status, run_at);
+
+COMMIT;
@@ -0,0 +1,22 @@
+-- Adds the domain event outbox on databases created before it. Fresh databases get the table from
+-- schema.sql and do not need this.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/009_event_outbox.sql
+
+BEGIN;
+
+CREATE TABLE event_outbox (
+    id UUID PRIMARY KEY,
+    event_type VARCHAR(255) NOT NULL,
+    exchange VARCHAR(255) NOT NULL,
+    routing_key VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_event_outbox_unpublished ON event_outbox(created_at) WHERE published_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,29 @@
+-- Adds the scheduler's run history and the expired payment status on databases created before
+-- them. Fresh databases get both from schema.sql and do not need this.
+--
+-- Intents that were left pending past expires_at are marked expired by the next expiry sweep.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/010_scheduled_jobs.sql
+
+-- Outside the transaction, since older Postgres versions cannot add an enum value inside one
+ALTER TYPE payment_status ADD VALUE 'expired';
+
+BEGIN;
+
+CREATE TYPE scheduled_run_status AS ENUM ('running', 'succeeded', 'failed');
+
+CREATE TABLE scheduled_job_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_name VARCHAR(100) NOT NULL,
+    trigger_type VARCHAR(20) NOT NULL,
+    status scheduled_run_status NOT NULL DEFAULT 'running',
+    instance VARCHAR(255) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    duration_ms BIGINT,
+    error TEXT
+);
+
+CREATE INDEX idx_scheduled_job_runs_job ON scheduled_job_runs(job_name, started_at DESC);
+
+COMMIT;
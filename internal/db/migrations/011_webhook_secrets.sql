@@ -0,0 +1,21 @@
+-- Adds the callback signing secrets on databases created before them. Fresh databases get the
+-- table from schema.sql and do not need this.
+--
+-- Existing merchants have no secret afterwards, and callbacks are never sent unsigned. Run rekey
+-- once after this migration to create one for each of them.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/011_webhook_secrets.sql
+
+BEGIN;
+
+CREATE TABLE merchant_webhook_secrets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_merchant_webhook_secrets_merchant ON merchant_webhook_secrets(merchant_id);
+
+COMMIT;
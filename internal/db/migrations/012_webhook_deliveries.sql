@@ -0,0 +1,75 @@
+-- Adds webhook endpoints, the callback delivery log and callback acknowledgements on databases
+-- created before them. Fresh databases get the tables and columns from schema.sql and do not need
+-- this.
+--
+-- callback_url becomes optional, since intents without one are delivered to the merchant's
+-- endpoints. Existing intents keep their callback_url and start with callback_ack_status pending.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/012_webhook_deliveries.sql
+
+BEGIN;
+
+CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'delivered', 'exhausted');
+CREATE TYPE callback_ack_status AS ENUM ('pending', 'acknowledged', 'unacknowledged', 'rejected', 'failed');
+
+ALTER TABLE payment_intents
+    ALTER COLUMN callback_url DROP NOT NULL,
+    ADD COLUMN callback_ack_status callback_ack_status NOT NULL DEFAULT 'pending',
+    ADD COLUMN callback_ack_message TEXT,
+    ADD COLUMN callback_ack_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE webhook_endpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id VARCHAR(50) NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    event_types TEXT[] NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    description TEXT,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    failing_since TIMESTAMP WITH TIME ZONE,
+    circuit_opened_at TIMESTAMP WITH TIME ZONE,
+    disabled_reason TEXT,
+    disabled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id VARCHAR(50) NOT NULL,
+    endpoint_id UUID REFERENCES webhook_endpoints(id) ON DELETE SET NULL,
+    payment_intent_id VARCHAR(20) NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    resend_of UUID REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+    status webhook_delivery_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_response_code INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE webhook_delivery_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    attempt_number INTEGER NOT NULL,
+    response_code INTEGER,
+    latency_ms BIGINT NOT NULL,
+    response_body TEXT,
+    acknowledged BOOLEAN,
+    ack_message TEXT,
+    error TEXT,
+    attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_endpoints_merchant ON webhook_endpoints(merchant_id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_intent ON webhook_deliveries(payment_intent_id);
+CREATE INDEX idx_webhook_deliveries_merchant_created ON webhook_deliveries(merchant_id, created_at DESC);
+CREATE INDEX idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts(delivery_id, attempt_number);
+
+COMMIT;
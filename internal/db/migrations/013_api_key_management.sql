@@ -0,0 +1,15 @@
+-- Adds labels, last use and revocation to merchant_api_keys on databases created before them.
+-- Fresh databases get the columns from schema.sql and do not need this.
+--
+-- Existing keys keep working unlabelled.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/013_api_key_management.sql
+
+BEGIN;
+
+ALTER TABLE merchant_api_keys
+    ADD COLUMN label VARCHAR(100),
+    ADD COLUMN last_used_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN revoked_at TIMESTAMP WITH TIME ZONE;
+
+COMMIT;
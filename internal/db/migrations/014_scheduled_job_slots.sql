@@ -0,0 +1,15 @@
+-- Records the schedule slot of each scheduled job run, so replicas run every slot once, on
+-- databases created before it. Fresh databases get the column and index from schema.sql and do
+-- not need this.
+--
+-- Existing runs keep no slot, like manual runs, and never conflict with new ones.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/014_scheduled_job_slots.sql
+
+BEGIN;
+
+ALTER TABLE scheduled_job_runs ADD COLUMN scheduled_for TIMESTAMP WITH TIME ZONE;
+
+CREATE UNIQUE INDEX idx_scheduled_job_runs_slot ON scheduled_job_runs(job_name, scheduled_for);
+
+COMMIT;
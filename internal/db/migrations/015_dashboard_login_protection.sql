@@ -0,0 +1,15 @@
+-- Adds TOTP replay protection and failed sign-in lockouts for dashboard users on databases
+-- created before them. Fresh databases get the columns from schema.sql and do not need this.
+--
+-- Existing users start with no failed sign-ins and no used TOTP step.
+--
+--   psql "$DATABASE_URL" -f internal/db/migrations/015_dashboard_login_protection.sql
+
+BEGIN;
+
+ALTER TABLE merchant_users
+    ADD COLUMN totp_last_step BIGINT,
+    ADD COLUMN failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+COMMIT;
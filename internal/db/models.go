@@ -7,7 +7,9 @@ package db
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sqlc-dev/pqtype"
@@ -74,6 +76,131 @@ func AllApiKeyStatusValues() []ApiKeyStatus {
 	}
 }
 
+type ApiMode string
+
+const (
+	ApiModeTest ApiMode = "test"
+	ApiModeLive ApiMode = "live"
+)
+
+func (e *ApiMode) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ApiMode(s)
+	case string:
+		*e = ApiMode(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ApiMode: %T", src)
+	}
+	return nil
+}
+
+type NullApiMode struct {
+	ApiMode ApiMode `json:"api_mode"`
+	Valid   bool    `json:"valid"` // Valid is true if ApiMode is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullApiMode) Scan(value interface{}) error {
+	if value == nil {
+		ns.ApiMode, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ApiMode.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullApiMode) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ApiMode), nil
+}
+
+func (e ApiMode) Valid() bool {
+	switch e {
+	case ApiModeTest,
+		ApiModeLive:
+		return true
+	}
+	return false
+}
+
+func AllApiModeValues() []ApiMode {
+	return []ApiMode{
+		ApiModeTest,
+		ApiModeLive,
+	}
+}
+
+type CallbackAckStatus string
+
+const (
+	CallbackAckStatusPending        CallbackAckStatus = "pending"
+	CallbackAckStatusAcknowledged   CallbackAckStatus = "acknowledged"
+	CallbackAckStatusUnacknowledged CallbackAckStatus = "unacknowledged"
+	CallbackAckStatusRejected       CallbackAckStatus = "rejected"
+	CallbackAckStatusFailed         CallbackAckStatus = "failed"
+)
+
+func (e *CallbackAckStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CallbackAckStatus(s)
+	case string:
+		*e = CallbackAckStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CallbackAckStatus: %T", src)
+	}
+	return nil
+}
+
+type NullCallbackAckStatus struct {
+	CallbackAckStatus CallbackAckStatus `json:"callback_ack_status"`
+	Valid             bool              `json:"valid"` // Valid is true if CallbackAckStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCallbackAckStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.CallbackAckStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CallbackAckStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCallbackAckStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CallbackAckStatus), nil
+}
+
+func (e CallbackAckStatus) Valid() bool {
+	switch e {
+	case CallbackAckStatusPending,
+		CallbackAckStatusAcknowledged,
+		CallbackAckStatusUnacknowledged,
+		CallbackAckStatusRejected,
+		CallbackAckStatusFailed:
+		return true
+	}
+	return false
+}
+
+func AllCallbackAckStatusValues() []CallbackAckStatus {
+	return []CallbackAckStatus{
+		CallbackAckStatusPending,
+		CallbackAckStatusAcknowledged,
+		CallbackAckStatusUnacknowledged,
+		CallbackAckStatusRejected,
+		CallbackAckStatusFailed,
+	}
+}
+
 type CurrencyType string
 
 const (
@@ -132,6 +259,73 @@ func AllCurrencyTypeValues() []CurrencyType {
 	}
 }
 
+type DashboardRole string
+
+const (
+	DashboardRoleOwner     DashboardRole = "owner"
+	DashboardRoleAdmin     DashboardRole = "admin"
+	DashboardRoleDeveloper DashboardRole = "developer"
+	DashboardRoleFinance   DashboardRole = "finance"
+	DashboardRoleReadOnly  DashboardRole = "read_only"
+)
+
+func (e *DashboardRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DashboardRole(s)
+	case string:
+		*e = DashboardRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DashboardRole: %T", src)
+	}
+	return nil
+}
+
+type NullDashboardRole struct {
+	DashboardRole DashboardRole `json:"dashboard_role"`
+	Valid         bool          `json:"valid"` // Valid is true if DashboardRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDashboardRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.DashboardRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DashboardRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDashboardRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DashboardRole), nil
+}
+
+func (e DashboardRole) Valid() bool {
+	switch e {
+	case DashboardRoleOwner,
+		DashboardRoleAdmin,
+		DashboardRoleDeveloper,
+		DashboardRoleFinance,
+		DashboardRoleReadOnly:
+		return true
+	}
+	return false
+}
+
+func AllDashboardRoleValues() []DashboardRole {
+	return []DashboardRole{
+		DashboardRoleOwner,
+		DashboardRoleAdmin,
+		DashboardRoleDeveloper,
+		DashboardRoleFinance,
+		DashboardRoleReadOnly,
+	}
+}
+
 type EventType string
 
 const (
@@ -199,6 +393,70 @@ func AllEventTypeValues() []EventType {
 	}
 }
 
+type JobStatus string
+
+const (
+	JobStatusQueued  JobStatus = "queued"
+	JobStatusRunning JobStatus = "running"
+	JobStatusDone    JobStatus = "done"
+	JobStatusFailed  JobStatus = "failed"
+)
+
+func (e *JobStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = JobStatus(s)
+	case string:
+		*e = JobStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for JobStatus: %T", src)
+	}
+	return nil
+}
+
+type NullJobStatus struct {
+	JobStatus JobStatus `json:"job_status"`
+	Valid     bool      `json:"valid"` // Valid is true if JobStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullJobStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.JobStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.JobStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullJobStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.JobStatus), nil
+}
+
+func (e JobStatus) Valid() bool {
+	switch e {
+	case JobStatusQueued,
+		JobStatusRunning,
+		JobStatusDone,
+		JobStatusFailed:
+		return true
+	}
+	return false
+}
+
+func AllJobStatusValues() []JobStatus {
+	return []JobStatus{
+		JobStatusQueued,
+		JobStatusRunning,
+		JobStatusDone,
+		JobStatusFailed,
+	}
+}
+
 type MerchantStatus string
 
 const (
@@ -260,6 +518,128 @@ func AllMerchantStatusValues() []MerchantStatus {
 	}
 }
 
+type MerchantTier string
+
+const (
+	MerchantTierStandard   MerchantTier = "standard"
+	MerchantTierGrowth     MerchantTier = "growth"
+	MerchantTierEnterprise MerchantTier = "enterprise"
+)
+
+func (e *MerchantTier) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MerchantTier(s)
+	case string:
+		*e = MerchantTier(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MerchantTier: %T", src)
+	}
+	return nil
+}
+
+type NullMerchantTier struct {
+	MerchantTier MerchantTier `json:"merchant_tier"`
+	Valid        bool         `json:"valid"` // Valid is true if MerchantTier is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMerchantTier) Scan(value interface{}) error {
+	if value == nil {
+		ns.MerchantTier, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MerchantTier.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMerchantTier) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MerchantTier), nil
+}
+
+func (e MerchantTier) Valid() bool {
+	switch e {
+	case MerchantTierStandard,
+		MerchantTierGrowth,
+		MerchantTierEnterprise:
+		return true
+	}
+	return false
+}
+
+func AllMerchantTierValues() []MerchantTier {
+	return []MerchantTier{
+		MerchantTierStandard,
+		MerchantTierGrowth,
+		MerchantTierEnterprise,
+	}
+}
+
+type MerchantUserStatus string
+
+const (
+	MerchantUserStatusInvited  MerchantUserStatus = "invited"
+	MerchantUserStatusActive   MerchantUserStatus = "active"
+	MerchantUserStatusDisabled MerchantUserStatus = "disabled"
+)
+
+func (e *MerchantUserStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MerchantUserStatus(s)
+	case string:
+		*e = MerchantUserStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MerchantUserStatus: %T", src)
+	}
+	return nil
+}
+
+type NullMerchantUserStatus struct {
+	MerchantUserStatus MerchantUserStatus `json:"merchant_user_status"`
+	Valid              bool               `json:"valid"` // Valid is true if MerchantUserStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMerchantUserStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.MerchantUserStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MerchantUserStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMerchantUserStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MerchantUserStatus), nil
+}
+
+func (e MerchantUserStatus) Valid() bool {
+	switch e {
+	case MerchantUserStatusInvited,
+		MerchantUserStatusActive,
+		MerchantUserStatusDisabled:
+		return true
+	}
+	return false
+}
+
+func AllMerchantUserStatusValues() []MerchantUserStatus {
+	return []MerchantUserStatus{
+		MerchantUserStatusInvited,
+		MerchantUserStatusActive,
+		MerchantUserStatusDisabled,
+	}
+}
+
 type PaymentMethodType string
 
 const (
@@ -341,6 +721,7 @@ const (
 	PaymentStatusSuccess    PaymentStatus = "success"
 	PaymentStatusFailed     PaymentStatus = "failed"
 	PaymentStatusCancelled  PaymentStatus = "cancelled"
+	PaymentStatusExpired    PaymentStatus = "expired"
 )
 
 func (e *PaymentStatus) Scan(src interface{}) error {
@@ -384,7 +765,8 @@ func (e PaymentStatus) Valid() bool {
 		PaymentStatusProcessing,
 		PaymentStatusSuccess,
 		PaymentStatusFailed,
-		PaymentStatusCancelled:
+		PaymentStatusCancelled,
+		PaymentStatusExpired:
 		return true
 	}
 	return false
@@ -397,6 +779,68 @@ func AllPaymentStatusValues() []PaymentStatus {
 		PaymentStatusSuccess,
 		PaymentStatusFailed,
 		PaymentStatusCancelled,
+		PaymentStatusExpired,
+	}
+}
+
+type ScheduledRunStatus string
+
+const (
+	ScheduledRunStatusRunning   ScheduledRunStatus = "running"
+	ScheduledRunStatusSucceeded ScheduledRunStatus = "succeeded"
+	ScheduledRunStatusFailed    ScheduledRunStatus = "failed"
+)
+
+func (e *ScheduledRunStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ScheduledRunStatus(s)
+	case string:
+		*e = ScheduledRunStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ScheduledRunStatus: %T", src)
+	}
+	return nil
+}
+
+type NullScheduledRunStatus struct {
+	ScheduledRunStatus ScheduledRunStatus `json:"scheduled_run_status"`
+	Valid              bool               `json:"valid"` // Valid is true if ScheduledRunStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullScheduledRunStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ScheduledRunStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ScheduledRunStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullScheduledRunStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ScheduledRunStatus), nil
+}
+
+func (e ScheduledRunStatus) Valid() bool {
+	switch e {
+	case ScheduledRunStatusRunning,
+		ScheduledRunStatusSucceeded,
+		ScheduledRunStatusFailed:
+		return true
+	}
+	return false
+}
+
+func AllScheduledRunStatusValues() []ScheduledRunStatus {
+	return []ScheduledRunStatus{
+		ScheduledRunStatusRunning,
+		ScheduledRunStatusSucceeded,
+		ScheduledRunStatusFailed,
 	}
 }
 
@@ -461,29 +905,142 @@ func AllTransactionStatusValues() []TransactionStatus {
 	}
 }
 
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusExhausted WebhookDeliveryStatus = "exhausted"
+)
+
+func (e *WebhookDeliveryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryStatus(s)
+	case string:
+		*e = WebhookDeliveryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryStatus struct {
+	WebhookDeliveryStatus WebhookDeliveryStatus `json:"webhook_delivery_status"`
+	Valid                 bool                  `json:"valid"` // Valid is true if WebhookDeliveryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryStatus), nil
+}
+
+func (e WebhookDeliveryStatus) Valid() bool {
+	switch e {
+	case WebhookDeliveryStatusPending,
+		WebhookDeliveryStatusDelivered,
+		WebhookDeliveryStatusExhausted:
+		return true
+	}
+	return false
+}
+
+func AllWebhookDeliveryStatusValues() []WebhookDeliveryStatus {
+	return []WebhookDeliveryStatus{
+		WebhookDeliveryStatusPending,
+		WebhookDeliveryStatusDelivered,
+		WebhookDeliveryStatusExhausted,
+	}
+}
+
+type EventOutbox struct {
+	ID          uuid.UUID       `db:"id" json:"id"`
+	EventType   string          `db:"event_type" json:"event_type"`
+	Exchange    string          `db:"exchange" json:"exchange"`
+	RoutingKey  string          `db:"routing_key" json:"routing_key"`
+	Payload     json.RawMessage `db:"payload" json:"payload"`
+	Attempts    int32           `db:"attempts" json:"attempts"`
+	LastError   sql.NullString  `db:"last_error" json:"last_error"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+	PublishedAt sql.NullTime    `db:"published_at" json:"published_at"`
+}
+
+type Job struct {
+	ID          uuid.UUID             `db:"id" json:"id"`
+	Queue       string                `db:"queue" json:"queue"`
+	Exchange    string                `db:"exchange" json:"exchange"`
+	RoutingKey  string                `db:"routing_key" json:"routing_key"`
+	MessageID   sql.NullString        `db:"message_id" json:"message_id"`
+	MessageType sql.NullString        `db:"message_type" json:"message_type"`
+	ContentType sql.NullString        `db:"content_type" json:"content_type"`
+	Headers     pqtype.NullRawMessage `db:"headers" json:"headers"`
+	Payload     []byte                `db:"payload" json:"payload"`
+	Status      JobStatus             `db:"status" json:"status"`
+	Attempts    int32                 `db:"attempts" json:"attempts"`
+	MaxAttempts int32                 `db:"max_attempts" json:"max_attempts"`
+	RunAt       time.Time             `db:"run_at" json:"run_at"`
+	LockedUntil sql.NullTime          `db:"locked_until" json:"locked_until"`
+	LockedBy    sql.NullString        `db:"locked_by" json:"locked_by"`
+	LastError   sql.NullString        `db:"last_error" json:"last_error"`
+	CreatedAt   sql.NullTime          `db:"created_at" json:"created_at"`
+	UpdatedAt   sql.NullTime          `db:"updated_at" json:"updated_at"`
+}
+
+type JobBinding struct {
+	Exchange  string       `db:"exchange" json:"exchange"`
+	Pattern   string       `db:"pattern" json:"pattern"`
+	Queue     string       `db:"queue" json:"queue"`
+	CreatedAt sql.NullTime `db:"created_at" json:"created_at"`
+}
+
 type Merchant struct {
-	ID         uuid.UUID          `db:"id" json:"id"`
-	MerchantID string             `db:"merchant_id" json:"merchant_id"`
-	Name       string             `db:"name" json:"name"`
-	Email      string             `db:"email" json:"email"`
-	Status     NullMerchantStatus `db:"status" json:"status"`
-	CreatedAt  sql.NullTime       `db:"created_at" json:"created_at"`
-	UpdatedAt  sql.NullTime       `db:"updated_at" json:"updated_at"`
+	ID              uuid.UUID          `db:"id" json:"id"`
+	MerchantID      string             `db:"merchant_id" json:"merchant_id"`
+	Name            string             `db:"name" json:"name"`
+	Email           string             `db:"email" json:"email"`
+	Status          NullMerchantStatus `db:"status" json:"status"`
+	CreatedAt       sql.NullTime       `db:"created_at" json:"created_at"`
+	UpdatedAt       sql.NullTime       `db:"updated_at" json:"updated_at"`
+	EmailVerifiedAt sql.NullTime       `db:"email_verified_at" json:"email_verified_at"`
+	PendingEmail    sql.NullString     `db:"pending_email" json:"pending_email"`
+	Tier            MerchantTier       `db:"tier" json:"tier"`
 }
 
 type MerchantApiKey struct {
 	ID         uuid.UUID        `db:"id" json:"id"`
 	MerchantID uuid.UUID        `db:"merchant_id" json:"merchant_id"`
-	ApiKey     string           `db:"api_key" json:"api_key"`
-	SecretKey  string           `db:"secret_key" json:"secret_key"`
+	KeyPrefix  sql.NullString   `db:"key_prefix" json:"key_prefix"`
+	KeyHash    string           `db:"key_hash" json:"key_hash"`
+	KeySalt    string           `db:"key_salt" json:"key_salt"`
+	LastFour   string           `db:"last_four" json:"last_four"`
 	Status     NullApiKeyStatus `db:"status" json:"status"`
 	CreatedAt  sql.NullTime     `db:"created_at" json:"created_at"`
 	ExpiresAt  sql.NullTime     `db:"expires_at" json:"expires_at"`
+	Label      sql.NullString   `db:"label" json:"label"`
+	LastUsedAt sql.NullTime     `db:"last_used_at" json:"last_used_at"`
+	RevokedAt  sql.NullTime     `db:"revoked_at" json:"revoked_at"`
+	MustRotate bool             `db:"must_rotate" json:"must_rotate"`
+	Scopes     []string         `db:"scopes" json:"scopes"`
+	Mode       ApiMode          `db:"mode" json:"mode"`
 }
 
 type MerchantBalance struct {
 	ID                    uuid.UUID      `db:"id" json:"id"`
 	MerchantID            uuid.UUID      `db:"merchant_id" json:"merchant_id"`
+	Mode                  ApiMode        `db:"mode" json:"mode"`
 	Currency              CurrencyType   `db:"currency" json:"currency"`
 	AvailableBalance      sql.NullString `db:"available_balance" json:"available_balance"`
 	TotalDeposit          sql.NullString `db:"total_deposit" json:"total_deposit"`
@@ -491,20 +1048,64 @@ type MerchantBalance struct {
 	LastUpdated           sql.NullTime   `db:"last_updated" json:"last_updated"`
 }
 
+type MerchantStatusEvent struct {
+	ID         uuid.UUID      `db:"id" json:"id"`
+	MerchantID uuid.UUID      `db:"merchant_id" json:"merchant_id"`
+	FromStatus MerchantStatus `db:"from_status" json:"from_status"`
+	ToStatus   MerchantStatus `db:"to_status" json:"to_status"`
+	Reason     string         `db:"reason" json:"reason"`
+	Actor      string         `db:"actor" json:"actor"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+type MerchantUser struct {
+	ID                  uuid.UUID          `db:"id" json:"id"`
+	MerchantID          uuid.UUID          `db:"merchant_id" json:"merchant_id"`
+	Email               string             `db:"email" json:"email"`
+	Name                string             `db:"name" json:"name"`
+	Role                DashboardRole      `db:"role" json:"role"`
+	Status              MerchantUserStatus `db:"status" json:"status"`
+	PasswordHash        sql.NullString     `db:"password_hash" json:"password_hash"`
+	TotpSecret          sql.NullString     `db:"totp_secret" json:"totp_secret"`
+	TotpEnabledAt       sql.NullTime       `db:"totp_enabled_at" json:"totp_enabled_at"`
+	InviteTokenHash     sql.NullString     `db:"invite_token_hash" json:"invite_token_hash"`
+	InviteExpiresAt     sql.NullTime       `db:"invite_expires_at" json:"invite_expires_at"`
+	InvitedBy           string             `db:"invited_by" json:"invited_by"`
+	LastLoginAt         sql.NullTime       `db:"last_login_at" json:"last_login_at"`
+	CreatedAt           time.Time          `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time          `db:"updated_at" json:"updated_at"`
+	TotpLastStep        sql.NullInt64      `db:"totp_last_step" json:"totp_last_step"`
+	FailedLoginAttempts int32              `db:"failed_login_attempts" json:"failed_login_attempts"`
+	LockedUntil         sql.NullTime       `db:"locked_until" json:"locked_until"`
+}
+
+type MerchantWebhookSecret struct {
+	ID         uuid.UUID    `db:"id" json:"id"`
+	MerchantID uuid.UUID    `db:"merchant_id" json:"merchant_id"`
+	Secret     string       `db:"secret" json:"secret"`
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`
+	ExpiresAt  sql.NullTime `db:"expires_at" json:"expires_at"`
+}
+
 type PaymentIntent struct {
-	ID              uuid.UUID             `db:"id" json:"id"`
-	PaymentIntentID string                `db:"payment_intent_id" json:"payment_intent_id"`
-	MerchantID      string                `db:"merchant_id" json:"merchant_id"`
-	Amount          string                `db:"amount" json:"amount"`
-	Currency        string                `db:"currency" json:"currency"`
-	Status          NullPaymentStatus     `db:"status" json:"status"`
-	Description     sql.NullString        `db:"description" json:"description"`
-	CallbackUrl     string                `db:"callback_url" json:"callback_url"`
-	Nonce           string                `db:"nonce" json:"nonce"`
-	Metadata        pqtype.NullRawMessage `db:"metadata" json:"metadata"`
-	CreatedAt       sql.NullTime          `db:"created_at" json:"created_at"`
-	UpdatedAt       sql.NullTime          `db:"updated_at" json:"updated_at"`
-	ExpiresAt       sql.NullTime          `db:"expires_at" json:"expires_at"`
+	ID                 uuid.UUID             `db:"id" json:"id"`
+	PaymentIntentID    string                `db:"payment_intent_id" json:"payment_intent_id"`
+	MerchantID         string                `db:"merchant_id" json:"merchant_id"`
+	Amount             string                `db:"amount" json:"amount"`
+	Currency           string                `db:"currency" json:"currency"`
+	Status             NullPaymentStatus     `db:"status" json:"status"`
+	Description        sql.NullString        `db:"description" json:"description"`
+	CallbackUrl        sql.NullString        `db:"callback_url" json:"callback_url"`
+	Nonce              string                `db:"nonce" json:"nonce"`
+	Metadata           pqtype.NullRawMessage `db:"metadata" json:"metadata"`
+	CallbackAckStatus  CallbackAckStatus     `db:"callback_ack_status" json:"callback_ack_status"`
+	CallbackAckMessage sql.NullString        `db:"callback_ack_message" json:"callback_ack_message"`
+	CallbackAckAt      sql.NullTime          `db:"callback_ack_at" json:"callback_ack_at"`
+	Mode               ApiMode               `db:"mode" json:"mode"`
+	AccountNumber      sql.NullString        `db:"account_number" json:"account_number"`
+	CreatedAt          sql.NullTime          `db:"created_at" json:"created_at"`
+	UpdatedAt          sql.NullTime          `db:"updated_at" json:"updated_at"`
+	ExpiresAt          sql.NullTime          `db:"expires_at" json:"expires_at"`
 }
 
 type PaymentTransaction struct {
@@ -518,7 +1119,81 @@ type PaymentTransaction struct {
 	PaymentMethod       NullPaymentMethodType `db:"payment_method" json:"payment_method"`
 	FeeAmount           sql.NullString        `db:"fee_amount" json:"fee_amount"`
 	AccountNumber       sql.NullString        `db:"account_number" json:"account_number"`
+	Mode                ApiMode               `db:"mode" json:"mode"`
 	ProcessedAt         sql.NullTime          `db:"processed_at" json:"processed_at"`
 	CreatedAt           sql.NullTime          `db:"created_at" json:"created_at"`
 	UpdatedAt           sql.NullTime          `db:"updated_at" json:"updated_at"`
 }
+
+type ScheduledJobRun struct {
+	ID           uuid.UUID          `db:"id" json:"id"`
+	JobName      string             `db:"job_name" json:"job_name"`
+	TriggerType  string             `db:"trigger_type" json:"trigger_type"`
+	Status       ScheduledRunStatus `db:"status" json:"status"`
+	Instance     string             `db:"instance" json:"instance"`
+	StartedAt    time.Time          `db:"started_at" json:"started_at"`
+	FinishedAt   sql.NullTime       `db:"finished_at" json:"finished_at"`
+	DurationMs   sql.NullInt64      `db:"duration_ms" json:"duration_ms"`
+	Error        sql.NullString     `db:"error" json:"error"`
+	ScheduledFor sql.NullTime       `db:"scheduled_for" json:"scheduled_for"`
+}
+
+type UserSession struct {
+	ID         uuid.UUID      `db:"id" json:"id"`
+	UserID     uuid.UUID      `db:"user_id" json:"user_id"`
+	TokenHash  string         `db:"token_hash" json:"token_hash"`
+	IpAddress  sql.NullString `db:"ip_address" json:"ip_address"`
+	UserAgent  sql.NullString `db:"user_agent" json:"user_agent"`
+	ExpiresAt  time.Time      `db:"expires_at" json:"expires_at"`
+	RevokedAt  sql.NullTime   `db:"revoked_at" json:"revoked_at"`
+	LastSeenAt sql.NullTime   `db:"last_seen_at" json:"last_seen_at"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID               uuid.UUID             `db:"id" json:"id"`
+	MerchantID       string                `db:"merchant_id" json:"merchant_id"`
+	EndpointID       uuid.NullUUID         `db:"endpoint_id" json:"endpoint_id"`
+	PaymentIntentID  string                `db:"payment_intent_id" json:"payment_intent_id"`
+	EventType        string                `db:"event_type" json:"event_type"`
+	Url              string                `db:"url" json:"url"`
+	Payload          json.RawMessage       `db:"payload" json:"payload"`
+	ResendOf         uuid.NullUUID         `db:"resend_of" json:"resend_of"`
+	Status           WebhookDeliveryStatus `db:"status" json:"status"`
+	Attempts         int32                 `db:"attempts" json:"attempts"`
+	NextAttemptAt    time.Time             `db:"next_attempt_at" json:"next_attempt_at"`
+	LastResponseCode sql.NullInt32         `db:"last_response_code" json:"last_response_code"`
+	LastError        sql.NullString        `db:"last_error" json:"last_error"`
+	DeliveredAt      sql.NullTime          `db:"delivered_at" json:"delivered_at"`
+	CreatedAt        time.Time             `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time             `db:"updated_at" json:"updated_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID            uuid.UUID      `db:"id" json:"id"`
+	DeliveryID    uuid.UUID      `db:"delivery_id" json:"delivery_id"`
+	AttemptNumber int32          `db:"attempt_number" json:"attempt_number"`
+	ResponseCode  sql.NullInt32  `db:"response_code" json:"response_code"`
+	LatencyMs     int64          `db:"latency_ms" json:"latency_ms"`
+	ResponseBody  sql.NullString `db:"response_body" json:"response_body"`
+	Acknowledged  sql.NullBool   `db:"acknowledged" json:"acknowledged"`
+	AckMessage    sql.NullString `db:"ack_message" json:"ack_message"`
+	Error         sql.NullString `db:"error" json:"error"`
+	AttemptedAt   time.Time      `db:"attempted_at" json:"attempted_at"`
+}
+
+type WebhookEndpoint struct {
+	ID                  uuid.UUID      `db:"id" json:"id"`
+	MerchantID          string         `db:"merchant_id" json:"merchant_id"`
+	Url                 string         `db:"url" json:"url"`
+	EventTypes          []string       `db:"event_types" json:"event_types"`
+	Enabled             bool           `db:"enabled" json:"enabled"`
+	Description         sql.NullString `db:"description" json:"description"`
+	ConsecutiveFailures int32          `db:"consecutive_failures" json:"consecutive_failures"`
+	FailingSince        sql.NullTime   `db:"failing_since" json:"failing_since"`
+	CircuitOpenedAt     sql.NullTime   `db:"circuit_opened_at" json:"circuit_opened_at"`
+	DisabledReason      sql.NullString `db:"disabled_reason" json:"disabled_reason"`
+	DisabledAt          sql.NullTime   `db:"disabled_at" json:"disabled_at"`
+	CreatedAt           time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time      `db:"updated_at" json:"updated_at"`
+}
@@ -14,9 +14,9 @@ import (
 )
 
 const createPaymentIntent = `-- name: CreatePaymentIntent :one
-INSERT INTO payment_intents (payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, metadata)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, status, metadata, created_at, updated_at, expires_at
+INSERT INTO payment_intents (payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, metadata, mode, account_number)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, status, metadata, mode, account_number, created_at, updated_at, expires_at
 `
 
 type CreatePaymentIntentParams struct {
@@ -25,9 +25,11 @@ type CreatePaymentIntentParams struct {
 	Amount          string                `db:"amount" json:"amount"`
 	Currency        string                `db:"currency" json:"currency"`
 	Description     sql.NullString        `db:"description" json:"description"`
-	CallbackUrl     string                `db:"callback_url" json:"callback_url"`
+	CallbackUrl     sql.NullString        `db:"callback_url" json:"callback_url"`
 	Nonce           string                `db:"nonce" json:"nonce"`
 	Metadata        pqtype.NullRawMessage `db:"metadata" json:"metadata"`
+	Mode            ApiMode               `db:"mode" json:"mode"`
+	AccountNumber   sql.NullString        `db:"account_number" json:"account_number"`
 }
 
 type CreatePaymentIntentRow struct {
@@ -37,10 +39,12 @@ type CreatePaymentIntentRow struct {
 	Amount          string                `db:"amount" json:"amount"`
 	Currency        string                `db:"currency" json:"currency"`
 	Description     sql.NullString        `db:"description" json:"description"`
-	CallbackUrl     string                `db:"callback_url" json:"callback_url"`
+	CallbackUrl     sql.NullString        `db:"callback_url" json:"callback_url"`
 	Nonce           string                `db:"nonce" json:"nonce"`
 	Status          NullPaymentStatus     `db:"status" json:"status"`
 	Metadata        pqtype.NullRawMessage `db:"metadata" json:"metadata"`
+	Mode            ApiMode               `db:"mode" json:"mode"`
+	AccountNumber   sql.NullString        `db:"account_number" json:"account_number"`
 	CreatedAt       sql.NullTime          `db:"created_at" json:"created_at"`
 	UpdatedAt       sql.NullTime          `db:"updated_at" json:"updated_at"`
 	ExpiresAt       sql.NullTime          `db:"expires_at" json:"expires_at"`
@@ -56,6 +60,8 @@ func (q *Queries) CreatePaymentIntent(ctx context.Context, arg *CreatePaymentInt
 		arg.CallbackUrl,
 		arg.Nonce,
 		arg.Metadata,
+		arg.Mode,
+		arg.AccountNumber,
 	)
 	var i CreatePaymentIntentRow
 	err := row.Scan(
@@ -69,6 +75,8 @@ func (q *Queries) CreatePaymentIntent(ctx context.Context, arg *CreatePaymentInt
 		&i.Nonce,
 		&i.Status,
 		&i.Metadata,
+		&i.Mode,
+		&i.AccountNumber,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ExpiresAt,
@@ -77,9 +85,9 @@ func (q *Queries) CreatePaymentIntent(ctx context.Context, arg *CreatePaymentInt
 }
 
 const createPaymentTransaction = `-- name: CreatePaymentTransaction :one
-INSERT INTO payment_transactions (payment_intent_id, merchant_id, amount, currency, payment_method, fee_amount, account_number)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, processed_at, created_at, updated_at
+INSERT INTO payment_transactions (payment_intent_id, merchant_id, amount, currency, payment_method, fee_amount, account_number, mode)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, mode, processed_at, created_at, updated_at
 `
 
 type CreatePaymentTransactionParams struct {
@@ -90,6 +98,7 @@ type CreatePaymentTransactionParams struct {
 	PaymentMethod   NullPaymentMethodType `db:"payment_method" json:"payment_method"`
 	FeeAmount       sql.NullString        `db:"fee_amount" json:"fee_amount"`
 	AccountNumber   sql.NullString        `db:"account_number" json:"account_number"`
+	Mode            ApiMode               `db:"mode" json:"mode"`
 }
 
 func (q *Queries) CreatePaymentTransaction(ctx context.Context, arg *CreatePaymentTransactionParams) (*PaymentTransaction, error) {
@@ -101,6 +110,7 @@ func (q *Queries) CreatePaymentTransaction(ctx context.Context, arg *CreatePayme
 		arg.PaymentMethod,
 		arg.FeeAmount,
 		arg.AccountNumber,
+		arg.Mode,
 	)
 	var i PaymentTransaction
 	err := row.Scan(
@@ -114,6 +124,7 @@ func (q *Queries) CreatePaymentTransaction(ctx context.Context, arg *CreatePayme
 		&i.PaymentMethod,
 		&i.FeeAmount,
 		&i.AccountNumber,
+		&i.Mode,
 		&i.ProcessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -121,15 +132,72 @@ func (q *Queries) CreatePaymentTransaction(ctx context.Context, arg *CreatePayme
 	return &i, err
 }
 
+const expirePendingPaymentIntents = `-- name: ExpirePendingPaymentIntents :execrows
+UPDATE payment_intents
+SET status = 'expired', updated_at = NOW()
+WHERE status = 'pending' AND expires_at < NOW()
+`
+
+func (q *Queries) ExpirePendingPaymentIntents(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expirePendingPaymentIntents)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getMerchantPaymentIntent = `-- name: GetMerchantPaymentIntent :one
+SELECT id, payment_intent_id, merchant_id, amount, currency, status, description, callback_url, nonce, metadata, callback_ack_status, callback_ack_message, callback_ack_at, mode, account_number, created_at, updated_at, expires_at
+FROM payment_intents
+WHERE payment_intent_id = $1 AND merchant_id = $2 AND mode = $3
+`
+
+type GetMerchantPaymentIntentParams struct {
+	PaymentIntentID string  `db:"payment_intent_id" json:"payment_intent_id"`
+	MerchantID      string  `db:"merchant_id" json:"merchant_id"`
+	Mode            ApiMode `db:"mode" json:"mode"`
+}
+
+func (q *Queries) GetMerchantPaymentIntent(ctx context.Context, arg *GetMerchantPaymentIntentParams) (*PaymentIntent, error) {
+	row := q.db.QueryRowContext(ctx, getMerchantPaymentIntent, arg.PaymentIntentID, arg.MerchantID, arg.Mode)
+	var i PaymentIntent
+	err := row.Scan(
+		&i.ID,
+		&i.PaymentIntentID,
+		&i.MerchantID,
+		&i.Amount,
+		&i.Currency,
+		&i.Status,
+		&i.Description,
+		&i.CallbackUrl,
+		&i.Nonce,
+		&i.Metadata,
+		&i.CallbackAckStatus,
+		&i.CallbackAckMessage,
+		&i.CallbackAckAt,
+		&i.Mode,
+		&i.AccountNumber,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+	)
+	return &i, err
+}
+
 const getMerchantTransactions = `-- name: GetMerchantTransactions :many
-SELECT id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, processed_at, created_at, updated_at
+SELECT id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, mode, processed_at, created_at, updated_at
 FROM payment_transactions
-WHERE merchant_id = $1
+WHERE merchant_id = $1 AND mode = $2
 ORDER BY created_at DESC
 `
 
-func (q *Queries) GetMerchantTransactions(ctx context.Context, merchantID string) ([]*PaymentTransaction, error) {
-	rows, err := q.db.QueryContext(ctx, getMerchantTransactions, merchantID)
+type GetMerchantTransactionsParams struct {
+	MerchantID string  `db:"merchant_id" json:"merchant_id"`
+	Mode       ApiMode `db:"mode" json:"mode"`
+}
+
+func (q *Queries) GetMerchantTransactions(ctx context.Context, arg *GetMerchantTransactionsParams) ([]*PaymentTransaction, error) {
+	rows, err := q.db.QueryContext(ctx, getMerchantTransactions, arg.MerchantID, arg.Mode)
 	if err != nil {
 		return nil, err
 	}
@@ -148,6 +216,7 @@ func (q *Queries) GetMerchantTransactions(ctx context.Context, merchantID string
 			&i.PaymentMethod,
 			&i.FeeAmount,
 			&i.AccountNumber,
+			&i.Mode,
 			&i.ProcessedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
@@ -166,7 +235,7 @@ func (q *Queries) GetMerchantTransactions(ctx context.Context, merchantID string
 }
 
 const getPaymentIntent = `-- name: GetPaymentIntent :one
-SELECT pi.id, pi.payment_intent_id, pi.merchant_id, pi.amount, pi.currency, pi.description, pi.callback_url, pi.nonce, pi.status, pi.metadata, pi.created_at, pi.updated_at, pi.expires_at
+SELECT pi.id, pi.payment_intent_id, pi.merchant_id, pi.amount, pi.currency, pi.description, pi.callback_url, pi.nonce, pi.status, pi.metadata, pi.mode, pi.account_number, pi.created_at, pi.updated_at, pi.expires_at
 FROM payment_intents pi
 WHERE pi.payment_intent_id = $1
 `
@@ -178,10 +247,12 @@ type GetPaymentIntentRow struct {
 	Amount          string                `db:"amount" json:"amount"`
 	Currency        string                `db:"currency" json:"currency"`
 	Description     sql.NullString        `db:"description" json:"description"`
-	CallbackUrl     string                `db:"callback_url" json:"callback_url"`
+	CallbackUrl     sql.NullString        `db:"callback_url" json:"callback_url"`
 	Nonce           string                `db:"nonce" json:"nonce"`
 	Status          NullPaymentStatus     `db:"status" json:"status"`
 	Metadata        pqtype.NullRawMessage `db:"metadata" json:"metadata"`
+	Mode            ApiMode               `db:"mode" json:"mode"`
+	AccountNumber   sql.NullString        `db:"account_number" json:"account_number"`
 	CreatedAt       sql.NullTime          `db:"created_at" json:"created_at"`
 	UpdatedAt       sql.NullTime          `db:"updated_at" json:"updated_at"`
 	ExpiresAt       sql.NullTime          `db:"expires_at" json:"expires_at"`
@@ -201,6 +272,8 @@ func (q *Queries) GetPaymentIntent(ctx context.Context, paymentIntentID string)
 		&i.Nonce,
 		&i.Status,
 		&i.Metadata,
+		&i.Mode,
+		&i.AccountNumber,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ExpiresAt,
@@ -221,7 +294,7 @@ type GetPaymentIntentByIDRow struct {
 	Amount          string                `db:"amount" json:"amount"`
 	Currency        string                `db:"currency" json:"currency"`
 	Description     sql.NullString        `db:"description" json:"description"`
-	CallbackUrl     string                `db:"callback_url" json:"callback_url"`
+	CallbackUrl     sql.NullString        `db:"callback_url" json:"callback_url"`
 	Nonce           string                `db:"nonce" json:"nonce"`
 	Status          NullPaymentStatus     `db:"status" json:"status"`
 	Metadata        pqtype.NullRawMessage `db:"metadata" json:"metadata"`
@@ -252,7 +325,7 @@ func (q *Queries) GetPaymentIntentByID(ctx context.Context, id uuid.UUID) (*GetP
 }
 
 const getPaymentIntentByNonce = `-- name: GetPaymentIntentByNonce :one
-SELECT id, payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, status, metadata, created_at, updated_at, expires_at
+SELECT id, payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, status, metadata, mode, created_at, updated_at, expires_at
 FROM payment_intents
 WHERE merchant_id = $1 AND nonce = $2
 `
@@ -269,10 +342,11 @@ type GetPaymentIntentByNonceRow struct {
 	Amount          string                `db:"amount" json:"amount"`
 	Currency        string                `db:"currency" json:"currency"`
 	Description     sql.NullString        `db:"description" json:"description"`
-	CallbackUrl     string                `db:"callback_url" json:"callback_url"`
+	CallbackUrl     sql.NullString        `db:"callback_url" json:"callback_url"`
 	Nonce           string                `db:"nonce" json:"nonce"`
 	Status          NullPaymentStatus     `db:"status" json:"status"`
 	Metadata        pqtype.NullRawMessage `db:"metadata" json:"metadata"`
+	Mode            ApiMode               `db:"mode" json:"mode"`
 	CreatedAt       sql.NullTime          `db:"created_at" json:"created_at"`
 	UpdatedAt       sql.NullTime          `db:"updated_at" json:"updated_at"`
 	ExpiresAt       sql.NullTime          `db:"expires_at" json:"expires_at"`
@@ -292,6 +366,7 @@ func (q *Queries) GetPaymentIntentByNonce(ctx context.Context, arg *GetPaymentIn
 		&i.Nonce,
 		&i.Status,
 		&i.Metadata,
+		&i.Mode,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ExpiresAt,
@@ -300,7 +375,7 @@ func (q *Queries) GetPaymentIntentByNonce(ctx context.Context, arg *GetPaymentIn
 }
 
 const getPaymentTransaction = `-- name: GetPaymentTransaction :one
-SELECT id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, processed_at, created_at, updated_at
+SELECT id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, mode, processed_at, created_at, updated_at
 FROM payment_transactions
 WHERE id = $1
 `
@@ -319,6 +394,7 @@ func (q *Queries) GetPaymentTransaction(ctx context.Context, id uuid.UUID) (*Pay
 		&i.PaymentMethod,
 		&i.FeeAmount,
 		&i.AccountNumber,
+		&i.Mode,
 		&i.ProcessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -326,6 +402,51 @@ func (q *Queries) GetPaymentTransaction(ctx context.Context, id uuid.UUID) (*Pay
 	return &i, err
 }
 
+const listPendingMerchantPaymentIntents = `-- name: ListPendingMerchantPaymentIntents :many
+SELECT payment_intent_id, merchant_id, amount, currency, created_at
+FROM payment_intents
+WHERE merchant_id = $1 AND status = 'pending' AND expires_at > NOW()
+ORDER BY created_at
+`
+
+type ListPendingMerchantPaymentIntentsRow struct {
+	PaymentIntentID string       `db:"payment_intent_id" json:"payment_intent_id"`
+	MerchantID      string       `db:"merchant_id" json:"merchant_id"`
+	Amount          string       `db:"amount" json:"amount"`
+	Currency        string       `db:"currency" json:"currency"`
+	CreatedAt       sql.NullTime `db:"created_at" json:"created_at"`
+}
+
+// Unexpired pending intents, oldest first, to queue again once a suspended merchant is reactivated.
+func (q *Queries) ListPendingMerchantPaymentIntents(ctx context.Context, merchantID string) ([]*ListPendingMerchantPaymentIntentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingMerchantPaymentIntents, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListPendingMerchantPaymentIntentsRow{}
+	for rows.Next() {
+		var i ListPendingMerchantPaymentIntentsRow
+		if err := rows.Scan(
+			&i.PaymentIntentID,
+			&i.MerchantID,
+			&i.Amount,
+			&i.Currency,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const lockPaymentIntentForProcessing = `-- name: LockPaymentIntentForProcessing :one
 SELECT pi.id, pi.payment_intent_id, pi.merchant_id, pi.amount, pi.currency, pi.description, pi.callback_url, pi.nonce, pi.status, pi.metadata, pi.created_at, pi.updated_at, pi.expires_at
 FROM payment_intents pi
@@ -345,7 +466,7 @@ type LockPaymentIntentForProcessingRow struct {
 	Amount          string                `db:"amount" json:"amount"`
 	Currency        string                `db:"currency" json:"currency"`
 	Description     sql.NullString        `db:"description" json:"description"`
-	CallbackUrl     string                `db:"callback_url" json:"callback_url"`
+	CallbackUrl     sql.NullString        `db:"callback_url" json:"callback_url"`
 	Nonce           string                `db:"nonce" json:"nonce"`
 	Status          NullPaymentStatus     `db:"status" json:"status"`
 	Metadata        pqtype.NullRawMessage `db:"metadata" json:"metadata"`
@@ -375,6 +496,27 @@ func (q *Queries) LockPaymentIntentForProcessing(ctx context.Context, arg *LockP
 	return &i, err
 }
 
+const updatePaymentIntentCallbackAck = `-- name: UpdatePaymentIntentCallbackAck :exec
+UPDATE payment_intents
+SET callback_ack_status = $1, callback_ack_message = $2, callback_ack_at = NOW(), updated_at = NOW()
+WHERE payment_intent_id = $3
+  AND callback_ack_status <> 'acknowledged'
+  AND (callback_ack_status <> 'unacknowledged' OR $1 = 'acknowledged')
+`
+
+type UpdatePaymentIntentCallbackAckParams struct {
+	CallbackAckStatus  CallbackAckStatus `db:"callback_ack_status" json:"callback_ack_status"`
+	CallbackAckMessage sql.NullString    `db:"callback_ack_message" json:"callback_ack_message"`
+	PaymentIntentID    string            `db:"payment_intent_id" json:"payment_intent_id"`
+}
+
+// An acknowledgement is final, and an unacknowledged delivery can only be upgraded to one; any other
+// state is replaced by the latest outcome.
+func (q *Queries) UpdatePaymentIntentCallbackAck(ctx context.Context, arg *UpdatePaymentIntentCallbackAckParams) error {
+	_, err := q.db.ExecContext(ctx, updatePaymentIntentCallbackAck, arg.CallbackAckStatus, arg.CallbackAckMessage, arg.PaymentIntentID)
+	return err
+}
+
 const updatePaymentIntentStatus = `-- name: UpdatePaymentIntentStatus :one
 UPDATE payment_intents
 SET status = $2, updated_at = NOW()
@@ -395,7 +537,7 @@ type UpdatePaymentIntentStatusRow struct {
 	Amount          string                `db:"amount" json:"amount"`
 	Currency        string                `db:"currency" json:"currency"`
 	Description     sql.NullString        `db:"description" json:"description"`
-	CallbackUrl     string                `db:"callback_url" json:"callback_url"`
+	CallbackUrl     sql.NullString        `db:"callback_url" json:"callback_url"`
 	Nonce           string                `db:"nonce" json:"nonce"`
 	Status          NullPaymentStatus     `db:"status" json:"status"`
 	Metadata        pqtype.NullRawMessage `db:"metadata" json:"metadata"`
@@ -429,7 +571,7 @@ const updatePaymentTransactionStatus = `-- name: UpdatePaymentTransactionStatus
 UPDATE payment_transactions
 SET status = $2, third_party_reference = $3, processed_at = NOW(), updated_at = NOW()
 WHERE id = $1 AND status = $4
-RETURNING id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, processed_at, created_at, updated_at
+RETURNING id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, mode, processed_at, created_at, updated_at
 `
 
 type UpdatePaymentTransactionStatusParams struct {
@@ -458,6 +600,7 @@ func (q *Queries) UpdatePaymentTransactionStatus(ctx context.Context, arg *Updat
 		&i.PaymentMethod,
 		&i.FeeAmount,
 		&i.AccountNumber,
+		&i.Mode,
 		&i.ProcessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
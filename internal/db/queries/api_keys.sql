@@ -0,0 +1,37 @@
+-- name: GetMerchantAPIKey :one
+SELECT id, merchant_id, key_prefix, key_hash, key_salt, last_four, status, created_at, expires_at, label, last_used_at, revoked_at, must_rotate, scopes, mode
+FROM merchant_api_keys
+WHERE id = $1 AND merchant_id = $2;
+
+-- name: ListMerchantAPIKeys :many
+SELECT id, merchant_id, key_prefix, key_hash, key_salt, last_four, status, created_at, expires_at, label, last_used_at, revoked_at, must_rotate, scopes, mode
+FROM merchant_api_keys
+WHERE merchant_id = $1
+ORDER BY created_at DESC;
+
+-- name: ExpireMerchantAPIKey :execrows
+-- Cuts a rotated key's lifetime short; a key that already expires sooner keeps its date.
+UPDATE merchant_api_keys
+SET expires_at = @expires_at
+WHERE id = @id AND merchant_id = @merchant_id AND status = 'active'
+  AND (expires_at IS NULL OR expires_at > @expires_at);
+
+-- name: RevokeMerchantAPIKey :execrows
+-- Only revokes a key while another usable key of its mode can still manage keys, so the merchant
+-- is never locked out of that mode or left unable to create and rotate keys in it.
+UPDATE merchant_api_keys
+SET status = 'inactive', revoked_at = NOW()
+WHERE id = $1 AND merchant_id = $2 AND status = 'active'
+  AND EXISTS (
+      SELECT 1
+      FROM merchant_api_keys other
+      WHERE other.merchant_id = $2 AND other.id <> $1 AND other.mode = merchant_api_keys.mode
+        AND other.status = 'active' AND (other.expires_at IS NULL OR other.expires_at > NOW())
+        AND 'keys:write' = ANY(other.scopes)
+  );
+
+-- name: TouchMerchantAPIKey :exec
+-- Writes at most once a minute per key so authenticating does not update a row on every request.
+UPDATE merchant_api_keys
+SET last_used_at = NOW()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
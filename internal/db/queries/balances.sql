@@ -1,31 +1,32 @@
 -- name: GetMerchantBalances :many
-SELECT id, merchant_id, currency, available_balance, total_deposit, total_transaction_count, last_updated
+SELECT id, merchant_id, mode, currency, available_balance, total_deposit, total_transaction_count, last_updated
 FROM merchant_balances
-WHERE merchant_id = $1;
+WHERE merchant_id = $1 AND mode = $2;
 
 -- name: GetMerchantBalance :one
-SELECT id, merchant_id, currency, available_balance, total_deposit, total_transaction_count, last_updated
+SELECT id, merchant_id, mode, currency, available_balance, total_deposit, total_transaction_count, last_updated
 FROM merchant_balances
-WHERE merchant_id = $1 AND currency = $2;
+WHERE merchant_id = $1 AND currency = $2 AND mode = $3;
 
 -- name: UpdateMerchantBalance :one
 UPDATE merchant_balances
 SET available_balance = $3, total_deposit = $4, total_transaction_count = $5, last_updated = NOW()
-WHERE merchant_id = $1 AND currency = $2
-RETURNING id, merchant_id, currency, available_balance, total_deposit, total_transaction_count, last_updated;
+WHERE merchant_id = $1 AND currency = $2 AND mode = $6
+RETURNING id, merchant_id, mode, currency, available_balance, total_deposit, total_transaction_count, last_updated;
 
 -- name: CreateMerchantBalance :one
-INSERT INTO merchant_balances (merchant_id, currency)
-VALUES ($1, $2)
-RETURNING id, merchant_id, currency, available_balance, total_deposit, total_transaction_count, last_updated;
+INSERT INTO merchant_balances (merchant_id, currency, mode)
+VALUES ($1, $2, $3)
+RETURNING id, merchant_id, mode, currency, available_balance, total_deposit, total_transaction_count, last_updated;
 
 -- name: IncrementMerchantBalance :one
-INSERT INTO merchant_balances (merchant_id, currency, available_balance, total_deposit, total_transaction_count)
-VALUES ($1, $2, $3::decimal - $4::decimal, $3::decimal, 1)
-ON CONFLICT (merchant_id, currency)
+-- Test and live payments credit separate rows, so sandbox traffic never moves a live balance.
+INSERT INTO merchant_balances (merchant_id, currency, available_balance, total_deposit, total_transaction_count, mode)
+VALUES ($1, $2, $3::decimal - $4::decimal, $3::decimal, 1, $5)
+ON CONFLICT (merchant_id, mode, currency)
 DO UPDATE SET
     available_balance = merchant_balances.available_balance + $3::decimal - $4::decimal,
     total_deposit = merchant_balances.total_deposit + $3::decimal,
     total_transaction_count = merchant_balances.total_transaction_count + 1,
     last_updated = NOW()
-RETURNING id, merchant_id, currency, available_balance, total_deposit, total_transaction_count, last_updated;
+RETURNING id, merchant_id, mode, currency, available_balance, total_deposit, total_transaction_count, last_updated;
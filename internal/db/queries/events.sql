@@ -0,0 +1,21 @@
+-- name: InsertOutboxEvent :exec
+INSERT INTO event_outbox (id, event_type, exchange, routing_key, payload)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ClaimOutboxEvents :many
+SELECT id, event_type, exchange, routing_key, payload, attempts, last_error, created_at, published_at
+FROM event_outbox
+WHERE published_at IS NULL
+ORDER BY created_at
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE event_outbox
+SET published_at = NOW(), attempts = attempts + 1, last_error = NULL
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE event_outbox
+SET attempts = attempts + 1, last_error = $2
+WHERE id = $1;
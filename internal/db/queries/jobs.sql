@@ -0,0 +1,64 @@
+-- name: UpsertJobBinding :exec
+INSERT INTO job_bindings (exchange, pattern, queue)
+VALUES ($1, $2, $3)
+ON CONFLICT (exchange, pattern, queue) DO NOTHING;
+
+-- name: ListJobBindings :many
+SELECT exchange, pattern, queue, created_at
+FROM job_bindings
+WHERE exchange = $1;
+
+-- name: EnqueueJob :one
+INSERT INTO jobs (queue, exchange, routing_key, message_id, message_type, content_type, headers, payload, max_attempts, run_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, queue, exchange, routing_key, message_id, message_type, content_type, headers, payload, status, attempts, max_attempts, run_at, locked_until, locked_by, last_error, created_at, updated_at;
+
+-- name: ClaimJobs :many
+UPDATE jobs
+SET status = 'running', attempts = attempts + 1, locked_until = NOW() + make_interval(secs => @visibility_seconds::int), locked_by = @locked_by, updated_at = NOW()
+WHERE id IN (
+    SELECT j.id
+    FROM jobs j
+    WHERE j.queue = @queue AND j.run_at <= NOW()
+      AND (j.status = 'queued' OR (j.status = 'running' AND j.locked_until < NOW()))
+      AND j.attempts < j.max_attempts
+    ORDER BY j.run_at
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, queue, exchange, routing_key, message_id, message_type, content_type, headers, payload, status, attempts, max_attempts, run_at, locked_until, locked_by, last_error, created_at, updated_at;
+
+-- name: FailExhaustedJobs :execrows
+-- Running jobs whose visibility timeout expired on their last attempt are failed rather than
+-- claimed again
+UPDATE jobs
+SET status = 'failed', last_error = 'visibility timeout expired on the last attempt', locked_until = NULL, locked_by = NULL, updated_at = NOW()
+WHERE queue = $1 AND status = 'running' AND locked_until < NOW() AND attempts >= max_attempts;
+
+-- name: ExtendJobLock :execrows
+-- Keeps a job hidden while its consumer is still working on it. attempts identifies the claim, so
+-- a consumer whose claim expired cannot extend the claim that replaced it.
+UPDATE jobs
+SET locked_until = NOW() + make_interval(secs => @visibility_seconds::int), updated_at = NOW()
+WHERE id = @id AND locked_by = @locked_by AND attempts = @attempts AND status = 'running';
+
+-- name: CompleteJob :execrows
+UPDATE jobs
+SET status = 'done', locked_until = NULL, updated_at = NOW()
+WHERE id = $1 AND locked_by = $2 AND attempts = $3 AND status = 'running';
+
+-- name: RetryJob :execrows
+UPDATE jobs
+SET status = CASE WHEN attempts >= max_attempts THEN 'failed'::job_status ELSE 'queued'::job_status END,
+    run_at = @run_at, last_error = @last_error, locked_until = NULL, locked_by = NULL, updated_at = NOW()
+WHERE id = @id AND locked_by = @locked_by AND attempts = @attempts AND status = 'running';
+
+-- name: FailJob :execrows
+UPDATE jobs
+SET status = 'failed', last_error = $3, locked_until = NULL, locked_by = NULL, updated_at = NOW()
+WHERE id = $1 AND locked_by = $2 AND attempts = $4 AND status = 'running';
+
+-- name: ReleaseJob :exec
+UPDATE jobs
+SET status = 'queued', attempts = GREATEST(attempts - 1, 0), locked_until = NULL, locked_by = NULL, updated_at = NOW()
+WHERE id = $1 AND locked_by = $2 AND status = 'running';
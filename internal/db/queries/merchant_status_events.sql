@@ -0,0 +1,10 @@
+-- name: CreateMerchantStatusEvent :one
+INSERT INTO merchant_status_events (merchant_id, from_status, to_status, reason, actor)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, merchant_id, from_status, to_status, reason, actor, created_at;
+
+-- name: ListMerchantStatusEvents :many
+SELECT id, merchant_id, from_status, to_status, reason, actor, created_at
+FROM merchant_status_events
+WHERE merchant_id = $1
+ORDER BY created_at DESC;
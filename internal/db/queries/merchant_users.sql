@@ -0,0 +1,118 @@
+-- name: CreateMerchantUser :one
+INSERT INTO merchant_users (merchant_id, email, name, role, invite_token_hash, invite_expires_at, invited_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until;
+
+-- name: RefreshMerchantUserInvite :one
+-- Re-invites a user who has not accepted yet or was removed. A removed user starts over with
+-- no password or TOTP.
+UPDATE merchant_users
+SET status = 'invited', name = $2, role = $3, invite_token_hash = $4, invite_expires_at = $5, invited_by = $6,
+    password_hash = NULL, totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL,
+    failed_login_attempts = 0, locked_until = NULL, updated_at = NOW()
+WHERE id = $1 AND status <> 'active'
+RETURNING id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until;
+
+-- name: GetMerchantUser :one
+SELECT id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until
+FROM merchant_users
+WHERE id = $1 AND merchant_id = $2;
+
+-- name: GetMerchantUserByID :one
+SELECT id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until
+FROM merchant_users
+WHERE id = $1;
+
+-- name: GetMerchantUserByEmail :one
+SELECT id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until
+FROM merchant_users
+WHERE email = $1;
+
+-- name: GetMerchantUserByInviteToken :one
+SELECT id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until
+FROM merchant_users
+WHERE invite_token_hash = $1 AND status = 'invited';
+
+-- name: ListMerchantUsers :many
+SELECT id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until
+FROM merchant_users
+WHERE merchant_id = $1
+ORDER BY created_at;
+
+-- name: CountActiveMerchantOwners :one
+SELECT COUNT(*)
+FROM merchant_users
+WHERE merchant_id = $1 AND role = 'owner' AND status = 'active';
+
+-- name: AcceptMerchantUserInvite :one
+UPDATE merchant_users
+SET status = 'active', name = $2, password_hash = $3, invite_token_hash = NULL, invite_expires_at = NULL, updated_at = NOW()
+WHERE id = $1 AND status = 'invited'
+RETURNING id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until;
+
+-- name: UpdateMerchantUserRole :one
+UPDATE merchant_users
+SET role = $3, updated_at = NOW()
+WHERE id = $1 AND merchant_id = $2
+RETURNING id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until;
+
+-- name: DisableMerchantUser :one
+UPDATE merchant_users
+SET status = 'disabled', invite_token_hash = NULL, invite_expires_at = NULL, updated_at = NOW()
+WHERE id = $1 AND merchant_id = $2
+RETURNING id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until;
+
+-- name: SetMerchantUserTOTPSecret :one
+-- Starts (or restarts) enrollment; refused once TOTP is enabled
+UPDATE merchant_users
+SET totp_secret = $2, totp_last_step = NULL, updated_at = NOW()
+WHERE id = $1 AND totp_enabled_at IS NULL
+RETURNING id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until;
+
+-- name: EnableMerchantUserTOTP :one
+UPDATE merchant_users
+SET totp_enabled_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+RETURNING id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until;
+
+-- name: DisableMerchantUserTOTP :one
+UPDATE merchant_users
+SET totp_secret = NULL, totp_enabled_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING id, merchant_id, email, name, role, status, password_hash, totp_secret, totp_enabled_at, invite_token_hash, invite_expires_at, invited_by, last_login_at, created_at, updated_at, totp_last_step, failed_login_attempts, locked_until;
+
+-- name: TouchMerchantUserLogin :exec
+UPDATE merchant_users
+SET last_login_at = NOW(), failed_login_attempts = 0, locked_until = NULL
+WHERE id = $1;
+
+-- name: RecordMerchantUserLoginFailure :one
+-- Counts a failed sign-in. The max_attempts-th failure in a row locks the user out until
+-- locked_until and starts the count again.
+UPDATE merchant_users
+SET failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= @max_attempts::int THEN 0 ELSE failed_login_attempts + 1 END,
+    locked_until = CASE WHEN failed_login_attempts + 1 >= @max_attempts::int THEN @locked_until::timestamptz ELSE locked_until END
+WHERE id = @id
+RETURNING locked_until;
+
+-- name: UseMerchantUserTOTPStep :execrows
+-- Records the time step of an accepted TOTP code. Refused when that step or a later one was
+-- already used, so a code cannot be replayed.
+UPDATE merchant_users
+SET totp_last_step = @step::bigint
+WHERE id = @id AND (totp_last_step IS NULL OR totp_last_step < @step::bigint);
+
+-- name: ListMerchantUserTOTPSecretsForRekey :many
+-- Pages through stored TOTP secrets, pending enrollments included, in id order
+SELECT id, totp_secret
+FROM merchant_users
+WHERE id > @after_id AND totp_secret IS NOT NULL
+ORDER BY id
+LIMIT @row_limit;
+
+-- name: ReencryptMerchantUserTOTPSecret :execrows
+-- Replaces the ciphertext only if it is still the one that was read, so a secret changed
+-- in the meantime is left alone
+UPDATE merchant_users
+SET totp_secret = @new_secret
+WHERE id = @id AND totp_secret = @old_secret;
@@ -1,13 +1,7 @@
 -- name: CreateMerchant :one
 INSERT INTO merchants (merchant_id, name, email)
 VALUES ($1, $2, $3)
-RETURNING id, merchant_id, name, email, status, created_at, updated_at;
-
--- name: GetMerchantByAPIKey :one
-SELECT m.id, m.name, m.email, m.status, m.created_at, m.updated_at
-FROM merchants m
-JOIN merchant_api_keys mak ON m.id = mak.merchant_id
-WHERE mak.api_key = $1 AND mak.status = 'active' AND m.status = 'active';
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier;
 
 -- name: GetMerchant :one
 SELECT id, name, email, status, created_at, updated_at
@@ -15,25 +9,113 @@ FROM merchants
 WHERE id = $1;
 
 -- name: GetMerchantByMerchantID :one
-SELECT id, merchant_id, name, email, status, created_at, updated_at
+SELECT id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier
 FROM merchants
 WHERE merchant_id = $1;
 
 -- name: GetMerchantWithAPIKey :one
-SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at,
-       mak.api_key, mak.secret_key, mak.status as api_key_status, mak.created_at as api_key_created_at
+SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at, m.email_verified_at, m.pending_email, m.tier,
+       mak.key_prefix, mak.last_four, mak.status as api_key_status, mak.created_at as api_key_created_at
+FROM merchants m
+JOIN merchant_api_keys mak ON m.id = mak.merchant_id
+WHERE m.merchant_id = $1 AND mak.mode = $2 AND mak.status = 'active' AND (mak.expires_at IS NULL OR mak.expires_at > NOW())
+ORDER BY mak.created_at DESC
+LIMIT 1;
+
+-- name: GetMerchantByAPIKeyPrefix :one
+-- The caller verifies the key against key_hash. Suspended merchants authenticate so they can still
+-- read their account; inactive ones do not.
+SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at, m.email_verified_at, m.pending_email, m.tier,
+       mak.id as api_key_id, mak.key_hash, mak.key_salt, mak.must_rotate, mak.scopes, mak.mode, mak.status as api_key_status, mak.created_at as api_key_created_at
 FROM merchants m
 JOIN merchant_api_keys mak ON m.id = mak.merchant_id
-WHERE m.merchant_id = $1 AND mak.status = 'active';
+WHERE mak.key_prefix = $1 AND mak.status = 'active' AND (mak.expires_at IS NULL OR mak.expires_at > NOW())
+  AND m.status IN ('active', 'suspended');
 
--- name: GetMerchantByAPIKeyValue :one
-SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at,
-       mak.api_key, mak.secret_key, mak.status as api_key_status, mak.created_at as api_key_created_at
+-- name: GetMerchantByLegacyAPIKey :one
+-- Keys issued before hash-only storage have no prefix and an unsalted hash.
+SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at, m.email_verified_at, m.pending_email, m.tier,
+       mak.id as api_key_id, mak.key_hash, mak.key_salt, mak.must_rotate, mak.scopes, mak.mode, mak.status as api_key_status, mak.created_at as api_key_created_at
 FROM merchants m
 JOIN merchant_api_keys mak ON m.id = mak.merchant_id
-WHERE mak.api_key = $1 AND mak.status = 'active' AND m.status = 'active';
+WHERE mak.key_hash = $1 AND mak.key_prefix IS NULL AND mak.status = 'active' AND (mak.expires_at IS NULL OR mak.expires_at > NOW())
+  AND m.status IN ('active', 'suspended');
 
 -- name: CreateMerchantAPIKey :one
-INSERT INTO merchant_api_keys (merchant_id, api_key, secret_key)
-VALUES ($1, $2, $3)
-RETURNING id, merchant_id, api_key, status, created_at, expires_at;
+INSERT INTO merchant_api_keys (merchant_id, key_prefix, key_hash, key_salt, last_four, label, scopes, mode)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, merchant_id, key_prefix, key_hash, key_salt, last_four, status, created_at, expires_at, label, last_used_at, revoked_at, must_rotate, scopes, mode;
+
+-- name: ListMerchants :many
+-- query matches the merchant ID, name or email, ignoring case.
+SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at, m.email_verified_at, m.pending_email, m.tier,
+       (SELECT COUNT(*) FROM merchant_api_keys mak
+        WHERE mak.merchant_id = m.id AND mak.status = 'active' AND (mak.expires_at IS NULL OR mak.expires_at > NOW())) AS active_api_keys,
+       (SELECT COUNT(*) FROM payment_intents pi WHERE pi.merchant_id = m.merchant_id AND pi.status = 'pending') AS pending_intents,
+       (SELECT MAX(mse.created_at) FROM merchant_status_events mse WHERE mse.merchant_id = m.id) AS status_changed_at
+FROM merchants m
+WHERE (sqlc.narg('status')::merchant_status IS NULL OR m.status = sqlc.narg('status'))
+  AND (sqlc.narg('query')::text IS NULL
+       OR m.merchant_id ILIKE '%' || sqlc.narg('query') || '%'
+       OR m.name ILIKE '%' || sqlc.narg('query') || '%'
+       OR m.email ILIKE '%' || sqlc.narg('query') || '%')
+ORDER BY m.created_at DESC
+LIMIT @row_limit OFFSET @row_offset;
+
+-- name: GetMerchantSummary :one
+SELECT m.id, m.merchant_id, m.name, m.email, m.status, m.created_at, m.updated_at, m.email_verified_at, m.pending_email, m.tier,
+       (SELECT COUNT(*) FROM merchant_api_keys mak
+        WHERE mak.merchant_id = m.id AND mak.status = 'active' AND (mak.expires_at IS NULL OR mak.expires_at > NOW())) AS active_api_keys,
+       (SELECT COUNT(*) FROM payment_intents pi WHERE pi.merchant_id = m.merchant_id AND pi.status = 'pending') AS pending_intents,
+       (SELECT MAX(mse.created_at) FROM merchant_status_events mse WHERE mse.merchant_id = m.id) AS status_changed_at
+FROM merchants m
+WHERE m.merchant_id = $1;
+
+-- name: LockMerchantByMerchantID :one
+SELECT id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier
+FROM merchants
+WHERE merchant_id = $1
+FOR UPDATE;
+
+-- name: UpdateMerchantStatus :one
+UPDATE merchants
+SET status = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier;
+
+-- name: UpdateMerchantTier :one
+UPDATE merchants
+SET tier = $2, updated_at = NOW()
+WHERE merchant_id = $1
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier;
+
+-- name: UpdateMerchantName :one
+UPDATE merchants
+SET name = $2, updated_at = NOW()
+WHERE merchant_id = $1
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier;
+
+-- name: UpdateMerchantPendingEmail :one
+-- A NULL pending_email cancels an unconfirmed change
+UPDATE merchants
+SET pending_email = $2, updated_at = NOW()
+WHERE merchant_id = $1
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier;
+
+-- name: MerchantEmailInUse :one
+SELECT EXISTS (
+    SELECT 1 FROM merchants
+    WHERE email = $1 AND merchant_id <> $2
+) AS in_use;
+
+-- name: ConfirmMerchantEmail :one
+-- Marks email as verified. It succeeds only for the address the token was issued for: either the
+-- pending address, which then replaces the current one, or the current address while unverified.
+UPDATE merchants
+SET email = $2,
+    email_verified_at = NOW(),
+    pending_email = CASE WHEN pending_email = $2 THEN NULL ELSE pending_email END,
+    updated_at = NOW()
+WHERE merchant_id = $1
+  AND (pending_email = $2 OR (email = $2 AND email_verified_at IS NULL))
+RETURNING id, merchant_id, name, email, status, created_at, updated_at, email_verified_at, pending_email, tier;
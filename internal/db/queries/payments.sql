@@ -1,29 +1,50 @@
 -- name: CreatePaymentIntent :one
-INSERT INTO payment_intents (payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, metadata)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, status, metadata, created_at, updated_at, expires_at;
+INSERT INTO payment_intents (payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, metadata, mode, account_number)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, status, metadata, mode, account_number, created_at, updated_at, expires_at;
 
 -- name: GetPaymentIntent :one
-SELECT pi.id, pi.payment_intent_id, pi.merchant_id, pi.amount, pi.currency, pi.description, pi.callback_url, pi.nonce, pi.status, pi.metadata, pi.created_at, pi.updated_at, pi.expires_at
+SELECT pi.id, pi.payment_intent_id, pi.merchant_id, pi.amount, pi.currency, pi.description, pi.callback_url, pi.nonce, pi.status, pi.metadata, pi.mode, pi.account_number, pi.created_at, pi.updated_at, pi.expires_at
 FROM payment_intents pi
 WHERE pi.payment_intent_id = $1;
 
+-- name: GetMerchantPaymentIntent :one
+SELECT id, payment_intent_id, merchant_id, amount, currency, status, description, callback_url, nonce, metadata, callback_ack_status, callback_ack_message, callback_ack_at, mode, account_number, created_at, updated_at, expires_at
+FROM payment_intents
+WHERE payment_intent_id = $1 AND merchant_id = $2 AND mode = $3;
+
 -- name: GetPaymentIntentByID :one
 SELECT id, payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, status, metadata, created_at, updated_at, expires_at
 FROM payment_intents
 WHERE id = $1;
 
 -- name: GetPaymentIntentByNonce :one
-SELECT id, payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, status, metadata, created_at, updated_at, expires_at
+SELECT id, payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, status, metadata, mode, created_at, updated_at, expires_at
 FROM payment_intents
 WHERE merchant_id = $1 AND nonce = $2;
 
+-- name: ListPendingMerchantPaymentIntents :many
+-- Unexpired pending intents, oldest first, to queue again once a suspended merchant is reactivated.
+SELECT payment_intent_id, merchant_id, amount, currency, created_at
+FROM payment_intents
+WHERE merchant_id = $1 AND status = 'pending' AND expires_at > NOW()
+ORDER BY created_at;
+
 -- name: UpdatePaymentIntentStatus :one
 UPDATE payment_intents
 SET status = $2, updated_at = NOW()
 WHERE id = $1 AND status = $3
 RETURNING id, payment_intent_id, merchant_id, amount, currency, description, callback_url, nonce, status, metadata, created_at, updated_at, expires_at;
 
+-- name: UpdatePaymentIntentCallbackAck :exec
+-- An acknowledgement is final, and an unacknowledged delivery can only be upgraded to one; any other
+-- state is replaced by the latest outcome.
+UPDATE payment_intents
+SET callback_ack_status = @callback_ack_status, callback_ack_message = @callback_ack_message, callback_ack_at = NOW(), updated_at = NOW()
+WHERE payment_intent_id = @payment_intent_id
+  AND callback_ack_status <> 'acknowledged'
+  AND (callback_ack_status <> 'unacknowledged' OR @callback_ack_status = 'acknowledged');
+
 -- name: LockPaymentIntentForProcessing :one
 SELECT pi.id, pi.payment_intent_id, pi.merchant_id, pi.amount, pi.currency, pi.description, pi.callback_url, pi.nonce, pi.status, pi.metadata, pi.created_at, pi.updated_at, pi.expires_at
 FROM payment_intents pi
@@ -31,23 +52,28 @@ WHERE pi.id = $1 AND pi.status = $2
 FOR UPDATE;
 
 -- name: CreatePaymentTransaction :one
-INSERT INTO payment_transactions (payment_intent_id, merchant_id, amount, currency, payment_method, fee_amount, account_number)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, processed_at, created_at, updated_at;
+INSERT INTO payment_transactions (payment_intent_id, merchant_id, amount, currency, payment_method, fee_amount, account_number, mode)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, mode, processed_at, created_at, updated_at;
 
 -- name: UpdatePaymentTransactionStatus :one
 UPDATE payment_transactions
 SET status = $2, third_party_reference = $3, processed_at = NOW(), updated_at = NOW()
 WHERE id = $1 AND status = $4
-RETURNING id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, processed_at, created_at, updated_at;
+RETURNING id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, mode, processed_at, created_at, updated_at;
 
 -- name: GetPaymentTransaction :one
-SELECT id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, processed_at, created_at, updated_at
+SELECT id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, mode, processed_at, created_at, updated_at
 FROM payment_transactions
 WHERE id = $1;
 
 -- name: GetMerchantTransactions :many
-SELECT id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, processed_at, created_at, updated_at
+SELECT id, payment_intent_id, merchant_id, amount, currency, status, third_party_reference, payment_method, fee_amount, account_number, mode, processed_at, created_at, updated_at
 FROM payment_transactions
-WHERE merchant_id = $1
+WHERE merchant_id = $1 AND mode = $2
 ORDER BY created_at DESC;
+
+-- name: ExpirePendingPaymentIntents :execrows
+UPDATE payment_intents
+SET status = 'expired', updated_at = NOW()
+WHERE status = 'pending' AND expires_at < NOW();
@@ -0,0 +1,25 @@
+-- name: TryAdvisoryLock :one
+SELECT pg_try_advisory_lock(@key::bigint);
+
+-- name: ReleaseAdvisoryLock :one
+SELECT pg_advisory_unlock(@key::bigint);
+
+-- name: CreateScheduledJobRun :one
+-- Records nothing, and returns no row, when the job already has a run for scheduled_for. Manual
+-- runs have no scheduled_for and are always recorded.
+INSERT INTO scheduled_job_runs (job_name, trigger_type, instance, scheduled_for)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (job_name, scheduled_for) DO NOTHING
+RETURNING id, job_name, trigger_type, status, instance, started_at, finished_at, duration_ms, error, scheduled_for;
+
+-- name: FinishScheduledJobRun :exec
+UPDATE scheduled_job_runs
+SET status = $2, finished_at = NOW(), duration_ms = $3, error = $4
+WHERE id = $1;
+
+-- name: GetLatestScheduledJobRun :one
+SELECT id, job_name, trigger_type, status, instance, started_at, finished_at, duration_ms, error, scheduled_for
+FROM scheduled_job_runs
+WHERE job_name = $1
+ORDER BY started_at DESC
+LIMIT 1;
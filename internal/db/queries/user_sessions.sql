@@ -0,0 +1,32 @@
+-- name: CreateUserSession :one
+INSERT INTO user_sessions (user_id, token_hash, ip_address, user_agent, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, token_hash, ip_address, user_agent, expires_at, revoked_at, last_seen_at, created_at;
+
+-- name: GetUserSessionByTokenHash :one
+-- Only live sessions of active users resolve. As with API keys, users of suspended merchants can
+-- still sign in to read their account; users of inactive merchants cannot.
+SELECT s.id AS session_id, s.expires_at AS session_expires_at,
+       u.id AS user_id, u.email, u.name, u.role, u.status, u.totp_enabled_at, u.invited_by, u.last_login_at, u.created_at,
+       m.merchant_id, m.name AS merchant_name, m.email AS merchant_email, m.status AS merchant_status, m.email_verified_at, m.tier AS merchant_tier
+FROM user_sessions s
+JOIN merchant_users u ON u.id = s.user_id
+JOIN merchants m ON m.id = u.merchant_id
+WHERE s.token_hash = $1 AND s.revoked_at IS NULL AND s.expires_at > NOW()
+  AND u.status = 'active' AND m.status IN ('active', 'suspended');
+
+-- name: TouchUserSession :exec
+-- Writes at most once a minute per session, like TouchMerchantAPIKey
+UPDATE user_sessions
+SET last_seen_at = NOW()
+WHERE id = $1 AND (last_seen_at IS NULL OR last_seen_at < NOW() - INTERVAL '1 minute');
+
+-- name: RevokeUserSession :exec
+UPDATE user_sessions
+SET revoked_at = NOW()
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserSessionsByUserID :exec
+UPDATE user_sessions
+SET revoked_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,81 @@
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (merchant_id, endpoint_id, payment_intent_id, event_type, url, payload)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, merchant_id, endpoint_id, payment_intent_id, event_type, url, payload, resend_of, status, attempts, next_attempt_at, last_response_code, last_error, delivered_at, created_at, updated_at;
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => @lease_seconds::int), updated_at = NOW()
+WHERE id IN (
+    SELECT d.id
+    FROM webhook_deliveries d
+    WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+      AND NOT EXISTS (
+          SELECT 1
+          FROM webhook_endpoints e
+          WHERE e.id = d.endpoint_id AND e.circuit_opened_at > NOW() - make_interval(secs => @circuit_cooldown_seconds::int)
+      )
+    ORDER BY d.next_attempt_at
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, merchant_id, endpoint_id, payment_intent_id, event_type, url, payload, resend_of, status, attempts, next_attempt_at, last_response_code, last_error, delivered_at, created_at, updated_at;
+
+-- name: RecordWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (delivery_id, attempt_number, response_code, latency_ms, response_body, acknowledged, ack_message, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered', last_response_code = $2, last_error = NULL, delivered_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status = 'pending';
+
+-- name: DeferWebhookDelivery :exec
+-- Hands back a claimed delivery that was not attempted, without counting it as an attempt.
+UPDATE webhook_deliveries
+SET attempts = attempts - 1, next_attempt_at = $2, updated_at = NOW()
+WHERE id = $1 AND status = 'pending';
+
+-- name: ExhaustWebhookEndpointDeliveries :many
+UPDATE webhook_deliveries
+SET status = 'exhausted', last_error = @last_error, updated_at = NOW()
+WHERE endpoint_id = @endpoint_id AND status = 'pending' AND id <> @except_id
+RETURNING payment_intent_id;
+
+-- name: RetryWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET next_attempt_at = $2, last_response_code = $3, last_error = $4, updated_at = NOW()
+WHERE id = $1 AND status = 'pending';
+
+-- name: MarkWebhookDeliveryExhausted :exec
+UPDATE webhook_deliveries
+SET status = 'exhausted', last_response_code = $2, last_error = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'pending';
+
+-- name: GetWebhookDelivery :one
+SELECT id, merchant_id, endpoint_id, payment_intent_id, event_type, url, payload, resend_of, status, attempts, next_attempt_at, last_response_code, last_error, delivered_at, created_at, updated_at
+FROM webhook_deliveries
+WHERE id = $1 AND merchant_id = $2;
+
+-- name: ListWebhookDeliveries :many
+SELECT id, merchant_id, endpoint_id, payment_intent_id, event_type, url, payload, resend_of, status, attempts, next_attempt_at, last_response_code, last_error, delivered_at, created_at, updated_at
+FROM webhook_deliveries
+WHERE merchant_id = @merchant_id
+  AND (sqlc.narg('payment_intent_id')::text IS NULL OR payment_intent_id = sqlc.narg('payment_intent_id'))
+  AND (sqlc.narg('status')::webhook_delivery_status IS NULL OR status = sqlc.narg('status'))
+  AND created_at >= @created_from AND created_at < @created_to
+ORDER BY created_at DESC
+LIMIT @row_limit;
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT id, delivery_id, attempt_number, response_code, latency_ms, response_body, acknowledged, ack_message, error, attempted_at
+FROM webhook_delivery_attempts
+WHERE delivery_id = ANY(@delivery_ids::uuid[])
+ORDER BY delivery_id, attempt_number;
+
+-- name: ResendWebhookDelivery :one
+INSERT INTO webhook_deliveries (merchant_id, endpoint_id, payment_intent_id, event_type, url, payload, resend_of)
+SELECT d.merchant_id, d.endpoint_id, d.payment_intent_id, d.event_type, d.url, d.payload, d.id
+FROM webhook_deliveries d
+WHERE d.id = $1 AND d.merchant_id = $2
+RETURNING id, merchant_id, endpoint_id, payment_intent_id, event_type, url, payload, resend_of, status, attempts, next_attempt_at, last_response_code, last_error, delivered_at, created_at, updated_at;
@@ -0,0 +1,69 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (merchant_id, url, event_types, enabled, description)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, merchant_id, url, event_types, enabled, description, consecutive_failures, failing_since, circuit_opened_at, disabled_reason, disabled_at, created_at, updated_at;
+
+-- name: GetWebhookEndpoint :one
+SELECT id, merchant_id, url, event_types, enabled, description, consecutive_failures, failing_since, circuit_opened_at, disabled_reason, disabled_at, created_at, updated_at
+FROM webhook_endpoints
+WHERE id = $1 AND merchant_id = $2;
+
+-- name: ListWebhookEndpoints :many
+SELECT id, merchant_id, url, event_types, enabled, description, consecutive_failures, failing_since, circuit_opened_at, disabled_reason, disabled_at, created_at, updated_at
+FROM webhook_endpoints
+WHERE merchant_id = $1
+ORDER BY created_at;
+
+-- name: ListWebhookEndpointsForEvent :many
+SELECT id, merchant_id, url, event_types, enabled, description, consecutive_failures, failing_since, circuit_opened_at, disabled_reason, disabled_at, created_at, updated_at
+FROM webhook_endpoints
+WHERE merchant_id = @merchant_id AND enabled
+  AND (@event_type::text = ANY(event_types) OR '*' = ANY(event_types))
+ORDER BY created_at;
+
+-- name: UpdateWebhookEndpoint :one
+-- Re-enabling an endpoint clears its failure streak and closes the circuit.
+UPDATE webhook_endpoints
+SET url = $3, event_types = $4, enabled = $5, description = $6,
+    consecutive_failures = CASE WHEN $5 AND NOT enabled THEN 0 ELSE consecutive_failures END,
+    failing_since = CASE WHEN $5 AND NOT enabled THEN NULL ELSE failing_since END,
+    circuit_opened_at = CASE WHEN $5 AND NOT enabled THEN NULL ELSE circuit_opened_at END,
+    disabled_reason = CASE WHEN $5 THEN NULL ELSE disabled_reason END,
+    disabled_at = CASE WHEN $5 THEN NULL ELSE disabled_at END,
+    updated_at = NOW()
+WHERE id = $1 AND merchant_id = $2
+RETURNING id, merchant_id, url, event_types, enabled, description, consecutive_failures, failing_since, circuit_opened_at, disabled_reason, disabled_at, created_at, updated_at;
+
+-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoints
+WHERE id = $1 AND merchant_id = $2;
+
+-- name: GetWebhookEndpointCircuit :one
+SELECT circuit_opened_at
+FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: AcquireWebhookEndpointProbe :execrows
+-- Only one dispatcher wins the half-open probe: renewing circuit_opened_at closes the window for the rest.
+UPDATE webhook_endpoints
+SET circuit_opened_at = NOW(), updated_at = NOW()
+WHERE id = @id AND circuit_opened_at <= NOW() - make_interval(secs => @cooldown_seconds::int);
+
+-- name: RecordWebhookEndpointSuccess :exec
+UPDATE webhook_endpoints
+SET consecutive_failures = 0, failing_since = NULL, circuit_opened_at = NULL, updated_at = NOW()
+WHERE id = $1 AND (consecutive_failures > 0 OR circuit_opened_at IS NOT NULL);
+
+-- name: RecordWebhookEndpointFailure :one
+UPDATE webhook_endpoints
+SET consecutive_failures = consecutive_failures + 1,
+    failing_since = COALESCE(failing_since, NOW()),
+    circuit_opened_at = CASE WHEN consecutive_failures + 1 >= @failure_threshold::int THEN NOW() ELSE circuit_opened_at END,
+    updated_at = NOW()
+WHERE id = @id
+RETURNING id, merchant_id, url, event_types, enabled, description, consecutive_failures, failing_since, circuit_opened_at, disabled_reason, disabled_at, created_at, updated_at;
+
+-- name: DisableWebhookEndpoint :execrows
+UPDATE webhook_endpoints
+SET enabled = FALSE, disabled_reason = $2, disabled_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND enabled;
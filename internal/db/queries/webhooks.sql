@@ -0,0 +1,50 @@
+-- name: CreateMerchantWebhookSecret :one
+INSERT INTO merchant_webhook_secrets (merchant_id, secret)
+VALUES ($1, $2)
+RETURNING id, merchant_id, secret, created_at, expires_at;
+
+-- name: CreateMissingMerchantWebhookSecret :execrows
+-- Stores the secret only while the merchant still has no unexpired one
+INSERT INTO merchant_webhook_secrets (merchant_id, secret)
+SELECT @merchant_id::uuid, @secret::text
+WHERE NOT EXISTS (
+    SELECT 1 FROM merchant_webhook_secrets
+    WHERE merchant_id = @merchant_id::uuid AND (expires_at IS NULL OR expires_at > NOW())
+);
+
+-- name: ExpireMerchantWebhookSecrets :exec
+UPDATE merchant_webhook_secrets
+SET expires_at = $3
+WHERE merchant_id = $1 AND id <> $2 AND (expires_at IS NULL OR expires_at > $3);
+
+-- name: ListActiveWebhookSecretsByMerchantID :many
+SELECT s.id, s.merchant_id, s.secret, s.created_at, s.expires_at
+FROM merchant_webhook_secrets s
+JOIN merchants m ON m.id = s.merchant_id
+WHERE m.merchant_id = $1 AND (s.expires_at IS NULL OR s.expires_at > NOW())
+ORDER BY s.created_at DESC;
+
+-- name: ListMerchantsWithoutWebhookSecret :many
+-- Pages through merchants without an unexpired webhook secret, in id order
+SELECT m.id, m.merchant_id
+FROM merchants m
+WHERE m.id > @after_id AND NOT EXISTS (
+    SELECT 1 FROM merchant_webhook_secrets s
+    WHERE s.merchant_id = m.id AND (s.expires_at IS NULL OR s.expires_at > NOW())
+)
+ORDER BY m.id
+LIMIT @row_limit;
+
+-- name: ListWebhookSecretsForRekey :many
+-- Pages through every stored secret, expired ones included, in id order
+SELECT id, secret
+FROM merchant_webhook_secrets
+WHERE id > @after_id
+ORDER BY id
+LIMIT @row_limit;
+
+-- name: ReencryptWebhookSecret :execrows
+-- Replaces the ciphertext only if it is still the one that was read
+UPDATE merchant_webhook_secrets
+SET secret = @new_secret
+WHERE id = @id AND secret = @old_secret;
@@ -1,10 +1,18 @@
 CREATE TYPE merchant_status AS ENUM ('active', 'inactive', 'suspended');
 CREATE TYPE api_key_status AS ENUM ('active', 'inactive', 'expired');
 CREATE TYPE currency_type AS ENUM ('ETB', 'USD');
-CREATE TYPE payment_status AS ENUM ('pending', 'processing', 'success', 'failed', 'cancelled');
+CREATE TYPE payment_status AS ENUM ('pending', 'processing', 'success', 'failed', 'cancelled', 'expired');
 CREATE TYPE transaction_status AS ENUM ('pending', 'success', 'failed');
 CREATE TYPE payment_method_type AS ENUM ('card', 'bank_transfer', 'mobile_money', 'cbe', 'mpesa', 'telebirr', 'awash');
 CREATE TYPE event_type AS ENUM ('created', 'processing', 'completed', 'failed', 'cancelled');
+CREATE TYPE job_status AS ENUM ('queued', 'running', 'done', 'failed');
+CREATE TYPE scheduled_run_status AS ENUM ('running', 'succeeded', 'failed');
+CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'delivered', 'exhausted');
+CREATE TYPE callback_ack_status AS ENUM ('pending', 'acknowledged', 'unacknowledged', 'rejected', 'failed');
+CREATE TYPE api_mode AS ENUM ('test', 'live');
+CREATE TYPE dashboard_role AS ENUM ('owner', 'admin', 'developer', 'finance', 'read_only');
+CREATE TYPE merchant_user_status AS ENUM ('invited', 'active', 'disabled');
+CREATE TYPE merchant_tier AS ENUM ('standard', 'growth', 'enterprise');
 
 CREATE TABLE merchants (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -14,21 +22,94 @@ CREATE TABLE merchants (
     status merchant_status DEFAULT 'active',
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    -- NULL until the merchant follows the link mailed to email; live API keys require it
+    email_verified_at TIMESTAMP WITH TIME ZONE,
+    -- A requested new address, kept apart from email until it is verified
+    pending_email VARCHAR(255),
+    -- Selects the request rate limit for the merchant's API keys and dashboard users
+    tier merchant_tier NOT NULL DEFAULT 'standard',
     UNIQUE(email),
     UNIQUE(merchant_id)
 );
 
+-- API keys are stored as a salted HMAC only. A key looks like api_<public prefix>_<secret>; the
+-- prefix finds the row and the hash verifies the rest, so a key cannot be recovered from the database.
 CREATE TABLE merchant_api_keys (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
-    api_key VARCHAR(255) UNIQUE NOT NULL,
-    secret_key VARCHAR(255) NOT NULL,
+    key_prefix VARCHAR(32) UNIQUE,           -- Public part of the key; NULL for legacy keys
+    key_hash VARCHAR(255) NOT NULL,          -- HMAC-SHA256 of salt and key under API_KEY_HASH_KEY
+    key_salt VARCHAR(64) NOT NULL DEFAULT '',
+    last_four VARCHAR(4) NOT NULL DEFAULT '',
     status api_key_status DEFAULT 'active',
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-    expires_at TIMESTAMP WITH TIME ZONE NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NULL,  -- Set when the key is rotated out; it keeps working until then
+    label VARCHAR(100),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    must_rotate BOOLEAN NOT NULL DEFAULT FALSE, -- Legacy key issued before hash-only storage
+    scopes TEXT[] NOT NULL,                  -- Routes the key may call, e.g. intents:write
+    mode api_mode NOT NULL,                  -- Test keys only see sandbox intents, transactions and balances
     CONSTRAINT fk_merchant_api_keys_merchant FOREIGN KEY (merchant_id) REFERENCES merchants(id)
 );
 
+-- Secrets used to sign merchant callbacks, stored encrypted. A rotated-out secret keeps signing
+-- until expires_at so merchants can switch over without rejecting callbacks.
+CREATE TABLE merchant_webhook_secrets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Audit trail of merchant status changes; actor is the name of the admin key that made the change
+CREATE TABLE merchant_status_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    from_status merchant_status NOT NULL,
+    to_status merchant_status NOT NULL,
+    reason TEXT NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Dashboard logins for a merchant's staff. Users are invited by email and choose a password when
+-- accepting; only hashes of passwords and invite tokens are stored, and the TOTP secret is encrypted.
+CREATE TABLE merchant_users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    email VARCHAR(255) UNIQUE NOT NULL,      -- Login name, so unique across merchants
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    role dashboard_role NOT NULL,
+    status merchant_user_status NOT NULL DEFAULT 'invited',
+    password_hash TEXT,                      -- bcrypt; NULL until the invite is accepted
+    totp_secret TEXT,                        -- Set on enrollment, in use once totp_enabled_at is set
+    totp_enabled_at TIMESTAMP WITH TIME ZONE,
+    invite_token_hash VARCHAR(64) UNIQUE,
+    invite_expires_at TIMESTAMP WITH TIME ZONE,
+    invited_by VARCHAR(255) NOT NULL,        -- Email of the inviting user, or api_key
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    totp_last_step BIGINT,                   -- Time step of the last accepted code, which cannot be used again
+    failed_login_attempts INTEGER NOT NULL DEFAULT 0, -- Failed sign-ins since the last success or lockout
+    locked_until TIMESTAMP WITH TIME ZONE    -- Sign-in is refused until then
+);
+
+-- Dashboard sessions; the bearer token itself is only returned at login
+CREATE TABLE user_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES merchant_users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(500),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    last_seen_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
 CREATE TABLE payment_intents (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     payment_intent_id VARCHAR(20) UNIQUE NOT NULL,
@@ -37,9 +118,14 @@ CREATE TABLE payment_intents (
     currency VARCHAR(3) NOT NULL,
     status payment_status DEFAULT 'pending',
     description TEXT,
-    callback_url VARCHAR(500) NOT NULL,
+    callback_url VARCHAR(500),               -- Optional override of the merchant's registered webhook endpoints
     nonce VARCHAR(64) UNIQUE NOT NULL,
     metadata JSONB,
+    callback_ack_status callback_ack_status NOT NULL DEFAULT 'pending', -- Merchant's answer to the latest callback
+    callback_ack_message TEXT,
+    callback_ack_at TIMESTAMP WITH TIME ZONE,
+    mode api_mode NOT NULL,                  -- Mode of the API key that created the intent
+    account_number VARCHAR(50),              -- Payer account; in test mode it selects the sandbox outcome
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     expires_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() + INTERVAL '30 minutes'),
@@ -57,6 +143,7 @@ CREATE TABLE payment_transactions (
     payment_method payment_method_type,
     fee_amount DECIMAL(10,2) DEFAULT 0 CHECK (fee_amount >= 0),
     account_number VARCHAR(50),
+    mode api_mode NOT NULL,
     processed_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
@@ -65,29 +152,151 @@ CREATE TABLE payment_transactions (
 CREATE TABLE merchant_balances (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     merchant_id UUID NOT NULL REFERENCES merchants(id),
+    mode api_mode NOT NULL,                  -- Test and live funds are kept in separate rows
     currency currency_type NOT NULL,
     available_balance DECIMAL(15,2) DEFAULT 0 CHECK (available_balance >= 0),
     total_deposit DECIMAL(15,2) DEFAULT 0 CHECK (total_deposit >= 0),
     total_transaction_count INTEGER DEFAULT 0 CHECK (total_transaction_count >= 0),
     last_updated TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     CONSTRAINT fk_merchant_balances_merchant FOREIGN KEY (merchant_id) REFERENCES merchants(id),
-    UNIQUE(merchant_id, currency)
+    UNIQUE(merchant_id, mode, currency)
+);
+
+-- Durable queue used when BROKER_DRIVER=postgres
+CREATE TABLE job_bindings (
+    exchange VARCHAR(255) NOT NULL,
+    pattern VARCHAR(255) NOT NULL,
+    queue VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (exchange, pattern, queue)
+);
+
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    queue VARCHAR(255) NOT NULL,
+    exchange VARCHAR(255) NOT NULL,
+    routing_key VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255),
+    message_type VARCHAR(255),
+    content_type VARCHAR(100),
+    headers JSONB,
+    payload BYTEA NOT NULL,
+    status job_status NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 10,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    locked_by VARCHAR(255),
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Domain events written in the same transaction as the state change and relayed to the broker after commit
+CREATE TABLE event_outbox (
+    id UUID PRIMARY KEY,
+    event_type VARCHAR(255) NOT NULL,
+    exchange VARCHAR(255) NOT NULL,
+    routing_key VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Run history of scheduler jobs; one row per run that acquired the job's advisory lock
+CREATE TABLE scheduled_job_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_name VARCHAR(100) NOT NULL,
+    trigger_type VARCHAR(20) NOT NULL,
+    status scheduled_run_status NOT NULL DEFAULT 'running',
+    instance VARCHAR(255) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    duration_ms BIGINT,
+    error TEXT,
+    -- Schedule slot of a scheduled run, so replicas run each slot once; NULL for manual runs
+    scheduled_for TIMESTAMP WITH TIME ZONE
+);
+
+-- Merchant-registered callback URLs; event_types holds event type names or '*' for every event
+CREATE TABLE webhook_endpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id VARCHAR(50) NOT NULL,        -- Custom merchant ID (CASM-XXXX)
+    url VARCHAR(500) NOT NULL,
+    event_types TEXT[] NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    description TEXT,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    failing_since TIMESTAMP WITH TIME ZONE,  -- First failure of the current streak
+    circuit_opened_at TIMESTAMP WITH TIME ZONE, -- Set while the circuit breaker is open; renewed by each half-open probe
+    disabled_reason TEXT,                    -- Set when the endpoint was disabled automatically
+    disabled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- One row per callback owed to a merchant; the dispatcher retries it until delivered or the retry horizon passes
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id VARCHAR(50) NOT NULL,        -- Custom merchant ID (CASM-XXXX)
+    endpoint_id UUID REFERENCES webhook_endpoints(id) ON DELETE SET NULL,  -- NULL for per-intent callback_url overrides
+    payment_intent_id VARCHAR(20) NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    resend_of UUID REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+    status webhook_delivery_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_response_code INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE webhook_delivery_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    attempt_number INTEGER NOT NULL,
+    response_code INTEGER,
+    latency_ms BIGINT NOT NULL,
+    response_body TEXT,                      -- First 1 KiB of the response
+    acknowledged BOOLEAN,                    -- status from a JSON acknowledgement body, NULL without one
+    ack_message TEXT,
+    error TEXT,
+    attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
 );
 
 CREATE INDEX idx_merchants_email ON merchants(email);
 CREATE INDEX idx_merchants_status ON merchants(status);
 CREATE INDEX idx_merchant_api_keys_merchant ON merchant_api_keys(merchant_id);
-CREATE INDEX idx_merchant_api_keys_api_key ON merchant_api_keys(api_key);
+CREATE INDEX idx_merchant_api_keys_legacy_hash ON merchant_api_keys(key_hash) WHERE key_prefix IS NULL;
 CREATE INDEX idx_merchant_api_keys_status ON merchant_api_keys(status);
-CREATE INDEX idx_payment_intents_merchant ON payment_intents(merchant_id);
+CREATE INDEX idx_payment_intents_merchant ON payment_intents(merchant_id, mode);
 CREATE INDEX idx_payment_intents_payment_intent_id ON payment_intents(payment_intent_id);
 CREATE INDEX idx_payment_intents_nonce ON payment_intents(nonce);
 CREATE INDEX idx_payment_intents_status ON payment_intents(status);
 CREATE INDEX idx_payment_intents_expires_at ON payment_intents(expires_at);
 CREATE INDEX idx_payment_transactions_id ON payment_transactions(id);
 CREATE INDEX idx_payment_transactions_intent_id ON payment_transactions(payment_intent_id);
-CREATE INDEX idx_payment_transactions_merchant ON payment_transactions(merchant_id);
+CREATE INDEX idx_payment_transactions_merchant ON payment_transactions(merchant_id, mode);
 CREATE INDEX idx_payment_transactions_status ON payment_transactions(status);
 CREATE INDEX idx_payment_transactions_third_party_ref ON payment_transactions(third_party_reference);
+CREATE INDEX idx_merchant_webhook_secrets_merchant ON merchant_webhook_secrets(merchant_id);
+CREATE INDEX idx_merchant_status_events_merchant ON merchant_status_events(merchant_id, created_at DESC);
+CREATE INDEX idx_merchant_users_merchant ON merchant_users(merchant_id);
+CREATE INDEX idx_user_sessions_user ON user_sessions(user_id);
 CREATE INDEX idx_merchant_balances_merchant ON merchant_balances(merchant_id);
 CREATE INDEX idx_merchant_balances_currency ON merchant_balances(currency);
+CREATE INDEX idx_jobs_dequeue ON jobs(queue, status, run_at);
+CREATE INDEX idx_event_outbox_unpublished ON event_outbox(created_at) WHERE published_at IS NULL;
+CREATE INDEX idx_scheduled_job_runs_job ON scheduled_job_runs(job_name, started_at DESC);
+CREATE UNIQUE INDEX idx_scheduled_job_runs_slot ON scheduled_job_runs(job_name, scheduled_for);
+CREATE INDEX idx_webhook_endpoints_merchant ON webhook_endpoints(merchant_id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_intent ON webhook_deliveries(payment_intent_id);
+CREATE INDEX idx_webhook_deliveries_merchant_created ON webhook_deliveries(merchant_id, created_at DESC);
+CREATE INDEX idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts(delivery_id, attempt_number);
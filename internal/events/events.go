@@ -0,0 +1,120 @@
+// Package events defines the versioned domain events published to the payment_intents_exchange
+// topic exchange. Every event travels in a CloudEvents-style envelope and its type doubles as
+// the routing key, so consumers can bind to patterns such as "payment.#" or "merchant.balance.*".
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SpecVersion = "1.0"
+	ContentType = "application/cloudevents+json"
+	Exchange    = "payment_intents_exchange"
+
+	SourcePaymentWorker = "/cash-flow-financial/payment-worker"
+)
+
+const (
+	TypePaymentIntentSucceeded      = "payment.intent.succeeded"
+	TypePaymentIntentFailed         = "payment.intent.failed"
+	TypePaymentTransactionSucceeded = "payment.transaction.succeeded"
+	TypePaymentTransactionFailed    = "payment.transaction.failed"
+	TypeMerchantBalanceCredited     = "merchant.balance.credited"
+)
+
+// schemaVersions is the current version of each event's data payload.
+// Adding fields is backwards compatible; renaming or removing one requires a bump.
+var schemaVersions = map[string]int{
+	TypePaymentIntentSucceeded:      1,
+	TypePaymentIntentFailed:         1,
+	TypePaymentTransactionSucceeded: 1,
+	TypePaymentTransactionFailed:    1,
+	TypeMerchantBalanceCredited:     1,
+}
+
+// Envelope carries the CloudEvents 1.0 context attributes plus a schemaversion extension
+type Envelope struct {
+	SpecVersion     string          `json:"specversion" example:"1.0"`
+	ID              string          `json:"id" example:"7f0c6d0e-2b8e-4f59-9b76-1f3c0d4f6a21"`
+	Type            string          `json:"type" example:"payment.intent.succeeded"`
+	Source          string          `json:"source" example:"/cash-flow-financial/payment-worker"`
+	Subject         string          `json:"subject,omitempty" example:"PI-ABC123"`
+	Time            time.Time       `json:"time" example:"2024-01-05T10:35:00Z"`
+	DataContentType string          `json:"datacontenttype" example:"application/json"`
+	SchemaVersion   int             `json:"schemaversion" example:"1"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// PaymentIntentData is the payload of payment.intent.succeeded and payment.intent.failed
+type PaymentIntentData struct {
+	PaymentIntentID string `json:"payment_intent_id" example:"PI-ABC123"`
+	MerchantID      string `json:"merchant_id" example:"CASM-ABC123"`
+	Mode            string `json:"mode" example:"live"`
+	Amount          string `json:"amount" example:"100.50"`
+	Currency        string `json:"currency" example:"ETB"`
+	Status          string `json:"status" example:"success"`
+	FailureReason   string `json:"failure_reason,omitempty"`
+}
+
+// PaymentTransactionData is the payload of payment.transaction.succeeded and payment.transaction.failed
+type PaymentTransactionData struct {
+	TransactionID       string `json:"transaction_id" example:"d49a7dd0-95b9-4636-acf6-d06b87a8e525"`
+	PaymentIntentID     string `json:"payment_intent_id" example:"PI-ABC123"`
+	MerchantID          string `json:"merchant_id" example:"CASM-ABC123"`
+	Mode                string `json:"mode" example:"live"`
+	Amount              string `json:"amount" example:"100.50"`
+	Currency            string `json:"currency" example:"ETB"`
+	Status              string `json:"status" example:"success"`
+	FeeAmount           string `json:"fee_amount" example:"1.01"`
+	PaymentMethod       string `json:"payment_method" example:"card"`
+	ThirdPartyReference string `json:"third_party_reference,omitempty" example:"TP123456789"`
+	FailureReason       string `json:"failure_reason,omitempty"`
+}
+
+// MerchantBalanceData is the payload of merchant.balance.credited
+type MerchantBalanceData struct {
+	MerchantID       string `json:"merchant_id" example:"CASM-ABC123"`
+	Mode             string `json:"mode" example:"live"`
+	PaymentIntentID  string `json:"payment_intent_id" example:"PI-ABC123"`
+	Currency         string `json:"currency" example:"ETB"`
+	CreditedAmount   string `json:"credited_amount" example:"99.49"`
+	FeeAmount        string `json:"fee_amount" example:"1.01"`
+	AvailableBalance string `json:"available_balance" example:"99.49"`
+	TotalDeposit     string `json:"total_deposit" example:"100.50"`
+	TransactionCount int32  `json:"transaction_count" example:"1"`
+}
+
+// New wraps data in an envelope with a fresh ID and the current schema version of eventType
+func New(eventType, source, subject string, data interface{}) (*Envelope, error) {
+	version, ok := schemaVersions[eventType]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s event data: %w", eventType, err)
+	}
+
+	return &Envelope{
+		SpecVersion:     SpecVersion,
+		ID:              uuid.New().String(),
+		Type:            eventType,
+		Source:          source,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		SchemaVersion:   version,
+		Data:            encoded,
+	}, nil
+}
+
+// SchemaVersion returns the current data schema version for eventType, or 0 if it is unknown
+func SchemaVersion(eventType string) int {
+	return schemaVersions[eventType]
+}
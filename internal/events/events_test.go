@@ -0,0 +1,45 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_BuildsEnvelope(t *testing.T) {
+	envelope, err := New(TypePaymentIntentSucceeded, SourcePaymentWorker, "PI-ABC123", PaymentIntentData{
+		PaymentIntentID: "PI-ABC123",
+		MerchantID:      "CASM-ABC123",
+		Amount:          "100.50",
+		Currency:        "ETB",
+		Status:          "success",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, SpecVersion, envelope.SpecVersion)
+	assert.NotEmpty(t, envelope.ID)
+	assert.Equal(t, "payment.intent.succeeded", envelope.Type)
+	assert.Equal(t, SourcePaymentWorker, envelope.Source)
+	assert.Equal(t, "PI-ABC123", envelope.Subject)
+	assert.Equal(t, 1, envelope.SchemaVersion)
+	assert.False(t, envelope.Time.IsZero())
+
+	encoded, err := json.Marshal(envelope)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &fields))
+	for _, attribute := range []string{"specversion", "id", "type", "source", "subject", "time", "datacontenttype", "schemaversion", "data"} {
+		assert.Contains(t, fields, attribute)
+	}
+	assert.Equal(t, "PI-ABC123", fields["data"].(map[string]interface{})["payment_intent_id"])
+	assert.NotContains(t, fields["data"], "failure_reason")
+}
+
+func TestNew_UnknownEventType(t *testing.T) {
+	_, err := New("payment.intent.exploded", SourcePaymentWorker, "PI-ABC123", nil)
+	assert.Error(t, err)
+	assert.Equal(t, 0, SchemaVersion("payment.intent.exploded"))
+}
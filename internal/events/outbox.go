@@ -0,0 +1,37 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cash-flow-financial/internal/db"
+
+	"github.com/google/uuid"
+)
+
+// Record stages an event in the outbox. Pass queries bound to the same transaction as the
+// state change, so the event only becomes visible to the relay once that change commits.
+func Record(ctx context.Context, queries *db.Queries, envelope *Envelope) error {
+	id, err := uuid.Parse(envelope.ID)
+	if err != nil {
+		return fmt.Errorf("invalid event id %q: %w", envelope.ID, err)
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", envelope.Type, err)
+	}
+
+	err = queries.InsertOutboxEvent(ctx, &db.InsertOutboxEventParams{
+		ID:         id,
+		EventType:  envelope.Type,
+		Exchange:   Exchange,
+		RoutingKey: envelope.Type,
+		Payload:    payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", envelope.Type, err)
+	}
+	return nil
+}
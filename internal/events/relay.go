@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/brokermanager"
+	"cash-flow-financial/internal/managers/dbmanager"
+	"cash-flow-financial/internal/managers/loggermanager"
+
+	"go.uber.org/zap"
+)
+
+const (
+	relayPollInterval = time.Second
+	relayBatchSize    = 100
+)
+
+// Relay publishes committed outbox rows to the broker in creation order.
+// Rows are claimed with SKIP LOCKED, so several relays can run side by side.
+type Relay struct {
+	queries   *db.Queries
+	dbManager dbmanager.IDBManager
+	publisher brokermanager.IPublisher
+	logger    *loggermanager.Logger
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewRelay(queries *db.Queries, dbManager dbmanager.IDBManager, publisher brokermanager.IPublisher, logger *loggermanager.Logger) *Relay {
+	return &Relay{
+		queries:   queries,
+		dbManager: dbManager,
+		publisher: publisher,
+		logger:    logger,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+func (r *Relay) Start() {
+	r.logger.Info("Starting event outbox relay", zap.Duration("poll_interval", relayPollInterval))
+	go r.run()
+}
+
+// Stop ends polling and makes one last pass so events committed during shutdown are not left behind
+func (r *Relay) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		<-r.done
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		r.drain(ctx)
+
+		r.logger.Info("Event outbox relay stopped")
+	})
+}
+
+func (r *Relay) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(relayPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.drain(context.Background())
+		}
+	}
+}
+
+// drain publishes full batches until the outbox is empty or a publish fails
+func (r *Relay) drain(ctx context.Context) {
+	for {
+		published, err := r.flush(ctx)
+		if err != nil {
+			r.logger.Error("Failed to relay outbox events", zap.Error(err))
+			return
+		}
+		if published < relayBatchSize {
+			return
+		}
+	}
+}
+
+func (r *Relay) flush(ctx context.Context) (int, error) {
+	published := 0
+
+	err := r.dbManager.WithTransaction(ctx, func(tx *sql.Tx) error {
+		qtx := r.queries.WithTx(tx)
+
+		rows, err := qtx.ClaimOutboxEvents(ctx, relayBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to claim outbox events: %w", err)
+		}
+
+		for _, row := range rows {
+			err := r.publisher.Publish(ctx, brokermanager.Message{
+				Exchange:    row.Exchange,
+				RoutingKey:  row.RoutingKey,
+				MessageID:   row.ID.String(),
+				Type:        row.EventType,
+				ContentType: ContentType,
+				Headers:     map[string]interface{}{"schema_version": SchemaVersion(row.EventType)},
+				Body:        row.Payload,
+			})
+			if err != nil {
+				// Stop at the first failure so events for the same aggregate stay in order
+				r.logger.Warn("Failed to publish outbox event, will retry",
+					zap.String("event_id", row.ID.String()),
+					zap.String("event_type", row.EventType),
+					zap.Int32("attempts", row.Attempts+1),
+					zap.Error(err))
+				if markErr := qtx.MarkOutboxEventFailed(ctx, &db.MarkOutboxEventFailedParams{
+					ID:        row.ID,
+					LastError: sql.NullString{String: err.Error(), Valid: true},
+				}); markErr != nil {
+					return fmt.Errorf("failed to record outbox publish failure: %w", markErr)
+				}
+				break
+			}
+
+			if err := qtx.MarkOutboxEventPublished(ctx, row.ID); err != nil {
+				return fmt.Errorf("failed to mark outbox event published: %w", err)
+			}
+			published++
+
+			r.logger.Info("Published domain event",
+				zap.String("event_id", row.ID.String()),
+				zap.String("event_type", row.EventType))
+		}
+
+		return nil
+	})
+
+	return published, err
+}
@@ -0,0 +1,32 @@
+package brokermanager
+
+import (
+	"database/sql"
+	"fmt"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/managers/rabbitmqmanager"
+	"cash-flow-financial/internal/models"
+)
+
+// NewBroker builds the broker selected by BROKER_DRIVER
+func NewBroker(cfg *models.Config, database *sql.DB, logger *loggermanager.Logger) (IBroker, error) {
+	switch cfg.Broker.Driver {
+	case models.BrokerDriverMemory:
+		// An in-process broker cannot connect separate API and worker processes
+		if cfg.App.Mode != models.RunModeAll {
+			return nil, fmt.Errorf("broker driver %q requires run mode %q", models.BrokerDriverMemory, models.RunModeAll)
+		}
+		return NewMemoryBroker(), nil
+	case models.BrokerDriverPostgres:
+		return NewPostgresBroker(database, &cfg.Broker.Jobs, logger), nil
+	case models.BrokerDriverRabbitMQ, "":
+		rabbitManager, err := rabbitmqmanager.NewRabbitMQManager(&cfg.RabbitMQ)
+		if err != nil {
+			return nil, err
+		}
+		return NewRabbitMQBroker(rabbitManager), nil
+	default:
+		return nil, fmt.Errorf("unknown broker driver %q", cfg.Broker.Driver)
+	}
+}
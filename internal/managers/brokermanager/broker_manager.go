@@ -0,0 +1,82 @@
+package brokermanager
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrBrokerClosed        = errors.New("broker is closed")
+	ErrAlreadyAcknowledged = errors.New("delivery already acknowledged")
+	ErrUnknownConsumer     = errors.New("unknown consumer tag")
+	ErrJobLockLost         = errors.New("job visibility timeout expired before acknowledgement")
+)
+
+// Message is a broker-neutral outgoing message; headers map onto AMQP headers for RabbitMQ.
+// RunAt schedules delivery for later on the postgres and memory drivers; RabbitMQ delivers immediately.
+type Message struct {
+	Exchange    string
+	RoutingKey  string
+	MessageID   string
+	Type        string
+	ContentType string
+	Headers     map[string]interface{}
+	Body        []byte
+	RunAt       time.Time
+}
+
+// Subscription describes the queue a consumer reads from and how it is bound
+type Subscription struct {
+	Queue       string
+	Exchange    string
+	RoutingKeys []string
+	ConsumerTag string
+	Prefetch    int
+}
+
+type Acknowledger interface {
+	Ack() error
+	Nack(requeue bool) error
+}
+
+// Delivery is a received message that must be acked or nacked exactly once
+type Delivery struct {
+	MessageID   string
+	Type        string
+	ContentType string
+	RoutingKey  string
+	Headers     map[string]interface{}
+	Body        []byte
+	Redelivered bool
+
+	Acknowledger Acknowledger
+}
+
+func (d Delivery) Ack() error {
+	return d.Acknowledger.Ack()
+}
+
+func (d Delivery) Nack(requeue bool) error {
+	return d.Acknowledger.Nack(requeue)
+}
+
+type IPublisher interface {
+	Publish(ctx context.Context, message Message) error
+}
+
+type IConsumer interface {
+	// Consume declares the subscription and streams deliveries until the consumer is cancelled
+	Consume(ctx context.Context, subscription Subscription) (<-chan Delivery, error)
+	// Cancel stops new deliveries; the stream closes once already-buffered deliveries are handed out
+	Cancel(consumerTag string) error
+	// CloseConsumer releases the consumer once every delivery it handed out has been acked or nacked
+	CloseConsumer(consumerTag string) error
+}
+
+type IBroker interface {
+	IPublisher
+	IConsumer
+	HealthCheck() error
+	Close() error
+}
@@ -0,0 +1,311 @@
+package brokermanager
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryBroker is an in-process broker with topic-exchange routing, for tests and single-node deployments.
+// Messages live only in memory and are lost when the process exits.
+type MemoryBroker struct {
+	mu        sync.Mutex
+	queues    map[string]*memoryQueue
+	bindings  []memoryBinding
+	consumers map[string]*memoryConsumer
+	closed    bool
+}
+
+type memoryBinding struct {
+	exchange string
+	pattern  string
+	queue    string
+}
+
+type memoryQueue struct {
+	messages []Delivery
+	ready    chan struct{}
+}
+
+type memoryConsumer struct {
+	broker   *MemoryBroker
+	queue    *memoryQueue
+	prefetch int
+	inFlight int
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewMemoryBroker() IBroker {
+	return &MemoryBroker{
+		queues:    make(map[string]*memoryQueue),
+		consumers: make(map[string]*memoryConsumer),
+	}
+}
+
+func (mb *MemoryBroker) Publish(ctx context.Context, message Message) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	if mb.closed {
+		return ErrBrokerClosed
+	}
+
+	if delay := time.Until(message.RunAt); delay > 0 {
+		message.RunAt = time.Time{}
+		time.AfterFunc(delay, func() {
+			mb.Publish(context.Background(), message)
+		})
+		return nil
+	}
+
+	// Like an AMQP exchange, a message is copied into every bound queue and dropped if none match
+	routed := make(map[string]bool)
+	for _, binding := range mb.bindings {
+		if binding.exchange != message.Exchange || routed[binding.queue] || !topicMatches(binding.pattern, message.RoutingKey) {
+			continue
+		}
+		routed[binding.queue] = true
+
+		queue := mb.queues[binding.queue]
+		queue.messages = append(queue.messages, Delivery{
+			MessageID:   message.MessageID,
+			Type:        message.Type,
+			ContentType: message.ContentType,
+			RoutingKey:  message.RoutingKey,
+			Headers:     copyHeaders(message.Headers),
+			Body:        append([]byte(nil), message.Body...),
+		})
+		queue.signal()
+	}
+
+	return nil
+}
+
+func (mb *MemoryBroker) Consume(ctx context.Context, subscription Subscription) (<-chan Delivery, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	if mb.closed {
+		return nil, ErrBrokerClosed
+	}
+
+	queue, ok := mb.queues[subscription.Queue]
+	if !ok {
+		queue = &memoryQueue{ready: make(chan struct{}, 1)}
+		mb.queues[subscription.Queue] = queue
+	}
+
+	for _, routingKey := range subscription.RoutingKeys {
+		mb.bindings = append(mb.bindings, memoryBinding{
+			exchange: subscription.Exchange,
+			pattern:  routingKey,
+			queue:    subscription.Queue,
+		})
+	}
+
+	consumer := &memoryConsumer{
+		broker:   mb,
+		queue:    queue,
+		prefetch: subscription.Prefetch,
+		stop:     make(chan struct{}),
+	}
+	mb.consumers[subscription.ConsumerTag] = consumer
+
+	deliveries := make(chan Delivery)
+	go consumer.run(deliveries)
+
+	return deliveries, nil
+}
+
+func (mb *MemoryBroker) Cancel(consumerTag string) error {
+	mb.mu.Lock()
+	consumer, ok := mb.consumers[consumerTag]
+	mb.mu.Unlock()
+	if !ok {
+		return ErrUnknownConsumer
+	}
+
+	consumer.cancel()
+	return nil
+}
+
+func (mb *MemoryBroker) CloseConsumer(consumerTag string) error {
+	mb.mu.Lock()
+	consumer, ok := mb.consumers[consumerTag]
+	delete(mb.consumers, consumerTag)
+	mb.mu.Unlock()
+	if !ok {
+		return ErrUnknownConsumer
+	}
+
+	consumer.cancel()
+	return nil
+}
+
+func (mb *MemoryBroker) HealthCheck() error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	if mb.closed {
+		return ErrBrokerClosed
+	}
+	return nil
+}
+
+func (mb *MemoryBroker) Close() error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	mb.closed = true
+	for tag, consumer := range mb.consumers {
+		consumer.cancel()
+		delete(mb.consumers, tag)
+	}
+	return nil
+}
+
+func (c *memoryConsumer) run(deliveries chan<- Delivery) {
+	defer close(deliveries)
+
+	for {
+		delivery, ok := c.next()
+		if !ok {
+			select {
+			case <-c.stop:
+				return
+			case <-c.queue.ready:
+				continue
+			}
+		}
+
+		select {
+		case deliveries <- delivery:
+		case <-c.stop:
+			// Not handed out yet, so it goes back to the head of the queue untouched
+			c.broker.mu.Lock()
+			c.inFlight--
+			c.queue.messages = append([]Delivery{delivery}, c.queue.messages...)
+			c.queue.signal()
+			c.broker.mu.Unlock()
+			return
+		}
+	}
+}
+
+// next pops the head of the queue if the consumer has prefetch capacity left
+func (c *memoryConsumer) next() (Delivery, bool) {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	select {
+	case <-c.stop:
+		return Delivery{}, false
+	default:
+	}
+
+	if len(c.queue.messages) == 0 || (c.prefetch > 0 && c.inFlight >= c.prefetch) {
+		return Delivery{}, false
+	}
+
+	delivery := c.queue.messages[0]
+	c.queue.messages = c.queue.messages[1:]
+	c.inFlight++
+	delivery.Acknowledger = &memoryAcknowledger{consumer: c, delivery: delivery}
+
+	// Wake any other consumer on the same queue if work remains
+	if len(c.queue.messages) > 0 {
+		c.queue.signal()
+	}
+
+	return delivery, true
+}
+
+func (c *memoryConsumer) cancel() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+func (q *memoryQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+type memoryAcknowledger struct {
+	consumer *memoryConsumer
+	delivery Delivery
+	done     atomic.Bool
+}
+
+func (a *memoryAcknowledger) Ack() error {
+	if !a.done.CompareAndSwap(false, true) {
+		return ErrAlreadyAcknowledged
+	}
+
+	a.consumer.broker.mu.Lock()
+	defer a.consumer.broker.mu.Unlock()
+
+	a.consumer.inFlight--
+	a.consumer.queue.signal()
+	return nil
+}
+
+func (a *memoryAcknowledger) Nack(requeue bool) error {
+	if !a.done.CompareAndSwap(false, true) {
+		return ErrAlreadyAcknowledged
+	}
+
+	a.consumer.broker.mu.Lock()
+	defer a.consumer.broker.mu.Unlock()
+
+	a.consumer.inFlight--
+	if requeue {
+		redelivery := a.delivery
+		redelivery.Redelivered = true
+		redelivery.Acknowledger = nil
+		a.consumer.queue.messages = append(a.consumer.queue.messages, redelivery)
+	}
+	a.consumer.queue.signal()
+	return nil
+}
+
+// topicMatches implements AMQP topic semantics: "*" matches one word and "#" matches zero or more
+func topicMatches(pattern, routingKey string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(routingKey, "."))
+}
+
+func matchWords(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if matchWords(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(words) > 0 && matchWords(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && pattern[0] == words[0] && matchWords(pattern[1:], words[1:])
+	}
+}
+
+func copyHeaders(headers map[string]interface{}) map[string]interface{} {
+	if headers == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(headers))
+	for key, value := range headers {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,168 @@
+package brokermanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func subscribe(t *testing.T, broker IBroker, tag string, prefetch int) <-chan Delivery {
+	t.Helper()
+	deliveries, err := broker.Consume(context.Background(), Subscription{
+		Queue:       "payment_intents_queue",
+		Exchange:    "payment_intents_exchange",
+		RoutingKeys: []string{"payment.intent.*"},
+		ConsumerTag: tag,
+		Prefetch:    prefetch,
+	})
+	require.NoError(t, err)
+	return deliveries
+}
+
+func receive(t *testing.T, deliveries <-chan Delivery) Delivery {
+	t.Helper()
+	select {
+	case d, ok := <-deliveries:
+		require.True(t, ok, "delivery channel closed")
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for delivery")
+		return Delivery{}
+	}
+}
+
+func assertNoDelivery(t *testing.T, deliveries <-chan Delivery) {
+	t.Helper()
+	select {
+	case d := <-deliveries:
+		t.Fatalf("unexpected delivery %q", d.MessageID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMemoryBroker_PublishAndAck(t *testing.T) {
+	broker := NewMemoryBroker()
+	defer broker.Close()
+	deliveries := subscribe(t, broker, "worker-1", 1)
+
+	err := broker.Publish(context.Background(), Message{
+		Exchange:    "payment_intents_exchange",
+		RoutingKey:  "payment.intent.created",
+		MessageID:   "PI-ABC123",
+		Type:        "payment.intent.created",
+		ContentType: "application/json",
+		Headers:     map[string]interface{}{"schema_version": 2},
+		Body:        []byte(`{"payment_intent_id":"PI-ABC123"}`),
+	})
+	require.NoError(t, err)
+
+	d := receive(t, deliveries)
+	assert.Equal(t, "PI-ABC123", d.MessageID)
+	assert.Equal(t, "payment.intent.created", d.Type)
+	assert.Equal(t, "application/json", d.ContentType)
+	assert.Equal(t, 2, d.Headers["schema_version"])
+	assert.JSONEq(t, `{"payment_intent_id":"PI-ABC123"}`, string(d.Body))
+	assert.False(t, d.Redelivered)
+
+	require.NoError(t, d.Ack())
+	assert.ErrorIs(t, d.Ack(), ErrAlreadyAcknowledged)
+	assert.ErrorIs(t, d.Nack(true), ErrAlreadyAcknowledged)
+}
+
+func TestMemoryBroker_NackRequeue(t *testing.T) {
+	broker := NewMemoryBroker()
+	defer broker.Close()
+	deliveries := subscribe(t, broker, "worker-1", 1)
+
+	require.NoError(t, broker.Publish(context.Background(), Message{
+		Exchange:   "payment_intents_exchange",
+		RoutingKey: "payment.intent.created",
+		MessageID:  "PI-RETRY",
+	}))
+
+	first := receive(t, deliveries)
+	require.NoError(t, first.Nack(true))
+
+	second := receive(t, deliveries)
+	assert.Equal(t, "PI-RETRY", second.MessageID)
+	assert.True(t, second.Redelivered)
+	require.NoError(t, second.Nack(false))
+
+	assertNoDelivery(t, deliveries)
+}
+
+func TestMemoryBroker_PrefetchLimitsInFlight(t *testing.T) {
+	broker := NewMemoryBroker()
+	defer broker.Close()
+	deliveries := subscribe(t, broker, "worker-1", 1)
+
+	for _, id := range []string{"PI-1", "PI-2"} {
+		require.NoError(t, broker.Publish(context.Background(), Message{
+			Exchange:   "payment_intents_exchange",
+			RoutingKey: "payment.intent.created",
+			MessageID:  id,
+		}))
+	}
+
+	first := receive(t, deliveries)
+	assert.Equal(t, "PI-1", first.MessageID)
+	assertNoDelivery(t, deliveries)
+
+	require.NoError(t, first.Ack())
+	assert.Equal(t, "PI-2", receive(t, deliveries).MessageID)
+}
+
+func TestMemoryBroker_UnroutedMessageIsDropped(t *testing.T) {
+	broker := NewMemoryBroker()
+	defer broker.Close()
+	deliveries := subscribe(t, broker, "worker-1", 1)
+
+	require.NoError(t, broker.Publish(context.Background(), Message{
+		Exchange:   "payment_intents_exchange",
+		RoutingKey: "merchant.balance.credited",
+		MessageID:  "EV-1",
+	}))
+
+	assertNoDelivery(t, deliveries)
+}
+
+func TestMemoryBroker_CancelClosesStream(t *testing.T) {
+	broker := NewMemoryBroker()
+	defer broker.Close()
+	deliveries := subscribe(t, broker, "worker-1", 1)
+
+	require.NoError(t, broker.Cancel("worker-1"))
+
+	select {
+	case _, ok := <-deliveries:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("delivery channel was not closed after cancel")
+	}
+
+	assert.ErrorIs(t, broker.Cancel("unknown"), ErrUnknownConsumer)
+	require.NoError(t, broker.CloseConsumer("worker-1"))
+}
+
+func TestTopicMatches(t *testing.T) {
+	cases := []struct {
+		pattern    string
+		routingKey string
+		want       bool
+	}{
+		{"payment.intent.created", "payment.intent.created", true},
+		{"payment.intent.*", "payment.intent.succeeded", true},
+		{"payment.*", "payment.intent.succeeded", false},
+		{"payment.#", "payment.intent.succeeded", true},
+		{"#", "merchant.balance.credited", true},
+		{"*.balance.#", "merchant.balance.credited", true},
+		{"payment.intent.created", "payment.intent.failed", false},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, topicMatches(tc.pattern, tc.routingKey), "%s vs %s", tc.pattern, tc.routingKey)
+	}
+}
@@ -0,0 +1,448 @@
+package brokermanager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+
+	"github.com/sqlc-dev/pqtype"
+	"go.uber.org/zap"
+)
+
+const maxJobRetryBackoff = 15 * time.Minute
+
+// PostgresBroker is a durable queue on the jobs table for deployments without RabbitMQ.
+// Consumers poll with FOR UPDATE SKIP LOCKED; a claimed job becomes visible again if it is
+// not acked or nacked before its visibility timeout expires. The timeout is renewed while the
+// delivery is in flight, so it only expires when the consumer has stopped.
+type PostgresBroker struct {
+	database *sql.DB
+	queries  *db.Queries
+	config   models.JobQueueConfig
+	instance string
+	logger   *loggermanager.Logger
+
+	mu        sync.Mutex
+	consumers map[string]*postgresConsumer
+	closed    bool
+}
+
+type postgresConsumer struct {
+	broker       *PostgresBroker
+	subscription Subscription
+	lockedBy     sql.NullString
+	inFlight     atomic.Int64
+	stop         chan struct{}
+	stopOnce     sync.Once
+}
+
+func NewPostgresBroker(database *sql.DB, cfg *models.JobQueueConfig, logger *loggermanager.Logger) IBroker {
+	hostname, _ := os.Hostname()
+	return &PostgresBroker{
+		database:  database,
+		queries:   db.New(database),
+		config:    *cfg,
+		instance:  fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		logger:    logger,
+		consumers: make(map[string]*postgresConsumer),
+	}
+}
+
+func (pb *PostgresBroker) Publish(ctx context.Context, message Message) error {
+	if pb.isClosed() {
+		return ErrBrokerClosed
+	}
+
+	bindings, err := pb.queries.ListJobBindings(ctx, message.Exchange)
+	if err != nil {
+		return fmt.Errorf("failed to load job bindings: %w", err)
+	}
+
+	var headers pqtype.NullRawMessage
+	if len(message.Headers) > 0 {
+		encoded, err := json.Marshal(message.Headers)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message headers: %w", err)
+		}
+		headers = pqtype.NullRawMessage{RawMessage: encoded, Valid: true}
+	}
+
+	runAt := message.RunAt
+	if runAt.IsZero() {
+		runAt = time.Now()
+	}
+
+	tx, err := pb.database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := pb.queries.WithTx(tx)
+
+	// Same fan-out as a topic exchange: one job per bound queue, nothing if no binding matches
+	routed := make(map[string]bool)
+	for _, binding := range bindings {
+		if routed[binding.Queue] || !topicMatches(binding.Pattern, message.RoutingKey) {
+			continue
+		}
+		routed[binding.Queue] = true
+
+		_, err := qtx.EnqueueJob(ctx, &db.EnqueueJobParams{
+			Queue:       binding.Queue,
+			Exchange:    message.Exchange,
+			RoutingKey:  message.RoutingKey,
+			MessageID:   sql.NullString{String: message.MessageID, Valid: message.MessageID != ""},
+			MessageType: sql.NullString{String: message.Type, Valid: message.Type != ""},
+			ContentType: sql.NullString{String: message.ContentType, Valid: message.ContentType != ""},
+			Headers:     headers,
+			Payload:     message.Body,
+			MaxAttempts: int32(pb.config.MaxAttempts),
+			RunAt:       runAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to enqueue job: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit jobs: %w", err)
+	}
+	return nil
+}
+
+func (pb *PostgresBroker) Consume(ctx context.Context, subscription Subscription) (<-chan Delivery, error) {
+	if pb.isClosed() {
+		return nil, ErrBrokerClosed
+	}
+
+	for _, routingKey := range subscription.RoutingKeys {
+		err := pb.queries.UpsertJobBinding(ctx, &db.UpsertJobBindingParams{
+			Exchange: subscription.Exchange,
+			Pattern:  routingKey,
+			Queue:    subscription.Queue,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind queue: %w", err)
+		}
+	}
+
+	if subscription.Prefetch < 1 {
+		subscription.Prefetch = 1
+	}
+
+	consumer := &postgresConsumer{
+		broker:       pb,
+		subscription: subscription,
+		lockedBy:     sql.NullString{String: pb.instance + ":" + subscription.ConsumerTag, Valid: true},
+		stop:         make(chan struct{}),
+	}
+
+	pb.mu.Lock()
+	pb.consumers[subscription.ConsumerTag] = consumer
+	pb.mu.Unlock()
+
+	deliveries := make(chan Delivery)
+	go consumer.run(deliveries)
+
+	return deliveries, nil
+}
+
+func (pb *PostgresBroker) Cancel(consumerTag string) error {
+	pb.mu.Lock()
+	consumer, ok := pb.consumers[consumerTag]
+	pb.mu.Unlock()
+	if !ok {
+		return ErrUnknownConsumer
+	}
+
+	consumer.cancel()
+	return nil
+}
+
+func (pb *PostgresBroker) CloseConsumer(consumerTag string) error {
+	pb.mu.Lock()
+	consumer, ok := pb.consumers[consumerTag]
+	delete(pb.consumers, consumerTag)
+	pb.mu.Unlock()
+	if !ok {
+		return ErrUnknownConsumer
+	}
+
+	consumer.cancel()
+	return nil
+}
+
+func (pb *PostgresBroker) HealthCheck() error {
+	if pb.isClosed() {
+		return ErrBrokerClosed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := pb.database.PingContext(ctx); err != nil {
+		return fmt.Errorf("job queue database unreachable: %w", err)
+	}
+	return nil
+}
+
+// Close stops all consumers; the database handle is owned by the DB manager
+func (pb *PostgresBroker) Close() error {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	pb.closed = true
+	for tag, consumer := range pb.consumers {
+		consumer.cancel()
+		delete(pb.consumers, tag)
+	}
+	return nil
+}
+
+func (pb *PostgresBroker) isClosed() bool {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.closed
+}
+
+func (c *postgresConsumer) run(deliveries chan<- Delivery) {
+	defer close(deliveries)
+
+	ticker := time.NewTicker(c.broker.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		capacity := int64(c.subscription.Prefetch) - c.inFlight.Load()
+		claimed := 0
+
+		if capacity > 0 {
+			jobs, err := c.claim(capacity)
+			if err != nil {
+				c.broker.logger.Error("Failed to claim jobs", zap.String("queue", c.subscription.Queue), zap.Error(err))
+			}
+			claimed = len(jobs)
+
+			for i, job := range jobs {
+				c.inFlight.Add(1)
+				ack := &postgresAcknowledger{consumer: c, job: job, settled: make(chan struct{})}
+				select {
+				case deliveries <- c.toDelivery(job, ack):
+					go ack.keepLocked()
+				case <-c.stop:
+					c.inFlight.Add(-1)
+					c.release(jobs[i:])
+					return
+				}
+			}
+		}
+
+		// A full batch suggests more work is waiting, so poll again straight away
+		if claimed > 0 && int64(claimed) == capacity {
+			select {
+			case <-c.stop:
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *postgresConsumer) claim(limit int64) ([]*db.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// A job whose consumer died on its last attempt has no attempt left to be claimed with
+	failed, err := c.broker.queries.FailExhaustedJobs(ctx, c.subscription.Queue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail exhausted jobs: %w", err)
+	}
+	if failed > 0 {
+		c.broker.logger.Warn("Failed jobs whose last attempt timed out", zap.String("queue", c.subscription.Queue), zap.Int64("jobs", failed))
+	}
+
+	return c.broker.queries.ClaimJobs(ctx, &db.ClaimJobsParams{
+		VisibilitySeconds: int32(c.broker.config.VisibilityTimeout / time.Second),
+		LockedBy:          c.lockedBy,
+		Queue:             c.subscription.Queue,
+		BatchSize:         int32(limit),
+	})
+}
+
+// release hands claimed-but-undelivered jobs back without counting the attempt
+func (c *postgresConsumer) release(jobs []*db.Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, job := range jobs {
+		if err := c.broker.queries.ReleaseJob(ctx, &db.ReleaseJobParams{ID: job.ID, LockedBy: c.lockedBy}); err != nil {
+			c.broker.logger.Error("Failed to release job", zap.String("queue", c.subscription.Queue), zap.String("job_id", job.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+func (c *postgresConsumer) toDelivery(job *db.Job, ack *postgresAcknowledger) Delivery {
+	var headers map[string]interface{}
+	if job.Headers.Valid {
+		if err := json.Unmarshal(job.Headers.RawMessage, &headers); err != nil {
+			c.broker.logger.Warn("Failed to decode job headers", zap.String("queue", c.subscription.Queue), zap.String("job_id", job.ID.String()), zap.Error(err))
+		}
+	}
+
+	return Delivery{
+		MessageID:    job.MessageID.String,
+		Type:         job.MessageType.String,
+		ContentType:  job.ContentType.String,
+		RoutingKey:   job.RoutingKey,
+		Headers:      headers,
+		Body:         job.Payload,
+		Redelivered:  job.Attempts > 1,
+		Acknowledger: ack,
+	}
+}
+
+func (c *postgresConsumer) cancel() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+// postgresAcknowledger settles one claim of a job. The claim is identified by the job's attempts,
+// which every claim increments, so a consumer whose claim expired and was replaced by another
+// cannot settle the job under it.
+type postgresAcknowledger struct {
+	consumer *postgresConsumer
+	job      *db.Job
+	done     atomic.Bool
+	settled  chan struct{}
+}
+
+// keepLocked renews the job's visibility timeout until the delivery is acked or nacked, so a
+// payment that runs past the timeout is not claimed and processed a second time meanwhile
+func (a *postgresAcknowledger) keepLocked() {
+	ticker := time.NewTicker(a.consumer.broker.config.VisibilityTimeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.settled:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		updated, err := a.consumer.broker.queries.ExtendJobLock(ctx, &db.ExtendJobLockParams{
+			VisibilitySeconds: int32(a.consumer.broker.config.VisibilityTimeout / time.Second),
+			ID:                a.job.ID,
+			LockedBy:          a.consumer.lockedBy,
+			Attempts:          a.job.Attempts,
+		})
+		cancel()
+		if err != nil {
+			a.consumer.broker.logger.Error("Failed to extend job lock", zap.String("queue", a.consumer.subscription.Queue), zap.String("job_id", a.job.ID.String()), zap.Error(err))
+			continue
+		}
+		if updated == 0 {
+			a.consumer.broker.logger.Warn("Job lock lost while in flight", zap.String("queue", a.consumer.subscription.Queue), zap.String("job_id", a.job.ID.String()))
+			return
+		}
+	}
+}
+
+// settle marks the delivery acked or nacked and stops renewing its lock
+func (a *postgresAcknowledger) settle() bool {
+	if !a.done.CompareAndSwap(false, true) {
+		return false
+	}
+	close(a.settled)
+	return true
+}
+
+func (a *postgresAcknowledger) Ack() error {
+	if !a.settle() {
+		return ErrAlreadyAcknowledged
+	}
+	defer a.consumer.inFlight.Add(-1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	updated, err := a.consumer.broker.queries.CompleteJob(ctx, &db.CompleteJobParams{
+		ID:       a.job.ID,
+		LockedBy: a.consumer.lockedBy,
+		Attempts: a.job.Attempts,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+	if updated == 0 {
+		return ErrJobLockLost
+	}
+	return nil
+}
+
+func (a *postgresAcknowledger) Nack(requeue bool) error {
+	if !a.settle() {
+		return ErrAlreadyAcknowledged
+	}
+	defer a.consumer.inFlight.Add(-1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		updated int64
+		err     error
+	)
+	if requeue {
+		// Jobs that used up max_attempts are marked failed by the query instead of requeued
+		updated, err = a.consumer.broker.queries.RetryJob(ctx, &db.RetryJobParams{
+			RunAt:     time.Now().Add(a.consumer.broker.retryBackoff(a.job.Attempts)),
+			LastError: sql.NullString{String: "nacked with requeue", Valid: true},
+			ID:        a.job.ID,
+			LockedBy:  a.consumer.lockedBy,
+			Attempts:  a.job.Attempts,
+		})
+	} else {
+		updated, err = a.consumer.broker.queries.FailJob(ctx, &db.FailJobParams{
+			ID:        a.job.ID,
+			LockedBy:  a.consumer.lockedBy,
+			LastError: sql.NullString{String: "rejected by consumer", Valid: true},
+			Attempts:  a.job.Attempts,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to nack job: %w", err)
+	}
+	if updated == 0 {
+		return ErrJobLockLost
+	}
+	return nil
+}
+
+// retryBackoff doubles the configured base delay per attempt, capped at maxJobRetryBackoff
+func (pb *PostgresBroker) retryBackoff(attempts int32) time.Duration {
+	backoff := pb.config.RetryBackoff
+	for i := int32(1); i < attempts && backoff < maxJobRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxJobRetryBackoff {
+		backoff = maxJobRetryBackoff
+	}
+	return backoff
+}
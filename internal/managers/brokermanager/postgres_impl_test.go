@@ -0,0 +1,40 @@
+package brokermanager
+
+import (
+	"testing"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresBroker_RetryBackoff(t *testing.T) {
+	broker := &PostgresBroker{config: models.JobQueueConfig{RetryBackoff: 5 * time.Second}}
+
+	assert.Equal(t, 5*time.Second, broker.retryBackoff(1))
+	assert.Equal(t, 10*time.Second, broker.retryBackoff(2))
+	assert.Equal(t, 40*time.Second, broker.retryBackoff(4))
+	assert.Equal(t, maxJobRetryBackoff, broker.retryBackoff(30))
+}
+
+func TestPostgresAcknowledger_SettleStopsLockRenewal(t *testing.T) {
+	consumer := &postgresConsumer{broker: &PostgresBroker{config: models.JobQueueConfig{VisibilityTimeout: time.Hour}}}
+	ack := &postgresAcknowledger{consumer: consumer, job: &db.Job{}, settled: make(chan struct{})}
+
+	stopped := make(chan struct{})
+	go func() {
+		ack.keepLocked()
+		close(stopped)
+	}()
+
+	assert.True(t, ack.settle())
+	assert.False(t, ack.settle(), "a delivery is settled once")
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("lock renewal kept running after the delivery was settled")
+	}
+}
@@ -0,0 +1,218 @@
+package brokermanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"cash-flow-financial/internal/managers/rabbitmqmanager"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type RabbitMQBroker struct {
+	manager *rabbitmqmanager.RabbitMQManager
+
+	mu        sync.Mutex
+	declared  map[string]bool
+	consumers map[string]*amqp.Channel
+}
+
+func NewRabbitMQBroker(manager *rabbitmqmanager.RabbitMQManager) IBroker {
+	return &RabbitMQBroker{
+		manager:   manager,
+		declared:  make(map[string]bool),
+		consumers: make(map[string]*amqp.Channel),
+	}
+}
+
+func (rb *RabbitMQBroker) Publish(ctx context.Context, message Message) error {
+	if err := rb.declareExchange(rb.manager.Channel, message.Exchange); err != nil {
+		return err
+	}
+
+	contentType := message.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	err := rb.manager.Channel.PublishWithContext(
+		ctx,
+		message.Exchange,   // exchange
+		message.RoutingKey, // routing key
+		false,              // mandatory
+		false,              // immediate
+		amqp.Publishing{
+			ContentType:  contentType,
+			Type:         message.Type,
+			MessageId:    message.MessageID,
+			Headers:      amqp.Table(message.Headers),
+			Body:         message.Body,
+			DeliveryMode: amqp.Persistent, // Make message persistent
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	return nil
+}
+
+func (rb *RabbitMQBroker) Consume(ctx context.Context, subscription Subscription) (<-chan Delivery, error) {
+	// Each consumer gets its own channel so prefetch and cancellation do not affect the publisher
+	channel, err := rb.manager.Connection.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open consumer channel: %w", err)
+	}
+
+	if err := rb.declareExchange(channel, subscription.Exchange); err != nil {
+		channel.Close()
+		return nil, err
+	}
+
+	queue, err := channel.QueueDeclare(
+		subscription.Queue, // name
+		true,               // durable
+		false,              // delete when unused
+		false,              // exclusive
+		false,              // no-wait
+		nil,                // arguments
+	)
+	if err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	for _, routingKey := range subscription.RoutingKeys {
+		if err := channel.QueueBind(queue.Name, routingKey, subscription.Exchange, false, nil); err != nil {
+			channel.Close()
+			return nil, fmt.Errorf("failed to bind queue: %w", err)
+		}
+	}
+
+	if subscription.Prefetch > 0 {
+		if err := channel.Qos(subscription.Prefetch, 0, false); err != nil {
+			channel.Close()
+			return nil, fmt.Errorf("failed to set channel prefetch: %w", err)
+		}
+	}
+
+	msgs, err := channel.ConsumeWithContext(
+		ctx,
+		queue.Name,               // queue
+		subscription.ConsumerTag, // consumer
+		false,                    // auto-ack
+		false,                    // exclusive
+		false,                    // no-local
+		false,                    // no-wait
+		nil,                      // args
+	)
+	if err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	rb.mu.Lock()
+	rb.consumers[subscription.ConsumerTag] = channel
+	rb.mu.Unlock()
+
+	deliveries := make(chan Delivery)
+	go func() {
+		defer close(deliveries)
+		for d := range msgs {
+			deliveries <- Delivery{
+				MessageID:    d.MessageId,
+				Type:         d.Type,
+				ContentType:  d.ContentType,
+				RoutingKey:   d.RoutingKey,
+				Headers:      map[string]interface{}(d.Headers),
+				Body:         d.Body,
+				Redelivered:  d.Redelivered,
+				Acknowledger: &rabbitMQAcknowledger{delivery: d},
+			}
+		}
+	}()
+
+	return deliveries, nil
+}
+
+func (rb *RabbitMQBroker) Cancel(consumerTag string) error {
+	rb.mu.Lock()
+	channel, ok := rb.consumers[consumerTag]
+	rb.mu.Unlock()
+	if !ok {
+		return ErrUnknownConsumer
+	}
+
+	if err := channel.Cancel(consumerTag, false); err != nil {
+		return fmt.Errorf("failed to cancel consumer: %w", err)
+	}
+	return nil
+}
+
+func (rb *RabbitMQBroker) CloseConsumer(consumerTag string) error {
+	rb.mu.Lock()
+	channel, ok := rb.consumers[consumerTag]
+	delete(rb.consumers, consumerTag)
+	rb.mu.Unlock()
+	if !ok {
+		return ErrUnknownConsumer
+	}
+
+	if err := channel.Close(); err != nil {
+		return fmt.Errorf("failed to close consumer channel: %w", err)
+	}
+	return nil
+}
+
+func (rb *RabbitMQBroker) HealthCheck() error {
+	return rb.manager.HealthCheck()
+}
+
+func (rb *RabbitMQBroker) Close() error {
+	rb.mu.Lock()
+	for tag, channel := range rb.consumers {
+		channel.Close()
+		delete(rb.consumers, tag)
+	}
+	rb.mu.Unlock()
+
+	return rb.manager.Close()
+}
+
+func (rb *RabbitMQBroker) declareExchange(channel *amqp.Channel, exchange string) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	if rb.declared[exchange] {
+		return nil
+	}
+
+	err := channel.ExchangeDeclare(
+		exchange, // name
+		"topic",  // type
+		true,     // durable
+		false,    // auto-deleted
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	rb.declared[exchange] = true
+	return nil
+}
+
+type rabbitMQAcknowledger struct {
+	delivery amqp.Delivery
+}
+
+func (a *rabbitMQAcknowledger) Ack() error {
+	return a.delivery.Ack(false)
+}
+
+func (a *rabbitMQAcknowledger) Nack(requeue bool) error {
+	return a.delivery.Nack(false, requeue)
+}
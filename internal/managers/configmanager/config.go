@@ -1,17 +1,26 @@
 package configmanager
 
 import (
+	"cash-flow-financial/internal/managers/keymanager"
 	"cash-flow-financial/internal/models"
+	"encoding/base64"
 	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 func Load() (*models.Config, error) {
 
+	viper.SetDefault("APP_MODE", models.RunModeAll)
+	viper.SetDefault("APP_ENV", models.AppEnvDevelopment)
+
 	viper.SetDefault("SERVER_PORT", "3074")
 
 	viper.SetDefault("LOG_LEVEL", "info")
@@ -29,7 +38,51 @@ func Load() (*models.Config, error) {
 	viper.SetDefault("RABBITMQ_PASSWORD", "guest")
 	viper.SetDefault("RABBITMQ_VHOST", "/")
 
-	viper.SetDefault("API_KEY_HASH_KEY", "cashflow_test_2024_secure_key_123456789")
+	viper.SetDefault("BROKER_DRIVER", models.BrokerDriverRabbitMQ)
+	viper.SetDefault("JOBS_POLL_INTERVAL_MS", 1000)
+	viper.SetDefault("JOBS_VISIBILITY_TIMEOUT", 300)
+	viper.SetDefault("JOBS_MAX_ATTEMPTS", 10)
+	viper.SetDefault("JOBS_RETRY_BACKOFF", 5)
+
+	viper.SetDefault("WORKER_CONCURRENCY", 4)
+	viper.SetDefault("WORKER_PREFETCH", 0)
+	viper.SetDefault("WORKER_HEALTH_PORT", "3075")
+	viper.SetDefault("WORKER_SHUTDOWN_TIMEOUT", 30)
+
+	viper.SetDefault("SCHEDULER_EXPIRY_SWEEP_CRON", "*/5 * * * *")
+	viper.SetDefault("SCHEDULER_MAX_JITTER", 30)
+
+	viper.SetDefault("WEBHOOK_SECRET_GRACE_PERIOD", 24)
+	viper.SetDefault("WEBHOOK_RETRY_HORIZON", 72)
+	viper.SetDefault("WEBHOOK_RETRY_BACKOFF", 30)
+	viper.SetDefault("WEBHOOK_DISPATCH_CONCURRENCY", 8)
+	viper.SetDefault("WEBHOOK_ALLOWED_HOSTS", "")
+	viper.SetDefault("WEBHOOK_DENIED_HOSTS", "")
+	viper.SetDefault("WEBHOOK_CALLBACK_TIMEOUT", 10)
+	viper.SetDefault("WEBHOOK_CIRCUIT_FAILURE_THRESHOLD", 5)
+	viper.SetDefault("WEBHOOK_CIRCUIT_COOLDOWN", 300)
+	viper.SetDefault("WEBHOOK_ENDPOINT_DISABLE_AFTER", 72)
+
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("MAIL_FROM", "Cash Flow <no-reply@cashflow.local>")
+
+	viper.SetDefault("EMAIL_VERIFICATION_URL", "http://localhost:3074/cashflow_test/v1/account/verify-email")
+	viper.SetDefault("EMAIL_VERIFICATION_TTL", 48)
+
+	viper.SetDefault("DASHBOARD_URL", "http://localhost:3000")
+	viper.SetDefault("DASHBOARD_SESSION_TTL", 12)
+	viper.SetDefault("DASHBOARD_INVITE_TTL", 72)
+	viper.SetDefault("DASHBOARD_LOGIN_MAX_ATTEMPTS", 5)
+	viper.SetDefault("DASHBOARD_LOGIN_LOCKOUT", 15)
+
+	viper.SetDefault("API_KEY_GRACE_PERIOD", 24)
+
+	viper.SetDefault("RATE_LIMIT_WINDOW", 60)
+	viper.SetDefault("RATE_LIMIT_STANDARD", 120)
+	viper.SetDefault("RATE_LIMIT_GROWTH", 600)
+	viper.SetDefault("RATE_LIMIT_ENTERPRISE", 3000)
+	viper.SetDefault("RATE_LIMIT_IP", 30)
 
 	viper.AutomaticEnv()
 
@@ -48,8 +101,13 @@ func Load() (*models.Config, error) {
 	}
 
 	config := &models.Config{
+		App: models.AppConfig{
+			Mode: strings.ToLower(getEnvAsString("APP_MODE", models.RunModeAll)),
+			Env:  strings.ToLower(getEnvAsString("APP_ENV", models.AppEnvDevelopment)),
+		},
 		Server: models.ServerConfig{
-			Port: getEnvAsString("SERVER_PORT", "8080"),
+			Port:           getEnvAsString("SERVER_PORT", "8080"),
+			TrustedProxies: parseTrustedProxies(getEnvAsList("TRUSTED_PROXIES")),
 		},
 		Logger: models.LoggerConfig{
 			Level: strings.ToLower(getEnvAsString("LOG_LEVEL", "info")),
@@ -69,7 +127,77 @@ func Load() (*models.Config, error) {
 			Password: getEnvAsString("RABBITMQ_PASSWORD", "guest"),
 			VHost:    getEnvAsString("RABBITMQ_VHOST", "/"),
 		},
-		APIKeyHash: getEnvAsString("API_KEY_HASH_KEY", "cashflow_test_2024_secure_key_123456789"),
+		Broker: models.BrokerConfig{
+			Driver: strings.ToLower(getEnvAsString("BROKER_DRIVER", models.BrokerDriverRabbitMQ)),
+			Jobs: models.JobQueueConfig{
+				PollInterval:      time.Duration(getEnvAsInt("JOBS_POLL_INTERVAL_MS", 1000)) * time.Millisecond,
+				VisibilityTimeout: time.Duration(getEnvAsInt("JOBS_VISIBILITY_TIMEOUT", 300)) * time.Second,
+				MaxAttempts:       getEnvAsInt("JOBS_MAX_ATTEMPTS", 10),
+				RetryBackoff:      time.Duration(getEnvAsInt("JOBS_RETRY_BACKOFF", 5)) * time.Second,
+			},
+		},
+		Worker: models.WorkerConfig{
+			Concurrency:     getEnvAsInt("WORKER_CONCURRENCY", 4),
+			Prefetch:        getEnvAsInt("WORKER_PREFETCH", 0),
+			HealthPort:      getEnvAsString("WORKER_HEALTH_PORT", "3075"),
+			ShutdownTimeout: time.Duration(getEnvAsInt("WORKER_SHUTDOWN_TIMEOUT", 30)) * time.Second,
+		},
+		Scheduler: models.SchedulerConfig{
+			ExpirySweepSchedule: getEnvAsString("SCHEDULER_EXPIRY_SWEEP_CRON", "*/5 * * * *"),
+			MaxJitter:           time.Duration(getEnvAsInt("SCHEDULER_MAX_JITTER", 30)) * time.Second,
+		},
+		Webhook: models.WebhookConfig{
+			SecretGracePeriod:       time.Duration(getEnvAsInt("WEBHOOK_SECRET_GRACE_PERIOD", 24)) * time.Hour,
+			RetryHorizon:            time.Duration(getEnvAsInt("WEBHOOK_RETRY_HORIZON", 72)) * time.Hour,
+			RetryBackoff:            time.Duration(getEnvAsInt("WEBHOOK_RETRY_BACKOFF", 30)) * time.Second,
+			DispatchConcurrency:     getEnvAsInt("WEBHOOK_DISPATCH_CONCURRENCY", 8),
+			AllowedHosts:            getEnvAsList("WEBHOOK_ALLOWED_HOSTS"),
+			DeniedHosts:             getEnvAsList("WEBHOOK_DENIED_HOSTS"),
+			CallbackTimeout:         time.Duration(getEnvAsInt("WEBHOOK_CALLBACK_TIMEOUT", 10)) * time.Second,
+			CircuitFailureThreshold: getEnvAsInt("WEBHOOK_CIRCUIT_FAILURE_THRESHOLD", 5),
+			CircuitCooldown:         time.Duration(getEnvAsInt("WEBHOOK_CIRCUIT_COOLDOWN", 300)) * time.Second,
+			EndpointDisableAfter:    time.Duration(getEnvAsInt("WEBHOOK_ENDPOINT_DISABLE_AFTER", 72)) * time.Hour,
+		},
+		Mail: models.MailConfig{
+			SMTPHost: getEnvAsString("SMTP_HOST", ""),
+			SMTPPort: getEnvAsString("SMTP_PORT", "587"),
+			Username: getEnvAsString("SMTP_USERNAME", ""),
+			Password: getEnvAsString("SMTP_PASSWORD", ""),
+			From:     getEnvAsString("MAIL_FROM", "Cash Flow <no-reply@cashflow.local>"),
+		},
+		EmailVerification: models.EmailVerificationConfig{
+			URL:      getEnvAsString("EMAIL_VERIFICATION_URL", "http://localhost:3074/cashflow_test/v1/account/verify-email"),
+			TokenTTL: time.Duration(getEnvAsInt("EMAIL_VERIFICATION_TTL", 48)) * time.Hour,
+		},
+		Dashboard: models.DashboardConfig{
+			URL:              getEnvAsString("DASHBOARD_URL", "http://localhost:3000"),
+			SessionTTL:       time.Duration(getEnvAsInt("DASHBOARD_SESSION_TTL", 12)) * time.Hour,
+			InviteTTL:        time.Duration(getEnvAsInt("DASHBOARD_INVITE_TTL", 72)) * time.Hour,
+			LoginMaxAttempts: getEnvAsInt("DASHBOARD_LOGIN_MAX_ATTEMPTS", 5),
+			LoginLockout:     time.Duration(getEnvAsInt("DASHBOARD_LOGIN_LOCKOUT", 15)) * time.Minute,
+		},
+		Admin: models.AdminConfig{
+			APIKeys: parseAdminKeys(getEnvAsList("ADMIN_API_KEYS")),
+		},
+		Keyring: models.KeyringConfig{
+			HashKey:        getEnvAsString("API_KEY_HASH_KEY", ""),
+			EncryptionKeys: parseEncryptionKeys(getEnvAsList("ENCRYPTION_KEYS")),
+		},
+		APIKeyGracePeriod: time.Duration(getEnvAsInt("API_KEY_GRACE_PERIOD", 24)) * time.Hour,
+		RateLimit: models.RateLimitConfig{
+			Window: time.Duration(getEnvAsInt("RATE_LIMIT_WINDOW", 60)) * time.Second,
+			TierLimits: map[string]int{
+				models.TierStandard:   getEnvAsInt("RATE_LIMIT_STANDARD", 120),
+				models.TierGrowth:     getEnvAsInt("RATE_LIMIT_GROWTH", 600),
+				models.TierEnterprise: getEnvAsInt("RATE_LIMIT_ENTERPRISE", 3000),
+			},
+			IPLimit: getEnvAsInt("RATE_LIMIT_IP", 30),
+		},
+	}
+
+	// Prefetch defaults to the pool size so each worker goroutine has exactly one delivery buffered
+	if config.Worker.Prefetch <= 0 {
+		config.Worker.Prefetch = config.Worker.Concurrency
 	}
 
 	log.Printf("Configuration loaded successfully. Server will run on port %s", config.Server.Port)
@@ -89,6 +217,36 @@ func validateConfig() error {
 		return fmt.Errorf("invalid LOG_LEVEL '%s', must be one of: debug, info, warn, error", logLevel)
 	}
 
+	appMode := strings.ToLower(viper.GetString("APP_MODE"))
+	if appMode != "" && !IsValidRunMode(appMode) {
+		return fmt.Errorf("invalid APP_MODE '%s', must be one of: api, worker, all", appMode)
+	}
+
+	appEnv := strings.ToLower(viper.GetString("APP_ENV"))
+	switch appEnv {
+	case "", models.AppEnvDevelopment, models.AppEnvProduction:
+	default:
+		return fmt.Errorf("invalid APP_ENV '%s', must be one of: development, production", appEnv)
+	}
+
+	brokerDriver := strings.ToLower(viper.GetString("BROKER_DRIVER"))
+	switch brokerDriver {
+	case "", models.BrokerDriverRabbitMQ, models.BrokerDriverMemory, models.BrokerDriverPostgres:
+	default:
+		return fmt.Errorf("invalid BROKER_DRIVER '%s', must be one of: rabbitmq, memory, postgres", brokerDriver)
+	}
+
+	for _, key := range []string{"JOBS_POLL_INTERVAL_MS", "JOBS_VISIBILITY_TIMEOUT", "JOBS_MAX_ATTEMPTS", "JOBS_RETRY_BACKOFF", "WEBHOOK_RETRY_HORIZON", "WEBHOOK_RETRY_BACKOFF", "WEBHOOK_DISPATCH_CONCURRENCY", "WEBHOOK_CIRCUIT_FAILURE_THRESHOLD", "WEBHOOK_CIRCUIT_COOLDOWN", "WEBHOOK_ENDPOINT_DISABLE_AFTER", "EMAIL_VERIFICATION_TTL", "DASHBOARD_SESSION_TTL", "DASHBOARD_INVITE_TTL", "DASHBOARD_LOGIN_MAX_ATTEMPTS", "DASHBOARD_LOGIN_LOCKOUT", "RATE_LIMIT_WINDOW", "RATE_LIMIT_STANDARD", "RATE_LIMIT_GROWTH", "RATE_LIMIT_ENTERPRISE", "RATE_LIMIT_IP"} {
+		value := viper.GetString(key)
+		if value == "" {
+			continue
+		}
+		var n int
+		if _, err := fmt.Sscanf(value, "%d", &n); err != nil || n < 1 {
+			return fmt.Errorf("invalid %s '%s', must be a positive integer", key, value)
+		}
+	}
+
 	dbPort := viper.GetString("DB_PORT")
 	if dbPort != "" {
 		if _, err := fmt.Sscanf(dbPort, "%d", new(int)); err != nil {
@@ -103,12 +261,220 @@ func validateConfig() error {
 		}
 	}
 
+	workerHealthPort := viper.GetString("WORKER_HEALTH_PORT")
+	if workerHealthPort != "" {
+		if _, err := fmt.Sscanf(workerHealthPort, "%d", new(int)); err != nil {
+			return fmt.Errorf("invalid WORKER_HEALTH_PORT '%s', must be numeric", workerHealthPort)
+		}
+	}
+
+	workerShutdownTimeout := viper.GetString("WORKER_SHUTDOWN_TIMEOUT")
+	if workerShutdownTimeout != "" {
+		var n int
+		if _, err := fmt.Sscanf(workerShutdownTimeout, "%d", &n); err != nil || n < 0 {
+			return fmt.Errorf("invalid WORKER_SHUTDOWN_TIMEOUT '%s', must be a non-negative number of seconds", workerShutdownTimeout)
+		}
+	}
+
+	workerConcurrency := viper.GetString("WORKER_CONCURRENCY")
+	if workerConcurrency != "" {
+		var n int
+		if _, err := fmt.Sscanf(workerConcurrency, "%d", &n); err != nil || n < 1 {
+			return fmt.Errorf("invalid WORKER_CONCURRENCY '%s', must be a positive integer", workerConcurrency)
+		}
+	}
+
+	schedulerMaxJitter := viper.GetString("SCHEDULER_MAX_JITTER")
+	if schedulerMaxJitter != "" {
+		var n int
+		if _, err := fmt.Sscanf(schedulerMaxJitter, "%d", &n); err != nil || n < 0 {
+			return fmt.Errorf("invalid SCHEDULER_MAX_JITTER '%s', must be a non-negative number of seconds", schedulerMaxJitter)
+		}
+	}
+
+	// The dispatcher leases a delivery for two minutes, so an attempt must finish well inside that
+	callbackTimeout := viper.GetString("WEBHOOK_CALLBACK_TIMEOUT")
+	if callbackTimeout != "" {
+		var n int
+		if _, err := fmt.Sscanf(callbackTimeout, "%d", &n); err != nil || n < 1 || n > 60 {
+			return fmt.Errorf("invalid WEBHOOK_CALLBACK_TIMEOUT '%s', must be between 1 and 60 seconds", callbackTimeout)
+		}
+	}
+
+	webhookGracePeriod := viper.GetString("WEBHOOK_SECRET_GRACE_PERIOD")
+	if webhookGracePeriod != "" {
+		var n int
+		if _, err := fmt.Sscanf(webhookGracePeriod, "%d", &n); err != nil || n < 0 {
+			return fmt.Errorf("invalid WEBHOOK_SECRET_GRACE_PERIOD '%s', must be a non-negative number of hours", webhookGracePeriod)
+		}
+	}
+
+	apiKeyGracePeriod := viper.GetString("API_KEY_GRACE_PERIOD")
+	if apiKeyGracePeriod != "" {
+		var n int
+		if _, err := fmt.Sscanf(apiKeyGracePeriod, "%d", &n); err != nil || n < 0 || n > 168 {
+			return fmt.Errorf("invalid API_KEY_GRACE_PERIOD '%s', must be between 0 and 168 hours", apiKeyGracePeriod)
+		}
+	}
+
+	for _, entry := range getEnvAsList("TRUSTED_PROXIES") {
+		if parseTrustedProxy(entry) == nil {
+			return fmt.Errorf("invalid TRUSTED_PROXIES entry '%s', must be a CIDR or IP", entry)
+		}
+	}
+
+	for _, key := range []string{"WEBHOOK_ALLOWED_HOSTS", "WEBHOOK_DENIED_HOSTS"} {
+		for _, entry := range strings.Split(viper.GetString(key), ",") {
+			entry = strings.TrimSpace(entry)
+			if !strings.Contains(entry, "/") {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return fmt.Errorf("invalid %s entry '%s', must be a CIDR, IP or hostname", key, entry)
+			}
+		}
+	}
+
+	// Entries are secrets, so errors name the position instead of the value
+	for i, entry := range strings.Split(viper.GetString("ADMIN_API_KEYS"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, key, found := strings.Cut(entry, ":")
+		if found && (strings.TrimSpace(name) == "" || strings.TrimSpace(key) == "") {
+			return fmt.Errorf("invalid ADMIN_API_KEYS entry %d, must be name:key or a bare key", i+1)
+		}
+	}
+
+	// Both are secrets without a default, so a deployment can never run on a key shared with others
+	if len(viper.GetString("API_KEY_HASH_KEY")) < keymanager.MinHashKeyLength {
+		return fmt.Errorf("API_KEY_HASH_KEY is required and must be at least %d characters", keymanager.MinHashKeyLength)
+	}
+
+	encryptionKeys := getEnvAsList("ENCRYPTION_KEYS")
+	if len(encryptionKeys) == 0 {
+		return fmt.Errorf("ENCRYPTION_KEYS is required, e.g. v1:<base64 of 32 random bytes>")
+	}
+	seenKeyIDs := map[string]bool{}
+	for i, entry := range encryptionKeys {
+		id, key, found := strings.Cut(entry, ":")
+		id = strings.TrimSpace(id)
+		if !found || !keymanager.IsValidKeyID(id) {
+			return fmt.Errorf("invalid ENCRYPTION_KEYS entry %d, must be id:key with an id of letters, digits, - or _", i+1)
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key)); err != nil || len(decoded) != keymanager.EncryptionKeyLength {
+			return fmt.Errorf("invalid ENCRYPTION_KEYS entry %d, key must be %d bytes encoded as base64", i+1, keymanager.EncryptionKeyLength)
+		}
+		if seenKeyIDs[id] {
+			return fmt.Errorf("invalid ENCRYPTION_KEYS entry %d, id %s is used more than once", i+1, id)
+		}
+		seenKeyIDs[id] = true
+	}
+
+	for _, key := range []string{"EMAIL_VERIFICATION_URL", "DASHBOARD_URL"} {
+		value := viper.GetString(key)
+		if value == "" {
+			continue
+		}
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid %s '%s', must be an absolute http or https URL", key, value)
+		}
+	}
+
+	workerPrefetch := viper.GetString("WORKER_PREFETCH")
+	if workerPrefetch != "" {
+		var n int
+		if _, err := fmt.Sscanf(workerPrefetch, "%d", &n); err != nil || n < 0 {
+			return fmt.Errorf("invalid WORKER_PREFETCH '%s', must be a non-negative integer", workerPrefetch)
+		}
+	}
+
 	return nil
 }
 
+func IsValidRunMode(mode string) bool {
+	switch mode {
+	case models.RunModeAPI, models.RunModeWorker, models.RunModeAll:
+		return true
+	}
+	return false
+}
+
 func getEnvAsString(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+// getEnvAsList splits a comma-separated variable, dropping empty entries
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseTrustedProxies reads TRUSTED_PROXIES entries, each a CIDR or a single IP
+func parseTrustedProxies(entries []string) []*net.IPNet {
+	proxies := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if proxy := parseTrustedProxy(entry); proxy != nil {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// parseTrustedProxy returns the network of a CIDR, or of a single IP, and nil for anything else
+func parseTrustedProxy(entry string) *net.IPNet {
+	if _, network, err := net.ParseCIDR(entry); err == nil {
+		return network
+	}
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return &net.IPNet{IP: ipv4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// parseAdminKeys reads ADMIN_API_KEYS entries of the form name:key. The name is recorded against
+// merchant status changes; a key without one is named "admin".
+func parseAdminKeys(entries []string) []models.AdminKey {
+	keys := make([]models.AdminKey, 0, len(entries))
+	for _, entry := range entries {
+		name, key, found := strings.Cut(entry, ":")
+		if !found {
+			name, key = "admin", entry
+		}
+		keys = append(keys, models.AdminKey{Name: strings.TrimSpace(name), Key: strings.TrimSpace(key)})
+	}
+	return keys
+}
+
+// parseEncryptionKeys reads ENCRYPTION_KEYS entries of the form id:base64key, newest first.
+// validateConfig has already checked them.
+func parseEncryptionKeys(entries []string) []models.EncryptionKey {
+	keys := make([]models.EncryptionKey, 0, len(entries))
+	for _, entry := range entries {
+		id, key, _ := strings.Cut(entry, ":")
+		decoded, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+		keys = append(keys, models.EncryptionKey{ID: strings.TrimSpace(id), Key: decoded})
+	}
+	return keys
+}
+
+func getEnvAsInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
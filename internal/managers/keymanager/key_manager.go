@@ -0,0 +1,23 @@
+package keymanager
+
+import "errors"
+
+var (
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+	ErrUnknownKeyID      = errors.New("ciphertext was encrypted with an unknown key")
+)
+
+// IKeyring holds the key used for keyed hashes and the versioned keys used to encrypt secrets at
+// rest. Ciphertext names the key that produced it, so older keys keep decrypting after a new one
+// becomes primary.
+type IKeyring interface {
+	// HMAC returns the HMAC-SHA256 of the concatenated parts under the hash key
+	HMAC(parts ...[]byte) []byte
+	// DeriveKey returns a key for a single purpose, so one signature can never double as another
+	DeriveKey(purpose string) []byte
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+	// NeedsRekey reports whether the ciphertext was not produced by the primary key
+	NeedsRekey(ciphertext string) bool
+	PrimaryKeyID() string
+}
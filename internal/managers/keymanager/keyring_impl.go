@@ -0,0 +1,151 @@
+package keymanager
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"cash-flow-financial/internal/models"
+)
+
+const (
+	// MinHashKeyLength keeps the HMAC key at least as long as its output
+	MinHashKeyLength = 32
+	// EncryptionKeyLength selects AES-256
+	EncryptionKeyLength = 32
+)
+
+// Keyring encrypts with the first configured key and decrypts with any of them. Values written
+// before keys were versioned carry no key ID and were encrypted with the first 32 bytes of the hash
+// key; they still decrypt, and NeedsRekey reports them until the rekey command replaces them.
+type Keyring struct {
+	hashKey   []byte
+	primaryID string
+	keys      map[string]cipher.AEAD
+	legacy    cipher.AEAD
+}
+
+func NewKeyring(config *models.KeyringConfig) (IKeyring, error) {
+	if len(config.HashKey) < MinHashKeyLength {
+		return nil, fmt.Errorf("hash key must be at least %d characters", MinHashKeyLength)
+	}
+	if len(config.EncryptionKeys) == 0 {
+		return nil, fmt.Errorf("at least one encryption key is required")
+	}
+
+	keys := make(map[string]cipher.AEAD, len(config.EncryptionKeys))
+	for i, key := range config.EncryptionKeys {
+		if !IsValidKeyID(key.ID) {
+			return nil, fmt.Errorf("encryption key %d has an invalid ID", i+1)
+		}
+		if _, exists := keys[key.ID]; exists {
+			return nil, fmt.Errorf("encryption key ID %s is used more than once", key.ID)
+		}
+		if len(key.Key) != EncryptionKeyLength {
+			return nil, fmt.Errorf("encryption key %s must be %d bytes", key.ID, EncryptionKeyLength)
+		}
+		aead, err := newAEAD(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s: %w", key.ID, err)
+		}
+		keys[key.ID] = aead
+	}
+
+	legacy, err := newAEAD([]byte(config.HashKey)[:EncryptionKeyLength])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keyring{
+		hashKey:   []byte(config.HashKey),
+		primaryID: config.EncryptionKeys[0].ID,
+		keys:      keys,
+		legacy:    legacy,
+	}, nil
+}
+
+func (k *Keyring) HMAC(parts ...[]byte) []byte {
+	h := hmac.New(sha256.New, k.hashKey)
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+func (k *Keyring) DeriveKey(purpose string) []byte {
+	return k.HMAC([]byte(purpose))
+}
+
+// Encrypt returns <key ID>:<base64 of nonce and sealed data>. The key ID is authenticated as
+// additional data, so a value cannot be relabelled to another key.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	aead := k.keys[k.primaryID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.primaryID))
+	return k.primaryID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (k *Keyring) Decrypt(ciphertext string) (string, error) {
+	keyID, encoded, versioned := strings.Cut(ciphertext, ":")
+	aead, additionalData := k.legacy, []byte(nil)
+	if versioned {
+		var ok bool
+		if aead, ok = k.keys[keyID]; !ok {
+			return "", fmt.Errorf("%w: %s", ErrUnknownKeyID, keyID)
+		}
+		additionalData = []byte(keyID)
+	} else {
+		encoded = ciphertext
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
+
+func (k *Keyring) NeedsRekey(ciphertext string) bool {
+	keyID, _, versioned := strings.Cut(ciphertext, ":")
+	return !versioned || keyID != k.primaryID
+}
+
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primaryID
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// IsValidKeyID allows letters, digits, dashes and underscores, which keeps the separator unambiguous
+func IsValidKeyID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package keymanager
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"cash-flow-financial/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHashKey = "test_hash_key_0123456789abcdefghij"
+
+// testKeyring builds a keyring whose key material depends only on each ID, so keyrings listing
+// the same ID in different positions can decrypt each other's values
+func testKeyring(t *testing.T, ids ...string) IKeyring {
+	t.Helper()
+	config := &models.KeyringConfig{HashKey: testHashKey}
+	for _, id := range ids {
+		config.EncryptionKeys = append(config.EncryptionKeys, models.EncryptionKey{
+			ID:  id,
+			Key: []byte(strings.Repeat(id, EncryptionKeyLength)[:EncryptionKeyLength]),
+		})
+	}
+	keyring, err := NewKeyring(config)
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestNewKeyringRejectsInvalidConfig(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, EncryptionKeyLength)
+	tests := []struct {
+		name   string
+		config models.KeyringConfig
+	}{
+		{"short hash key", models.KeyringConfig{HashKey: "short", EncryptionKeys: []models.EncryptionKey{{ID: "v1", Key: key}}}},
+		{"no encryption keys", models.KeyringConfig{HashKey: testHashKey}},
+		{"short encryption key", models.KeyringConfig{HashKey: testHashKey, EncryptionKeys: []models.EncryptionKey{{ID: "v1", Key: key[:16]}}}},
+		{"invalid key ID", models.KeyringConfig{HashKey: testHashKey, EncryptionKeys: []models.EncryptionKey{{ID: "v:1", Key: key}}}},
+		{"duplicate key ID", models.KeyringConfig{HashKey: testHashKey, EncryptionKeys: []models.EncryptionKey{{ID: "v1", Key: key}, {ID: "v1", Key: key}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(&tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	keyring := testKeyring(t, "v1")
+
+	ciphertext, err := keyring.Encrypt("whsec_secret")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ciphertext, "v1:"))
+	assert.False(t, keyring.NeedsRekey(ciphertext))
+
+	plaintext, err := keyring.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "whsec_secret", plaintext)
+}
+
+func TestDecryptWithOlderKey(t *testing.T) {
+	old := testKeyring(t, "v1")
+	ciphertext, err := old.Encrypt("whsec_secret")
+	require.NoError(t, err)
+
+	rotated := testKeyring(t, "v2", "v1")
+	assert.Equal(t, "v2", rotated.PrimaryKeyID())
+	assert.True(t, rotated.NeedsRekey(ciphertext))
+
+	plaintext, err := rotated.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "whsec_secret", plaintext)
+
+	reencrypted, err := rotated.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.False(t, rotated.NeedsRekey(reencrypted))
+}
+
+func TestDecryptRejectsUnknownKey(t *testing.T) {
+	ciphertext, err := testKeyring(t, "v1").Encrypt("whsec_secret")
+	require.NoError(t, err)
+
+	_, err = testKeyring(t, "v2").Decrypt(ciphertext)
+	assert.ErrorIs(t, err, ErrUnknownKeyID)
+}
+
+func TestDecryptRejectsRelabelledCiphertext(t *testing.T) {
+	config := &models.KeyringConfig{HashKey: testHashKey, EncryptionKeys: []models.EncryptionKey{
+		{ID: "v2", Key: bytes.Repeat([]byte{1}, EncryptionKeyLength)},
+		{ID: "v1", Key: bytes.Repeat([]byte{1}, EncryptionKeyLength)},
+	}}
+	keyring, err := NewKeyring(config)
+	require.NoError(t, err)
+
+	ciphertext, err := keyring.Encrypt("whsec_secret")
+	require.NoError(t, err)
+
+	_, err = keyring.Decrypt("v1" + strings.TrimPrefix(ciphertext, "v2"))
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestDecryptLegacyCiphertext(t *testing.T) {
+	block, err := aes.NewCipher([]byte(testHashKey)[:32])
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+	legacy := base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte("whsec_secret"), nil))
+
+	keyring := testKeyring(t, "v1")
+	assert.True(t, keyring.NeedsRekey(legacy))
+
+	plaintext, err := keyring.Decrypt(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, "whsec_secret", plaintext)
+}
+
+func TestDeriveKeyIsPerPurpose(t *testing.T) {
+	keyring := testKeyring(t, "v1")
+	assert.Equal(t, keyring.HMAC([]byte("email-verification")), keyring.DeriveKey("email-verification"))
+	assert.NotEqual(t, keyring.DeriveKey("email-verification"), keyring.DeriveKey("other"))
+	assert.Equal(t, keyring.HMAC([]byte("salt"), []byte("key")), keyring.HMAC([]byte("saltkey")))
+}
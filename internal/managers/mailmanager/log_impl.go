@@ -0,0 +1,26 @@
+package mailmanager
+
+import (
+	"context"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+
+	"go.uber.org/zap"
+)
+
+// LogMailer writes messages to the log instead of sending them
+type LogMailer struct {
+	logger *loggermanager.Logger
+}
+
+func NewLogMailer(logger *loggermanager.Logger) IMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	m.logger.Info("Email not sent, SMTP is not configured",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body))
+	return nil
+}
@@ -0,0 +1,28 @@
+package mailmanager
+
+import (
+	"context"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type IMailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer sends through SMTP when SMTP_HOST is set and otherwise only logs each message, which
+// keeps local and test deployments free of a mail server
+func NewMailer(config *models.MailConfig, logger *loggermanager.Logger) IMailer {
+	if config.SMTPHost == "" {
+		return NewLogMailer(logger)
+	}
+	return NewSMTPMailer(config)
+}
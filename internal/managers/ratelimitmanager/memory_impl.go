@@ -0,0 +1,65 @@
+package ratelimitmanager
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often Increment drops windows that have ended, so keys that stop sending
+// requests do not stay in memory
+const sweepInterval = time.Minute
+
+type memoryWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// MemoryStore keeps the counts in a map guarded by a mutex
+type MemoryStore struct {
+	mu        sync.Mutex
+	windows   map[string]*memoryWindow
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() IStore {
+	return &MemoryStore{
+		windows: make(map[string]*memoryWindow),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Increment(ctx context.Context, key string, window time.Duration) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, w := range s.windows {
+			if !now.Before(w.resetAt) {
+				delete(s.windows, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = &memoryWindow{resetAt: now.Add(window)}
+		s.windows[key] = w
+	}
+	w.count++
+	return Usage{Count: w.count, ResetAt: w.resetAt}, nil
+}
+
+func (s *MemoryStore) Peek(ctx context.Context, key string) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.windows[key]
+	if !ok || !s.now().Before(w.resetAt) {
+		return Usage{}, nil
+	}
+	return Usage{Count: w.count, ResetAt: w.resetAt}, nil
+}
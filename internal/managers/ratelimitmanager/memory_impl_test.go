@@ -0,0 +1,88 @@
+package ratelimitmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(now *time.Time) *MemoryStore {
+	store := NewMemoryStore().(*MemoryStore)
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func TestMemoryStore_CountsWithinWindow(t *testing.T) {
+	now := time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)
+	store := newTestStore(&now)
+	resetAt := now.Add(time.Minute)
+
+	for i := 1; i <= 3; i++ {
+		usage, err := store.Increment(context.Background(), "key", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, usage.Count)
+		assert.Equal(t, resetAt, usage.ResetAt)
+		now = now.Add(10 * time.Second)
+	}
+
+	usage, err := store.Increment(context.Background(), "other", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage.Count, "keys are counted apart")
+}
+
+func TestMemoryStore_StartsOverAfterWindow(t *testing.T) {
+	now := time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)
+	store := newTestStore(&now)
+
+	_, err := store.Increment(context.Background(), "key", time.Minute)
+	require.NoError(t, err)
+	_, err = store.Increment(context.Background(), "key", time.Minute)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	usage, err := store.Increment(context.Background(), "key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage.Count)
+	assert.Equal(t, now.Add(time.Minute), usage.ResetAt)
+}
+
+func TestMemoryStore_SweepsEndedWindows(t *testing.T) {
+	now := time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)
+	store := newTestStore(&now)
+
+	_, err := store.Increment(context.Background(), "idle", time.Second)
+	require.NoError(t, err)
+
+	now = now.Add(sweepInterval)
+	_, err = store.Increment(context.Background(), "active", time.Minute)
+	require.NoError(t, err)
+
+	assert.NotContains(t, store.windows, "idle")
+	assert.Contains(t, store.windows, "active")
+}
+
+func TestMemoryStore_PeekDoesNotCount(t *testing.T) {
+	now := time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)
+	store := newTestStore(&now)
+
+	usage, err := store.Peek(context.Background(), "key")
+	require.NoError(t, err)
+	assert.Zero(t, usage.Count)
+
+	_, err = store.Increment(context.Background(), "key", time.Minute)
+	require.NoError(t, err)
+	for range 2 {
+		usage, err = store.Peek(context.Background(), "key")
+		require.NoError(t, err)
+		assert.Equal(t, 1, usage.Count)
+		assert.Equal(t, now.Add(time.Minute), usage.ResetAt)
+	}
+
+	now = now.Add(time.Minute)
+	usage, err = store.Peek(context.Background(), "key")
+	require.NoError(t, err)
+	assert.Zero(t, usage.Count, "an ended window is not reported")
+}
@@ -0,0 +1,23 @@
+package ratelimitmanager
+
+import (
+	"context"
+	"time"
+)
+
+// Usage is the state of a key's window
+type Usage struct {
+	// Count includes the request just counted by Increment
+	Count   int
+	ResetAt time.Time
+}
+
+// IStore counts requests per key in fixed windows. A window starts with the key's first request
+// and the count starts over once it ends. MemoryStore keeps the counts of one process; a store
+// shared between API instances can implement the same interface.
+type IStore interface {
+	Increment(ctx context.Context, key string, window time.Duration) (Usage, error)
+	// Peek returns the key's current window without counting a request. Count is 0 when the key has
+	// no window or it has ended.
+	Peek(ctx context.Context, key string) (Usage, error)
+}
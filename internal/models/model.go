@@ -1,17 +1,58 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"net"
+	"time"
+)
+
+const (
+	RunModeAPI    = "api"
+	RunModeWorker = "worker"
+	RunModeAll    = "all"
+)
+
+const (
+	AppEnvDevelopment = "development"
+	AppEnvProduction  = "production"
+)
+
+const (
+	BrokerDriverRabbitMQ = "rabbitmq"
+	BrokerDriverMemory   = "memory"
+	BrokerDriverPostgres = "postgres"
+)
 
 type Config struct {
-	Server     ServerConfig
-	Logger     LoggerConfig
-	Database   DatabaseConfig
-	RabbitMQ   RabbitMQConfig
-	APIKeyHash string
+	App       AppConfig
+	Server    ServerConfig
+	Logger    LoggerConfig
+	Database  DatabaseConfig
+	RabbitMQ  RabbitMQConfig
+	Broker    BrokerConfig
+	Worker    WorkerConfig
+	Scheduler SchedulerConfig
+	Webhook   WebhookConfig
+	Mail      MailConfig
+	Admin     AdminConfig
+
+	EmailVerification EmailVerificationConfig
+	Dashboard         DashboardConfig
+	Keyring           KeyringConfig
+	RateLimit         RateLimitConfig
+	// How long a rotated API key keeps authenticating alongside its replacement
+	APIKeyGracePeriod time.Duration
+}
+
+type AppConfig struct {
+	Mode string
+	Env  string
 }
 
 type ServerConfig struct {
 	Port string
+	// Proxies whose X-Forwarded-For is believed; without any the client IP is the connection's address
+	TrustedProxies []*net.IPNet
 }
 
 type LoggerConfig struct {
@@ -35,6 +76,102 @@ type RabbitMQConfig struct {
 	VHost    string
 }
 
+type BrokerConfig struct {
+	Driver string
+	Jobs   JobQueueConfig
+}
+
+// JobQueueConfig tunes the postgres broker driver
+type JobQueueConfig struct {
+	PollInterval      time.Duration
+	VisibilityTimeout time.Duration
+	MaxAttempts       int
+	RetryBackoff      time.Duration
+}
+
+type WorkerConfig struct {
+	Concurrency     int
+	Prefetch        int
+	HealthPort      string
+	ShutdownTimeout time.Duration
+}
+
+type SchedulerConfig struct {
+	ExpirySweepSchedule string
+	MaxJitter           time.Duration
+}
+
+type WebhookConfig struct {
+	SecretGracePeriod       time.Duration
+	RetryHorizon            time.Duration
+	RetryBackoff            time.Duration
+	DispatchConcurrency     int
+	AllowedHosts            []string // Exempt from the private address block
+	DeniedHosts             []string // Never called, even if allowlisted
+	CallbackTimeout         time.Duration
+	CircuitFailureThreshold int           // Consecutive failures that open an endpoint's circuit
+	CircuitCooldown         time.Duration // How long an open circuit waits before a half-open probe
+	EndpointDisableAfter    time.Duration // Failing this long without a success disables the endpoint
+}
+
+// MailConfig configures outgoing email; without SMTPHost messages are only logged
+type MailConfig struct {
+	SMTPHost string
+	SMTPPort string
+	Username string
+	Password string
+	From     string
+}
+
+// EmailVerificationConfig controls the links mailed to confirm a merchant's email address
+type EmailVerificationConfig struct {
+	// Page or endpoint the link opens; the token is appended as the token query parameter
+	URL      string
+	TokenTTL time.Duration
+}
+
+// DashboardConfig controls dashboard sign-in for merchant staff
+type DashboardConfig struct {
+	// Dashboard address; invite links point to its /invite page
+	URL        string
+	SessionTTL time.Duration
+	InviteTTL  time.Duration
+	// Failed sign-ins, wrong passwords or two-factor codes, before a user is locked out for LoginLockout
+	LoginMaxAttempts int
+	LoginLockout     time.Duration
+}
+
+// RateLimitConfig sets how many requests fit in each window: per API key or dashboard user by the
+// merchant's tier, and per client IP on routes that need no credentials
+type RateLimitConfig struct {
+	Window     time.Duration
+	TierLimits map[string]int
+	IPLimit    int
+}
+
+// AdminConfig holds the operator credentials accepted on admin routes and merchant creation
+type AdminConfig struct {
+	APIKeys []AdminKey
+}
+
+// AdminKey is an operator key and the name recorded against the changes made with it
+type AdminKey struct {
+	Name string
+	Key  string
+}
+
+// KeyringConfig holds the key for keyed hashes and the keys that encrypt secrets at rest, newest
+// first. Only the first encryption key encrypts; the rest are kept so older values still decrypt.
+type KeyringConfig struct {
+	HashKey        string
+	EncryptionKeys []EncryptionKey
+}
+
+type EncryptionKey struct {
+	ID  string
+	Key []byte
+}
+
 type CreateMerchantRequest struct {
 	Name  string `json:"name" validate:"required,min=2,max=100" example:"John Doe"`
 	Email string `json:"email" validate:"required,email,max=255" example:"john.doe@example.com"`
@@ -45,8 +182,12 @@ type CreateMerchantResponse struct {
 	MerchantID string `json:"merchant_id,omitempty" example:"CASM-ABC123"`
 	Name       string `json:"name,omitempty" example:"John Doe"`
 	Email      string `json:"email,omitempty" example:"john.doe@example.com"`
-	APIKey     string `json:"api_key,omitempty" example:"cash_test_abc123def456"`
-	Message    string `json:"message" example:"Merchant created successfully"`
+	APIKey     string `json:"api_key,omitempty" example:"api_live_AbCd1234EfGh_AbCdEfGhIjKlMnOpQrStUvWxYz012345"`
+	// Sandbox key; its intents are processed deterministically and never touch live balances
+	TestAPIKey string `json:"test_api_key,omitempty" example:"api_test_IjKl5678MnOp_AbCdEfGhIjKlMnOpQrStUvWxYz012345"`
+	// Shown once; used to verify the Cashflow-Signature header on callbacks
+	WebhookSecret string `json:"webhook_secret,omitempty" example:"sk_abc123def456"`
+	Message       string `json:"message" example:"Merchant created successfully"`
 }
 
 type ErrorResponse struct {
@@ -56,6 +197,7 @@ type ErrorResponse struct {
 }
 
 type MerchantBalance struct {
+	Mode                  string `json:"mode"`
 	Currency              string `json:"currency"`
 	AvailableBalance      string `json:"available_balance"`
 	TotalDeposit          string `json:"total_deposit"`
@@ -67,6 +209,7 @@ type MerchantTransaction struct {
 	ID                  string `json:"id"`
 	PaymentIntentID     string `json:"payment_intent_id"`
 	MerchantID          string `json:"merchant_id"`
+	Mode                string `json:"mode"`
 	Amount              string `json:"amount"`
 	Currency            string `json:"currency"`
 	Status              string `json:"status"`
@@ -80,32 +223,42 @@ type MerchantTransaction struct {
 }
 
 type GetMerchantResponse struct {
-	Status         bool                  `json:"status"`
-	MerchantID     string                `json:"merchant_id"`
-	Name           string                `json:"name"`
-	Email          string                `json:"email"`
-	MerchantStatus string                `json:"merchant_status"`
-	APIKey         string                `json:"api_key"`
-	APIKeyStatus   string                `json:"api_key_status"`
-	CreatedAt      string                `json:"created_at"`
-	APIKeyCreated  string                `json:"api_key_created"`
-	Balances       []MerchantBalance     `json:"balances,omitempty"`
-	Transactions   []MerchantTransaction `json:"transactions,omitempty"`
-	Message        string                `json:"message"`
+	Status           bool                  `json:"status"`
+	MerchantID       string                `json:"merchant_id"`
+	Name             string                `json:"name"`
+	Email            string                `json:"email"`
+	EmailVerified    bool                  `json:"email_verified"`          // Live API keys and live intents require it
+	PendingEmail     string                `json:"pending_email,omitempty"` // Requested address awaiting verification
+	MerchantStatus   string                `json:"merchant_status"`
+	Tier             string                `json:"tier" example:"standard"`
+	Mode             string                `json:"mode" example:"live"` // Mode of the API key; balances and transactions are limited to it
+	APIKeyID         string                `json:"api_key_id,omitempty" example:"9a7b6c5d-4e3f-4a2b-8c1d-0e9f8a7b6c5d"`
+	MaskedAPIKey     string                `json:"masked_api_key" example:"api_live_AbCd1234EfGh_...wxyz"`
+	APIKeyStatus     string                `json:"api_key_status"`
+	APIKeyMustRotate bool                  `json:"api_key_must_rotate,omitempty"` // Legacy key that expires unless rotated
+	APIKeyScopes     []string              `json:"api_key_scopes,omitempty"`
+	CreatedAt        string                `json:"created_at"`
+	APIKeyCreated    string                `json:"api_key_created"`
+	Balances         []MerchantBalance     `json:"balances,omitempty"`
+	Transactions     []MerchantTransaction `json:"transactions,omitempty"`
+	Message          string                `json:"message"`
 }
 
 type CreatePaymentIntentRequest struct {
-	Amount      float64                `json:"amount" validate:"required,gt=0,lte=100000" example:"100.50"`
-	Currency    string                 `json:"currency" validate:"required,len=3,oneof=ETB USD" example:"ETB"`
-	Description string                 `json:"description,omitempty" validate:"max=500" example:"Payment for order #123"`
-	CallbackURL string                 `json:"callback_url" validate:"required,url" example:"https://example.com/callback"`
-	Nonce       string                 `json:"nonce" validate:"required,min=16,max=64" example:"unique_nonce_123456789"`
-	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Amount      float64 `json:"amount" validate:"required,gt=0,lte=100000" example:"100.50"`
+	Currency    string  `json:"currency" validate:"required,len=3,oneof=ETB USD" example:"ETB"`
+	Description string  `json:"description,omitempty" validate:"max=500" example:"Payment for order #123"`
+	CallbackURL string  `json:"callback_url,omitempty" validate:"omitempty,url" example:"https://example.com/callback"` // Overrides registered webhook endpoints
+	Nonce       string  `json:"nonce" validate:"required,min=16,max=64" example:"unique_nonce_123456789"`
+	// Payer account. In test mode the sandbox account numbers choose the outcome.
+	AccountNumber string                 `json:"account_number,omitempty" validate:"omitempty,numeric,min=9,max=15" example:"251900000001"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 }
 
 type CreatePaymentIntentResponse struct {
 	Status          bool      `json:"status" example:"true"`
 	PaymentIntentID string    `json:"payment_intent_id" example:"PI-ABC123"`
+	Mode            string    `json:"mode" example:"live"`
 	Amount          float64   `json:"amount" example:"100.5"`
 	Currency        string    `json:"currency" example:"ETB"`
 	PaymentStatus   string    `json:"payment_status" example:"pending"`
@@ -114,3 +267,536 @@ type CreatePaymentIntentResponse struct {
 	ExpiresAt       time.Time `json:"expires_at" example:"2024-01-05T10:45:00Z"`
 	Message         string    `json:"message" example:"Payment intent created successfully"`
 }
+
+// CallbackAcknowledgement is the merchant's answer to the intent's callbacks: pending until an
+// attempt completes, then acknowledged, unacknowledged (2xx without an acknowledgement body),
+// rejected or failed
+type CallbackAcknowledgement struct {
+	Status    string     `json:"status" example:"acknowledged"`
+	Message   string     `json:"message,omitempty" example:"Order 123 marked as paid"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" example:"2024-01-05T10:35:01Z"`
+}
+
+type PaymentIntentResponse struct {
+	Status          bool                    `json:"status" example:"true"`
+	PaymentIntentID string                  `json:"payment_intent_id" example:"PI-ABC123"`
+	Mode            string                  `json:"mode" example:"live"`
+	Amount          float64                 `json:"amount" example:"100.5"`
+	Currency        string                  `json:"currency" example:"ETB"`
+	PaymentStatus   string                  `json:"payment_status" example:"success"`
+	Description     string                  `json:"description,omitempty" example:"Payment for order #123"`
+	CallbackURL     string                  `json:"callback_url,omitempty" example:"https://example.com/callback"`
+	CallbackAck     CallbackAcknowledgement `json:"callback_ack"`
+	CreatedAt       time.Time               `json:"created_at" example:"2024-01-05T10:30:00Z"`
+	ExpiresAt       time.Time               `json:"expires_at" example:"2024-01-05T10:45:00Z"`
+	Message         string                  `json:"message" example:"Payment intent retrieved successfully"`
+}
+
+type WorkerStats struct {
+	Concurrency int   `json:"concurrency" example:"4"`
+	Prefetch    int   `json:"prefetch" example:"4"`
+	InFlight    int64 `json:"in_flight" example:"2"`
+	Processed   int64 `json:"processed" example:"120"`
+	Failed      int64 `json:"failed" example:"3"`
+}
+
+type HealthResponse struct {
+	Status  string            `json:"status" example:"healthy"`
+	Service string            `json:"service" example:"cash-flow-financial"`
+	Mode    string            `json:"mode" example:"api"`
+	Checks  map[string]string `json:"checks"`
+}
+
+type WorkerStatsResponse struct {
+	Status  bool        `json:"status" example:"true"`
+	Worker  WorkerStats `json:"worker"`
+	Message string      `json:"message" example:"Worker stats retrieved successfully"`
+}
+
+type ScheduledJobRun struct {
+	ID         string     `json:"id" example:"3b9f5a62-0c1e-4a55-8f3d-2e6b7c8d9e01"`
+	JobName    string     `json:"job_name" example:"expire-payment-intents"`
+	Trigger    string     `json:"trigger" example:"schedule"`
+	Status     string     `json:"status" example:"succeeded"`
+	Instance   string     `json:"instance" example:"worker-1-7"`
+	StartedAt  time.Time  `json:"started_at" example:"2024-01-05T10:30:00Z"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" example:"2024-01-05T10:30:01Z"`
+	DurationMs *int64     `json:"duration_ms,omitempty" example:"42"`
+	Error      string     `json:"error,omitempty"`
+	// Schedule slot the run was due for; absent on manual runs
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty" example:"2024-01-05T10:30:00Z"`
+}
+
+type ScheduledJob struct {
+	Name      string           `json:"name" example:"expire-payment-intents"`
+	Schedule  string           `json:"schedule" example:"*/5 * * * *"`
+	NextRunAt time.Time        `json:"next_run_at" example:"2024-01-05T10:35:00Z"`
+	LastRun   *ScheduledJobRun `json:"last_run,omitempty"`
+}
+
+type ScheduledJobsResponse struct {
+	Status  bool           `json:"status" example:"true"`
+	Jobs    []ScheduledJob `json:"jobs"`
+	Message string         `json:"message" example:"Scheduled jobs retrieved successfully"`
+}
+
+type TriggerScheduledJobResponse struct {
+	Status  bool            `json:"status" example:"true"`
+	Run     ScheduledJobRun `json:"run"`
+	Message string          `json:"message" example:"Scheduled job run started"`
+}
+
+type RotateWebhookSecretRequest struct {
+	// Hours the previous secret keeps signing callbacks; defaults to WEBHOOK_SECRET_GRACE_PERIOD
+	GracePeriodHours *int `json:"grace_period_hours,omitempty" validate:"omitempty,min=0,max=168" example:"24"`
+}
+
+type RotateWebhookSecretResponse struct {
+	Status                  bool       `json:"status" example:"true"`
+	WebhookSecret           string     `json:"webhook_secret" example:"sk_abc123def456"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty" example:"2024-01-06T10:30:00Z"`
+	Message                 string     `json:"message" example:"Webhook secret rotated successfully"`
+}
+
+// API key modes. Test keys create sandbox intents, which are processed deterministically and kept
+// apart from live intents, transactions and balances.
+const (
+	ModeTest = "test"
+	ModeLive = "live"
+)
+
+// Merchant tiers. The tier selects the request rate limit of the merchant's API keys and dashboard
+// users.
+const (
+	TierStandard   = "standard"
+	TierGrowth     = "growth"
+	TierEnterprise = "enterprise"
+)
+
+var MerchantTiers = []string{TierStandard, TierGrowth, TierEnterprise}
+
+// API key scopes. Each merchant route requires one, and a key can only call the routes its scopes
+// cover.
+const (
+	ScopeIntentsRead   = "intents:read"
+	ScopeIntentsWrite  = "intents:write"
+	ScopeBalancesRead  = "balances:read"
+	ScopeRefundsWrite  = "refunds:write"
+	ScopePayoutsWrite  = "payouts:write"
+	ScopeWebhooksRead  = "webhooks:read"
+	ScopeWebhooksWrite = "webhooks:write"
+	ScopeKeysRead      = "keys:read"
+	ScopeKeysWrite     = "keys:write"
+	ScopeAccountWrite  = "account:write"
+	ScopeUsersRead     = "users:read"
+	ScopeUsersWrite    = "users:write"
+)
+
+// APIKeyScopes lists every scope in the order keys report them
+var APIKeyScopes = []string{
+	ScopeIntentsRead,
+	ScopeIntentsWrite,
+	ScopeBalancesRead,
+	ScopeRefundsWrite,
+	ScopePayoutsWrite,
+	ScopeWebhooksRead,
+	ScopeWebhooksWrite,
+	ScopeKeysRead,
+	ScopeKeysWrite,
+	ScopeAccountWrite,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+}
+
+// Dashboard roles. A signed-in user holds the scopes of their role and is checked against them
+// exactly as an API key is against its own.
+const (
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleDeveloper = "developer"
+	RoleFinance   = "finance"
+	RoleReadOnly  = "read_only"
+)
+
+// DashboardRoles lists every role from most to least privileged
+var DashboardRoles = []string{RoleOwner, RoleAdmin, RoleDeveloper, RoleFinance, RoleReadOnly}
+
+// RoleScopes maps each dashboard role to the scopes it grants. Owners and admins hold every
+// scope; only owners can grant, change or remove the owner role.
+var RoleScopes = map[string][]string{
+	RoleOwner: APIKeyScopes,
+	RoleAdmin: APIKeyScopes,
+	RoleDeveloper: {
+		ScopeIntentsRead,
+		ScopeIntentsWrite,
+		ScopeBalancesRead,
+		ScopeWebhooksRead,
+		ScopeWebhooksWrite,
+		ScopeKeysRead,
+		ScopeKeysWrite,
+	},
+	RoleFinance: {
+		ScopeIntentsRead,
+		ScopeBalancesRead,
+		ScopeRefundsWrite,
+		ScopePayoutsWrite,
+	},
+	RoleReadOnly: {
+		ScopeIntentsRead,
+		ScopeBalancesRead,
+		ScopeWebhooksRead,
+		ScopeKeysRead,
+	},
+}
+
+// APIKey describes a merchant API key. The full key is only returned when it is created.
+type APIKey struct {
+	ID         string     `json:"id" example:"9a7b6c5d-4e3f-4a2b-8c1d-0e9f8a7b6c5d"`
+	Label      string     `json:"label,omitempty" example:"Production server"`
+	MaskedKey  string     `json:"masked_key" example:"api_live_AbCd1234EfGh_...wxyz"`
+	Mode       string     `json:"mode" example:"live"`
+	Status     string     `json:"status" example:"active"` // active, expired or revoked
+	MustRotate bool       `json:"must_rotate,omitempty"`   // Legacy key that expires unless rotated
+	Scopes     []string   `json:"scopes" example:"intents:read,intents:write"`
+	CreatedAt  time.Time  `json:"created_at" example:"2024-01-05T10:30:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2024-01-05T11:02:13Z"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2024-01-06T10:30:00Z"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" example:"2024-01-06T10:30:00Z"`
+}
+
+// UpdateProfileRequest changes the fields that are set. A new email replaces the current one only
+// once the link mailed to it is followed; the current email is used until then.
+type UpdateProfileRequest struct {
+	Name  *string `json:"name,omitempty" validate:"omitempty,min=2,max=100" example:"John Doe Trading"`
+	Email *string `json:"email,omitempty" validate:"omitempty,email,max=255" example:"billing@example.com"`
+}
+
+type ProfileResponse struct {
+	Status        bool   `json:"status" example:"true"`
+	MerchantID    string `json:"merchant_id" example:"CASM-ABC123"`
+	Name          string `json:"name" example:"John Doe Trading"`
+	Email         string `json:"email" example:"john.doe@example.com"`
+	EmailVerified bool   `json:"email_verified" example:"true"`
+	PendingEmail  string `json:"pending_email,omitempty" example:"billing@example.com"`
+	// Set when a verification link was mailed by this request
+	VerificationSent bool   `json:"verification_sent,omitempty" example:"true"`
+	Message          string `json:"message" example:"Profile updated successfully"`
+}
+
+type CreateAPIKeyRequest struct {
+	Label string `json:"label" validate:"required,max=100" example:"Production server"`
+	// Defaults to the scopes of the key making the request, which is also the most it can grant
+	Scopes []string `json:"scopes,omitempty" example:"intents:write"`
+	// Defaults to the mode of the key making the request; a test key can only create test keys
+	Mode string `json:"mode,omitempty" validate:"omitempty,oneof=test live" example:"test"`
+}
+
+type CreateAPIKeyResponse struct {
+	Status  bool   `json:"status" example:"true"`
+	APIKey  string `json:"api_key" example:"api_live_AbCd1234EfGh_AbCdEfGhIjKlMnOpQrStUvWxYz012345"`
+	Key     APIKey `json:"key"`
+	Message string `json:"message" example:"API key created successfully"`
+}
+
+type ListAPIKeysResponse struct {
+	Status  bool     `json:"status" example:"true"`
+	Keys    []APIKey `json:"keys"`
+	Message string   `json:"message" example:"API keys retrieved successfully"`
+}
+
+type APIKeyResponse struct {
+	Status  bool   `json:"status" example:"true"`
+	Key     APIKey `json:"key"`
+	Message string `json:"message" example:"API key revoked successfully"`
+}
+
+type RotateAPIKeyRequest struct {
+	// Label for the new key; defaults to the rotated key's label
+	Label string `json:"label,omitempty" validate:"max=100" example:"Production server"`
+	// Hours the rotated key keeps authenticating; defaults to API_KEY_GRACE_PERIOD
+	GracePeriodHours *int `json:"grace_period_hours,omitempty" validate:"omitempty,min=0,max=168" example:"24"`
+}
+
+type RotateAPIKeyResponse struct {
+	Status      bool   `json:"status" example:"true"`
+	APIKey      string `json:"api_key" example:"api_live_AbCd1234EfGh_AbCdEfGhIjKlMnOpQrStUvWxYz012345"`
+	Key         APIKey `json:"key"`
+	PreviousKey APIKey `json:"previous_key"`
+	Message     string `json:"message" example:"API key rotated successfully"`
+}
+
+type WebhookDeliveryAttempt struct {
+	AttemptNumber int32     `json:"attempt_number" example:"1"`
+	ResponseCode  *int32    `json:"response_code,omitempty" example:"500"`
+	LatencyMs     int64     `json:"latency_ms" example:"184"`
+	ResponseBody  string    `json:"response_body,omitempty" example:"Internal Server Error"`
+	Acknowledged  *bool     `json:"acknowledged,omitempty" example:"false"` // Set when the body was an acknowledgement
+	AckMessage    string    `json:"ack_message,omitempty" example:"Order not found"`
+	Error         string    `json:"error,omitempty" example:"callback endpoint returned non-2xx status: 500"`
+	AttemptedAt   time.Time `json:"attempted_at" example:"2024-01-05T10:35:01Z"`
+}
+
+type WebhookDelivery struct {
+	ID               string                   `json:"id" example:"0c4a8f5e-6d3b-4e1a-9f2c-7b8d9e0a1b2c"`
+	EndpointID       string                   `json:"endpoint_id,omitempty" example:"5d2e7c1a-8b4f-4a3e-9c6d-1f0e2a3b4c5d"`
+	PaymentIntentID  string                   `json:"payment_intent_id" example:"PI-ABC123DEF456"`
+	EventType        string                   `json:"event_type" example:"payment.intent.succeeded"`
+	URL              string                   `json:"url" example:"https://example.com/callback"`
+	Status           string                   `json:"status" example:"pending"`
+	Attempts         int32                    `json:"attempts" example:"2"`
+	NextAttemptAt    *time.Time               `json:"next_attempt_at,omitempty" example:"2024-01-05T10:36:01Z"`
+	LastResponseCode *int32                   `json:"last_response_code,omitempty" example:"500"`
+	LastError        string                   `json:"last_error,omitempty" example:"callback endpoint returned non-2xx status: 500"`
+	DeliveredAt      *time.Time               `json:"delivered_at,omitempty"`
+	ResendOf         string                   `json:"resend_of,omitempty"`
+	CreatedAt        time.Time                `json:"created_at" example:"2024-01-05T10:35:00Z"`
+	Payload          json.RawMessage          `json:"payload" swaggertype:"object"`
+	AttemptLog       []WebhookDeliveryAttempt `json:"attempt_log"`
+}
+
+type WebhookDeliveryFilter struct {
+	PaymentIntentID string
+	Status          string
+	From            time.Time
+	To              time.Time
+	Limit           int
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Status     bool              `json:"status" example:"true"`
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	Message    string            `json:"message" example:"Webhook deliveries retrieved successfully"`
+}
+
+type WebhookDeliveryResponse struct {
+	Status   bool            `json:"status" example:"true"`
+	Delivery WebhookDelivery `json:"delivery"`
+	Message  string          `json:"message" example:"Webhook delivery retrieved successfully"`
+}
+
+type SendTestWebhookRequest struct {
+	CallbackURL string `json:"callback_url" validate:"required,url" example:"https://example.com/callback"`
+}
+
+type SendTestWebhookResponse struct {
+	Status       bool   `json:"status" example:"true"`
+	Delivered    bool   `json:"delivered" example:"true"`
+	ResponseCode int    `json:"response_code,omitempty" example:"200"`
+	LatencyMs    int64  `json:"latency_ms" example:"184"`
+	ResponseBody string `json:"response_body,omitempty" example:"{\"status\":true}"`
+	Acknowledged *bool  `json:"acknowledged,omitempty" example:"true"` // Set when the body was an acknowledgement
+	AckMessage   string `json:"ack_message,omitempty" example:"Received"`
+	Error        string `json:"error,omitempty"`
+	Message      string `json:"message" example:"Test webhook delivered"`
+}
+
+type WebhookEndpoint struct {
+	ID                  string     `json:"id" example:"5d2e7c1a-8b4f-4a3e-9c6d-1f0e2a3b4c5d"`
+	URL                 string     `json:"url" example:"https://example.com/webhooks/payments"`
+	EventTypes          []string   `json:"event_types" example:"payment.intent.succeeded,payment.intent.failed"`
+	Enabled             bool       `json:"enabled" example:"true"`
+	Description         string     `json:"description,omitempty" example:"Order service"`
+	ConsecutiveFailures int32      `json:"consecutive_failures" example:"0"`
+	CircuitOpen         bool       `json:"circuit_open" example:"false"`
+	FailingSince        *time.Time `json:"failing_since,omitempty" example:"2024-01-05T10:30:00Z"`
+	DisabledReason      string     `json:"disabled_reason,omitempty" example:"25 consecutive failed deliveries since 2024-01-02T10:30:00Z; last error: unexpected status code 503"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty" example:"2024-01-05T10:30:00Z"`
+	CreatedAt           time.Time  `json:"created_at" example:"2024-01-05T10:30:00Z"`
+	UpdatedAt           time.Time  `json:"updated_at" example:"2024-01-05T10:30:00Z"`
+}
+
+type CreateWebhookEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=500" example:"https://example.com/webhooks/payments"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1" example:"payment.intent.succeeded"`
+	Enabled     *bool    `json:"enabled,omitempty" example:"true"` // Defaults to true
+	Description string   `json:"description,omitempty" validate:"max=500" example:"Order service"`
+}
+
+// UpdateWebhookEndpointRequest changes only the fields that are present
+type UpdateWebhookEndpointRequest struct {
+	URL         *string  `json:"url,omitempty" validate:"omitempty,url,max=500" example:"https://example.com/webhooks/payments"`
+	EventTypes  []string `json:"event_types,omitempty" validate:"omitempty,min=1" example:"*"`
+	Enabled     *bool    `json:"enabled,omitempty" example:"false"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=500" example:"Order service"`
+}
+
+type WebhookEndpointResponse struct {
+	Status   bool            `json:"status" example:"true"`
+	Endpoint WebhookEndpoint `json:"endpoint"`
+	Message  string          `json:"message" example:"Webhook endpoint created successfully"`
+}
+
+type ListWebhookEndpointsResponse struct {
+	Status    bool              `json:"status" example:"true"`
+	Endpoints []WebhookEndpoint `json:"endpoints"`
+	Message   string            `json:"message" example:"Webhook endpoints retrieved successfully"`
+}
+
+// Merchant statuses. Suspended merchants keep API access but cannot create intents, and their
+// pending intents wait until they are reactivated. Inactive merchants cannot authenticate.
+const (
+	MerchantStatusActive    = "active"
+	MerchantStatusSuspended = "suspended"
+	MerchantStatusInactive  = "inactive"
+)
+
+// AdminMerchant is a merchant as operators see it
+type AdminMerchant struct {
+	MerchantID      string     `json:"merchant_id" example:"CASM-ABC123"`
+	Name            string     `json:"name" example:"John Doe"`
+	Email           string     `json:"email" example:"john.doe@example.com"`
+	EmailVerified   bool       `json:"email_verified" example:"true"`
+	PendingEmail    string     `json:"pending_email,omitempty" example:"billing@example.com"`
+	MerchantStatus  string     `json:"merchant_status" example:"active"`
+	Tier            string     `json:"tier" example:"standard"`
+	ActiveAPIKeys   int64      `json:"active_api_keys" example:"2"`
+	PendingIntents  int64      `json:"pending_intents" example:"0"` // Pending intents across both modes
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" example:"2024-01-05T10:30:00Z"`
+	CreatedAt       time.Time  `json:"created_at" example:"2024-01-05T10:30:00Z"`
+	UpdatedAt       time.Time  `json:"updated_at" example:"2024-01-05T10:30:00Z"`
+}
+
+// MerchantStatusEvent records one status change and the operator who made it
+type MerchantStatusEvent struct {
+	FromStatus string    `json:"from_status" example:"active"`
+	ToStatus   string    `json:"to_status" example:"suspended"`
+	Reason     string    `json:"reason" example:"Chargeback ratio above 2%"`
+	Actor      string    `json:"actor" example:"alice"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-01-05T10:30:00Z"`
+}
+
+type MerchantFilter struct {
+	Query  string // Matches merchant ID, name or email
+	Status string
+	Limit  int
+	Offset int
+}
+
+type ListMerchantsResponse struct {
+	Status    bool            `json:"status" example:"true"`
+	Merchants []AdminMerchant `json:"merchants"`
+	Message   string          `json:"message" example:"Merchants retrieved successfully"`
+}
+
+type AdminMerchantResponse struct {
+	Status        bool                  `json:"status" example:"true"`
+	Merchant      AdminMerchant         `json:"merchant"`
+	StatusHistory []MerchantStatusEvent `json:"status_history"` // Newest first
+	Message       string                `json:"message" example:"Merchant retrieved successfully"`
+}
+
+type ChangeMerchantStatusRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500" example:"Chargeback ratio above 2%"`
+}
+
+type ChangeMerchantStatusResponse struct {
+	Status             bool                `json:"status" example:"true"`
+	Merchant           AdminMerchant       `json:"merchant"`
+	Event              MerchantStatusEvent `json:"event"`
+	RepublishedIntents int                 `json:"republished_intents,omitempty" example:"3"` // Pending intents queued again on reactivation
+	Message            string              `json:"message" example:"Merchant suspended"`
+}
+
+type ChangeMerchantTierRequest struct {
+	Tier string `json:"tier" validate:"required,oneof=standard growth enterprise" example:"growth"`
+}
+
+type ChangeMerchantTierResponse struct {
+	Status   bool          `json:"status" example:"true"`
+	Merchant AdminMerchant `json:"merchant"`
+	Message  string        `json:"message" example:"Merchant tier changed"`
+}
+
+// DashboardUser is a member of a merchant's staff who signs in to the dashboard
+type DashboardUser struct {
+	ID          string     `json:"id" example:"3f2b1c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d"`
+	Email       string     `json:"email" example:"finance@example.com"`
+	Name        string     `json:"name,omitempty" example:"Abebe Kebede"`
+	Role        string     `json:"role" example:"finance"`
+	Status      string     `json:"status" example:"active"` // invited, active or disabled
+	TOTPEnabled bool       `json:"totp_enabled" example:"true"`
+	InvitedBy   string     `json:"invited_by" example:"owner@example.com"` // Email of the inviting user, or api_key
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" example:"2024-01-05T10:30:00Z"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-01-05T10:30:00Z"`
+}
+
+// DashboardPrincipal is a resolved dashboard session. Merchant carries the role's scopes in
+// APIKeyScopes so merchant routes treat the session like an API key.
+type DashboardPrincipal struct {
+	SessionID string
+	User      DashboardUser
+	Merchant  GetMerchantResponse
+}
+
+type InviteUserRequest struct {
+	Email string `json:"email" validate:"required,email,max=255" example:"finance@example.com"`
+	Name  string `json:"name,omitempty" validate:"max=100" example:"Abebe Kebede"`
+	Role  string `json:"role" validate:"required,oneof=owner admin developer finance read_only" example:"finance"`
+}
+
+type InviteUserResponse struct {
+	Status          bool          `json:"status" example:"true"`
+	User            DashboardUser `json:"user"`
+	InviteExpiresAt time.Time     `json:"invite_expires_at" example:"2024-01-08T10:30:00Z"`
+	Message         string        `json:"message" example:"Invitation sent"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner admin developer finance read_only" example:"developer"`
+}
+
+type UserResponse struct {
+	Status  bool          `json:"status" example:"true"`
+	User    DashboardUser `json:"user"`
+	Message string        `json:"message" example:"User updated successfully"`
+}
+
+type ListUsersResponse struct {
+	Status  bool            `json:"status" example:"true"`
+	Users   []DashboardUser `json:"users"`
+	Message string          `json:"message" example:"Users retrieved successfully"`
+}
+
+type AcceptInviteRequest struct {
+	Token string `json:"token" validate:"required" example:"inv_AbCdEfGhIjKlMnOpQrStUvWxYz012345"`
+	Name  string `json:"name,omitempty" validate:"max=100" example:"Abebe Kebede"`
+	// bcrypt only uses the first 72 bytes, so longer passwords are refused rather than truncated
+	Password string `json:"password" validate:"required,min=12,max=72" example:"correct horse battery staple"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email,max=255" example:"finance@example.com"`
+	Password string `json:"password" validate:"required,max=72" example:"correct horse battery staple"`
+	// Required once the user has enabled two-factor authentication
+	TOTPCode string `json:"totp_code,omitempty" validate:"omitempty,len=6,numeric" example:"123456"`
+}
+
+type LoginResponse struct {
+	Status bool `json:"status" example:"true"`
+	// Sent as Authorization: Bearer <session_token> on dashboard requests; shown only once
+	SessionToken string        `json:"session_token" example:"sess_AbCdEfGhIjKlMnOpQrStUvWxYz0123456789AbCdEfGh"`
+	ExpiresAt    time.Time     `json:"expires_at" example:"2024-01-05T22:30:00Z"`
+	User         DashboardUser `json:"user"`
+	Message      string        `json:"message" example:"Signed in successfully"`
+}
+
+type DashboardMeResponse struct {
+	Status       bool          `json:"status" example:"true"`
+	User         DashboardUser `json:"user"`
+	MerchantID   string        `json:"merchant_id" example:"CASM-ABC123DEF456"`
+	MerchantName string        `json:"merchant_name" example:"Acme Store"`
+	Scopes       []string      `json:"scopes"` // Granted by the user's role
+	Message      string        `json:"message" example:"Signed in"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Status bool `json:"status" example:"true"`
+	// Base32 secret for authenticator apps that cannot scan the URI
+	Secret  string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	URI     string `json:"uri" example:"otpauth://totp/Cash%20Flow:finance@example.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=Cash%20Flow"`
+	Message string `json:"message" example:"Scan the code, then confirm it with a code from the app"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric" example:"123456"`
+}
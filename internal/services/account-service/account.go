@@ -1,9 +1,24 @@
 package accountservice
 
-import "cash-flow-financial/internal/models"
+import (
+	"context"
+	"time"
+
+	"cash-flow-financial/internal/models"
+)
 
 type IAccountService interface {
 	CreateMerchant(name, email string) (*models.CreateMerchantResponse, error)
-	GetMerchantByID(merchantID string) (*models.GetMerchantResponse, error)
+	GetMerchantByID(merchantID, mode string) (*models.GetMerchantResponse, error)
 	GetMerchantByAPIKey(apiKey string) (*models.GetMerchantResponse, error)
+	RotateWebhookSecret(merchantID string, gracePeriod time.Duration) (*models.RotateWebhookSecretResponse, error)
+	GetWebhookSigningSecrets(ctx context.Context, merchantID string) ([]string, error)
+	CreateAPIKey(merchantID, label, mode string, scopes []string) (*models.CreateAPIKeyResponse, error)
+	ListAPIKeys(merchantID string) (*models.ListAPIKeysResponse, error)
+	GetAPIKey(merchantID, keyID string) (*models.APIKeyResponse, error)
+	RevokeAPIKey(merchantID, keyID string) (*models.APIKeyResponse, error)
+	RotateAPIKey(merchantID, keyID, label string, gracePeriod time.Duration) (*models.RotateAPIKeyResponse, error)
+	UpdateProfile(ctx context.Context, merchantID string, req models.UpdateProfileRequest) (*models.ProfileResponse, error)
+	SendVerificationEmail(ctx context.Context, merchantID string) (*models.ProfileResponse, error)
+	VerifyEmail(ctx context.Context, token string) (*models.ProfileResponse, error)
 }
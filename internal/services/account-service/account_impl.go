@@ -2,31 +2,60 @@ package accountservice
 
 import (
 	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/dbmanager"
+	"cash-flow-financial/internal/managers/keymanager"
 	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/managers/mailmanager"
 	"cash-flow-financial/internal/models"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
 var (
-	ErrDuplicateEmail = errors.New("merchant with this email already exists")
+	ErrDuplicateEmail  = errors.New("merchant with this email already exists")
+	ErrAPIKeyNotFound  = errors.New("API key not found")
+	ErrAPIKeyNotActive = errors.New("API key is revoked or expired")
+	ErrLastAPIKey      = errors.New("cannot revoke the last active API key that can manage keys")
+	ErrInvalidAPIKey   = errors.New("invalid API key")
+	ErrInvalidScopes   = errors.New("API key needs at least one valid scope")
+	ErrInvalidMode     = errors.New("API key mode must be test or live")
+
+	ErrEmailNotVerified         = errors.New("email address is not verified")
+	ErrEmailAlreadyVerified     = errors.New("email address is already verified")
+	ErrInvalidVerificationToken = errors.New("invalid or superseded verification token")
+	ErrVerificationTokenExpired = errors.New("verification token has expired")
+
+	errAPIKeyMismatch   = errors.New("API key does not match its stored hash")
+	errMerchantNotFound = errors.New("merchant not found")
 )
 
+// defaultAPIKeyLabel names the key issued when a merchant is created
+const defaultAPIKeyLabel = "Default"
+
 type AccountService struct {
-	queries *db.Queries
-	logger  *loggermanager.Logger
-	hashKey string
+	queries      *db.Queries
+	dbManager    dbmanager.IDBManager
+	mailer       mailmanager.IMailer
+	logger       *loggermanager.Logger
+	keyring      keymanager.IKeyring
+	verification models.EmailVerificationConfig
 }
 
-func NewAccountService(queries *db.Queries, logger *loggermanager.Logger, config *models.Config) IAccountService {
+func NewAccountService(queries *db.Queries, dbManager dbmanager.IDBManager, keyring keymanager.IKeyring, mailer mailmanager.IMailer, logger *loggermanager.Logger, config *models.Config) IAccountService {
 	return &AccountService{
-		queries: queries,
-		logger:  logger,
-		hashKey: config.APIKeyHash,
+		queries:      queries,
+		dbManager:    dbManager,
+		mailer:       mailer,
+		logger:       logger,
+		keyring:      keyring,
+		verification: config.EmailVerification,
 	}
 }
 
@@ -35,64 +64,79 @@ func (as *AccountService) CreateMerchant(name, email string) (*models.CreateMerc
 	as.logger.Info("Starting merchant creation process", zap.String("email", email), zap.String("name", name))
 
 	merchantID := generateMerchantID()
-	apiKey := generateAPIKey()
-	as.logger.Info("Generated plain API key", zap.String("plain_api_key", maskAPIKey(apiKey)))
 
-	hashedAPIKey := hashAPIKey(apiKey, as.hashKey)
-	hashPreview := hashedAPIKey
-	if len(hashedAPIKey) > 50 {
-		hashPreview = hashedAPIKey[:50] + "..."
+	webhookSecret := GenerateWebhookSecret()
+	encryptedSecret, err := as.keyring.Encrypt(webhookSecret)
+	if err != nil {
+		as.logger.Error("Failed to encrypt merchant webhook secret", zap.String("merchant_id", merchantID), zap.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to encrypt merchant webhook secret: %w", err)
 	}
-	as.logger.Info("Generated hashed API key", zap.String("hashed_preview", hashPreview))
 
-	encryptedAPIKey := encryptAPIKey(apiKey, as.hashKey)
-	as.logger.Info("Generated encrypted API key for storage", zap.String("encrypted_preview", encryptedAPIKey[:50]+"..."))
+	// The merchant is only stored together with its keys and webhook secret. Otherwise a failure
+	// part way would leave a merchant nobody can use, holding the email so the signup cannot be retried.
+	ctx := context.Background()
+	var merchant *db.Merchant
+	var apiKey, testAPIKey string
+	err = as.dbManager.WithTransaction(ctx, func(tx *sql.Tx) error {
+		qtx := as.queries.WithTx(tx)
+
+		var err error
+		merchant, err = qtx.CreateMerchant(ctx, &db.CreateMerchantParams{
+			MerchantID: merchantID,
+			Name:       name,
+			Email:      email,
+		})
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return ErrDuplicateEmail
+			}
+			return fmt.Errorf("failed to create merchant: %w", err)
+		}
 
-	merchant, err := as.queries.CreateMerchant(context.Background(), &db.CreateMerchantParams{
-		MerchantID: merchantID,
-		Name:       name,
-		Email:      email,
-	})
-	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			as.logger.Warn("Duplicate email attempt", zap.String("email", email), zap.String("error", "unique constraint violation"))
-			return nil, ErrDuplicateEmail
+		// Only a salted hash of the keys is stored, so this response is the one time they are shown
+		apiKey, _, err = as.issueAPIKey(qtx, merchant.ID, defaultAPIKeyLabel, db.ApiModeLive, models.APIKeyScopes)
+		if err != nil {
+			return fmt.Errorf("failed to create merchant API key: %w", err)
 		}
-		as.logger.Error("Failed to create merchant in database", zap.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to create merchant: %w", err)
-	}
 
-	as.logger.Info("Merchant record created successfully", zap.String("merchant_id", merchantID))
+		testAPIKey, _, err = as.issueAPIKey(qtx, merchant.ID, defaultAPIKeyLabel, db.ApiModeTest, models.APIKeyScopes)
+		if err != nil {
+			return fmt.Errorf("failed to create merchant test API key: %w", err)
+		}
 
-	_, err = as.queries.CreateMerchantAPIKey(context.Background(), &db.CreateMerchantAPIKeyParams{
-		MerchantID: merchant.ID,
-		ApiKey:     hashedAPIKey,
-		SecretKey:  encryptedAPIKey,
+		_, err = qtx.CreateMerchantWebhookSecret(ctx, &db.CreateMerchantWebhookSecretParams{
+			MerchantID: merchant.ID,
+			Secret:     encryptedSecret,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create merchant webhook secret: %w", err)
+		}
+		return nil
 	})
+	if errors.Is(err, ErrDuplicateEmail) {
+		as.logger.Warn("Duplicate email attempt", zap.String("email", email), zap.String("error", "unique constraint violation"))
+		return nil, ErrDuplicateEmail
+	}
 	if err != nil {
-		as.logger.Error("Failed to create merchant API key", zap.String("merchant_id", merchant.ID.String()), zap.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to create merchant API key: %w", err)
+		as.logger.Error("Failed to create merchant in database", zap.String("merchant_id", merchantID), zap.String("error", err.Error()))
+		return nil, err
 	}
 
-	as.logger.Info("Merchant API key created successfully", zap.String("merchant_id", merchant.ID.String()))
-	storedHashPreview := hashedAPIKey
-	if len(hashedAPIKey) > 50 {
-		storedHashPreview = hashedAPIKey[:50] + "..."
-	}
-	storedEncryptedPreview := encryptedAPIKey
-	if len(encryptedAPIKey) > 50 {
-		storedEncryptedPreview = encryptedAPIKey[:50] + "..."
+	as.logger.Info("Merchant record created successfully", zap.String("merchant_id", merchantID))
+	as.logger.Info("Merchant API keys created successfully",
+		zap.String("merchant_id", merchant.ID.String()),
+		zap.String("api_key", maskAPIKey(apiKey)),
+		zap.String("test_api_key", maskAPIKey(testAPIKey)))
+	as.logger.Info("Merchant webhook signing secret created", zap.String("merchant_id", merchant.ID.String()))
+
+	// The merchant can ask for another link, so a failed send does not undo the signup
+	if err := as.sendVerificationEmail(ctx, merchant, merchant.Email); err != nil {
+		as.logger.Error("Failed to send verification email", zap.String("merchant_id", merchantID), zap.Error(err))
 	}
 
-	as.logger.Info("=== DATABASE STORAGE DETAILS ===")
-	as.logger.Info("Stored in api_key column (for auth)", zap.String("stored_hash", storedHashPreview))
-	as.logger.Info("Stored in secret_key column (for retrieval)", zap.String("stored_encrypted", storedEncryptedPreview))
-	as.logger.Info("Plain API key for user", zap.String("user_api_key", maskAPIKey(apiKey)))
-	as.logger.Info("=== END DATABASE STORAGE ===")
-
 	as.logger.Info("Merchant creation completed successfully", zap.String("merchant_id", merchant.ID.String()), zap.String("email", email))
 	as.logger.Info("=== MERCHANT CREATION END ===")
-	as.logger.Info("Returning API key to user", zap.String("returned_api_key", maskAPIKey(apiKey)))
 
 	return &models.CreateMerchantResponse{
 		Status:     true,
@@ -100,25 +144,27 @@ func (as *AccountService) CreateMerchant(name, email string) (*models.CreateMerc
 		Name:       name,
 		Email:      email,
 		APIKey:     apiKey,
-		Message:    "Merchant created successfully",
+		TestAPIKey: testAPIKey,
+
+		WebhookSecret: webhookSecret,
+		Message:       "Merchant created successfully",
 	}, nil
 }
 
-func (as *AccountService) GetMerchantByID(merchantID string) (*models.GetMerchantResponse, error) {
-	as.logger.Info("Getting merchant by ID", zap.String("merchant_id", merchantID))
+// GetMerchantByID returns the merchant with the balances and transactions of one mode, so test
+// traffic never shows up next to live figures
+func (as *AccountService) GetMerchantByID(merchantID, mode string) (*models.GetMerchantResponse, error) {
+	as.logger.Info("Getting merchant by ID", zap.String("merchant_id", merchantID), zap.String("mode", mode))
 
-	merchant, err := as.queries.GetMerchantWithAPIKey(context.Background(), merchantID)
+	merchant, err := as.queries.GetMerchantWithAPIKey(context.Background(), &db.GetMerchantWithAPIKeyParams{
+		MerchantID: merchantID,
+		Mode:       db.ApiMode(mode),
+	})
 	if err != nil {
 		as.logger.Error("Failed to get merchant by ID", zap.String("merchant_id", merchantID), zap.String("error", err.Error()))
 		return nil, fmt.Errorf("merchant not found")
 	}
 
-	as.logger.Info("=== MERCHANT RETRIEVAL DETAILS ===")
-	as.logger.Info("Retrieved from database", zap.String("merchant_id", merchantID))
-	as.logger.Info("Stored api_key (hash)", zap.String("db_api_key", maskAPIKey(merchant.ApiKey)))
-	as.logger.Info("Stored secret_key (encrypted)", zap.String("db_secret_key", maskAPIKey(merchant.SecretKey)))
-	as.logger.Info("=== END RETRIEVAL DETAILS ===")
-
 	merchantStatus := "unknown"
 	if merchant.Status.Valid {
 		merchantStatus = string(merchant.Status.MerchantStatus)
@@ -139,13 +185,10 @@ func (as *AccountService) GetMerchantByID(merchantID string) (*models.GetMerchan
 		apiKeyCreatedAt = merchant.ApiKeyCreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
 	}
 
-	decryptedAPIKey, err := decryptAPIKey(merchant.SecretKey, as.hashKey)
-	if err != nil {
-		as.logger.Error("Failed to decrypt API key", zap.Error(err))
-		return nil, fmt.Errorf("failed to retrieve API key")
-	}
-
-	balances, err := as.queries.GetMerchantBalances(context.Background(), merchant.ID)
+	balances, err := as.queries.GetMerchantBalances(context.Background(), &db.GetMerchantBalancesParams{
+		MerchantID: merchant.ID,
+		Mode:       db.ApiMode(mode),
+	})
 	if err != nil {
 		as.logger.Warn("Failed to get merchant balances", zap.String("merchant_id", merchantID), zap.Error(err))
 
@@ -154,6 +197,7 @@ func (as *AccountService) GetMerchantByID(merchantID string) (*models.GetMerchan
 	var merchantBalances []models.MerchantBalance
 	for _, balance := range balances {
 		merchantBalances = append(merchantBalances, models.MerchantBalance{
+			Mode:                  string(balance.Mode),
 			Currency:              string(balance.Currency),
 			AvailableBalance:      balance.AvailableBalance.String,
 			TotalDeposit:          balance.TotalDeposit.String,
@@ -163,7 +207,10 @@ func (as *AccountService) GetMerchantByID(merchantID string) (*models.GetMerchan
 	}
 
 	// Get merchant transactions
-	transactions, err := as.queries.GetMerchantTransactions(context.Background(), merchant.MerchantID)
+	transactions, err := as.queries.GetMerchantTransactions(context.Background(), &db.GetMerchantTransactionsParams{
+		MerchantID: merchant.MerchantID,
+		Mode:       db.ApiMode(mode),
+	})
 	if err != nil {
 		as.logger.Warn("Failed to get merchant transactions", zap.String("merchant_id", merchantID), zap.Error(err))
 		// Don't fail the request, just log the warning
@@ -210,6 +257,7 @@ func (as *AccountService) GetMerchantByID(merchantID string) (*models.GetMerchan
 			ID:                  transaction.ID.String(),
 			PaymentIntentID:     transaction.PaymentIntentID,
 			MerchantID:          transaction.MerchantID,
+			Mode:                string(transaction.Mode),
 			Amount:              transaction.Amount,
 			Currency:            string(transaction.Currency),
 			Status:              string(transaction.Status.TransactionStatus),
@@ -230,8 +278,12 @@ func (as *AccountService) GetMerchantByID(merchantID string) (*models.GetMerchan
 		MerchantID:     merchant.MerchantID,
 		Name:           merchant.Name,
 		Email:          merchant.Email,
+		EmailVerified:  merchant.EmailVerifiedAt.Valid,
+		PendingEmail:   merchant.PendingEmail.String,
 		MerchantStatus: merchantStatus,
-		APIKey:         decryptedAPIKey,
+		Tier:           string(merchant.Tier),
+		Mode:           mode,
+		MaskedAPIKey:   displayAPIKey(merchant.KeyPrefix, merchant.LastFour),
 		APIKeyStatus:   apiKeyStatus,
 		CreatedAt:      createdAt,
 		APIKeyCreated:  apiKeyCreatedAt,
@@ -246,27 +298,27 @@ func (as *AccountService) GetMerchantByAPIKey(apiKey string) (*models.GetMerchan
 	as.logger.Info("Received API key for authentication", zap.String("received_key", maskAPIKey(apiKey)))
 	as.logger.Info("API key length", zap.Int("length", len(apiKey)))
 
-	hashedAPIKey := hashAPIKey(apiKey, as.hashKey)
-	authHashPreview := hashedAPIKey
-	if len(hashedAPIKey) > 50 {
-		authHashPreview = hashedAPIKey[:50] + "..."
-	}
-	as.logger.Info("Generated hash for lookup", zap.String("hashed_key_preview", authHashPreview))
-	as.logger.Info("Full hashed key length", zap.Int("hashed_length", len(hashedAPIKey)))
-
-	as.logger.Info("Executing database query with hashed key")
-	merchant, err := as.queries.GetMerchantByAPIKeyValue(context.Background(), hashedAPIKey)
-
+	merchant, err := as.findAPIKey(apiKey)
 	if err != nil {
-		as.logger.Error("Database query failed", zap.Error(err))
+		if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, errAPIKeyMismatch) {
+			as.logger.Error("Database query failed", zap.Error(err))
+			return nil, fmt.Errorf("failed to look up API key: %w", err)
+		}
 		as.logger.Warn("Invalid API key lookup failed", zap.String("api_key", maskAPIKey(apiKey)))
 		as.logger.Info("=== API KEY AUTHENTICATION FAILED ===")
-		return nil, fmt.Errorf("invalid API key")
+		return nil, ErrInvalidAPIKey
 	}
 
 	as.logger.Info("Database query successful - merchant found", zap.String("merchant_id", merchant.MerchantID))
 	as.logger.Info("=== API KEY AUTHENTICATION SUCCESS ===")
 
+	if err := as.queries.TouchMerchantAPIKey(context.Background(), merchant.ApiKeyID); err != nil {
+		as.logger.Warn("Failed to record API key usage", zap.String("api_key_id", merchant.ApiKeyID.String()), zap.Error(err))
+	}
+	if merchant.MustRotate {
+		as.logger.Warn("Legacy API key used, rotation required", zap.String("merchant_id", merchant.MerchantID), zap.String("api_key_id", merchant.ApiKeyID.String()))
+	}
+
 	merchantStatus := "unknown"
 	if merchant.Status.Valid {
 		merchantStatus = string(merchant.Status.MerchantStatus)
@@ -289,22 +341,579 @@ func (as *AccountService) GetMerchantByAPIKey(apiKey string) (*models.GetMerchan
 
 	as.logger.Info("Merchant found by API key", zap.String("merchant_id", merchant.MerchantID), zap.String("email", merchant.Email))
 
-	decryptedAPIKey, err := decryptAPIKey(merchant.SecretKey, as.hashKey)
+	return &models.GetMerchantResponse{
+		Status:           true,
+		MerchantID:       merchant.MerchantID,
+		Name:             merchant.Name,
+		Email:            merchant.Email,
+		EmailVerified:    merchant.EmailVerifiedAt.Valid,
+		PendingEmail:     merchant.PendingEmail.String,
+		MerchantStatus:   merchantStatus,
+		Tier:             string(merchant.Tier),
+		Mode:             string(merchant.Mode),
+		APIKeyID:         merchant.ApiKeyID.String(),
+		MaskedAPIKey:     maskPlainAPIKey(apiKey),
+		APIKeyStatus:     apiKeyStatus,
+		APIKeyMustRotate: merchant.MustRotate,
+		APIKeyScopes:     merchant.Scopes,
+		CreatedAt:        createdAt,
+		APIKeyCreated:    apiKeyCreatedAt,
+		Message:          "Merchant found",
+	}, nil
+}
+
+func (as *AccountService) RotateWebhookSecret(merchantID string, gracePeriod time.Duration) (*models.RotateWebhookSecretResponse, error) {
+	as.logger.Info("Rotating merchant webhook secret", zap.String("merchant_id", merchantID), zap.Duration("grace_period", gracePeriod))
+
+	webhookSecret := GenerateWebhookSecret()
+	encryptedSecret, err := as.keyring.Encrypt(webhookSecret)
 	if err != nil {
-		as.logger.Error("Failed to decrypt API key for authentication response", zap.String("merchant_id", merchant.MerchantID), zap.Error(err))
-		return nil, fmt.Errorf("failed to decrypt API key")
+		as.logger.Error("Failed to encrypt merchant webhook secret", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to encrypt webhook secret: %w", err)
 	}
 
-	return &models.GetMerchantResponse{
-		Status:         true,
-		MerchantID:     merchant.MerchantID,
-		Name:           merchant.Name,
-		Email:          merchant.Email,
-		MerchantStatus: merchantStatus,
-		APIKey:         decryptedAPIKey,
-		APIKeyStatus:   apiKeyStatus,
-		CreatedAt:      createdAt,
-		APIKeyCreated:  apiKeyCreatedAt,
-		Message:        "Merchant found",
+	// Older secrets keep signing alongside the new one until the grace period ends
+	previousExpiresAt := time.Now().Add(gracePeriod)
+
+	// The merchant row lock serializes rotations, so two concurrent ones cannot each leave the
+	// other's secret unexpired, and the new secret is never stored without the old ones expiring
+	ctx := context.Background()
+	err = as.dbManager.WithTransaction(ctx, func(tx *sql.Tx) error {
+		qtx := as.queries.WithTx(tx)
+
+		merchant, err := qtx.LockMerchantByMerchantID(ctx, merchantID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return errMerchantNotFound
+			}
+			return fmt.Errorf("failed to lock merchant: %w", err)
+		}
+
+		created, err := qtx.CreateMerchantWebhookSecret(ctx, &db.CreateMerchantWebhookSecretParams{
+			MerchantID: merchant.ID,
+			Secret:     encryptedSecret,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create webhook secret: %w", err)
+		}
+
+		if err := qtx.ExpireMerchantWebhookSecrets(ctx, &db.ExpireMerchantWebhookSecretsParams{
+			MerchantID: merchant.ID,
+			ID:         created.ID,
+			ExpiresAt:  sql.NullTime{Time: previousExpiresAt, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to expire previous webhook secrets: %w", err)
+		}
+		return nil
+	})
+	if errors.Is(err, errMerchantNotFound) {
+		as.logger.Warn("Merchant not found for webhook secret rotation", zap.String("merchant_id", merchantID))
+		return nil, fmt.Errorf("merchant not found")
+	}
+	if err != nil {
+		as.logger.Error("Failed to rotate merchant webhook secret", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, err
+	}
+
+	as.logger.Info("Merchant webhook secret rotated", zap.String("merchant_id", merchantID), zap.Time("previous_expires_at", previousExpiresAt))
+
+	return &models.RotateWebhookSecretResponse{
+		Status:                  true,
+		WebhookSecret:           webhookSecret,
+		PreviousSecretExpiresAt: &previousExpiresAt,
+		Message:                 "Webhook secret rotated successfully",
+	}, nil
+}
+
+// GetWebhookSigningSecrets returns the merchant's unexpired webhook secrets, newest first
+func (as *AccountService) GetWebhookSigningSecrets(ctx context.Context, merchantID string) ([]string, error) {
+	stored, err := as.queries.ListActiveWebhookSecretsByMerchantID(ctx, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook secrets: %w", err)
+	}
+
+	secrets := make([]string, 0, len(stored))
+	for _, secret := range stored {
+		decrypted, err := as.keyring.Decrypt(secret.Secret)
+		if err != nil {
+			as.logger.Error("Failed to decrypt webhook secret", zap.String("merchant_id", merchantID), zap.String("secret_id", secret.ID.String()), zap.Error(err))
+			continue
+		}
+		secrets = append(secrets, decrypted)
+	}
+
+	return secrets, nil
+}
+
+// CreateAPIKey issues an additional test or live API key limited to the given scopes. The plain key
+// is only returned here. Live keys require a verified email.
+func (as *AccountService) CreateAPIKey(merchantID, label, mode string, scopes []string) (*models.CreateAPIKeyResponse, error) {
+	as.logger.Info("Creating merchant API key", zap.String("merchant_id", merchantID), zap.String("label", label), zap.String("mode", mode), zap.Strings("scopes", scopes))
+
+	if !db.ApiMode(mode).Valid() {
+		return nil, ErrInvalidMode
+	}
+
+	merchant, err := as.queries.GetMerchantByMerchantID(context.Background(), merchantID)
+	if err != nil {
+		as.logger.Error("Failed to get merchant for API key creation", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("merchant not found")
+	}
+	if mode == models.ModeLive && !merchant.EmailVerifiedAt.Valid {
+		return nil, ErrEmailNotVerified
+	}
+
+	apiKey, created, err := as.issueAPIKey(as.queries, merchant.ID, label, db.ApiMode(mode), scopes)
+	if err != nil {
+		as.logger.Error("Failed to create merchant API key", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, err
+	}
+
+	as.logger.Info("Merchant API key created", zap.String("merchant_id", merchantID), zap.String("api_key_id", created.ID.String()))
+
+	return &models.CreateAPIKeyResponse{
+		Status:  true,
+		APIKey:  apiKey,
+		Key:     toAPIKey(created, time.Now()),
+		Message: "API key created successfully",
+	}, nil
+}
+
+// ListAPIKeys returns all of the merchant's keys, including revoked and expired ones, newest first
+func (as *AccountService) ListAPIKeys(merchantID string) (*models.ListAPIKeysResponse, error) {
+	merchant, err := as.queries.GetMerchantByMerchantID(context.Background(), merchantID)
+	if err != nil {
+		as.logger.Error("Failed to get merchant for API key listing", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("merchant not found")
+	}
+
+	stored, err := as.queries.ListMerchantAPIKeys(context.Background(), merchant.ID)
+	if err != nil {
+		as.logger.Error("Failed to list merchant API keys", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	now := time.Now()
+	keys := make([]models.APIKey, 0, len(stored))
+	for _, key := range stored {
+		keys = append(keys, toAPIKey(key, now))
+	}
+
+	return &models.ListAPIKeysResponse{
+		Status:  true,
+		Keys:    keys,
+		Message: "API keys retrieved successfully",
+	}, nil
+}
+
+// GetAPIKey returns one of the merchant's keys, including a revoked or expired one
+func (as *AccountService) GetAPIKey(merchantID, keyID string) (*models.APIKeyResponse, error) {
+	key, err := as.getAPIKey(merchantID, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.APIKeyResponse{
+		Status:  true,
+		Key:     toAPIKey(key, time.Now()),
+		Message: "API key retrieved successfully",
+	}, nil
+}
+
+// RevokeAPIKey stops a key from authenticating immediately. The merchant must keep another usable
+// key of the same mode that can manage keys; otherwise rotate the key instead.
+func (as *AccountService) RevokeAPIKey(merchantID, keyID string) (*models.APIKeyResponse, error) {
+	as.logger.Info("Revoking merchant API key", zap.String("merchant_id", merchantID), zap.String("api_key_id", keyID))
+
+	ctx := context.Background()
+	var key *db.MerchantApiKey
+	err := as.withUsableAPIKey(ctx, merchantID, keyID, func(qtx *db.Queries, previous *db.MerchantApiKey) error {
+		revoked, err := qtx.RevokeMerchantAPIKey(ctx, &db.RevokeMerchantAPIKeyParams{
+			ID:         previous.ID,
+			MerchantID: previous.MerchantID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to revoke API key: %w", err)
+		}
+		if revoked == 0 {
+			return ErrLastAPIKey
+		}
+
+		key, err = qtx.GetMerchantAPIKey(ctx, &db.GetMerchantAPIKeyParams{
+			ID:         previous.ID,
+			MerchantID: previous.MerchantID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get revoked API key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		as.logger.Warn("Merchant API key not revoked", zap.String("merchant_id", merchantID), zap.String("api_key_id", keyID), zap.Error(err))
+		return nil, err
+	}
+
+	as.logger.Info("Merchant API key revoked", zap.String("merchant_id", merchantID), zap.String("api_key_id", keyID))
+
+	return &models.APIKeyResponse{
+		Status:  true,
+		Key:     toAPIKey(key, time.Now()),
+		Message: "API key revoked successfully",
 	}, nil
 }
+
+// RotateAPIKey issues a replacement for a key with the same mode and scopes. The old key keeps
+// authenticating until the grace period ends so deployments can switch over without downtime.
+func (as *AccountService) RotateAPIKey(merchantID, keyID, label string, gracePeriod time.Duration) (*models.RotateAPIKeyResponse, error) {
+	as.logger.Info("Rotating merchant API key", zap.String("merchant_id", merchantID), zap.String("api_key_id", keyID), zap.Duration("grace_period", gracePeriod))
+
+	ctx := context.Background()
+	var apiKey string
+	var created, previous *db.MerchantApiKey
+	// The replacement is only stored together with the old key's shortened lifetime
+	err := as.withUsableAPIKey(ctx, merchantID, keyID, func(qtx *db.Queries, key *db.MerchantApiKey) error {
+		if label == "" {
+			label = key.Label.String
+		}
+
+		var err error
+		apiKey, created, err = as.issueAPIKey(qtx, key.MerchantID, label, key.Mode, key.Scopes)
+		if err != nil {
+			return err
+		}
+
+		_, err = qtx.ExpireMerchantAPIKey(ctx, &db.ExpireMerchantAPIKeyParams{
+			ExpiresAt:  sql.NullTime{Time: time.Now().Add(gracePeriod), Valid: true},
+			ID:         key.ID,
+			MerchantID: key.MerchantID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to expire rotated API key: %w", err)
+		}
+
+		previous, err = qtx.GetMerchantAPIKey(ctx, &db.GetMerchantAPIKeyParams{
+			ID:         key.ID,
+			MerchantID: key.MerchantID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get rotated API key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		as.logger.Warn("Merchant API key not rotated", zap.String("merchant_id", merchantID), zap.String("api_key_id", keyID), zap.Error(err))
+		return nil, err
+	}
+
+	as.logger.Info("Merchant API key rotated",
+		zap.String("merchant_id", merchantID),
+		zap.String("previous_api_key_id", keyID),
+		zap.String("api_key_id", created.ID.String()),
+		zap.Time("previous_expires_at", previous.ExpiresAt.Time))
+
+	now := time.Now()
+	return &models.RotateAPIKeyResponse{
+		Status:      true,
+		APIKey:      apiKey,
+		Key:         toAPIKey(created, now),
+		PreviousKey: toAPIKey(previous, now),
+		Message:     "API key rotated successfully",
+	}, nil
+}
+
+// UpdateProfile applies a new name right away. A new email is only recorded as pending and a
+// verification link is mailed to it; the current email stays in use until the link is followed.
+// Asking for the current email again cancels a pending change.
+func (as *AccountService) UpdateProfile(ctx context.Context, merchantID string, req models.UpdateProfileRequest) (*models.ProfileResponse, error) {
+	as.logger.Info("Updating merchant profile", zap.String("merchant_id", merchantID))
+
+	merchant, err := as.queries.GetMerchantByMerchantID(ctx, merchantID)
+	if err != nil {
+		as.logger.Error("Failed to get merchant for profile update", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("merchant not found")
+	}
+
+	if req.Name != nil && *req.Name != merchant.Name {
+		merchant, err = as.queries.UpdateMerchantName(ctx, &db.UpdateMerchantNameParams{
+			MerchantID: merchantID,
+			Name:       *req.Name,
+		})
+		if err != nil {
+			as.logger.Error("Failed to update merchant name", zap.String("merchant_id", merchantID), zap.Error(err))
+			return nil, fmt.Errorf("failed to update merchant name: %w", err)
+		}
+	}
+
+	sendVerification := false
+	if req.Email != nil {
+		pendingEmail := sql.NullString{String: *req.Email, Valid: *req.Email != merchant.Email}
+		if pendingEmail.Valid {
+			inUse, err := as.queries.MerchantEmailInUse(ctx, &db.MerchantEmailInUseParams{
+				Email:      pendingEmail.String,
+				MerchantID: merchantID,
+			})
+			if err != nil {
+				as.logger.Error("Failed to check merchant email", zap.String("merchant_id", merchantID), zap.Error(err))
+				return nil, fmt.Errorf("failed to check email: %w", err)
+			}
+			if inUse {
+				as.logger.Warn("Email change to an address in use", zap.String("merchant_id", merchantID), zap.String("email", pendingEmail.String))
+				return nil, ErrDuplicateEmail
+			}
+		}
+
+		if pendingEmail.Valid || merchant.PendingEmail.Valid {
+			merchant, err = as.queries.UpdateMerchantPendingEmail(ctx, &db.UpdateMerchantPendingEmailParams{
+				MerchantID:   merchantID,
+				PendingEmail: pendingEmail,
+			})
+			if err != nil {
+				as.logger.Error("Failed to update merchant pending email", zap.String("merchant_id", merchantID), zap.Error(err))
+				return nil, fmt.Errorf("failed to update email: %w", err)
+			}
+		}
+		sendVerification = pendingEmail.Valid
+	}
+
+	response := toProfile(merchant)
+	response.Message = "Profile updated successfully"
+	if sendVerification {
+		if err := as.sendVerificationEmail(ctx, merchant, merchant.PendingEmail.String); err != nil {
+			as.logger.Error("Failed to send verification email", zap.String("merchant_id", merchantID), zap.Error(err))
+			response.Message = "Profile updated, but the verification email could not be sent; request a new one"
+		} else {
+			response.VerificationSent = true
+			response.Message = "Profile updated; follow the link sent to the new email to confirm it"
+		}
+	}
+
+	as.logger.Info("Merchant profile updated",
+		zap.String("merchant_id", merchantID),
+		zap.Bool("email_change_pending", merchant.PendingEmail.Valid))
+
+	return response, nil
+}
+
+// SendVerificationEmail mails a new link for the pending email, or for the current one while it is
+// unverified. Links sent earlier for the same address stay valid until they expire.
+func (as *AccountService) SendVerificationEmail(ctx context.Context, merchantID string) (*models.ProfileResponse, error) {
+	merchant, err := as.queries.GetMerchantByMerchantID(ctx, merchantID)
+	if err != nil {
+		as.logger.Error("Failed to get merchant for verification email", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("merchant not found")
+	}
+
+	var email string
+	switch {
+	case merchant.PendingEmail.Valid:
+		email = merchant.PendingEmail.String
+	case !merchant.EmailVerifiedAt.Valid:
+		email = merchant.Email
+	default:
+		return nil, ErrEmailAlreadyVerified
+	}
+
+	if err := as.sendVerificationEmail(ctx, merchant, email); err != nil {
+		as.logger.Error("Failed to send verification email", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	response := toProfile(merchant)
+	response.VerificationSent = true
+	response.Message = "Verification email sent"
+	return response, nil
+}
+
+// VerifyEmail confirms the address a verification token was issued for. A token for a pending
+// address makes it the merchant's email; a token for an address the merchant has since replaced
+// or withdrawn is rejected.
+func (as *AccountService) VerifyEmail(ctx context.Context, token string) (*models.ProfileResponse, error) {
+	claims, err := parseVerificationToken(token, as.keyring, time.Now())
+	if err != nil {
+		as.logger.Warn("Rejected email verification token", zap.Error(err))
+		return nil, err
+	}
+
+	merchant, err := as.queries.ConfirmMerchantEmail(ctx, &db.ConfirmMerchantEmailParams{
+		MerchantID: claims.MerchantID,
+		Email:      claims.Email,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			as.logger.Warn("Verified email was taken by another merchant", zap.String("merchant_id", claims.MerchantID), zap.String("email", claims.Email))
+			return nil, ErrDuplicateEmail
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			as.logger.Error("Failed to confirm merchant email", zap.String("merchant_id", claims.MerchantID), zap.Error(err))
+			return nil, fmt.Errorf("failed to confirm email: %w", err)
+		}
+
+		// Following the same link twice is not an error
+		merchant, err = as.queries.GetMerchantByMerchantID(ctx, claims.MerchantID)
+		if err != nil || merchant.Email != claims.Email || !merchant.EmailVerifiedAt.Valid {
+			as.logger.Warn("Email verification token no longer matches the merchant", zap.String("merchant_id", claims.MerchantID))
+			return nil, ErrInvalidVerificationToken
+		}
+		response := toProfile(merchant)
+		response.Message = "Email already verified"
+		return response, nil
+	}
+
+	as.logger.Info("Merchant email verified", zap.String("merchant_id", merchant.MerchantID), zap.String("email", merchant.Email))
+
+	response := toProfile(merchant)
+	response.Message = "Email verified successfully"
+	return response, nil
+}
+
+// sendVerificationEmail mails a link that confirms email for the merchant
+func (as *AccountService) sendVerificationEmail(ctx context.Context, merchant *db.Merchant, email string) error {
+	token := signVerificationToken(verificationClaims{
+		MerchantID: merchant.MerchantID,
+		Email:      email,
+		ExpiresAt:  time.Now().Add(as.verification.TokenTTL).Unix(),
+	}, as.keyring)
+	link, err := verificationLink(as.verification.URL, token)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(`Hello %s,
+
+Please confirm that %s is the email address for your Cash Flow merchant account %s by opening
+this link:
+
+%s
+
+The link expires in %s. Live API keys can only be created once the address is confirmed. If you
+did not ask for this, you can ignore this email.
+`, merchant.Name, email, merchant.MerchantID, link, as.verification.TokenTTL)
+
+	return as.mailer.Send(ctx, mailmanager.Message{
+		To:      email,
+		Subject: "Confirm your Cash Flow email address",
+		Body:    body,
+	})
+}
+
+// issueAPIKey generates a key and stores its public prefix, last four characters and a salted hash
+// through q, which may be a transaction. The plain key is returned to the caller and not kept anywhere.
+func (as *AccountService) issueAPIKey(q *db.Queries, merchantID uuid.UUID, label string, mode db.ApiMode, scopes []string) (string, *db.MerchantApiKey, error) {
+	scopes = normalizeScopes(scopes)
+	if len(scopes) == 0 {
+		return "", nil, ErrInvalidScopes
+	}
+
+	apiKey, keyPrefix := generateAPIKey(mode)
+	salt := generateKeySalt()
+	created, err := q.CreateMerchantAPIKey(context.Background(), &db.CreateMerchantAPIKeyParams{
+		MerchantID: merchantID,
+		KeyPrefix:  sql.NullString{String: keyPrefix, Valid: true},
+		KeyHash:    hashAPIKey(apiKey, salt, as.keyring),
+		KeySalt:    salt,
+		LastFour:   apiKey[len(apiKey)-4:],
+		Label:      sql.NullString{String: label, Valid: label != ""},
+		Scopes:     scopes,
+		Mode:       mode,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+	return apiKey, created, nil
+}
+
+// findAPIKey looks a key up by its public prefix and verifies it against the stored hash. Legacy
+// keys have no prefix and are found by their unsalted hash instead.
+func (as *AccountService) findAPIKey(apiKey string) (*db.GetMerchantByAPIKeyPrefixRow, error) {
+	keyPrefix, ok := splitAPIKey(apiKey)
+	if !ok {
+		legacy, err := as.queries.GetMerchantByLegacyAPIKey(context.Background(), hashAPIKey(apiKey, "", as.keyring))
+		if err != nil {
+			return nil, err
+		}
+		merchant := db.GetMerchantByAPIKeyPrefixRow(*legacy)
+		return &merchant, nil
+	}
+
+	merchant, err := as.queries.GetMerchantByAPIKeyPrefix(context.Background(), sql.NullString{String: keyPrefix, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	if !verifyAPIKey(apiKey, merchant.KeySalt, merchant.KeyHash, as.keyring) {
+		return nil, errAPIKeyMismatch
+	}
+	return merchant, nil
+}
+
+// getAPIKey loads one of the merchant's keys whatever its status
+func (as *AccountService) getAPIKey(merchantID, keyID string) (*db.MerchantApiKey, error) {
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	merchant, err := as.queries.GetMerchantByMerchantID(context.Background(), merchantID)
+	if err != nil {
+		as.logger.Error("Failed to get merchant for API key lookup", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("merchant not found")
+	}
+
+	key, err := as.queries.GetMerchantAPIKey(context.Background(), &db.GetMerchantAPIKeyParams{
+		ID:         id,
+		MerchantID: merchant.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		as.logger.Error("Failed to get merchant API key", zap.String("api_key_id", keyID), zap.Error(err))
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return key, nil
+}
+
+// withUsableAPIKey runs fn in a transaction holding the merchant's row lock with one of the
+// merchant's keys that is neither revoked nor expired. The lock serializes revocations and
+// rotations, so two concurrent revocations cannot each count the other's key as the one left.
+func (as *AccountService) withUsableAPIKey(ctx context.Context, merchantID, keyID string, fn func(qtx *db.Queries, key *db.MerchantApiKey) error) error {
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return ErrAPIKeyNotFound
+	}
+
+	err = as.dbManager.WithTransaction(ctx, func(tx *sql.Tx) error {
+		qtx := as.queries.WithTx(tx)
+
+		merchant, err := qtx.LockMerchantByMerchantID(ctx, merchantID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return errMerchantNotFound
+			}
+			return fmt.Errorf("failed to lock merchant: %w", err)
+		}
+
+		key, err := qtx.GetMerchantAPIKey(ctx, &db.GetMerchantAPIKeyParams{
+			ID:         id,
+			MerchantID: merchant.ID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrAPIKeyNotFound
+			}
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+		if apiKeyStatus(key, time.Now()) != apiKeyStatusActive {
+			return ErrAPIKeyNotActive
+		}
+
+		return fn(qtx, key)
+	})
+
+	// Handlers show these errors to the caller, so they are returned without the transaction's wrapping
+	for _, sentinel := range []error{ErrAPIKeyNotFound, ErrAPIKeyNotActive, ErrLastAPIKey, ErrInvalidScopes} {
+		if errors.Is(err, sentinel) {
+			return sentinel
+		}
+	}
+	return err
+}
@@ -1,16 +1,155 @@
 package accountservice
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/keymanager"
+	"cash-flow-financial/internal/models"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
 )
 
+const (
+	apiKeyPrefix       = "api_"
+	apiKeyPublicLength = 12
+	apiKeySecretLength = 32
+)
+
+const (
+	apiKeyStatusActive  = "active"
+	apiKeyStatusExpired = "expired"
+	apiKeyStatusRevoked = "revoked"
+)
+
+// apiKeyStatus reports whether a key still authenticates. A rotated key stays active until its
+// expires_at passes.
+func apiKeyStatus(key *db.MerchantApiKey, now time.Time) string {
+	switch {
+	case key.RevokedAt.Valid || key.Status.ApiKeyStatus == db.ApiKeyStatusInactive:
+		return apiKeyStatusRevoked
+	case key.Status.ApiKeyStatus == db.ApiKeyStatusExpired:
+		return apiKeyStatusExpired
+	case key.ExpiresAt.Valid && !key.ExpiresAt.Time.After(now):
+		return apiKeyStatusExpired
+	}
+	return apiKeyStatusActive
+}
+
+func toAPIKey(key *db.MerchantApiKey, now time.Time) models.APIKey {
+	response := models.APIKey{
+		ID:         key.ID.String(),
+		Label:      key.Label.String,
+		MaskedKey:  displayAPIKey(key.KeyPrefix, key.LastFour),
+		Status:     apiKeyStatus(key, now),
+		Mode:       string(key.Mode),
+		MustRotate: key.MustRotate,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt.Time,
+	}
+	if key.LastUsedAt.Valid {
+		response.LastUsedAt = &key.LastUsedAt.Time
+	}
+	if key.ExpiresAt.Valid {
+		response.ExpiresAt = &key.ExpiresAt.Time
+	}
+	if key.RevokedAt.Valid {
+		response.RevokedAt = &key.RevokedAt.Time
+	}
+	return response
+}
+
+// normalizeScopes drops unknown and repeated scopes and returns the rest in the order of
+// models.APIKeyScopes
+func normalizeScopes(scopes []string) []string {
+	normalized := []string{}
+	for _, scope := range models.APIKeyScopes {
+		if slices.Contains(scopes, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized
+}
+
+func toProfile(merchant *db.Merchant) *models.ProfileResponse {
+	return &models.ProfileResponse{
+		Status:        true,
+		MerchantID:    merchant.MerchantID,
+		Name:          merchant.Name,
+		Email:         merchant.Email,
+		EmailVerified: merchant.EmailVerifiedAt.Valid,
+		PendingEmail:  merchant.PendingEmail.String,
+	}
+}
+
+// verificationClaims is what an email verification token vouches for: that the merchant received
+// mail at Email before ExpiresAt
+type verificationClaims struct {
+	MerchantID string `json:"merchant_id"`
+	Email      string `json:"email"`
+	ExpiresAt  int64  `json:"exp"`
+}
+
+// signVerificationToken returns the claims and their HMAC-SHA256, each base64url encoded and joined
+// by a dot. The key is derived from API_KEY_HASH_KEY so a token can never double as an API key hash.
+func signVerificationToken(claims verificationClaims, keyring keymanager.IKeyring) string {
+	payload, _ := json.Marshal(claims)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(verificationSignature(encoded, keyring))
+}
+
+// parseVerificationToken checks the signature before looking at the claims, then their expiry
+func parseVerificationToken(token string, keyring keymanager.IKeyring, now time.Time) (*verificationClaims, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidVerificationToken
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decodedSignature, verificationSignature(encoded, keyring)) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	var claims verificationClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.MerchantID == "" || claims.Email == "" {
+		return nil, ErrInvalidVerificationToken
+	}
+	if !now.Before(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, ErrVerificationTokenExpired
+	}
+	return &claims, nil
+}
+
+func verificationSignature(encodedClaims string, keyring keymanager.IKeyring) []byte {
+	h := hmac.New(sha256.New, keyring.DeriveKey("email-verification"))
+	h.Write([]byte(encodedClaims))
+	return h.Sum(nil)
+}
+
+// verificationLink adds the token to the configured verification URL, keeping any query it has
+func verificationLink(baseURL, token string) (string, error) {
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid verification URL: %w", err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
 func maskAPIKey(apiKey string) string {
 	if len(apiKey) <= 10 {
 		return apiKey
@@ -18,14 +157,47 @@ func maskAPIKey(apiKey string) string {
 	return apiKey[:10] + "..."
 }
 
-func generateAPIKey() string {
+// generateAPIKey returns a key of the form api_<mode>_<public>_<secret> along with its public
+// prefix, which is stored in the clear for lookups. The mode in the key is only there to tell test
+// and live keys apart at a glance; the stored mode is what the API enforces.
+func generateAPIKey(mode db.ApiMode) (apiKey, keyPrefix string) {
+	keyPrefix = apiKeyPrefix + string(mode) + "_" + randomAlphanumeric(apiKeyPublicLength)
+	return keyPrefix + "_" + randomAlphanumeric(apiKeySecretLength), keyPrefix
+}
+
+// splitAPIKey returns a key's public prefix. Legacy keys (api_ followed by 32 characters) have none.
+func splitAPIKey(apiKey string) (string, bool) {
+	i := strings.LastIndex(apiKey, "_")
+	if !strings.HasPrefix(apiKey, apiKeyPrefix) || i < len(apiKeyPrefix) || i == len(apiKey)-1 {
+		return "", false
+	}
+	return apiKey[:i], true
+}
+
+// displayAPIKey is how a stored key is shown once its plain value is gone
+func displayAPIKey(keyPrefix sql.NullString, lastFour string) string {
+	if !keyPrefix.Valid {
+		return apiKeyPrefix + "..."
+	}
+	return keyPrefix.String + "_..." + lastFour
+}
+
+func maskPlainAPIKey(apiKey string) string {
+	keyPrefix, ok := splitAPIKey(apiKey)
+	if !ok || len(apiKey) < 4 {
+		return apiKeyPrefix + "..."
+	}
+	return keyPrefix + "_..." + apiKey[len(apiKey)-4:]
+}
+
+func randomAlphanumeric(n int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, 32)
+	b := make([]byte, n)
 	for i := range b {
 		num, _ := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
 		b[i] = charset[num.Int64()]
 	}
-	return "api_" + string(b)
+	return string(b)
 }
 
 func generateMerchantID() string {
@@ -38,7 +210,8 @@ func generateMerchantID() string {
 	return "CASM-" + string(b)
 }
 
-func generateSecretKey() string {
+// GenerateWebhookSecret returns a new random callback signing secret
+func GenerateWebhookSecret() string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	b := make([]byte, 64)
 	for i := range b {
@@ -48,59 +221,20 @@ func generateSecretKey() string {
 	return "sk_" + string(b)
 }
 
-func hashAPIKey(apiKey, hashKey string) string {
-	h := hmac.New(sha256.New, []byte(hashKey))
-	h.Write([]byte(apiKey))
-	hashed := h.Sum(nil)
-	return base64.StdEncoding.EncodeToString(hashed)
-}
-
-func encryptAPIKey(apiKey, hashKey string) string {
-	block, err := aes.NewCipher([]byte(hashKey)[:32])
-	if err != nil {
-		panic(err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
+func generateKeySalt() string {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
 		panic(err)
 	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := rand.Read(nonce); err != nil {
-		panic(err)
-	}
-
-	ciphertext := gcm.Seal(nonce, nonce, []byte(apiKey), nil)
-	return base64.StdEncoding.EncodeToString(ciphertext)
+	return hex.EncodeToString(salt)
 }
 
-func decryptAPIKey(encryptedKey, hashKey string) (string, error) {
-	block, err := aes.NewCipher([]byte(hashKey)[:32])
-	if err != nil {
-		return "", err
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-
-	ciphertext, err := base64.StdEncoding.DecodeString(encryptedKey)
-	if err != nil {
-		return "", err
-	}
-
-	nonceSize := gcm.NonceSize()
-	if len(ciphertext) < nonceSize {
-		return "", fmt.Errorf("ciphertext too short")
-	}
-
-	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return "", err
-	}
+// hashAPIKey is keyed with API_KEY_HASH_KEY, so a leaked table alone cannot be used to test
+// guesses. Legacy keys were hashed the same way with an empty salt.
+func hashAPIKey(apiKey, salt string, keyring keymanager.IKeyring) string {
+	return base64.StdEncoding.EncodeToString(keyring.HMAC([]byte(salt), []byte(apiKey)))
+}
 
-	return string(plaintext), nil
+func verifyAPIKey(apiKey, salt, keyHash string, keyring keymanager.IKeyring) bool {
+	return hmac.Equal([]byte(hashAPIKey(apiKey, salt, keyring)), []byte(keyHash))
 }
@@ -0,0 +1,123 @@
+package accountservice
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/keymanager"
+	"cash-flow-financial/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyring(t *testing.T, hashKey string) keymanager.IKeyring {
+	t.Helper()
+	keyring, err := keymanager.NewKeyring(&models.KeyringConfig{
+		HashKey:        hashKey,
+		EncryptionKeys: []models.EncryptionKey{{ID: "v1", Key: []byte(strings.Repeat("k", keymanager.EncryptionKeyLength))}},
+	})
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	for _, mode := range db.AllApiModeValues() {
+		apiKey, keyPrefix := generateAPIKey(mode)
+
+		assert.True(t, strings.HasPrefix(keyPrefix, apiKeyPrefix+string(mode)+"_"))
+		assert.True(t, strings.HasPrefix(apiKey, keyPrefix+"_"))
+		assert.Len(t, keyPrefix, len(apiKeyPrefix)+len(mode)+1+apiKeyPublicLength)
+		assert.LessOrEqual(t, len(keyPrefix), 32) // merchant_api_keys.key_prefix
+		assert.Len(t, apiKey, len(keyPrefix)+1+apiKeySecretLength)
+
+		split, ok := splitAPIKey(apiKey)
+		require.True(t, ok)
+		assert.Equal(t, keyPrefix, split)
+	}
+}
+
+func TestSplitAPIKey_Legacy(t *testing.T) {
+	for _, apiKey := range []string{"api_AbCdEfGhIjKlMnOpQrStUvWxYz012345", "api_AbCd_", "sk_AbCd_1234", ""} {
+		_, ok := splitAPIKey(apiKey)
+		assert.False(t, ok, apiKey)
+	}
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	apiKey, _ := generateAPIKey(db.ApiModeLive)
+	salt := generateKeySalt()
+	keyring := newTestKeyring(t, "test_hash_key_0123456789abcdefghij")
+	keyHash := hashAPIKey(apiKey, salt, keyring)
+
+	assert.True(t, verifyAPIKey(apiKey, salt, keyHash, keyring))
+	assert.False(t, verifyAPIKey(apiKey+"x", salt, keyHash, keyring))
+	assert.False(t, verifyAPIKey(apiKey, generateKeySalt(), keyHash, keyring))
+	assert.False(t, verifyAPIKey(apiKey, salt, keyHash, newTestKeyring(t, "other_hash_key_0123456789abcdefghij")))
+}
+
+// Hashes stored before the keyring existed must keep verifying
+func TestHashAPIKey_MatchesStoredHashes(t *testing.T) {
+	hashKey := "test_hash_key_0123456789abcdefghij"
+	h := hmac.New(sha256.New, []byte(hashKey))
+	h.Write([]byte("salt"))
+	h.Write([]byte("api_live_AbCd1234EfGh_secret"))
+
+	assert.Equal(t, base64.StdEncoding.EncodeToString(h.Sum(nil)), hashAPIKey("api_live_AbCd1234EfGh_secret", "salt", newTestKeyring(t, hashKey)))
+}
+
+func TestDisplayAPIKey(t *testing.T) {
+	assert.Equal(t, "api_AbCd1234EfGh_...wxyz", displayAPIKey(sql.NullString{String: "api_AbCd1234EfGh", Valid: true}, "wxyz"))
+	assert.Equal(t, "api_...", displayAPIKey(sql.NullString{}, ""))
+	assert.Equal(t, "api_AbCd1234EfGh_...wxyz", maskPlainAPIKey("api_AbCd1234EfGh_0123456789abcdefghijklmnopqrwxyz"))
+	assert.Equal(t, "api_test_AbCd1234EfGh_...wxyz", maskPlainAPIKey("api_test_AbCd1234EfGh_0123456789abcdefghijklmnopqrwxyz"))
+}
+
+func TestNormalizeScopes(t *testing.T) {
+	scopes := normalizeScopes([]string{models.ScopeKeysRead, "admin:all", models.ScopeIntentsWrite, models.ScopeKeysRead})
+	assert.Equal(t, []string{models.ScopeIntentsWrite, models.ScopeKeysRead}, scopes)
+
+	assert.Empty(t, normalizeScopes(nil))
+	assert.Equal(t, models.APIKeyScopes, normalizeScopes(models.APIKeyScopes))
+}
+
+func TestVerificationToken(t *testing.T) {
+	now := time.Now()
+	claims := verificationClaims{MerchantID: "CASM-ABC123", Email: "billing@example.com", ExpiresAt: now.Add(time.Hour).Unix()}
+	keyring := newTestKeyring(t, "test_hash_key_0123456789abcdefghij")
+	token := signVerificationToken(claims, keyring)
+
+	parsed, err := parseVerificationToken(token, keyring, now)
+	require.NoError(t, err)
+	assert.Equal(t, claims, *parsed)
+
+	_, err = parseVerificationToken(token, keyring, now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, ErrVerificationTokenExpired)
+
+	_, err = parseVerificationToken(token, newTestKeyring(t, "other_hash_key_0123456789abcdefghij"), now)
+	assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+
+	// Claims for another address cannot reuse the signature
+	forged := signVerificationToken(verificationClaims{MerchantID: "CASM-ABC123", Email: "attacker@example.com", ExpiresAt: claims.ExpiresAt}, keyring)
+	payload, _, _ := strings.Cut(forged, ".")
+	_, signature, _ := strings.Cut(token, ".")
+	_, err = parseVerificationToken(payload+"."+signature, keyring, now)
+	assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+
+	for _, malformed := range []string{"", "no-dot", "a.b", token + "x"} {
+		_, err = parseVerificationToken(malformed, keyring, now)
+		assert.ErrorIs(t, err, ErrInvalidVerificationToken, malformed)
+	}
+}
+
+func TestVerificationLink(t *testing.T) {
+	link, err := verificationLink("https://dashboard.example.com/verify?lang=en", "abc.def")
+	require.NoError(t, err)
+	assert.Equal(t, "https://dashboard.example.com/verify?lang=en&token=abc.def", link)
+}
@@ -1,11 +1,29 @@
 package callback
 
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrUnexpectedStatus marks a callback the merchant answered with a non-2xx status
+	ErrUnexpectedStatus = errors.New("callback endpoint returned non-2xx status")
+	// ErrCallbackRejected marks a 2xx callback whose acknowledgement body says {"status": false}
+	ErrCallbackRejected = errors.New("merchant rejected the callback")
+	// ErrCallbackUnsigned marks a callback that was not sent because it could not be signed, for
+	// example because the merchant has no active webhook secret
+	ErrCallbackUnsigned = errors.New("callback could not be signed")
+)
+
 type CallbackRequest struct {
 	PaymentIntentID    string                 `json:"payment_intent_id"`
 	MerchantID         string                 `json:"merchant_id"`
+	Mode               string                 `json:"mode"`
 	Amount             float64                `json:"amount"`
 	Currency           string                 `json:"currency"`
 	Status             string                 `json:"status"`
+	FailureReason      string                 `json:"failure_reason,omitempty"`
 	AccountNumber      string                 `json:"account_number,omitempty"`
 	PaymentMethod      string                 `json:"payment_method,omitempty"`
 	ThirdPartyReference string                `json:"third_party_reference,omitempty"`
@@ -15,11 +33,27 @@ type CallbackRequest struct {
 	Metadata           map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// CallbackResponse is the acknowledgement a merchant returns in the body of a 2xx response
 type CallbackResponse struct {
 	Status  bool   `json:"status"`
 	Message string `json:"message,omitempty"`
 }
 
+// CallbackResult describes a single delivery attempt. Err is nil only for a 2xx response.
+type CallbackResult struct {
+	StatusCode   int
+	Latency      time.Duration
+	ResponseBody string            // At most responseExcerptLimit bytes
+	Ack          *CallbackResponse // Nil unless a 2xx body was an acknowledgement
+	Err          error
+}
+
 type ICallbackService interface {
-	SendCallback(callbackURL string, request CallbackRequest) error
+	// SendCallback makes one signed POST of body to callbackURL; retries are the dispatcher's job
+	SendCallback(ctx context.Context, merchantID, callbackURL string, body []byte) *CallbackResult
+}
+
+// SigningSecretProvider supplies the secrets a merchant's callbacks are signed with, newest first
+type SigningSecretProvider interface {
+	GetWebhookSigningSecrets(ctx context.Context, merchantID string) ([]string, error)
 }
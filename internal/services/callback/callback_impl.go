@@ -2,78 +2,153 @@ package callback
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
 	"cash-flow-financial/internal/managers/loggermanager"
 	"cash-flow-financial/internal/models"
+	"cash-flow-financial/pkg/webhook"
 
 	"go.uber.org/zap"
 )
 
+const (
+	responseExcerptLimit = 1024
+	// responseBodyLimit bounds how much of a response is read, both to find an acknowledgement and
+	// to drain the connection for reuse
+	responseBodyLimit = 64 * 1024
+)
+
 type CallbackService struct {
-	logger *loggermanager.Logger
-	config *models.Config
-	client *http.Client
+	logger  *loggermanager.Logger
+	config  *models.Config
+	client  *http.Client
+	guard   *Guard
+	secrets SigningSecretProvider
 }
 
-func NewCallbackService(logger *loggermanager.Logger, config *models.Config) ICallbackService {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+func NewCallbackService(logger *loggermanager.Logger, config *models.Config, secrets SigningSecretProvider) ICallbackService {
+	guard := NewGuard(config)
 
 	return &CallbackService{
-		logger: logger,
-		config: config,
-		client: client,
+		logger:  logger,
+		config:  config,
+		client:  guard.Client(config.Webhook.CallbackTimeout),
+		guard:   guard,
+		secrets: secrets,
 	}
 }
 
-func (cs *CallbackService) SendCallback(callbackURL string, request CallbackRequest) error {
+func (cs *CallbackService) SendCallback(ctx context.Context, merchantID, callbackURL string, body []byte) *CallbackResult {
 	cs.logger.Info("Sending callback to merchant",
 		zap.String("callback_url", callbackURL),
-		zap.String("payment_intent_id", request.PaymentIntentID),
-		zap.String("merchant_id", request.MerchantID),
-		zap.String("status", request.Status))
+		zap.String("merchant_id", merchantID))
 
-	requestBody, err := json.Marshal(request)
-	if err != nil {
-		cs.logger.Error("Failed to marshal callback request", zap.Error(err))
-		return fmt.Errorf("failed to marshal callback request: %w", err)
+	if err := cs.guard.CheckURL(callbackURL); err != nil {
+		cs.logger.Warn("Callback destination blocked",
+			zap.String("callback_url", callbackURL),
+			zap.String("merchant_id", merchantID),
+			zap.Error(err))
+		return &CallbackResult{Err: err}
 	}
 
-	httpReq, err := http.NewRequest("POST", callbackURL, bytes.NewBuffer(requestBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", callbackURL, bytes.NewReader(body))
 	if err != nil {
 		cs.logger.Error("Failed to create callback HTTP request", zap.Error(err))
-		return fmt.Errorf("failed to create callback HTTP request: %w", err)
+		return &CallbackResult{Err: fmt.Errorf("failed to create callback HTTP request: %w", err)}
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("User-Agent", "CashFlow-Financial/1.0")
 
+	signature, err := cs.sign(ctx, merchantID, body)
+	if err != nil {
+		cs.logger.Error("Failed to sign callback request", zap.String("merchant_id", merchantID), zap.Error(err))
+		return &CallbackResult{Err: err}
+	}
+	httpReq.Header.Set(webhook.SignatureHeader, signature)
+
+	started := time.Now()
 	resp, err := cs.client.Do(httpReq)
 	if err != nil {
-		cs.logger.Error("Failed to send callback HTTP request",
+		cs.logger.Warn("Failed to send callback HTTP request",
 			zap.String("callback_url", callbackURL),
 			zap.Error(err))
-		return fmt.Errorf("failed to send callback HTTP request: %w", err)
+		return &CallbackResult{Latency: time.Since(started), Err: fmt.Errorf("failed to send callback HTTP request: %w", err)}
 	}
 	defer resp.Body.Close()
 
+	// The acknowledgement is decoded from the whole bounded body; only the excerpt is kept for display
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, responseBodyLimit))
+	excerpt := respBody
+	if len(excerpt) > responseExcerptLimit {
+		excerpt = excerpt[:responseExcerptLimit]
+	}
+
+	result := &CallbackResult{
+		StatusCode:   resp.StatusCode,
+		Latency:      time.Since(started),
+		ResponseBody: string(excerpt),
+	}
+
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		cs.logger.Warn("Callback request failed with non-2xx status",
 			zap.String("callback_url", callbackURL),
 			zap.Int("status_code", resp.StatusCode),
-			zap.String("payment_intent_id", request.PaymentIntentID))
-		return nil
+			zap.String("merchant_id", merchantID))
+		result.Err = fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+		return result
+	}
+
+	result.Ack = parseAcknowledgement(respBody)
+	if result.Ack != nil && !result.Ack.Status {
+		cs.logger.Warn("Merchant rejected callback",
+			zap.String("callback_url", callbackURL),
+			zap.String("merchant_id", merchantID),
+			zap.String("message", result.Ack.Message))
+		result.Err = ErrCallbackRejected
+		if result.Ack.Message != "" {
+			result.Err = fmt.Errorf("%w: %s", ErrCallbackRejected, result.Ack.Message)
+		}
+		return result
 	}
 
 	cs.logger.Info("Callback sent successfully",
 		zap.String("callback_url", callbackURL),
 		zap.Int("status_code", resp.StatusCode),
-		zap.String("payment_intent_id", request.PaymentIntentID))
+		zap.Duration("latency", result.Latency))
+
+	return result
+}
+
+// parseAcknowledgement reads a CallbackResponse from a 2xx body. Only a JSON object with a boolean
+// status counts; empty, non-JSON or truncated bodies are not acknowledgements.
+func parseAcknowledgement(body []byte) *CallbackResponse {
+	var ack struct {
+		Status  *bool  `json:"status"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &ack); err != nil || ack.Status == nil {
+		return nil
+	}
+	return &CallbackResponse{Status: *ack.Status, Message: ack.Message}
+}
+
+// sign builds the signature header over the exact bytes sent. During a rotation grace period the
+// body is signed with every active secret so receivers holding either one can verify it. A callback
+// is never sent unsigned, since receivers could not tell it from a forged one.
+func (cs *CallbackService) sign(ctx context.Context, merchantID string, body []byte) (string, error) {
+	secrets, err := cs.secrets.GetWebhookSigningSecrets(ctx, merchantID)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrCallbackUnsigned, err)
+	}
+	if len(secrets) == 0 {
+		return "", fmt.Errorf("%w: merchant has no active webhook secret", ErrCallbackUnsigned)
+	}
 
-	return nil
+	return webhook.Header(body, secrets, time.Now()), nil
 }
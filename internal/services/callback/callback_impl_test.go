@@ -0,0 +1,88 @@
+package callback
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/pkg/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSecrets []string
+
+func (s staticSecrets) GetWebhookSigningSecrets(ctx context.Context, merchantID string) ([]string, error) {
+	return s, nil
+}
+
+func newTestCallbackService(secrets SigningSecretProvider) *CallbackService {
+	config := &models.Config{
+		App:     models.AppConfig{Env: models.AppEnvDevelopment},
+		Webhook: models.WebhookConfig{AllowedHosts: []string{"127.0.0.1"}, CallbackTimeout: 5 * time.Second},
+	}
+	return NewCallbackService(loggermanager.NewLogger("error"), config, secrets).(*CallbackService)
+}
+
+func TestSendCallback_Signing(t *testing.T) {
+	body := []byte(`{"payment_intent_id": "pi_123"}`)
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatures = append(signatures, r.Header.Get(webhook.SignatureHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	result := newTestCallbackService(staticSecrets{"whsec_test"}).SendCallback(context.Background(), "CASM-ABC123", server.URL, body)
+	require.NoError(t, result.Err)
+	require.Len(t, signatures, 1)
+	assert.NoError(t, webhook.Verify(body, signatures[0], "whsec_test", time.Minute))
+
+	// Without a secret the callback is not sent at all rather than sent unsigned
+	result = newTestCallbackService(staticSecrets{}).SendCallback(context.Background(), "CASM-ABC123", server.URL, body)
+	assert.ErrorIs(t, result.Err, ErrCallbackUnsigned)
+	assert.Len(t, signatures, 1)
+}
+
+func TestSendCallback_AcknowledgementBeyondExcerpt(t *testing.T) {
+	message := strings.Repeat("x", 2*responseExcerptLimit)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"message": %q, "status": false}`, message)
+	}))
+	defer server.Close()
+
+	result := newTestCallbackService(staticSecrets{"whsec_test"}).SendCallback(context.Background(), "CASM-ABC123", server.URL, []byte(`{}`))
+	assert.ErrorIs(t, result.Err, ErrCallbackRejected)
+	if assert.NotNil(t, result.Ack) {
+		assert.False(t, result.Ack.Status)
+		assert.Equal(t, message, result.Ack.Message)
+	}
+	assert.Len(t, result.ResponseBody, responseExcerptLimit)
+}
+
+func TestParseAcknowledgement(t *testing.T) {
+	ack := parseAcknowledgement([]byte(`{"status": true, "message": "Order 123 marked as paid"}`))
+	if assert.NotNil(t, ack) {
+		assert.True(t, ack.Status)
+		assert.Equal(t, "Order 123 marked as paid", ack.Message)
+	}
+
+	ack = parseAcknowledgement([]byte(`{"status": false, "message": "Order not found"}`))
+	if assert.NotNil(t, ack) {
+		assert.False(t, ack.Status)
+		assert.Equal(t, "Order not found", ack.Message)
+	}
+
+	notAcks := []string{``, `OK`, `{"received": true}`, `{"status": "ok"}`, `{"status": true, "message": "trunc`}
+	for _, body := range notAcks {
+		assert.Nil(t, parseAcknowledgement([]byte(body)), body)
+	}
+}
@@ -0,0 +1,187 @@
+package callback
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/mailmanager"
+
+	"go.uber.org/zap"
+)
+
+// While another dispatcher holds an endpoint's half-open probe, its other deliveries wait this long
+const halfOpenDeferral = 15 * time.Second
+
+// admit applies the endpoint's circuit breaker to a claimed delivery. Deliveries for an open circuit
+// are not claimed at all; once the cooldown has passed the circuit is half-open and exactly one
+// delivery is let through as a probe. Deliveries to a callback_url override have no breaker.
+func (d *Dispatcher) admit(ctx context.Context, delivery *db.WebhookDelivery) (bool, error) {
+	if !delivery.EndpointID.Valid {
+		return true, nil
+	}
+
+	openedAt, err := d.queries.GetWebhookEndpointCircuit(ctx, delivery.EndpointID.UUID)
+	if err != nil {
+		// A deleted endpoint's queued deliveries are still attempted
+		if errors.Is(err, sql.ErrNoRows) {
+			return true, nil
+		}
+		return false, err
+	}
+	if !openedAt.Valid {
+		return true, nil
+	}
+
+	won, err := d.queries.AcquireWebhookEndpointProbe(ctx, &db.AcquireWebhookEndpointProbeParams{
+		ID:              delivery.EndpointID.UUID,
+		CooldownSeconds: int32(d.config.CircuitCooldown.Seconds()),
+	})
+	if err != nil {
+		return false, err
+	}
+	if won == 0 {
+		return false, nil
+	}
+
+	d.logger.Info("Probing webhook endpoint with half-open circuit",
+		zap.String("endpoint_id", delivery.EndpointID.UUID.String()),
+		zap.String("delivery_id", delivery.ID.String()))
+	return true, nil
+}
+
+// countsAgainstEndpoint reports whether an attempt shows the endpoint is unhealthy. A rejection is
+// the merchant's application answering, so it proves the endpoint is reachable.
+func countsAgainstEndpoint(result *CallbackResult) bool {
+	return result.Err != nil && !errors.Is(result.Err, ErrCallbackRejected)
+}
+
+// shouldDisable reports whether an endpoint has been failing for long enough to be switched off
+func shouldDisable(endpoint *db.WebhookEndpoint, now time.Time, threshold int, disableAfter time.Duration) bool {
+	return endpoint.Enabled &&
+		int(endpoint.ConsecutiveFailures) >= threshold &&
+		endpoint.FailingSince.Valid &&
+		now.Sub(endpoint.FailingSince.Time) >= disableAfter
+}
+
+// recordEndpointOutcome updates the endpoint's failure streak inside the attempt's transaction. An
+// endpoint that has failed persistently is disabled and its other pending deliveries are exhausted;
+// the disabled endpoint is returned so the merchant can be notified once the transaction commits.
+func (d *Dispatcher) recordEndpointOutcome(ctx context.Context, qtx *db.Queries, delivery *db.WebhookDelivery, result *CallbackResult) (*db.WebhookEndpoint, error) {
+	if !delivery.EndpointID.Valid {
+		return nil, nil
+	}
+	endpointID := delivery.EndpointID.UUID
+
+	// An unsigned callback was never sent, so it says nothing about the endpoint
+	if errors.Is(result.Err, ErrCallbackUnsigned) {
+		return nil, nil
+	}
+
+	if !countsAgainstEndpoint(result) {
+		return nil, qtx.RecordWebhookEndpointSuccess(ctx, endpointID)
+	}
+
+	endpoint, err := qtx.RecordWebhookEndpointFailure(ctx, &db.RecordWebhookEndpointFailureParams{
+		FailureThreshold: int32(d.config.CircuitFailureThreshold),
+		ID:               endpointID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to record endpoint failure: %w", err)
+	}
+
+	if int(endpoint.ConsecutiveFailures) == d.config.CircuitFailureThreshold {
+		d.logger.Warn("Webhook endpoint circuit opened",
+			zap.String("endpoint_id", endpointID.String()),
+			zap.String("merchant_id", endpoint.MerchantID),
+			zap.Int32("consecutive_failures", endpoint.ConsecutiveFailures),
+			zap.Duration("cooldown", d.config.CircuitCooldown))
+	}
+
+	if !shouldDisable(endpoint, time.Now(), d.config.CircuitFailureThreshold, d.config.EndpointDisableAfter) {
+		return nil, nil
+	}
+
+	reason := fmt.Sprintf("%d consecutive failed deliveries since %s; last error: %v",
+		endpoint.ConsecutiveFailures, endpoint.FailingSince.Time.UTC().Format(time.RFC3339), result.Err)
+	disabled, err := qtx.DisableWebhookEndpoint(ctx, &db.DisableWebhookEndpointParams{
+		ID:             endpointID,
+		DisabledReason: sql.NullString{String: reason, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to disable endpoint: %w", err)
+	}
+	if disabled == 0 {
+		return nil, nil
+	}
+
+	lastError := "endpoint disabled after persistent failures"
+	intentIDs, err := qtx.ExhaustWebhookEndpointDeliveries(ctx, &db.ExhaustWebhookEndpointDeliveriesParams{
+		LastError:  sql.NullString{String: lastError, Valid: true},
+		EndpointID: delivery.EndpointID,
+		ExceptID:   delivery.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to exhaust endpoint deliveries: %w", err)
+	}
+	for _, intentID := range intentIDs {
+		err := qtx.UpdatePaymentIntentCallbackAck(ctx, &db.UpdatePaymentIntentCallbackAckParams{
+			CallbackAckStatus:  db.CallbackAckStatusFailed,
+			CallbackAckMessage: sql.NullString{String: lastError, Valid: true},
+			PaymentIntentID:    intentID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update payment intent acknowledgement: %w", err)
+		}
+	}
+
+	d.logger.Warn("Webhook endpoint disabled",
+		zap.String("endpoint_id", endpointID.String()),
+		zap.String("merchant_id", endpoint.MerchantID),
+		zap.String("reason", reason),
+		zap.Int("deliveries_exhausted", len(intentIDs)+1))
+
+	endpoint.Enabled = false
+	endpoint.DisabledReason = sql.NullString{String: reason, Valid: true}
+	return endpoint, nil
+}
+
+// notifyDisabled emails the merchant about an endpoint that was switched off. Failures are only
+// logged: the endpoint stays disabled either way and shows its reason in the API.
+func (d *Dispatcher) notifyDisabled(ctx context.Context, endpoint *db.WebhookEndpoint) {
+	merchant, err := d.queries.GetMerchantByMerchantID(ctx, endpoint.MerchantID)
+	if err != nil {
+		d.logger.Error("Failed to look up merchant for endpoint notification",
+			zap.String("merchant_id", endpoint.MerchantID),
+			zap.Error(err))
+		return
+	}
+
+	body := fmt.Sprintf(`Hello %s,
+
+We disabled your webhook endpoint %s (%s) because it kept failing:
+
+%s
+
+Pending callbacks for this endpoint were stopped and can be resent from the delivery log. Once the
+endpoint is working again, re-enable it by setting "enabled": true on PATCH /webhooks/endpoints/%s.
+`, merchant.Name, endpoint.Url, endpoint.ID, endpoint.DisabledReason.String, endpoint.ID)
+
+	err = d.mailer.Send(ctx, mailmanager.Message{
+		To:      merchant.Email,
+		Subject: "Your Cash Flow webhook endpoint was disabled",
+		Body:    body,
+	})
+	if err != nil {
+		d.logger.Error("Failed to send endpoint disabled notification",
+			zap.String("merchant_id", endpoint.MerchantID),
+			zap.String("endpoint_id", endpoint.ID.String()),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,37 @@
+package callback
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cash-flow-financial/internal/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountsAgainstEndpoint(t *testing.T) {
+	assert.False(t, countsAgainstEndpoint(&CallbackResult{StatusCode: 200}))
+	assert.False(t, countsAgainstEndpoint(&CallbackResult{StatusCode: 200, Err: fmt.Errorf("%w: out of stock", ErrCallbackRejected)}))
+	assert.True(t, countsAgainstEndpoint(&CallbackResult{StatusCode: 503, Err: ErrUnexpectedStatus}))
+	assert.True(t, countsAgainstEndpoint(&CallbackResult{Err: errors.New("connection refused")}))
+}
+
+func TestShouldDisable(t *testing.T) {
+	now := time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)
+	endpoint := func(enabled bool, failures int32, failingFor time.Duration) *db.WebhookEndpoint {
+		return &db.WebhookEndpoint{
+			Enabled:             enabled,
+			ConsecutiveFailures: failures,
+			FailingSince:        sql.NullTime{Time: now.Add(-failingFor), Valid: true},
+		}
+	}
+
+	assert.True(t, shouldDisable(endpoint(true, 5, 72*time.Hour), now, 5, 72*time.Hour))
+	assert.False(t, shouldDisable(endpoint(true, 5, 71*time.Hour), now, 5, 72*time.Hour), "not failing for long enough")
+	assert.False(t, shouldDisable(endpoint(true, 4, 96*time.Hour), now, 5, 72*time.Hour), "circuit never opened")
+	assert.False(t, shouldDisable(endpoint(false, 50, 96*time.Hour), now, 5, 72*time.Hour), "already disabled")
+	assert.False(t, shouldDisable(&db.WebhookEndpoint{Enabled: true, ConsecutiveFailures: 5}, now, 5, 72*time.Hour))
+}
@@ -0,0 +1,122 @@
+package callback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/events"
+
+	"github.com/google/uuid"
+)
+
+const maxDeliveryBackoff = 6 * time.Hour
+
+// AllEvents subscribes an endpoint to every event type, including ones added later
+const AllEvents = "*"
+
+// EventTypes lists the events merchants can subscribe webhook endpoints to
+var EventTypes = []string{
+	events.TypePaymentIntentSucceeded,
+	events.TypePaymentIntentFailed,
+}
+
+// IsSubscribable reports whether eventType can be used in an endpoint subscription
+func IsSubscribable(eventType string) bool {
+	if eventType == AllEvents {
+		return true
+	}
+	for _, known := range EventTypes {
+		if eventType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Enqueue stores one delivery per destination of an event. A non-empty overrideURL (the intent's
+// callback_url) replaces the merchant's registered endpoints; otherwise every enabled endpoint
+// subscribed to eventType gets a delivery. Pass queries bound to the transaction that makes the
+// state change, so callbacks exist if and only if the change commits.
+func Enqueue(ctx context.Context, queries *db.Queries, eventType, overrideURL string, request CallbackRequest) ([]*db.WebhookDelivery, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal callback request: %w", err)
+	}
+
+	type destination struct {
+		endpointID uuid.NullUUID
+		url        string
+	}
+	var destinations []destination
+	if overrideURL != "" {
+		destinations = append(destinations, destination{url: overrideURL})
+	} else {
+		endpoints, err := queries.ListWebhookEndpointsForEvent(ctx, &db.ListWebhookEndpointsForEventParams{
+			MerchantID: request.MerchantID,
+			EventType:  eventType,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+		}
+		for _, endpoint := range endpoints {
+			destinations = append(destinations, destination{
+				endpointID: uuid.NullUUID{UUID: endpoint.ID, Valid: true},
+				url:        endpoint.Url,
+			})
+		}
+	}
+
+	deliveries := make([]*db.WebhookDelivery, 0, len(destinations))
+	for _, dest := range destinations {
+		delivery, err := queries.CreateWebhookDelivery(ctx, &db.CreateWebhookDeliveryParams{
+			MerchantID:      request.MerchantID,
+			EndpointID:      dest.endpointID,
+			PaymentIntentID: request.PaymentIntentID,
+			EventType:       eventType,
+			Url:             dest.url,
+			Payload:         payload,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to store webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// nextAttempt schedules the retry after a failed attempt. The delay starts at backoff and doubles
+// per attempt up to maxDeliveryBackoff; ok is false once the retry would land past the horizon.
+func nextAttempt(createdAt, now time.Time, attempts int32, backoff, horizon time.Duration) (time.Time, bool) {
+	delay := backoff
+	for i := int32(1); i < attempts && delay < maxDeliveryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxDeliveryBackoff {
+		delay = maxDeliveryBackoff
+	}
+
+	next := now.Add(delay)
+	if next.After(createdAt.Add(horizon)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// intentAckStatus maps an attempt to the acknowledgement state shown on the payment intent. Transport
+// errors and non-2xx responses leave the state alone until the delivery is exhausted.
+func intentAckStatus(result *CallbackResult, exhausted bool) (db.CallbackAckStatus, string, bool) {
+	switch {
+	case result.Err == nil && result.Ack != nil:
+		return db.CallbackAckStatusAcknowledged, result.Ack.Message, true
+	case result.Err == nil:
+		return db.CallbackAckStatusUnacknowledged, "", true
+	case exhausted:
+		return db.CallbackAckStatusFailed, result.Err.Error(), true
+	case result.Ack != nil:
+		return db.CallbackAckStatusRejected, result.Ack.Message, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,71 @@
+package callback
+
+import (
+	"testing"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextAttempt_Backoff(t *testing.T) {
+	created := time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)
+	horizon := 72 * time.Hour
+
+	next, ok := nextAttempt(created, created, 1, 30*time.Second, horizon)
+	assert.True(t, ok)
+	assert.Equal(t, created.Add(30*time.Second), next)
+
+	next, ok = nextAttempt(created, created, 3, 30*time.Second, horizon)
+	assert.True(t, ok)
+	assert.Equal(t, created.Add(2*time.Minute), next)
+
+	next, ok = nextAttempt(created, created, 40, 30*time.Second, horizon)
+	assert.True(t, ok)
+	assert.Equal(t, created.Add(maxDeliveryBackoff), next)
+}
+
+func TestNextAttempt_ExhaustedPastHorizon(t *testing.T) {
+	created := time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)
+	now := created.Add(71 * time.Hour)
+
+	_, ok := nextAttempt(created, now, 20, 30*time.Second, 72*time.Hour)
+	assert.False(t, ok)
+
+	_, ok = nextAttempt(created, now, 1, 30*time.Second, 72*time.Hour)
+	assert.True(t, ok)
+}
+
+func TestIsSubscribable(t *testing.T) {
+	assert.True(t, IsSubscribable(events.TypePaymentIntentSucceeded))
+	assert.True(t, IsSubscribable(events.TypePaymentIntentFailed))
+	assert.True(t, IsSubscribable(AllEvents))
+	assert.False(t, IsSubscribable("payment.intent.created"))
+	assert.False(t, IsSubscribable(""))
+}
+
+func TestIntentAckStatus(t *testing.T) {
+	cases := []struct {
+		name      string
+		result    *CallbackResult
+		exhausted bool
+		status    db.CallbackAckStatus
+		message   string
+		ok        bool
+	}{
+		{"acknowledged", &CallbackResult{Ack: &CallbackResponse{Status: true, Message: "paid"}}, false, db.CallbackAckStatusAcknowledged, "paid", true},
+		{"2xx without acknowledgement", &CallbackResult{StatusCode: 204}, false, db.CallbackAckStatusUnacknowledged, "", true},
+		{"rejected", &CallbackResult{Ack: &CallbackResponse{Message: "Order not found"}, Err: ErrCallbackRejected}, false, db.CallbackAckStatusRejected, "Order not found", true},
+		{"transport error", &CallbackResult{Err: ErrUnexpectedStatus}, false, "", "", false},
+		{"exhausted", &CallbackResult{Err: ErrUnexpectedStatus}, true, db.CallbackAckStatusFailed, ErrUnexpectedStatus.Error(), true},
+	}
+
+	for _, tc := range cases {
+		status, message, ok := intentAckStatus(tc.result, tc.exhausted)
+		assert.Equal(t, tc.ok, ok, tc.name)
+		assert.Equal(t, tc.status, status, tc.name)
+		assert.Equal(t, tc.message, message, tc.name)
+	}
+}
@@ -0,0 +1,264 @@
+package callback
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/dbmanager"
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/managers/mailmanager"
+	"cash-flow-financial/internal/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	dispatchPollInterval = time.Second
+	// A claimed delivery is hidden for this long, so a dispatcher that dies mid-attempt only delays it
+	deliveryLease = 2 * time.Minute
+)
+
+// Dispatcher sends stored webhook deliveries and retries failures with exponential backoff until
+// each one is delivered or its retry horizon passes. Deliveries are claimed with SKIP LOCKED, so
+// several dispatchers can run side by side. Each registered endpoint has a circuit breaker, and an
+// endpoint that keeps failing is disabled and its merchant notified.
+type Dispatcher struct {
+	queries     *db.Queries
+	dbManager   dbmanager.IDBManager
+	callbackSvc ICallbackService
+	mailer      mailmanager.IMailer
+	config      models.WebhookConfig
+	logger      *loggermanager.Logger
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewDispatcher(queries *db.Queries, dbManager dbmanager.IDBManager, callbackSvc ICallbackService, mailer mailmanager.IMailer, cfg *models.WebhookConfig, logger *loggermanager.Logger) *Dispatcher {
+	return &Dispatcher{
+		queries:     queries,
+		dbManager:   dbManager,
+		callbackSvc: callbackSvc,
+		mailer:      mailer,
+		config:      *cfg,
+		logger:      logger,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+func (d *Dispatcher) Start() {
+	d.logger.Info("Starting webhook dispatcher",
+		zap.Int("concurrency", d.config.DispatchConcurrency),
+		zap.Duration("retry_horizon", d.config.RetryHorizon),
+		zap.Duration("callback_timeout", d.config.CallbackTimeout),
+		zap.Int("circuit_failure_threshold", d.config.CircuitFailureThreshold))
+	go d.run()
+}
+
+// Stop ends polling and waits for in-flight attempts, which are bounded by the HTTP client timeout
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+		<-d.done
+		d.logger.Info("Webhook dispatcher stopped")
+	})
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(dispatchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.drain(context.Background())
+		}
+	}
+}
+
+// drain dispatches full batches until no delivery is due or the dispatcher is stopping
+func (d *Dispatcher) drain(ctx context.Context) {
+	for {
+		deliveries, err := d.queries.ClaimWebhookDeliveries(ctx, &db.ClaimWebhookDeliveriesParams{
+			LeaseSeconds:           int32(deliveryLease / time.Second),
+			CircuitCooldownSeconds: int32(d.config.CircuitCooldown / time.Second),
+			BatchSize:              int32(d.config.DispatchConcurrency),
+		})
+		if err != nil {
+			d.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery *db.WebhookDelivery) {
+				defer wg.Done()
+				d.attempt(ctx, delivery)
+			}(delivery)
+		}
+		wg.Wait()
+
+		if len(deliveries) < d.config.DispatchConcurrency {
+			return
+		}
+		select {
+		case <-d.stop:
+			return
+		default:
+		}
+	}
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery *db.WebhookDelivery) {
+	admitted, err := d.admit(ctx, delivery)
+	if err != nil {
+		// The lease expires and the delivery is claimed again
+		d.logger.Error("Failed to check webhook endpoint circuit",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.Error(err))
+		return
+	}
+	if !admitted {
+		err := d.queries.DeferWebhookDelivery(ctx, &db.DeferWebhookDeliveryParams{
+			ID:            delivery.ID,
+			NextAttemptAt: time.Now().Add(halfOpenDeferral),
+		})
+		if err != nil {
+			d.logger.Error("Failed to defer webhook delivery",
+				zap.String("delivery_id", delivery.ID.String()),
+				zap.Error(err))
+		}
+		return
+	}
+
+	result := d.callbackSvc.SendCallback(ctx, delivery.MerchantID, delivery.Url, delivery.Payload)
+
+	disabled, err := d.record(ctx, delivery, result)
+	if err != nil {
+		// The lease expires and the delivery is attempted again
+		d.logger.Error("Failed to record webhook delivery attempt",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.Error(err))
+		return
+	}
+	if disabled != nil {
+		d.notifyDisabled(ctx, disabled)
+	}
+}
+
+// record stores the attempt, updates the endpoint's circuit breaker, moves the delivery to
+// delivered, a later retry or exhausted, and updates the acknowledgement state shown on the payment
+// intent. It returns the endpoint if this attempt got it disabled.
+func (d *Dispatcher) record(ctx context.Context, delivery *db.WebhookDelivery, result *CallbackResult) (*db.WebhookEndpoint, error) {
+	responseCode := sql.NullInt32{Int32: int32(result.StatusCode), Valid: result.StatusCode != 0}
+	var lastError sql.NullString
+	if result.Err != nil {
+		lastError = sql.NullString{String: result.Err.Error(), Valid: true}
+	}
+	var acknowledged sql.NullBool
+	var ackMessage sql.NullString
+	if result.Ack != nil {
+		acknowledged = sql.NullBool{Bool: result.Ack.Status, Valid: true}
+		ackMessage = sql.NullString{String: result.Ack.Message, Valid: result.Ack.Message != ""}
+	}
+
+	var next time.Time
+	exhausted := false
+	if result.Err != nil {
+		var ok bool
+		next, ok = nextAttempt(delivery.CreatedAt, time.Now(), delivery.Attempts, d.config.RetryBackoff, d.config.RetryHorizon)
+		exhausted = !ok || errors.Is(result.Err, ErrDestinationBlocked)
+	}
+
+	var disabled *db.WebhookEndpoint
+	err := d.dbManager.WithTransaction(ctx, func(tx *sql.Tx) error {
+		qtx := d.queries.WithTx(tx)
+
+		err := qtx.RecordWebhookDeliveryAttempt(ctx, &db.RecordWebhookDeliveryAttemptParams{
+			DeliveryID:    delivery.ID,
+			AttemptNumber: delivery.Attempts,
+			ResponseCode:  responseCode,
+			LatencyMs:     result.Latency.Milliseconds(),
+			ResponseBody:  sql.NullString{String: result.ResponseBody, Valid: result.StatusCode != 0},
+			Acknowledged:  acknowledged,
+			AckMessage:    ackMessage,
+			Error:         lastError,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert delivery attempt: %w", err)
+		}
+
+		disabled, err = d.recordEndpointOutcome(ctx, qtx, delivery, result)
+		if err != nil {
+			return err
+		}
+		if disabled != nil {
+			exhausted = true
+		}
+
+		if status, message, ok := intentAckStatus(result, exhausted); ok {
+			err = qtx.UpdatePaymentIntentCallbackAck(ctx, &db.UpdatePaymentIntentCallbackAckParams{
+				CallbackAckStatus:  status,
+				CallbackAckMessage: sql.NullString{String: message, Valid: message != ""},
+				PaymentIntentID:    delivery.PaymentIntentID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update payment intent acknowledgement: %w", err)
+			}
+		}
+
+		switch {
+		case result.Err == nil:
+			d.logger.Info("Webhook delivered",
+				zap.String("delivery_id", delivery.ID.String()),
+				zap.String("payment_intent_id", delivery.PaymentIntentID),
+				zap.Int32("attempt", delivery.Attempts),
+				zap.Bool("acknowledged", result.Ack != nil))
+			return qtx.MarkWebhookDeliveryDelivered(ctx, &db.MarkWebhookDeliveryDeliveredParams{
+				ID:               delivery.ID,
+				LastResponseCode: responseCode,
+			})
+
+		case exhausted:
+			d.logger.Warn("Webhook delivery exhausted",
+				zap.String("delivery_id", delivery.ID.String()),
+				zap.String("payment_intent_id", delivery.PaymentIntentID),
+				zap.Int32("attempts", delivery.Attempts),
+				zap.Error(result.Err))
+			return qtx.MarkWebhookDeliveryExhausted(ctx, &db.MarkWebhookDeliveryExhaustedParams{
+				ID:               delivery.ID,
+				LastResponseCode: responseCode,
+				LastError:        lastError,
+			})
+		}
+
+		d.logger.Warn("Webhook delivery failed, will retry",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.String("payment_intent_id", delivery.PaymentIntentID),
+			zap.Int32("attempt", delivery.Attempts),
+			zap.Time("next_attempt_at", next),
+			zap.Error(result.Err))
+		return qtx.RetryWebhookDelivery(ctx, &db.RetryWebhookDeliveryParams{
+			ID:               delivery.ID,
+			NextAttemptAt:    next,
+			LastResponseCode: responseCode,
+			LastError:        lastError,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return disabled, nil
+}
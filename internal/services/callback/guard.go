@@ -0,0 +1,284 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"cash-flow-financial/internal/models"
+)
+
+// ErrDestinationBlocked marks a callback URL the guard refuses to connect to. Retrying cannot help.
+var ErrDestinationBlocked = errors.New("destination is not allowed")
+
+const (
+	maxRedirects           = 3
+	maxResponseHeaderBytes = 16 * 1024
+)
+
+// blockedPrefixes are the non-public ranges a callback may only reach when the operator allowlists
+// them: private, loopback, link-local (including cloud metadata at 169.254.169.254), CGNAT,
+// documentation, benchmarking, multicast and reserved space, plus the IPv6 transition prefixes that
+// can embed one of those IPv4 addresses.
+var blockedPrefixes = mustParsePrefixes(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"64:ff9b:1::/48",
+	"100::/64",
+	"2001:db8::/32",
+	"2002::/16",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+// hostRule is one WEBHOOK_ALLOWED_HOSTS or WEBHOOK_DENIED_HOSTS entry: an IP, a CIDR, a hostname,
+// or "*.example.com" for any subdomain of example.com
+type hostRule struct {
+	prefix   netip.Prefix
+	host     string
+	wildcard bool
+}
+
+// Guard vets outbound callback destinations. Addresses are checked after DNS resolution and the
+// connection is made to the vetted IP, so a rebinding DNS server cannot swap in a private address
+// between the check and the dial.
+type Guard struct {
+	allow        []hostRule
+	deny         []hostRule
+	requireHTTPS bool
+	resolver     *net.Resolver
+	dialer       *net.Dialer
+}
+
+func NewGuard(config *models.Config) *Guard {
+	return &Guard{
+		allow:        parseHostRules(config.Webhook.AllowedHosts),
+		deny:         parseHostRules(config.Webhook.DeniedHosts),
+		requireHTTPS: config.App.Env == models.AppEnvProduction,
+		resolver:     net.DefaultResolver,
+		dialer: &net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		},
+	}
+}
+
+// Client returns an HTTP client whose every connection, including those made for redirects, goes
+// through the guard
+func (g *Guard) Client(timeout time.Duration) *http.Client {
+	transport := &http.Transport{
+		// A proxy would open the connection on our behalf and bypass DialContext
+		Proxy:                  nil,
+		DialContext:            g.DialContext,
+		ForceAttemptHTTP2:      true,
+		MaxIdleConns:           100,
+		IdleConnTimeout:        90 * time.Second,
+		TLSHandshakeTimeout:    10 * time.Second,
+		ExpectContinueTimeout:  1 * time.Second,
+		MaxResponseHeaderBytes: maxResponseHeaderBytes,
+	}
+
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     transport,
+		CheckRedirect: g.checkRedirect,
+	}
+}
+
+// CheckURL applies the checks that need no DNS lookup: scheme, https in production, the denylist,
+// and IP literals or localhost names outside the allowlist. Addresses behind hostnames are checked
+// when the connection is dialed.
+func (g *Guard) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDestinationBlocked, err)
+	}
+	return g.checkURL(u)
+}
+
+func (g *Guard) checkURL(u *url.URL) error {
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if g.requireHTTPS {
+			return fmt.Errorf("%w: https is required", ErrDestinationBlocked)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported scheme %q", ErrDestinationBlocked, u.Scheme)
+	}
+
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrDestinationBlocked)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return g.checkAddr(host, addr)
+	}
+	if matchHost(g.deny, host) {
+		return fmt.Errorf("%w: %s is denylisted", ErrDestinationBlocked, host)
+	}
+	if (host == "localhost" || strings.HasSuffix(host, ".localhost")) && !matchHost(g.allow, host) {
+		return fmt.Errorf("%w: %s is a loopback name", ErrDestinationBlocked, host)
+	}
+	return nil
+}
+
+// checkAddr decides whether host may be reached at addr. The denylist wins over the allowlist, and
+// the allowlist wins over the built-in non-public ranges.
+func (g *Guard) checkAddr(host string, addr netip.Addr) error {
+	addr = addr.Unmap().WithZone("")
+
+	if matchHost(g.deny, host) || matchAddr(g.deny, addr) {
+		return fmt.Errorf("%w: %s is denylisted", ErrDestinationBlocked, host)
+	}
+	if matchHost(g.allow, host) || matchAddr(g.allow, addr) {
+		return nil
+	}
+	if isBlockedAddr(addr) {
+		return fmt.Errorf("%w: %s resolves to non-public address %s", ErrDestinationBlocked, host, addr)
+	}
+	return nil
+}
+
+// DialContext resolves the host, refuses the connection if any of its addresses is blocked, and
+// otherwise dials the vetted addresses directly
+func (g *Guard) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	rawHost, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	host := normalizeHost(rawHost)
+
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else {
+		addrs, err = g.resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, addr := range addrs {
+		if err := g.checkAddr(host, addr); err != nil {
+			return nil, err
+		}
+	}
+
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := g.dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no addresses found for %s", host)
+	}
+	return nil, lastErr
+}
+
+func (g *Guard) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return g.checkURL(req.URL)
+}
+
+func isBlockedAddr(addr netip.Addr) bool {
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchHost(rules []hostRule, host string) bool {
+	for _, rule := range rules {
+		if rule.host == "" {
+			continue
+		}
+		if rule.wildcard {
+			if strings.HasSuffix(host, "."+rule.host) {
+				return true
+			}
+		} else if host == rule.host {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAddr(rules []hostRule, addr netip.Addr) bool {
+	for _, rule := range rules {
+		if rule.prefix.IsValid() && rule.prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHostRules skips entries it cannot parse; configmanager rejects them at startup
+func parseHostRules(entries []string) []hostRule {
+	rules := make([]hostRule, 0, len(entries))
+	for _, entry := range entries {
+		entry = normalizeHost(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			if prefix, err := netip.ParsePrefix(entry); err == nil {
+				rules = append(rules, hostRule{prefix: prefix.Masked()})
+			}
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			addr = addr.Unmap()
+			rules = append(rules, hostRule{prefix: netip.PrefixFrom(addr, addr.BitLen())})
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(entry, "*."); ok {
+			rules = append(rules, hostRule{host: suffix, wildcard: true})
+			continue
+		}
+		rules = append(rules, hostRule{host: entry})
+	}
+	return rules
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+func mustParsePrefixes(cidrs ...string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefixes = append(prefixes, netip.MustParsePrefix(cidr))
+	}
+	return prefixes
+}
@@ -0,0 +1,123 @@
+package callback
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"cash-flow-financial/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGuard(env string, allowed, denied []string) *Guard {
+	return NewGuard(&models.Config{
+		App:     models.AppConfig{Env: env},
+		Webhook: models.WebhookConfig{AllowedHosts: allowed, DeniedHosts: denied},
+	})
+}
+
+func TestGuard_CheckURL(t *testing.T) {
+	guard := newTestGuard(models.AppEnvDevelopment, nil, []string{"*.internal.example.com", "evil.example.org"})
+
+	allowed := []string{
+		"https://example.com/webhook",
+		"http://example.com:8080/webhook",
+		"https://93.184.216.34/webhook",
+	}
+	for _, rawURL := range allowed {
+		assert.NoError(t, guard.CheckURL(rawURL), rawURL)
+	}
+
+	blocked := []string{
+		"ftp://example.com/webhook",
+		"http:///webhook",
+		"http://localhost:15672/api/users",
+		"http://api.localhost/webhook",
+		"http://127.0.0.1:5432",
+		"http://10.1.2.3/webhook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]:3074/health",
+		"http://[::ffff:127.0.0.1]/webhook",
+		"http://[fd00::1]/webhook",
+		"http://0.0.0.0:3074/health",
+		"https://db.internal.example.com/webhook",
+		"https://EVIL.example.org./webhook",
+	}
+	for _, rawURL := range blocked {
+		assert.ErrorIs(t, guard.CheckURL(rawURL), ErrDestinationBlocked, rawURL)
+	}
+}
+
+func TestGuard_RequiresHTTPSInProduction(t *testing.T) {
+	guard := newTestGuard(models.AppEnvProduction, nil, nil)
+
+	assert.NoError(t, guard.CheckURL("https://example.com/webhook"))
+	assert.ErrorIs(t, guard.CheckURL("http://example.com/webhook"), ErrDestinationBlocked)
+}
+
+func TestGuard_AllowAndDenyLists(t *testing.T) {
+	guard := newTestGuard(models.AppEnvDevelopment,
+		[]string{"10.20.0.0/16", "receiver.corp", "172.16.5.5"},
+		[]string{"10.20.30.0/24"})
+
+	assert.NoError(t, guard.CheckURL("http://10.20.1.1/webhook"))
+	assert.NoError(t, guard.CheckURL("http://172.16.5.5/webhook"))
+	assert.ErrorIs(t, guard.CheckURL("http://172.16.5.6/webhook"), ErrDestinationBlocked)
+	assert.ErrorIs(t, guard.CheckURL("http://10.20.30.40/webhook"), ErrDestinationBlocked, "the denylist wins")
+
+	// An allowlisted name may resolve to a private address
+	assert.NoError(t, guard.checkAddr("receiver.corp", netip.MustParseAddr("192.168.1.10")))
+	assert.ErrorIs(t, guard.checkAddr("other.corp", netip.MustParseAddr("192.168.1.10")), ErrDestinationBlocked)
+}
+
+func TestGuard_BlocksAtDialTime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The test server listens on loopback, which is only reachable once allowlisted
+	client := newTestGuard(models.AppEnvDevelopment, nil, nil).Client(5 * time.Second)
+	_, err := client.Get(server.URL)
+	assert.ErrorIs(t, err, ErrDestinationBlocked)
+
+	client = newTestGuard(models.AppEnvDevelopment, []string{"127.0.0.1"}, nil).Client(5 * time.Second)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGuard_ChecksRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	// Only the redirecting server's name is allowlisted, so following it to 127.0.0.1 is refused
+	guard := newTestGuard(models.AppEnvDevelopment, []string{"localhost"}, nil)
+	redirectorURL, err := url.Parse(redirector.URL)
+	require.NoError(t, err)
+	redirectorURL.Host = "localhost:" + redirectorURL.Port()
+
+	_, err = guard.Client(5 * time.Second).Get(redirectorURL.String())
+	assert.ErrorIs(t, err, ErrDestinationBlocked)
+}
+
+func TestGuard_DialRejectsResolvedPrivateAddress(t *testing.T) {
+	guard := newTestGuard(models.AppEnvDevelopment, nil, nil)
+
+	_, err := guard.DialContext(context.Background(), "tcp", "localhost:5432")
+	assert.ErrorIs(t, err, ErrDestinationBlocked)
+}
@@ -1,7 +1,19 @@
 package checkoutservice
 
-import "cash-flow-financial/internal/models"
+import (
+	"context"
+	"errors"
+
+	"cash-flow-financial/internal/models"
+)
+
+var (
+	ErrPaymentIntentNotFound = errors.New("payment intent not found")
+	ErrNonceUsedInOtherMode  = errors.New("nonce was already used by a payment intent in the other mode")
+)
 
 type ICheckoutService interface {
-	CreatePaymentIntent(merchantID string, req models.CreatePaymentIntentRequest) (*models.CreatePaymentIntentResponse, error)
+	CreatePaymentIntent(merchantID, mode string, req models.CreatePaymentIntentRequest) (*models.CreatePaymentIntentResponse, error)
+	GetPaymentIntent(merchantID, mode, paymentIntentID string) (*models.PaymentIntentResponse, error)
+	RepublishPendingIntents(ctx context.Context, merchantID string) (int, error)
 }
@@ -6,12 +6,14 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
 	"strconv"
 
+	"cash-flow-financial/internal/contracts"
 	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/brokermanager"
 	"cash-flow-financial/internal/managers/loggermanager"
-	"cash-flow-financial/internal/managers/rabbitmqmanager"
 	"cash-flow-financial/internal/models"
 
 	"github.com/sqlc-dev/pqtype"
@@ -19,21 +21,23 @@ import (
 )
 
 type CheckoutService struct {
-	queries       *db.Queries
-	logger        *loggermanager.Logger
-	rabbitManager rabbitmqmanager.IRabbitMQManager
+	queries   *db.Queries
+	logger    *loggermanager.Logger
+	publisher brokermanager.IPublisher
 }
 
-func NewCheckoutService(queries *db.Queries, logger *loggermanager.Logger, rabbitManager rabbitmqmanager.IRabbitMQManager) ICheckoutService {
+func NewCheckoutService(queries *db.Queries, logger *loggermanager.Logger, publisher brokermanager.IPublisher) ICheckoutService {
 	return &CheckoutService{
-		queries:       queries,
-		logger:        logger,
-		rabbitManager: rabbitManager,
+		queries:   queries,
+		logger:    logger,
+		publisher: publisher,
 	}
 }
 
-func (cs *CheckoutService) CreatePaymentIntent(merchantID string, req models.CreatePaymentIntentRequest) (*models.CreatePaymentIntentResponse, error) {
-	cs.logger.Info("Creating payment intent", zap.String("merchant_id", merchantID), zap.Float64("amount", req.Amount), zap.String("nonce", req.Nonce))
+// CreatePaymentIntent stores an intent in the mode of the API key that created it and queues it for
+// processing. Test intents are settled by the worker's sandbox.
+func (cs *CheckoutService) CreatePaymentIntent(merchantID, mode string, req models.CreatePaymentIntentRequest) (*models.CreatePaymentIntentResponse, error) {
+	cs.logger.Info("Creating payment intent", zap.String("merchant_id", merchantID), zap.String("mode", mode), zap.Float64("amount", req.Amount), zap.String("nonce", req.Nonce))
 
 	// Get merchant UUID from merchant_id string
 	merchant, err := cs.queries.GetMerchantByMerchantID(context.Background(), merchantID)
@@ -47,6 +51,13 @@ func (cs *CheckoutService) CreatePaymentIntent(merchantID string, req models.Cre
 		MerchantID: merchant.MerchantID,
 		Nonce:      req.Nonce,
 	})
+	if err == nil && string(existingIntent.Mode) != mode {
+		cs.logger.Warn("Payment intent nonce reused across modes",
+			zap.String("existing_payment_intent_id", existingIntent.PaymentIntentID),
+			zap.String("existing_mode", string(existingIntent.Mode)),
+			zap.String("mode", mode))
+		return nil, ErrNonceUsedInOtherMode
+	}
 	if err == nil {
 		// Payment intent already exists, return it (idempotent behavior)
 		cs.logger.Info("Payment intent already exists for nonce, returning existing", zap.String("existing_payment_intent_id", existingIntent.PaymentIntentID), zap.String("nonce", req.Nonce))
@@ -64,6 +75,7 @@ func (cs *CheckoutService) CreatePaymentIntent(merchantID string, req models.Cre
 		return &models.CreatePaymentIntentResponse{
 			Status:          true,
 			PaymentIntentID: existingIntent.PaymentIntentID,
+			Mode:            string(existingIntent.Mode),
 			Amount:          amount,
 			Currency:        existingIntent.Currency,
 			PaymentStatus:   paymentStatus,
@@ -98,9 +110,11 @@ func (cs *CheckoutService) CreatePaymentIntent(merchantID string, req models.Cre
 		Amount:          strconv.FormatFloat(req.Amount, 'f', 2, 64),
 		Currency:        req.Currency,
 		Description:     sql.NullString{String: req.Description, Valid: req.Description != ""},
-		CallbackUrl:     req.CallbackURL,
+		CallbackUrl:     sql.NullString{String: req.CallbackURL, Valid: req.CallbackURL != ""},
 		Nonce:           req.Nonce,
 		Metadata:        metadata,
+		Mode:            db.ApiMode(mode),
+		AccountNumber:   sql.NullString{String: req.AccountNumber, Valid: req.AccountNumber != ""},
 	})
 	if err != nil {
 		cs.logger.Error("Failed to create payment intent in database", zap.Error(err))
@@ -109,20 +123,14 @@ func (cs *CheckoutService) CreatePaymentIntent(merchantID string, req models.Cre
 
 	cs.logger.Info("Payment intent created successfully", zap.String("payment_intent_id", paymentIntentID), zap.String("nonce", req.Nonce))
 
-	// Publish to RabbitMQ for async processing
-	paymentMessage := rabbitmqmanager.PaymentMessage{
-		PaymentIntentID: paymentIntentID,
-		TransactionID:   "", // Will be generated by worker
-		Amount:          strconv.FormatFloat(req.Amount, 'f', 2, 64),
-		Currency:        req.Currency,
-		Timestamp:       intent.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
-	}
+	// Publish to the broker for async processing
+	paymentMessage := contracts.NewPaymentIntentCreated(paymentIntentID, intent.MerchantID, intent.Amount, intent.Currency, intent.CreatedAt.Time)
 
-	if err := cs.rabbitManager.PublishPaymentIntent(paymentMessage); err != nil {
-		cs.logger.Error("Failed to publish payment intent to RabbitMQ", zap.Error(err), zap.String("payment_intent_id", paymentIntentID))
+	if err := cs.publishPaymentIntent(paymentMessage); err != nil {
+		cs.logger.Error("Failed to publish payment intent to broker", zap.Error(err), zap.String("payment_intent_id", paymentIntentID))
 		// Don't return error here - payment intent is still created, just async processing will be delayed
 	} else {
-		cs.logger.Info("Payment intent published to RabbitMQ for processing", zap.String("payment_intent_id", paymentIntentID))
+		cs.logger.Info("Payment intent published to broker for processing", zap.String("payment_intent_id", paymentIntentID))
 	}
 
 	amount, _ := strconv.ParseFloat(intent.Amount, 64)
@@ -138,6 +146,7 @@ func (cs *CheckoutService) CreatePaymentIntent(merchantID string, req models.Cre
 	return &models.CreatePaymentIntentResponse{
 		Status:          true,
 		PaymentIntentID: intent.PaymentIntentID,
+		Mode:            string(intent.Mode),
 		Amount:          amount,
 		Currency:        intent.Currency,
 		PaymentStatus:   paymentStatus,
@@ -148,6 +157,92 @@ func (cs *CheckoutService) CreatePaymentIntent(merchantID string, req models.Cre
 	}, nil
 }
 
+// GetPaymentIntent returns one of the merchant's intents with the acknowledgement state of its
+// callbacks. Intents created in the other mode are reported as not found.
+func (cs *CheckoutService) GetPaymentIntent(merchantID, mode, paymentIntentID string) (*models.PaymentIntentResponse, error) {
+	intent, err := cs.queries.GetMerchantPaymentIntent(context.Background(), &db.GetMerchantPaymentIntentParams{
+		PaymentIntentID: paymentIntentID,
+		MerchantID:      merchantID,
+		Mode:            db.ApiMode(mode),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPaymentIntentNotFound
+		}
+		cs.logger.Error("Failed to get payment intent", zap.String("payment_intent_id", paymentIntentID), zap.Error(err))
+		return nil, fmt.Errorf("failed to get payment intent: %w", err)
+	}
+
+	amount, _ := strconv.ParseFloat(intent.Amount, 64)
+	paymentStatus := string(intent.Status.PaymentStatus)
+	if !intent.Status.Valid {
+		paymentStatus = "unknown"
+	}
+
+	response := &models.PaymentIntentResponse{
+		Status:          true,
+		PaymentIntentID: intent.PaymentIntentID,
+		Mode:            string(intent.Mode),
+		Amount:          amount,
+		Currency:        intent.Currency,
+		PaymentStatus:   paymentStatus,
+		Description:     intent.Description.String,
+		CallbackURL:     intent.CallbackUrl.String,
+		CallbackAck: models.CallbackAcknowledgement{
+			Status:  string(intent.CallbackAckStatus),
+			Message: intent.CallbackAckMessage.String,
+		},
+		CreatedAt: intent.CreatedAt.Time,
+		ExpiresAt: intent.ExpiresAt.Time,
+		Message:   "Payment intent retrieved successfully",
+	}
+	if intent.CallbackAckAt.Valid {
+		response.CallbackAck.UpdatedAt = &intent.CallbackAckAt.Time
+	}
+	return response, nil
+}
+
+// RepublishPendingIntents queues the merchant's unexpired pending intents for processing again. The
+// worker leaves intents of suspended merchants pending, so they are picked up once the merchant is
+// reactivated; intents already settled are skipped by the worker.
+func (cs *CheckoutService) RepublishPendingIntents(ctx context.Context, merchantID string) (int, error) {
+	intents, err := cs.queries.ListPendingMerchantPaymentIntents(ctx, merchantID)
+	if err != nil {
+		cs.logger.Error("Failed to list pending payment intents", zap.String("merchant_id", merchantID), zap.Error(err))
+		return 0, fmt.Errorf("failed to list pending payment intents: %w", err)
+	}
+
+	published := 0
+	for _, intent := range intents {
+		message := contracts.NewPaymentIntentCreated(intent.PaymentIntentID, intent.MerchantID, intent.Amount, intent.Currency, intent.CreatedAt.Time)
+		if err := cs.publishPaymentIntent(message); err != nil {
+			cs.logger.Error("Failed to republish payment intent", zap.String("payment_intent_id", intent.PaymentIntentID), zap.Error(err))
+			return published, fmt.Errorf("failed to republish payment intent %s: %w", intent.PaymentIntentID, err)
+		}
+		published++
+	}
+
+	cs.logger.Info("Pending payment intents republished", zap.String("merchant_id", merchantID), zap.Int("count", published))
+	return published, nil
+}
+
+func (cs *CheckoutService) publishPaymentIntent(message *contracts.PaymentIntentCreated) error {
+	body, headers, err := message.Encode()
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	return cs.publisher.Publish(context.Background(), brokermanager.Message{
+		Exchange:    "payment_intents_exchange",
+		RoutingKey:  contracts.TypePaymentIntentCreated,
+		MessageID:   message.PaymentIntentID,
+		Type:        contracts.TypePaymentIntentCreated,
+		ContentType: contracts.ContentTypeJSON,
+		Headers:     headers,
+		Body:        body,
+	})
+}
+
 func (cs *CheckoutService) generatePaymentIntentID() string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	b := make([]byte, 12)
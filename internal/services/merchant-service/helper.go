@@ -0,0 +1,72 @@
+package merchantservice
+
+import (
+	"fmt"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/models"
+)
+
+// allowedFromStatuses lists, for each target status, the statuses a merchant may move to it from
+var allowedFromStatuses = map[db.MerchantStatus][]db.MerchantStatus{
+	db.MerchantStatusActive:    {db.MerchantStatusSuspended, db.MerchantStatusInactive},
+	db.MerchantStatusSuspended: {db.MerchantStatusActive},
+	db.MerchantStatusInactive:  {db.MerchantStatusActive, db.MerchantStatusSuspended},
+}
+
+// checkTransition returns ErrInvalidStatusTransition, with the reason, when a merchant cannot move
+// from one status to the other
+func checkTransition(from, to db.MerchantStatus) error {
+	if from == to {
+		return fmt.Errorf("%w: merchant is already %s", ErrInvalidStatusTransition, to)
+	}
+	for _, allowed := range allowedFromStatuses[to] {
+		if allowed == from {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: a %s merchant cannot become %s", ErrInvalidStatusTransition, from, to)
+}
+
+// statusChangeMessage describes a completed change in the response
+func statusChangeMessage(to db.MerchantStatus) string {
+	switch to {
+	case db.MerchantStatusActive:
+		return "Merchant reactivated"
+	case db.MerchantStatusSuspended:
+		return "Merchant suspended"
+	default:
+		return "Merchant deactivated"
+	}
+}
+
+func toAdminMerchant(merchant *db.ListMerchantsRow) models.AdminMerchant {
+	result := models.AdminMerchant{
+		MerchantID:     merchant.MerchantID,
+		Name:           merchant.Name,
+		Email:          merchant.Email,
+		EmailVerified:  merchant.EmailVerifiedAt.Valid,
+		PendingEmail:   merchant.PendingEmail.String,
+		MerchantStatus: string(merchant.Status.MerchantStatus),
+		Tier:           string(merchant.Tier),
+		ActiveAPIKeys:  merchant.ActiveApiKeys,
+		PendingIntents: merchant.PendingIntents,
+		CreatedAt:      merchant.CreatedAt.Time,
+		UpdatedAt:      merchant.UpdatedAt.Time,
+	}
+	if merchant.StatusChangedAt.Valid {
+		statusChangedAt := merchant.StatusChangedAt.Time
+		result.StatusChangedAt = &statusChangedAt
+	}
+	return result
+}
+
+func toMerchantStatusEvent(event *db.MerchantStatusEvent) models.MerchantStatusEvent {
+	return models.MerchantStatusEvent{
+		FromStatus: string(event.FromStatus),
+		ToStatus:   string(event.ToStatus),
+		Reason:     event.Reason,
+		Actor:      event.Actor,
+		CreatedAt:  event.CreatedAt,
+	}
+}
@@ -0,0 +1,37 @@
+package merchantservice
+
+import (
+	"testing"
+
+	"cash-flow-financial/internal/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		from, to db.MerchantStatus
+		allowed  bool
+	}{
+		{db.MerchantStatusActive, db.MerchantStatusSuspended, true},
+		{db.MerchantStatusActive, db.MerchantStatusInactive, true},
+		{db.MerchantStatusSuspended, db.MerchantStatusActive, true},
+		{db.MerchantStatusSuspended, db.MerchantStatusInactive, true},
+		{db.MerchantStatusInactive, db.MerchantStatusActive, true},
+		{db.MerchantStatusInactive, db.MerchantStatusSuspended, false},
+		{db.MerchantStatusActive, db.MerchantStatusActive, false},
+		{db.MerchantStatusSuspended, db.MerchantStatusSuspended, false},
+	}
+
+	for _, tt := range tests {
+		err := checkTransition(tt.from, tt.to)
+		if tt.allowed {
+			assert.NoError(t, err, "%s to %s", tt.from, tt.to)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidStatusTransition, "%s to %s", tt.from, tt.to)
+		}
+	}
+
+	assert.EqualError(t, checkTransition(db.MerchantStatusSuspended, db.MerchantStatusSuspended),
+		"merchant status cannot change: merchant is already suspended")
+}
@@ -0,0 +1,24 @@
+package merchantservice
+
+import (
+	"context"
+	"errors"
+
+	"cash-flow-financial/internal/models"
+)
+
+var (
+	ErrMerchantNotFound        = errors.New("merchant not found")
+	ErrInvalidMerchantStatus   = errors.New("merchant status must be one of: active, suspended, inactive")
+	ErrInvalidStatusTransition = errors.New("merchant status cannot change")
+	ErrInvalidMerchantTier     = errors.New("merchant tier must be one of: standard, growth, enterprise")
+)
+
+// IMerchantService manages merchants on behalf of operators. Every status change is recorded with
+// the admin who made it.
+type IMerchantService interface {
+	ListMerchants(ctx context.Context, filter models.MerchantFilter) (*models.ListMerchantsResponse, error)
+	GetMerchant(ctx context.Context, merchantID string) (*models.AdminMerchantResponse, error)
+	ChangeStatus(ctx context.Context, merchantID, status, reason, actor string) (*models.ChangeMerchantStatusResponse, error)
+	ChangeTier(ctx context.Context, merchantID, tier, actor string) (*models.ChangeMerchantTierResponse, error)
+}
@@ -0,0 +1,232 @@
+package merchantservice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/dbmanager"
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	checkoutservice "cash-flow-financial/internal/services/checkout-service"
+
+	"go.uber.org/zap"
+)
+
+type MerchantService struct {
+	queries         *db.Queries
+	dbManager       dbmanager.IDBManager
+	checkoutService checkoutservice.ICheckoutService
+	logger          *loggermanager.Logger
+}
+
+func NewMerchantService(queries *db.Queries, dbManager dbmanager.IDBManager, checkoutService checkoutservice.ICheckoutService, logger *loggermanager.Logger) IMerchantService {
+	return &MerchantService{
+		queries:         queries,
+		dbManager:       dbManager,
+		checkoutService: checkoutService,
+		logger:          logger,
+	}
+}
+
+func (ms *MerchantService) ListMerchants(ctx context.Context, filter models.MerchantFilter) (*models.ListMerchantsResponse, error) {
+	ms.logger.Info("Listing merchants",
+		zap.String("query", filter.Query),
+		zap.String("status", filter.Status),
+		zap.Int("limit", filter.Limit),
+		zap.Int("offset", filter.Offset))
+
+	params := &db.ListMerchantsParams{
+		Query:     sql.NullString{String: filter.Query, Valid: filter.Query != ""},
+		RowLimit:  int32(filter.Limit),
+		RowOffset: int32(filter.Offset),
+	}
+	if filter.Status != "" {
+		params.Status = db.NullMerchantStatus{MerchantStatus: db.MerchantStatus(filter.Status), Valid: true}
+	}
+
+	merchants, err := ms.queries.ListMerchants(ctx, params)
+	if err != nil {
+		ms.logger.Error("Failed to list merchants", zap.Error(err))
+		return nil, fmt.Errorf("failed to list merchants: %w", err)
+	}
+
+	response := &models.ListMerchantsResponse{
+		Status:    true,
+		Merchants: make([]models.AdminMerchant, 0, len(merchants)),
+		Message:   "Merchants retrieved successfully",
+	}
+	for _, merchant := range merchants {
+		response.Merchants = append(response.Merchants, toAdminMerchant(merchant))
+	}
+	return response, nil
+}
+
+// GetMerchant returns the merchant with its status history, newest change first
+func (ms *MerchantService) GetMerchant(ctx context.Context, merchantID string) (*models.AdminMerchantResponse, error) {
+	merchant, err := ms.queries.GetMerchantSummary(ctx, merchantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMerchantNotFound
+		}
+		ms.logger.Error("Failed to get merchant", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+
+	events, err := ms.queries.ListMerchantStatusEvents(ctx, merchant.ID)
+	if err != nil {
+		ms.logger.Error("Failed to list merchant status events", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list merchant status events: %w", err)
+	}
+
+	response := &models.AdminMerchantResponse{
+		Status:        true,
+		Merchant:      toAdminMerchant((*db.ListMerchantsRow)(merchant)),
+		StatusHistory: make([]models.MerchantStatusEvent, 0, len(events)),
+		Message:       "Merchant retrieved successfully",
+	}
+	for _, event := range events {
+		response.StatusHistory = append(response.StatusHistory, toMerchantStatusEvent(event))
+	}
+	return response, nil
+}
+
+// ChangeStatus moves the merchant to status and records the change, with the reason and the admin
+// who made it, in the same transaction. The new status applies to the merchant's next request:
+// suspension blocks intent creation and pauses processing of pending intents, and reactivation
+// queues those intents again.
+func (ms *MerchantService) ChangeStatus(ctx context.Context, merchantID, status, reason, actor string) (*models.ChangeMerchantStatusResponse, error) {
+	to := db.MerchantStatus(status)
+	if !to.Valid() {
+		return nil, ErrInvalidMerchantStatus
+	}
+
+	ms.logger.Info("Changing merchant status",
+		zap.String("merchant_id", merchantID),
+		zap.String("status", status),
+		zap.String("actor", actor))
+
+	var event *db.MerchantStatusEvent
+	err := ms.dbManager.WithTransaction(ctx, func(tx *sql.Tx) error {
+		qtx := ms.queries.WithTx(tx)
+
+		merchant, err := qtx.LockMerchantByMerchantID(ctx, merchantID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrMerchantNotFound
+			}
+			return fmt.Errorf("failed to lock merchant: %w", err)
+		}
+
+		from := db.MerchantStatusActive
+		if merchant.Status.Valid {
+			from = merchant.Status.MerchantStatus
+		}
+		if err := checkTransition(from, to); err != nil {
+			return err
+		}
+
+		if _, err := qtx.UpdateMerchantStatus(ctx, &db.UpdateMerchantStatusParams{
+			ID:     merchant.ID,
+			Status: db.NullMerchantStatus{MerchantStatus: to, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to update merchant status: %w", err)
+		}
+
+		event, err = qtx.CreateMerchantStatusEvent(ctx, &db.CreateMerchantStatusEventParams{
+			MerchantID: merchant.ID,
+			FromStatus: from,
+			ToStatus:   to,
+			Reason:     reason,
+			Actor:      actor,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record merchant status event: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrMerchantNotFound) || errors.Is(err, ErrInvalidStatusTransition) {
+			ms.logger.Warn("Merchant status change rejected", zap.String("merchant_id", merchantID), zap.Error(err))
+		} else {
+			ms.logger.Error("Failed to change merchant status", zap.String("merchant_id", merchantID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	ms.logger.Info("Merchant status changed",
+		zap.String("merchant_id", merchantID),
+		zap.String("from", string(event.FromStatus)),
+		zap.String("to", string(event.ToStatus)),
+		zap.String("actor", actor))
+
+	response := &models.ChangeMerchantStatusResponse{
+		Status:  true,
+		Event:   toMerchantStatusEvent(event),
+		Message: statusChangeMessage(to),
+	}
+
+	// The status change stands even if requeueing fails; like a failed publish at intent creation,
+	// the intents then wait for their expiry
+	if to == db.MerchantStatusActive {
+		republished, err := ms.checkoutService.RepublishPendingIntents(ctx, merchantID)
+		if err != nil {
+			ms.logger.Error("Failed to republish pending intents after reactivation",
+				zap.String("merchant_id", merchantID),
+				zap.Int("republished", republished),
+				zap.Error(err))
+		}
+		response.RepublishedIntents = republished
+	}
+
+	merchant, err := ms.queries.GetMerchantSummary(ctx, merchantID)
+	if err != nil {
+		ms.logger.Error("Failed to get merchant after status change", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+	response.Merchant = toAdminMerchant((*db.ListMerchantsRow)(merchant))
+	return response, nil
+}
+
+// ChangeTier moves the merchant to another tier, whose rate limit applies from the merchant's next
+// request
+func (ms *MerchantService) ChangeTier(ctx context.Context, merchantID, tier, actor string) (*models.ChangeMerchantTierResponse, error) {
+	to := db.MerchantTier(tier)
+	if !to.Valid() {
+		return nil, ErrInvalidMerchantTier
+	}
+
+	ms.logger.Info("Changing merchant tier",
+		zap.String("merchant_id", merchantID),
+		zap.String("tier", tier),
+		zap.String("actor", actor))
+
+	if _, err := ms.queries.UpdateMerchantTier(ctx, &db.UpdateMerchantTierParams{
+		MerchantID: merchantID,
+		Tier:       to,
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMerchantNotFound
+		}
+		ms.logger.Error("Failed to change merchant tier", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to update merchant tier: %w", err)
+	}
+
+	ms.logger.Info("Merchant tier changed",
+		zap.String("merchant_id", merchantID),
+		zap.String("tier", tier),
+		zap.String("actor", actor))
+
+	merchant, err := ms.queries.GetMerchantSummary(ctx, merchantID)
+	if err != nil {
+		ms.logger.Error("Failed to get merchant after tier change", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+	return &models.ChangeMerchantTierResponse{
+		Status:   true,
+		Merchant: toAdminMerchant((*db.ListMerchantsRow)(merchant)),
+		Message:  "Merchant tier changed",
+	}, nil
+}
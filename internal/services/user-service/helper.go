@@ -0,0 +1,208 @@
+package userservice
+
+import (
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/keymanager"
+	"cash-flow-financial/internal/models"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const (
+	sessionTokenPrefix = "sess_"
+	inviteTokenPrefix  = "inv_"
+	tokenLength        = 40
+
+	// apiKeyActor is recorded as the inviter when a user is invited with an API key
+	apiKeyActor = "api_key"
+)
+
+// TOTP parameters (RFC 6238). These are the defaults every authenticator app assumes.
+const (
+	totpIssuer       = "Cash Flow"
+	totpDigits       = 6
+	totpPeriod       = 30
+	totpSkew         = 1 // Steps accepted either side of now, for clock drift
+	totpSecretLength = 20
+)
+
+func toDashboardUser(user *db.MerchantUser) models.DashboardUser {
+	dashboardUser := models.DashboardUser{
+		ID:          user.ID.String(),
+		Email:       user.Email,
+		Name:        user.Name,
+		Role:        string(user.Role),
+		Status:      string(user.Status),
+		TOTPEnabled: user.TotpEnabledAt.Valid,
+		InvitedBy:   user.InvitedBy,
+		CreatedAt:   user.CreatedAt,
+	}
+	if user.LastLoginAt.Valid {
+		dashboardUser.LastLoginAt = &user.LastLoginAt.Time
+	}
+	return dashboardUser
+}
+
+// toPrincipal turns a resolved session into the user and the merchant view the API routes expect.
+// Sessions act in live mode with the scopes of the user's role.
+func toPrincipal(session *db.GetUserSessionByTokenHashRow) *models.DashboardPrincipal {
+	user := models.DashboardUser{
+		ID:          session.UserID.String(),
+		Email:       session.Email,
+		Name:        session.Name,
+		Role:        string(session.Role),
+		Status:      string(session.Status),
+		TOTPEnabled: session.TotpEnabledAt.Valid,
+		InvitedBy:   session.InvitedBy,
+		CreatedAt:   session.CreatedAt,
+	}
+	if session.LastLoginAt.Valid {
+		user.LastLoginAt = &session.LastLoginAt.Time
+	}
+
+	merchantStatus := "unknown"
+	if session.MerchantStatus.Valid {
+		merchantStatus = string(session.MerchantStatus.MerchantStatus)
+	}
+
+	return &models.DashboardPrincipal{
+		SessionID: session.SessionID.String(),
+		User:      user,
+		Merchant: models.GetMerchantResponse{
+			Status:         true,
+			MerchantID:     session.MerchantID,
+			Name:           session.MerchantName,
+			Email:          session.MerchantEmail,
+			EmailVerified:  session.EmailVerifiedAt.Valid,
+			MerchantStatus: merchantStatus,
+			Tier:           string(session.MerchantTier),
+			Mode:           models.ModeLive,
+			APIKeyScopes:   models.RoleScopes[user.Role],
+			Message:        "Merchant found",
+		},
+	}
+}
+
+// checkOwnerChange refuses a change touching the owner role unless it is made by an owner. An API key
+// (nil actor) can only touch the role while the merchant has no active owner, which is how the first
+// owner is invited; otherwise an admin could mint a users:write key and promote themselves. roles are
+// the user's current and requested roles.
+func checkOwnerChange(actor *models.DashboardUser, activeOwners int64, roles ...string) error {
+	if !slices.Contains(roles, models.RoleOwner) {
+		return nil
+	}
+	if actor != nil && actor.Role == models.RoleOwner {
+		return nil
+	}
+	if actor == nil && activeOwners == 0 {
+		return nil
+	}
+	return ErrOwnerRequired
+}
+
+func actorName(actor *models.DashboardUser) string {
+	if actor == nil {
+		return apiKeyActor
+	}
+	return actor.Email
+}
+
+// generateToken returns an opaque random token. Only its hash is stored.
+func generateToken(prefix string) string {
+	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := make([]byte, tokenLength)
+	for i := range b {
+		num, _ := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		b[i] = charset[num.Int64()]
+	}
+	return prefix + string(b)
+}
+
+// hashToken is keyed with API_KEY_HASH_KEY like API key hashes, so a leaked table alone cannot be
+// replayed as session or invite tokens
+func hashToken(token string, keyring keymanager.IKeyring) string {
+	return hex.EncodeToString(keyring.HMAC([]byte(token)))
+}
+
+// inviteLink points to the dashboard page that accepts the invitation
+func inviteLink(dashboardURL, token string) (string, error) {
+	link, err := url.Parse(dashboardURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid dashboard URL: %w", err)
+	}
+	link = link.JoinPath("invite")
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+func generateTOTPSecret() string {
+	secret := make([]byte, totpSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+}
+
+// totpURI is the otpauth URI authenticator apps read from a QR code
+func totpURI(email, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", totpIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+	label := url.PathEscape(totpIssuer + ":" + email)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// totpCode computes the code for the time step containing t (RFC 6238, HMAC-SHA1)
+func totpCode(secret string, t time.Time) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	return hotp(key, uint64(t.Unix()/totpPeriod)), nil
+}
+
+func hotp(key []byte, counter uint64) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], counter)
+	h := hmac.New(sha1.New, key)
+	h.Write(message[:])
+	sum := h.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// validateTOTP accepts the code for now or for totpSkew steps either side of it, and returns the
+// time step the code belongs to so the caller can refuse it a second time
+func validateTOTP(secret, code string, now time.Time) (int64, bool) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	step := now.Unix() / totpPeriod
+	var matched int64
+	valid := false
+	for i := -totpSkew; i <= totpSkew; i++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, uint64(step+int64(i)))), []byte(code)) == 1 {
+			matched = step + int64(i)
+			valid = true
+		}
+	}
+	return matched, valid
+}
@@ -0,0 +1,128 @@
+package userservice
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/keymanager"
+	"cash-flow-financial/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyring(t *testing.T, hashKey string) keymanager.IKeyring {
+	t.Helper()
+	keyring, err := keymanager.NewKeyring(&models.KeyringConfig{
+		HashKey:        hashKey,
+		EncryptionKeys: []models.EncryptionKey{{ID: "v1", Key: []byte(strings.Repeat("e", keymanager.EncryptionKeyLength))}},
+	})
+	require.NoError(t, err)
+	return keyring
+}
+
+// Secret "12345678901234567890" from the RFC 6238 test vectors, base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := totpCode(rfcSecret, time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.code, code, "at %d", tt.unix)
+	}
+
+	_, err := totpCode("not base32!", time.Now())
+	assert.Error(t, err)
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	valid := func(secret, code string, at time.Time) bool {
+		_, ok := validateTOTP(secret, code, at)
+		return ok
+	}
+
+	assert.True(t, valid(rfcSecret, "081804", now))
+	assert.True(t, valid(rfcSecret, "081804", now.Add(totpPeriod*time.Second)), "one step of clock drift")
+	assert.False(t, valid(rfcSecret, "081804", now.Add(3*totpPeriod*time.Second)))
+	assert.False(t, valid(rfcSecret, "000000", now))
+	assert.False(t, valid(rfcSecret, "81804", now))
+
+	// The step returned is the code's own, whatever the clock drift, so a replay is recognised
+	step, ok := validateTOTP(rfcSecret, "081804", now.Add(totpPeriod*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/totpPeriod, step)
+
+	secret := generateTOTPSecret()
+	code, err := totpCode(secret, now)
+	require.NoError(t, err)
+	assert.True(t, valid(secret, code, now))
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri, err := url.Parse(totpURI("finance@example.com", rfcSecret))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Cash Flow:finance@example.com", uri.Path)
+	assert.Equal(t, rfcSecret, uri.Query().Get("secret"))
+	assert.Equal(t, "Cash Flow", uri.Query().Get("issuer"))
+}
+
+func TestHashToken(t *testing.T) {
+	token := generateToken(sessionTokenPrefix)
+
+	assert.True(t, strings.HasPrefix(token, sessionTokenPrefix))
+	assert.Len(t, token, len(sessionTokenPrefix)+tokenLength)
+	keyring := newTestKeyring(t, strings.Repeat("k", keymanager.MinHashKeyLength))
+	assert.Equal(t, hashToken(token, keyring), hashToken(token, keyring))
+	assert.NotEqual(t, hashToken(token, keyring), hashToken(token, newTestKeyring(t, strings.Repeat("x", keymanager.MinHashKeyLength))))
+}
+
+func TestInviteLink(t *testing.T) {
+	link, err := inviteLink("https://dashboard.example.com/app/", "inv_abc")
+	require.NoError(t, err)
+	assert.Equal(t, "https://dashboard.example.com/app/invite?token=inv_abc", link)
+}
+
+func TestCheckOwnerChange(t *testing.T) {
+	owner := &models.DashboardUser{Role: models.RoleOwner}
+	admin := &models.DashboardUser{Role: models.RoleAdmin}
+
+	assert.NoError(t, checkOwnerChange(nil, 0, models.RoleOwner), "an API key sets up the first owner")
+	assert.ErrorIs(t, checkOwnerChange(nil, 1, models.RoleOwner), ErrOwnerRequired, "once there is an owner API keys act like admins")
+	assert.NoError(t, checkOwnerChange(nil, 1, models.RoleAdmin, models.RoleDeveloper))
+	assert.NoError(t, checkOwnerChange(owner, 1, models.RoleOwner, models.RoleAdmin))
+	assert.NoError(t, checkOwnerChange(admin, 1, models.RoleDeveloper, models.RoleFinance))
+	assert.ErrorIs(t, checkOwnerChange(admin, 0, models.RoleOwner), ErrOwnerRequired)
+	assert.ErrorIs(t, checkOwnerChange(admin, 1, models.RoleAdmin, models.RoleOwner), ErrOwnerRequired)
+}
+
+func TestRoleScopes(t *testing.T) {
+	for _, role := range models.DashboardRoles {
+		assert.True(t, db.DashboardRole(role).Valid(), role)
+
+		scopes, ok := models.RoleScopes[role]
+		require.True(t, ok, "role %s has no scopes", role)
+		assert.Subset(t, models.APIKeyScopes, scopes, role)
+	}
+	assert.Len(t, models.RoleScopes, len(db.AllDashboardRoleValues()))
+	assert.NotContains(t, models.RoleScopes[models.RoleDeveloper], models.ScopeUsersWrite)
+	assert.NotContains(t, models.RoleScopes[models.RoleReadOnly], models.ScopeIntentsWrite)
+}
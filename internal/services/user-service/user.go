@@ -0,0 +1,44 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+
+	"cash-flow-financial/internal/models"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserExists         = errors.New("a dashboard user with this email already exists")
+	ErrInvalidRole        = errors.New("role must be one of: owner, admin, developer, finance, read_only")
+	ErrOwnerRequired      = errors.New("only an owner can grant, change or remove the owner role")
+	ErrLastOwner          = errors.New("the merchant must keep at least one active owner")
+	ErrInvalidInvite      = errors.New("invalid or already used invitation")
+	ErrInviteExpired      = errors.New("invitation has expired")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrMerchantInactive   = errors.New("merchant account is inactive")
+	ErrTOTPRequired       = errors.New("two-factor code is required")
+	ErrInvalidTOTPCode    = errors.New("invalid two-factor code")
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnrolled    = errors.New("two-factor authentication is not set up")
+	ErrInvalidSession     = errors.New("invalid or expired session")
+)
+
+// IUserService manages the staff who sign in to a merchant's dashboard. Management calls take the
+// acting user, or nil when the caller is an API key, which can only touch the owner role while the
+// merchant has no active owner.
+type IUserService interface {
+	InviteUser(ctx context.Context, merchantID string, actor *models.DashboardUser, req models.InviteUserRequest) (*models.InviteUserResponse, error)
+	ListUsers(ctx context.Context, merchantID string) (*models.ListUsersResponse, error)
+	UpdateUserRole(ctx context.Context, merchantID string, actor *models.DashboardUser, userID, role string) (*models.UserResponse, error)
+	RemoveUser(ctx context.Context, merchantID string, actor *models.DashboardUser, userID string) (*models.UserResponse, error)
+	AcceptInvite(ctx context.Context, req models.AcceptInviteRequest) (*models.UserResponse, error)
+
+	Login(ctx context.Context, req models.LoginRequest, ipAddress, userAgent string) (*models.LoginResponse, error)
+	Logout(ctx context.Context, sessionID string) error
+	Authenticate(ctx context.Context, sessionToken string) (*models.DashboardPrincipal, error)
+
+	BeginTOTPEnrollment(ctx context.Context, userID string) (*models.TOTPEnrollmentResponse, error)
+	ConfirmTOTP(ctx context.Context, userID, code string) (*models.UserResponse, error)
+	DisableTOTP(ctx context.Context, userID, code string) (*models.UserResponse, error)
+}
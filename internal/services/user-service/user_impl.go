@@ -0,0 +1,689 @@
+package userservice
+
+import (
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/dbmanager"
+	"cash-flow-financial/internal/managers/keymanager"
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/managers/mailmanager"
+	"cash-flow-financial/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type UserService struct {
+	queries   *db.Queries
+	dbManager dbmanager.IDBManager
+	mailer    mailmanager.IMailer
+	logger    *loggermanager.Logger
+	keyring   keymanager.IKeyring
+	dashboard models.DashboardConfig
+
+	// dummyPasswordHash is compared against when the email is unknown, so a failed login takes
+	// as long whether or not the account exists
+	dummyPasswordHash []byte
+}
+
+func NewUserService(queries *db.Queries, dbManager dbmanager.IDBManager, keyring keymanager.IKeyring, mailer mailmanager.IMailer, logger *loggermanager.Logger, config *models.Config) IUserService {
+	dummyPasswordHash, err := bcrypt.GenerateFromPassword([]byte(generateToken("")), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return &UserService{
+		queries:           queries,
+		dbManager:         dbManager,
+		mailer:            mailer,
+		logger:            logger,
+		keyring:           keyring,
+		dashboard:         config.Dashboard,
+		dummyPasswordHash: dummyPasswordHash,
+	}
+}
+
+// InviteUser creates the user and mails them a link to set a password. Inviting an address that
+// was invited before or removed starts its invitation over; an active user cannot be invited again.
+func (us *UserService) InviteUser(ctx context.Context, merchantID string, actor *models.DashboardUser, req models.InviteUserRequest) (*models.InviteUserResponse, error) {
+	role := db.DashboardRole(req.Role)
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+
+	us.logger.Info("Inviting dashboard user",
+		zap.String("merchant_id", merchantID),
+		zap.String("email", req.Email),
+		zap.String("role", req.Role),
+		zap.String("actor", actorName(actor)))
+
+	merchant, err := us.queries.GetMerchantByMerchantID(ctx, merchantID)
+	if err != nil {
+		us.logger.Error("Failed to get merchant for invitation", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("merchant not found")
+	}
+	if err := us.authorizeOwnerChange(ctx, us.queries, merchant, actor, req.Role); err != nil {
+		return nil, err
+	}
+
+	token := generateToken(inviteTokenPrefix)
+	expiresAt := time.Now().Add(us.dashboard.InviteTTL)
+	tokenHash := sql.NullString{String: hashToken(token, us.keyring), Valid: true}
+
+	existing, err := us.queries.GetMerchantUserByEmail(ctx, req.Email)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			us.logger.Error("Failed to look up dashboard user", zap.String("merchant_id", merchantID), zap.Error(err))
+			return nil, fmt.Errorf("failed to look up user: %w", err)
+		}
+		existing = nil
+	}
+
+	var user *db.MerchantUser
+	if existing != nil {
+		if existing.MerchantID != merchant.ID || existing.Status == db.MerchantUserStatusActive {
+			us.logger.Warn("Invitation for an existing user", zap.String("merchant_id", merchantID), zap.String("email", req.Email))
+			return nil, ErrUserExists
+		}
+		if err := us.authorizeOwnerChange(ctx, us.queries, merchant, actor, string(existing.Role)); err != nil {
+			return nil, err
+		}
+		user, err = us.queries.RefreshMerchantUserInvite(ctx, &db.RefreshMerchantUserInviteParams{
+			ID:              existing.ID,
+			Name:            req.Name,
+			Role:            role,
+			InviteTokenHash: tokenHash,
+			InviteExpiresAt: sql.NullTime{Time: expiresAt, Valid: true},
+			InvitedBy:       actorName(actor),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// Accepted between the lookup and the update
+			return nil, ErrUserExists
+		}
+	} else {
+		user, err = us.queries.CreateMerchantUser(ctx, &db.CreateMerchantUserParams{
+			MerchantID:      merchant.ID,
+			Email:           req.Email,
+			Name:            req.Name,
+			Role:            role,
+			InviteTokenHash: tokenHash,
+			InviteExpiresAt: sql.NullTime{Time: expiresAt, Valid: true},
+			InvitedBy:       actorName(actor),
+		})
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, ErrUserExists
+		}
+	}
+	if err != nil {
+		us.logger.Error("Failed to store dashboard user invitation", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to invite user: %w", err)
+	}
+
+	response := &models.InviteUserResponse{
+		Status:          true,
+		User:            toDashboardUser(user),
+		InviteExpiresAt: expiresAt,
+		Message:         "Invitation sent",
+	}
+	if err := us.sendInvite(ctx, merchant, user, token); err != nil {
+		us.logger.Error("Failed to send invitation email", zap.String("merchant_id", merchantID), zap.String("email", user.Email), zap.Error(err))
+		response.Message = "User invited, but the invitation email could not be sent; invite them again"
+	}
+
+	us.logger.Info("Dashboard user invited",
+		zap.String("merchant_id", merchantID),
+		zap.String("user_id", user.ID.String()),
+		zap.String("role", string(user.Role)))
+
+	return response, nil
+}
+
+func (us *UserService) ListUsers(ctx context.Context, merchantID string) (*models.ListUsersResponse, error) {
+	merchant, err := us.queries.GetMerchantByMerchantID(ctx, merchantID)
+	if err != nil {
+		us.logger.Error("Failed to get merchant for user list", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("merchant not found")
+	}
+
+	users, err := us.queries.ListMerchantUsers(ctx, merchant.ID)
+	if err != nil {
+		us.logger.Error("Failed to list dashboard users", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	response := &models.ListUsersResponse{
+		Status:  true,
+		Users:   make([]models.DashboardUser, 0, len(users)),
+		Message: "Users retrieved successfully",
+	}
+	for _, user := range users {
+		response.Users = append(response.Users, toDashboardUser(user))
+	}
+	return response, nil
+}
+
+// UpdateUserRole changes a user's role. It takes effect on the user's next request, since sessions
+// resolve the role each time.
+func (us *UserService) UpdateUserRole(ctx context.Context, merchantID string, actor *models.DashboardUser, userID, role string) (*models.UserResponse, error) {
+	newRole := db.DashboardRole(role)
+	if !newRole.Valid() {
+		return nil, ErrInvalidRole
+	}
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var user *db.MerchantUser
+	err = us.withMerchantUser(ctx, merchantID, id, func(qtx *db.Queries, merchant *db.Merchant, current *db.MerchantUser) error {
+		if err := us.authorizeOwnerChange(ctx, qtx, merchant, actor, string(current.Role), role); err != nil {
+			return err
+		}
+		if current.Role == db.DashboardRoleOwner && newRole != db.DashboardRoleOwner {
+			if err := us.checkNotLastOwner(ctx, qtx, merchant, current); err != nil {
+				return err
+			}
+		}
+
+		user, err = qtx.UpdateMerchantUserRole(ctx, &db.UpdateMerchantUserRoleParams{
+			ID:         current.ID,
+			MerchantID: merchant.ID,
+			Role:       newRole,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update user role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		us.logRejected("Dashboard user role change", merchantID, userID, err)
+		return nil, err
+	}
+
+	us.logger.Info("Dashboard user role changed",
+		zap.String("merchant_id", merchantID),
+		zap.String("user_id", userID),
+		zap.String("role", role),
+		zap.String("actor", actorName(actor)))
+
+	return &models.UserResponse{
+		Status:  true,
+		User:    toDashboardUser(user),
+		Message: "User role updated successfully",
+	}, nil
+}
+
+// RemoveUser disables the user and ends their sessions. The user is kept so the audit fields that
+// name them stay meaningful; inviting the address again reinstates it.
+func (us *UserService) RemoveUser(ctx context.Context, merchantID string, actor *models.DashboardUser, userID string) (*models.UserResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var user *db.MerchantUser
+	err = us.withMerchantUser(ctx, merchantID, id, func(qtx *db.Queries, merchant *db.Merchant, current *db.MerchantUser) error {
+		if err := us.authorizeOwnerChange(ctx, qtx, merchant, actor, string(current.Role)); err != nil {
+			return err
+		}
+		if current.Role == db.DashboardRoleOwner {
+			if err := us.checkNotLastOwner(ctx, qtx, merchant, current); err != nil {
+				return err
+			}
+		}
+
+		user, err = qtx.DisableMerchantUser(ctx, &db.DisableMerchantUserParams{
+			ID:         current.ID,
+			MerchantID: merchant.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to disable user: %w", err)
+		}
+		if err := qtx.RevokeUserSessionsByUserID(ctx, current.ID); err != nil {
+			return fmt.Errorf("failed to revoke user sessions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		us.logRejected("Dashboard user removal", merchantID, userID, err)
+		return nil, err
+	}
+
+	us.logger.Info("Dashboard user removed",
+		zap.String("merchant_id", merchantID),
+		zap.String("user_id", userID),
+		zap.String("actor", actorName(actor)))
+
+	return &models.UserResponse{
+		Status:  true,
+		User:    toDashboardUser(user),
+		Message: "User removed successfully",
+	}, nil
+}
+
+// AcceptInvite sets the invited user's password and activates them. The user then signs in with
+// Login; accepting does not start a session.
+func (us *UserService) AcceptInvite(ctx context.Context, req models.AcceptInviteRequest) (*models.UserResponse, error) {
+	invited, err := us.queries.GetMerchantUserByInviteToken(ctx, sql.NullString{String: hashToken(req.Token, us.keyring), Valid: true})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			us.logger.Warn("Rejected invitation token")
+			return nil, ErrInvalidInvite
+		}
+		us.logger.Error("Failed to look up invitation", zap.Error(err))
+		return nil, fmt.Errorf("failed to look up invitation: %w", err)
+	}
+	if !invited.InviteExpiresAt.Valid || !time.Now().Before(invited.InviteExpiresAt.Time) {
+		us.logger.Warn("Expired invitation used", zap.String("user_id", invited.ID.String()))
+		return nil, ErrInviteExpired
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	name := invited.Name
+	if req.Name != "" {
+		name = req.Name
+	}
+
+	user, err := us.queries.AcceptMerchantUserInvite(ctx, &db.AcceptMerchantUserInviteParams{
+		ID:           invited.ID,
+		Name:         name,
+		PasswordHash: sql.NullString{String: string(passwordHash), Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidInvite
+		}
+		us.logger.Error("Failed to accept invitation", zap.String("user_id", invited.ID.String()), zap.Error(err))
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	us.logger.Info("Dashboard invitation accepted", zap.String("user_id", user.ID.String()), zap.String("email", user.Email))
+
+	return &models.UserResponse{
+		Status:  true,
+		User:    toDashboardUser(user),
+		Message: "Invitation accepted; sign in with your email and password",
+	}, nil
+}
+
+// Login checks the password, and the TOTP code once two-factor authentication is enabled, and
+// starts a session. Unknown emails, users who have not accepted their invitation, removed users and
+// users locked out after too many failed attempts all get ErrInvalidCredentials.
+func (us *UserService) Login(ctx context.Context, req models.LoginRequest, ipAddress, userAgent string) (*models.LoginResponse, error) {
+	user, err := us.queries.GetMerchantUserByEmail(ctx, req.Email)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			us.logger.Error("Failed to look up dashboard user", zap.Error(err))
+			return nil, fmt.Errorf("failed to look up user: %w", err)
+		}
+		user = nil
+	}
+
+	passwordHash := us.dummyPasswordHash
+	if user != nil && user.Status == db.MerchantUserStatusActive && user.PasswordHash.Valid {
+		passwordHash = []byte(user.PasswordHash.String)
+	}
+	// A locked user still pays for the hash comparison so the response does not give the lock away
+	passwordErr := bcrypt.CompareHashAndPassword(passwordHash, []byte(req.Password))
+	if user != nil && user.LockedUntil.Valid && user.LockedUntil.Time.After(time.Now()) {
+		us.logger.Warn("Dashboard login refused: user is locked out",
+			zap.String("user_id", user.ID.String()),
+			zap.Time("locked_until", user.LockedUntil.Time),
+			zap.String("ip_address", ipAddress))
+		return nil, ErrInvalidCredentials
+	}
+	if passwordErr != nil || user == nil || user.Status != db.MerchantUserStatusActive {
+		us.logger.Warn("Dashboard login failed", zap.String("email", req.Email), zap.String("ip_address", ipAddress))
+		if user != nil && user.Status == db.MerchantUserStatusActive {
+			us.recordLoginFailure(ctx, user, ipAddress)
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.TotpEnabledAt.Valid {
+		if req.TOTPCode == "" {
+			return nil, ErrTOTPRequired
+		}
+		if err := us.checkTOTP(ctx, user, req.TOTPCode); err != nil {
+			us.logger.Warn("Dashboard login failed: invalid two-factor code", zap.String("user_id", user.ID.String()), zap.String("ip_address", ipAddress))
+			if errors.Is(err, ErrInvalidTOTPCode) {
+				us.recordLoginFailure(ctx, user, ipAddress)
+			}
+			return nil, err
+		}
+	}
+
+	merchant, err := us.queries.GetMerchant(ctx, user.MerchantID)
+	if err != nil {
+		us.logger.Error("Failed to get merchant for login", zap.String("user_id", user.ID.String()), zap.Error(err))
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+	if merchant.Status.Valid && merchant.Status.MerchantStatus == db.MerchantStatusInactive {
+		us.logger.Warn("Dashboard login refused: merchant is inactive", zap.String("user_id", user.ID.String()))
+		return nil, ErrMerchantInactive
+	}
+
+	token := generateToken(sessionTokenPrefix)
+	session, err := us.queries.CreateUserSession(ctx, &db.CreateUserSessionParams{
+		UserID:    user.ID,
+		TokenHash: hashToken(token, us.keyring),
+		IpAddress: sql.NullString{String: ipAddress, Valid: ipAddress != ""},
+		UserAgent: sql.NullString{String: userAgent, Valid: userAgent != ""},
+		ExpiresAt: time.Now().Add(us.dashboard.SessionTTL),
+	})
+	if err != nil {
+		us.logger.Error("Failed to create session", zap.String("user_id", user.ID.String()), zap.Error(err))
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	if err := us.queries.TouchMerchantUserLogin(ctx, user.ID); err != nil {
+		us.logger.Warn("Failed to record login time", zap.String("user_id", user.ID.String()), zap.Error(err))
+	}
+	dashboardUser := toDashboardUser(user)
+	dashboardUser.LastLoginAt = &session.CreatedAt
+
+	us.logger.Info("Dashboard user signed in",
+		zap.String("user_id", user.ID.String()),
+		zap.String("session_id", session.ID.String()),
+		zap.String("ip_address", ipAddress))
+
+	return &models.LoginResponse{
+		Status:       true,
+		SessionToken: token,
+		ExpiresAt:    session.ExpiresAt,
+		User:         dashboardUser,
+		Message:      "Signed in successfully",
+	}, nil
+}
+
+func (us *UserService) Logout(ctx context.Context, sessionID string) error {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return ErrInvalidSession
+	}
+	if err := us.queries.RevokeUserSession(ctx, id); err != nil {
+		us.logger.Error("Failed to revoke session", zap.String("session_id", sessionID), zap.Error(err))
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	us.logger.Info("Dashboard user signed out", zap.String("session_id", sessionID))
+	return nil
+}
+
+// Authenticate resolves a session token to its user and merchant. Sessions of removed users and of
+// inactive merchants no longer resolve.
+func (us *UserService) Authenticate(ctx context.Context, sessionToken string) (*models.DashboardPrincipal, error) {
+	session, err := us.queries.GetUserSessionByTokenHash(ctx, hashToken(sessionToken, us.keyring))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidSession
+		}
+		us.logger.Error("Failed to look up session", zap.Error(err))
+		return nil, fmt.Errorf("failed to look up session: %w", err)
+	}
+
+	if err := us.queries.TouchUserSession(ctx, session.SessionID); err != nil {
+		us.logger.Warn("Failed to record session usage", zap.String("session_id", session.SessionID.String()), zap.Error(err))
+	}
+
+	return toPrincipal(session), nil
+}
+
+// BeginTOTPEnrollment stores a new secret for the user and returns it. Two-factor authentication
+// is only enforced once ConfirmTOTP has seen a code generated from it.
+func (us *UserService) BeginTOTPEnrollment(ctx context.Context, userID string) (*models.TOTPEnrollmentResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	// TOTP secrets are kept encrypted at rest like webhook secrets
+	secret := generateTOTPSecret()
+	encryptedSecret, err := us.keyring.Encrypt(secret)
+	if err != nil {
+		us.logger.Error("Failed to encrypt TOTP secret", zap.String("user_id", userID), zap.Error(err))
+		return nil, fmt.Errorf("failed to start two-factor enrollment: %w", err)
+	}
+	user, err := us.queries.SetMerchantUserTOTPSecret(ctx, &db.SetMerchantUserTOTPSecretParams{
+		ID:         id,
+		TotpSecret: sql.NullString{String: encryptedSecret, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTOTPAlreadyEnabled
+		}
+		us.logger.Error("Failed to store TOTP secret", zap.String("user_id", userID), zap.Error(err))
+		return nil, fmt.Errorf("failed to start two-factor enrollment: %w", err)
+	}
+
+	us.logger.Info("TOTP enrollment started", zap.String("user_id", userID))
+
+	return &models.TOTPEnrollmentResponse{
+		Status:  true,
+		Secret:  secret,
+		URI:     totpURI(user.Email, secret),
+		Message: "Scan the code, then confirm it with a code from the app",
+	}, nil
+}
+
+func (us *UserService) ConfirmTOTP(ctx context.Context, userID, code string) (*models.UserResponse, error) {
+	user, err := us.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TotpEnabledAt.Valid {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if err := us.checkTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	user, err = us.queries.EnableMerchantUserTOTP(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTOTPAlreadyEnabled
+		}
+		us.logger.Error("Failed to enable TOTP", zap.String("user_id", userID), zap.Error(err))
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	us.logger.Info("TOTP enabled", zap.String("user_id", userID))
+
+	return &models.UserResponse{
+		Status:  true,
+		User:    toDashboardUser(user),
+		Message: "Two-factor authentication enabled",
+	}, nil
+}
+
+// DisableTOTP turns two-factor authentication off. It asks for a current code so a stolen session
+// alone cannot remove the second factor.
+func (us *UserService) DisableTOTP(ctx context.Context, userID, code string) (*models.UserResponse, error) {
+	user, err := us.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TotpEnabledAt.Valid {
+		return nil, ErrTOTPNotEnrolled
+	}
+	if err := us.checkTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	user, err = us.queries.DisableMerchantUserTOTP(ctx, user.ID)
+	if err != nil {
+		us.logger.Error("Failed to disable TOTP", zap.String("user_id", userID), zap.Error(err))
+		return nil, fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	us.logger.Info("TOTP disabled", zap.String("user_id", userID))
+
+	return &models.UserResponse{
+		Status:  true,
+		User:    toDashboardUser(user),
+		Message: "Two-factor authentication disabled",
+	}, nil
+}
+
+func (us *UserService) getUser(ctx context.Context, userID string) (*db.MerchantUser, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	user, err := us.queries.GetMerchantUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		us.logger.Error("Failed to get dashboard user", zap.String("user_id", userID), zap.Error(err))
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// checkTOTP validates code against the user's stored secret, enrolled or pending, and records its
+// time step so the same code, or an older one, is refused afterwards
+func (us *UserService) checkTOTP(ctx context.Context, user *db.MerchantUser, code string) error {
+	if !user.TotpSecret.Valid {
+		return ErrTOTPNotEnrolled
+	}
+	secret, err := us.keyring.Decrypt(user.TotpSecret.String)
+	if err != nil {
+		us.logger.Error("Failed to decrypt TOTP secret", zap.String("user_id", user.ID.String()), zap.Error(err))
+		return fmt.Errorf("failed to read two-factor secret: %w", err)
+	}
+	step, ok := validateTOTP(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTOTPCode
+	}
+	used, err := us.queries.UseMerchantUserTOTPStep(ctx, &db.UseMerchantUserTOTPStepParams{Step: step, ID: user.ID})
+	if err != nil {
+		us.logger.Error("Failed to record TOTP step", zap.String("user_id", user.ID.String()), zap.Error(err))
+		return fmt.Errorf("failed to record two-factor code: %w", err)
+	}
+	if used == 0 {
+		us.logger.Warn("Refused a reused TOTP code", zap.String("user_id", user.ID.String()))
+		return ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed sign-in against user and locks them out for the configured
+// period once they reach the limit
+func (us *UserService) recordLoginFailure(ctx context.Context, user *db.MerchantUser, ipAddress string) {
+	lockedUntil, err := us.queries.RecordMerchantUserLoginFailure(ctx, &db.RecordMerchantUserLoginFailureParams{
+		MaxAttempts: int32(us.dashboard.LoginMaxAttempts),
+		LockedUntil: time.Now().Add(us.dashboard.LoginLockout),
+		ID:          user.ID,
+	})
+	if err != nil {
+		us.logger.Error("Failed to record failed login", zap.String("user_id", user.ID.String()), zap.Error(err))
+		return
+	}
+	if lockedUntil.Valid {
+		us.logger.Warn("Dashboard user locked out after repeated failed sign-ins",
+			zap.String("user_id", user.ID.String()),
+			zap.Time("locked_until", lockedUntil.Time),
+			zap.String("ip_address", ipAddress))
+	}
+}
+
+// withMerchantUser runs fn in a transaction holding the merchant's row lock, so concurrent role
+// changes cannot both pass the last-owner check
+func (us *UserService) withMerchantUser(ctx context.Context, merchantID string, userID uuid.UUID, fn func(qtx *db.Queries, merchant *db.Merchant, user *db.MerchantUser) error) error {
+	return us.dbManager.WithTransaction(ctx, func(tx *sql.Tx) error {
+		qtx := us.queries.WithTx(tx)
+
+		merchant, err := qtx.LockMerchantByMerchantID(ctx, merchantID)
+		if err != nil {
+			return fmt.Errorf("failed to lock merchant: %w", err)
+		}
+
+		user, err := qtx.GetMerchantUser(ctx, &db.GetMerchantUserParams{
+			ID:         userID,
+			MerchantID: merchant.ID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		return fn(qtx, merchant, user)
+	})
+}
+
+// authorizeOwnerChange applies checkOwnerChange, counting the merchant's active owners when the change is
+// made with an API key
+func (us *UserService) authorizeOwnerChange(ctx context.Context, q *db.Queries, merchant *db.Merchant, actor *models.DashboardUser, roles ...string) error {
+	var owners int64
+	if actor == nil && slices.Contains(roles, models.RoleOwner) {
+		var err error
+		owners, err = q.CountActiveMerchantOwners(ctx, merchant.ID)
+		if err != nil {
+			return fmt.Errorf("failed to count owners: %w", err)
+		}
+	}
+	return checkOwnerChange(actor, owners, roles...)
+}
+
+// checkNotLastOwner refuses to take the owner role away from the merchant's only active owner
+func (us *UserService) checkNotLastOwner(ctx context.Context, qtx *db.Queries, merchant *db.Merchant, user *db.MerchantUser) error {
+	if user.Status != db.MerchantUserStatusActive {
+		return nil
+	}
+	owners, err := qtx.CountActiveMerchantOwners(ctx, merchant.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count owners: %w", err)
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func (us *UserService) logRejected(action, merchantID, userID string, err error) {
+	if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrOwnerRequired) || errors.Is(err, ErrLastOwner) {
+		us.logger.Warn(action+" rejected", zap.String("merchant_id", merchantID), zap.String("user_id", userID), zap.Error(err))
+		return
+	}
+	us.logger.Error(action+" failed", zap.String("merchant_id", merchantID), zap.String("user_id", userID), zap.Error(err))
+}
+
+// sendInvite mails the invitation link to the user
+func (us *UserService) sendInvite(ctx context.Context, merchant *db.Merchant, user *db.MerchantUser, token string) error {
+	link, err := inviteLink(us.dashboard.URL, token)
+	if err != nil {
+		return err
+	}
+
+	invitedBy := ""
+	if user.InvitedBy != apiKeyActor {
+		invitedBy = " by " + user.InvitedBy
+	}
+
+	body := fmt.Sprintf(`Hello,
+
+You have been invited%s to the Cash Flow dashboard of %s as %s. Open this link to choose a
+password and activate your account:
+
+%s
+
+The link expires in %s. If you were not expecting this invitation, you can ignore this email.
+`, invitedBy, merchant.Name, user.Role, link, us.dashboard.InviteTTL)
+
+	return us.mailer.Send(ctx, mailmanager.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("You have been invited to %s on Cash Flow", merchant.Name),
+		Body:    body,
+	})
+}
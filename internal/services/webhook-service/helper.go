@@ -0,0 +1,120 @@
+package webhookservice
+
+import (
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/internal/services/callback"
+)
+
+const testPaymentIntentID = "PI-TEST00000000"
+
+func toWebhookDelivery(delivery *db.WebhookDelivery, attempts []*db.WebhookDeliveryAttempt) models.WebhookDelivery {
+	result := models.WebhookDelivery{
+		ID:              delivery.ID.String(),
+		PaymentIntentID: delivery.PaymentIntentID,
+		EventType:       delivery.EventType,
+		URL:             delivery.Url,
+		Status:          string(delivery.Status),
+		Attempts:        delivery.Attempts,
+		LastError:       delivery.LastError.String,
+		CreatedAt:       delivery.CreatedAt,
+		Payload:         delivery.Payload,
+		AttemptLog:      make([]models.WebhookDeliveryAttempt, 0, len(attempts)),
+	}
+	if delivery.Status == db.WebhookDeliveryStatusPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		result.NextAttemptAt = &nextAttemptAt
+	}
+	if delivery.LastResponseCode.Valid {
+		code := delivery.LastResponseCode.Int32
+		result.LastResponseCode = &code
+	}
+	if delivery.DeliveredAt.Valid {
+		deliveredAt := delivery.DeliveredAt.Time
+		result.DeliveredAt = &deliveredAt
+	}
+	if delivery.EndpointID.Valid {
+		result.EndpointID = delivery.EndpointID.UUID.String()
+	}
+	if delivery.ResendOf.Valid {
+		result.ResendOf = delivery.ResendOf.UUID.String()
+	}
+
+	for _, attempt := range attempts {
+		entry := models.WebhookDeliveryAttempt{
+			AttemptNumber: attempt.AttemptNumber,
+			LatencyMs:     attempt.LatencyMs,
+			ResponseBody:  attempt.ResponseBody.String,
+			AckMessage:    attempt.AckMessage.String,
+			Error:         attempt.Error.String,
+			AttemptedAt:   attempt.AttemptedAt,
+		}
+		if attempt.ResponseCode.Valid {
+			code := attempt.ResponseCode.Int32
+			entry.ResponseCode = &code
+		}
+		if attempt.Acknowledged.Valid {
+			acknowledged := attempt.Acknowledged.Bool
+			entry.Acknowledged = &acknowledged
+		}
+		result.AttemptLog = append(result.AttemptLog, entry)
+	}
+
+	return result
+}
+
+// testCallbackRequest builds a callback with the same shape as a real one, marked by status "test"
+func testCallbackRequest(merchantID string, now time.Time) callback.CallbackRequest {
+	return callback.CallbackRequest{
+		PaymentIntentID: testPaymentIntentID,
+		MerchantID:      merchantID,
+		Mode:            models.ModeTest,
+		Amount:          1.00,
+		Currency:        "USD",
+		Status:          "test",
+		FeeAmount:       "0.01",
+		ProcessedAt:     now.Format("2006-01-02T15:04:05Z07:00"),
+		Nonce:           "test_" + now.Format("20060102150405"),
+		Metadata:        map[string]interface{}{"test": true},
+	}
+}
+
+func toWebhookEndpoint(endpoint *db.WebhookEndpoint) models.WebhookEndpoint {
+	response := models.WebhookEndpoint{
+		ID:                  endpoint.ID.String(),
+		URL:                 endpoint.Url,
+		EventTypes:          endpoint.EventTypes,
+		Enabled:             endpoint.Enabled,
+		Description:         endpoint.Description.String,
+		ConsecutiveFailures: endpoint.ConsecutiveFailures,
+		CircuitOpen:         endpoint.CircuitOpenedAt.Valid,
+		DisabledReason:      endpoint.DisabledReason.String,
+		CreatedAt:           endpoint.CreatedAt,
+		UpdatedAt:           endpoint.UpdatedAt,
+	}
+	if endpoint.FailingSince.Valid {
+		response.FailingSince = &endpoint.FailingSince.Time
+	}
+	if endpoint.DisabledAt.Valid {
+		response.DisabledAt = &endpoint.DisabledAt.Time
+	}
+	return response
+}
+
+// normalizeEventTypes drops duplicates and collapses any list containing "*" to just "*"
+func normalizeEventTypes(eventTypes []string) []string {
+	seen := make(map[string]bool, len(eventTypes))
+	normalized := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if eventType == callback.AllEvents {
+			return []string{callback.AllEvents}
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			normalized = append(normalized, eventType)
+		}
+	}
+	return normalized
+}
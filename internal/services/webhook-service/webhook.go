@@ -0,0 +1,25 @@
+package webhookservice
+
+import (
+	"context"
+	"errors"
+
+	"cash-flow-financial/internal/models"
+)
+
+var (
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+)
+
+type IWebhookService interface {
+	ListDeliveries(ctx context.Context, merchantID string, filter models.WebhookDeliveryFilter) (*models.ListWebhookDeliveriesResponse, error)
+	GetDelivery(ctx context.Context, merchantID, deliveryID string) (*models.WebhookDeliveryResponse, error)
+	ResendDelivery(ctx context.Context, merchantID, deliveryID string) (*models.WebhookDeliveryResponse, error)
+	SendTestEvent(ctx context.Context, merchantID, callbackURL string) (*models.SendTestWebhookResponse, error)
+
+	CreateEndpoint(ctx context.Context, merchantID string, req models.CreateWebhookEndpointRequest) (*models.WebhookEndpointResponse, error)
+	ListEndpoints(ctx context.Context, merchantID string) (*models.ListWebhookEndpointsResponse, error)
+	UpdateEndpoint(ctx context.Context, merchantID, endpointID string, req models.UpdateWebhookEndpointRequest) (*models.WebhookEndpointResponse, error)
+	DeleteEndpoint(ctx context.Context, merchantID, endpointID string) error
+}
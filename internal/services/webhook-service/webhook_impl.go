@@ -0,0 +1,309 @@
+package webhookservice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/internal/services/callback"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WebhookService struct {
+	queries     *db.Queries
+	logger      *loggermanager.Logger
+	callbackSvc callback.ICallbackService
+}
+
+func NewWebhookService(queries *db.Queries, logger *loggermanager.Logger, callbackSvc callback.ICallbackService) IWebhookService {
+	return &WebhookService{
+		queries:     queries,
+		logger:      logger,
+		callbackSvc: callbackSvc,
+	}
+}
+
+func (ws *WebhookService) ListDeliveries(ctx context.Context, merchantID string, filter models.WebhookDeliveryFilter) (*models.ListWebhookDeliveriesResponse, error) {
+	ws.logger.Info("Listing webhook deliveries",
+		zap.String("merchant_id", merchantID),
+		zap.String("payment_intent_id", filter.PaymentIntentID),
+		zap.Time("from", filter.From),
+		zap.Time("to", filter.To))
+
+	params := &db.ListWebhookDeliveriesParams{
+		MerchantID:      merchantID,
+		PaymentIntentID: sql.NullString{String: filter.PaymentIntentID, Valid: filter.PaymentIntentID != ""},
+		CreatedFrom:     filter.From,
+		CreatedTo:       filter.To,
+		RowLimit:        int32(filter.Limit),
+	}
+	if filter.Status != "" {
+		params.Status = db.NullWebhookDeliveryStatus{WebhookDeliveryStatus: db.WebhookDeliveryStatus(filter.Status), Valid: true}
+	}
+
+	deliveries, err := ws.queries.ListWebhookDeliveries(ctx, params)
+	if err != nil {
+		ws.logger.Error("Failed to list webhook deliveries", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	attempts, err := ws.loadAttempts(ctx, deliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.ListWebhookDeliveriesResponse{
+		Status:     true,
+		Deliveries: make([]models.WebhookDelivery, 0, len(deliveries)),
+		Message:    "Webhook deliveries retrieved successfully",
+	}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, toWebhookDelivery(delivery, attempts[delivery.ID]))
+	}
+	return response, nil
+}
+
+func (ws *WebhookService) GetDelivery(ctx context.Context, merchantID, deliveryID string) (*models.WebhookDeliveryResponse, error) {
+	id, err := uuid.Parse(deliveryID)
+	if err != nil {
+		return nil, ErrDeliveryNotFound
+	}
+
+	delivery, err := ws.queries.GetWebhookDelivery(ctx, &db.GetWebhookDeliveryParams{ID: id, MerchantID: merchantID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeliveryNotFound
+		}
+		ws.logger.Error("Failed to get webhook delivery", zap.String("delivery_id", deliveryID), zap.Error(err))
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	attempts, err := ws.loadAttempts(ctx, []*db.WebhookDelivery{delivery})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookDeliveryResponse{
+		Status:   true,
+		Delivery: toWebhookDelivery(delivery, attempts[delivery.ID]),
+		Message:  "Webhook delivery retrieved successfully",
+	}, nil
+}
+
+// ResendDelivery queues a copy of a delivery with a fresh retry horizon; the original keeps its history
+func (ws *WebhookService) ResendDelivery(ctx context.Context, merchantID, deliveryID string) (*models.WebhookDeliveryResponse, error) {
+	id, err := uuid.Parse(deliveryID)
+	if err != nil {
+		return nil, ErrDeliveryNotFound
+	}
+
+	delivery, err := ws.queries.ResendWebhookDelivery(ctx, &db.ResendWebhookDeliveryParams{ID: id, MerchantID: merchantID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeliveryNotFound
+		}
+		ws.logger.Error("Failed to resend webhook delivery", zap.String("delivery_id", deliveryID), zap.Error(err))
+		return nil, fmt.Errorf("failed to resend webhook delivery: %w", err)
+	}
+
+	ws.logger.Info("Webhook delivery queued for resend",
+		zap.String("merchant_id", merchantID),
+		zap.String("resend_of", deliveryID),
+		zap.String("delivery_id", delivery.ID.String()))
+
+	return &models.WebhookDeliveryResponse{
+		Status:   true,
+		Delivery: toWebhookDelivery(delivery, nil),
+		Message:  "Webhook delivery queued for resend",
+	}, nil
+}
+
+// SendTestEvent posts a synthetic, signed callback to callbackURL once and reports the outcome.
+// Test events are not stored and never retried.
+func (ws *WebhookService) SendTestEvent(ctx context.Context, merchantID, callbackURL string) (*models.SendTestWebhookResponse, error) {
+	body, err := json.Marshal(testCallbackRequest(merchantID, time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal test callback: %w", err)
+	}
+
+	result := ws.callbackSvc.SendCallback(ctx, merchantID, callbackURL, body)
+
+	ws.logger.Info("Test webhook sent",
+		zap.String("merchant_id", merchantID),
+		zap.String("callback_url", callbackURL),
+		zap.Int("status_code", result.StatusCode),
+		zap.Bool("delivered", result.Err == nil))
+
+	response := &models.SendTestWebhookResponse{
+		Status:       true,
+		Delivered:    result.Err == nil,
+		ResponseCode: result.StatusCode,
+		LatencyMs:    result.Latency.Milliseconds(),
+		ResponseBody: result.ResponseBody,
+		Message:      "Test webhook delivered",
+	}
+	if result.Ack != nil {
+		response.Acknowledged = &result.Ack.Status
+		response.AckMessage = result.Ack.Message
+	}
+	if result.Err != nil {
+		response.Error = result.Err.Error()
+		response.Message = "Test webhook was not accepted by the endpoint"
+	}
+	return response, nil
+}
+
+// loadAttempts fetches the attempt log of every delivery in one query, keyed by delivery ID
+func (ws *WebhookService) loadAttempts(ctx context.Context, deliveries []*db.WebhookDelivery) (map[uuid.UUID][]*db.WebhookDeliveryAttempt, error) {
+	byDelivery := make(map[uuid.UUID][]*db.WebhookDeliveryAttempt, len(deliveries))
+	if len(deliveries) == 0 {
+		return byDelivery, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.ID)
+	}
+
+	attempts, err := ws.queries.ListWebhookDeliveryAttempts(ctx, ids)
+	if err != nil {
+		ws.logger.Error("Failed to list webhook delivery attempts", zap.Error(err))
+		return nil, fmt.Errorf("failed to list webhook delivery attempts: %w", err)
+	}
+	for _, attempt := range attempts {
+		byDelivery[attempt.DeliveryID] = append(byDelivery[attempt.DeliveryID], attempt)
+	}
+	return byDelivery, nil
+}
+
+func (ws *WebhookService) CreateEndpoint(ctx context.Context, merchantID string, req models.CreateWebhookEndpointRequest) (*models.WebhookEndpointResponse, error) {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	endpoint, err := ws.queries.CreateWebhookEndpoint(ctx, &db.CreateWebhookEndpointParams{
+		MerchantID:  merchantID,
+		Url:         req.URL,
+		EventTypes:  normalizeEventTypes(req.EventTypes),
+		Enabled:     enabled,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+	})
+	if err != nil {
+		ws.logger.Error("Failed to create webhook endpoint", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	ws.logger.Info("Webhook endpoint created",
+		zap.String("merchant_id", merchantID),
+		zap.String("endpoint_id", endpoint.ID.String()),
+		zap.Strings("event_types", endpoint.EventTypes))
+
+	return &models.WebhookEndpointResponse{
+		Status:   true,
+		Endpoint: toWebhookEndpoint(endpoint),
+		Message:  "Webhook endpoint created successfully",
+	}, nil
+}
+
+func (ws *WebhookService) ListEndpoints(ctx context.Context, merchantID string) (*models.ListWebhookEndpointsResponse, error) {
+	endpoints, err := ws.queries.ListWebhookEndpoints(ctx, merchantID)
+	if err != nil {
+		ws.logger.Error("Failed to list webhook endpoints", zap.String("merchant_id", merchantID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+
+	response := &models.ListWebhookEndpointsResponse{
+		Status:    true,
+		Endpoints: make([]models.WebhookEndpoint, 0, len(endpoints)),
+		Message:   "Webhook endpoints retrieved successfully",
+	}
+	for _, endpoint := range endpoints {
+		response.Endpoints = append(response.Endpoints, toWebhookEndpoint(endpoint))
+	}
+	return response, nil
+}
+
+func (ws *WebhookService) UpdateEndpoint(ctx context.Context, merchantID, endpointID string, req models.UpdateWebhookEndpointRequest) (*models.WebhookEndpointResponse, error) {
+	id, err := uuid.Parse(endpointID)
+	if err != nil {
+		return nil, ErrEndpointNotFound
+	}
+
+	current, err := ws.queries.GetWebhookEndpoint(ctx, &db.GetWebhookEndpointParams{ID: id, MerchantID: merchantID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEndpointNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	params := &db.UpdateWebhookEndpointParams{
+		ID:          current.ID,
+		MerchantID:  merchantID,
+		Url:         current.Url,
+		EventTypes:  current.EventTypes,
+		Enabled:     current.Enabled,
+		Description: current.Description,
+	}
+	if req.URL != nil {
+		params.Url = *req.URL
+	}
+	if req.EventTypes != nil {
+		params.EventTypes = normalizeEventTypes(req.EventTypes)
+	}
+	if req.Enabled != nil {
+		params.Enabled = *req.Enabled
+	}
+	if req.Description != nil {
+		params.Description = sql.NullString{String: *req.Description, Valid: *req.Description != ""}
+	}
+
+	endpoint, err := ws.queries.UpdateWebhookEndpoint(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEndpointNotFound
+		}
+		ws.logger.Error("Failed to update webhook endpoint", zap.String("endpoint_id", endpointID), zap.Error(err))
+		return nil, fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+
+	ws.logger.Info("Webhook endpoint updated",
+		zap.String("merchant_id", merchantID),
+		zap.String("endpoint_id", endpointID),
+		zap.Bool("enabled", endpoint.Enabled))
+
+	return &models.WebhookEndpointResponse{
+		Status:   true,
+		Endpoint: toWebhookEndpoint(endpoint),
+		Message:  "Webhook endpoint updated successfully",
+	}, nil
+}
+
+// DeleteEndpoint removes an endpoint; its past deliveries stay in the log with endpoint_id cleared
+func (ws *WebhookService) DeleteEndpoint(ctx context.Context, merchantID, endpointID string) error {
+	id, err := uuid.Parse(endpointID)
+	if err != nil {
+		return ErrEndpointNotFound
+	}
+
+	deleted, err := ws.queries.DeleteWebhookEndpoint(ctx, &db.DeleteWebhookEndpointParams{ID: id, MerchantID: merchantID})
+	if err != nil {
+		ws.logger.Error("Failed to delete webhook endpoint", zap.String("endpoint_id", endpointID), zap.Error(err))
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	if deleted == 0 {
+		return ErrEndpointNotFound
+	}
+
+	ws.logger.Info("Webhook endpoint deleted", zap.String("merchant_id", merchantID), zap.String("endpoint_id", endpointID))
+	return nil
+}
@@ -0,0 +1,121 @@
+// Package webhook signs and verifies Cash Flow merchant callbacks.
+//
+// Every callback carries a header of the form
+//
+//	Cashflow-Signature: t=1704450600,v1=5257a869...,v1=9f86d081...
+//
+// where t is the Unix time the callback was signed and each v1 is a hex HMAC-SHA256 of
+// "<t>.<raw request body>" under one of the merchant's signing secrets. While a secret is being
+// rotated, callbacks are signed with both the old and the new secret, so a receiver holding
+// either one accepts them.
+//
+// Merchants verify a callback with:
+//
+//	body, _ := io.ReadAll(r.Body)
+//	if err := webhook.Verify(body, r.Header.Get(webhook.SignatureHeader), secret, webhook.DefaultTolerance); err != nil {
+//		http.Error(w, "invalid signature", http.StatusBadRequest)
+//		return
+//	}
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "Cashflow-Signature"
+
+	// DefaultTolerance bounds how old a signed timestamp may be, which limits replays of captured callbacks
+	DefaultTolerance = 5 * time.Minute
+
+	signatureScheme = "v1"
+)
+
+var (
+	ErrInvalidHeader    = errors.New("webhook: malformed signature header")
+	ErrNoValidSignature = errors.New("webhook: no signature matches the secret")
+	ErrTooOld           = errors.New("webhook: timestamp outside the tolerance window")
+)
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<payload>" under secret
+func Sign(payload []byte, secret string, timestamp time.Time) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Header builds the Cashflow-Signature value with one v1 signature per secret
+func Header(payload []byte, secrets []string, timestamp time.Time) string {
+	parts := make([]string, 0, len(secrets)+1)
+	parts = append(parts, "t="+strconv.FormatInt(timestamp.Unix(), 10))
+	for _, secret := range secrets {
+		parts = append(parts, signatureScheme+"="+Sign(payload, secret, timestamp))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Verify checks that header carries a v1 signature of payload under secret and that its
+// timestamp is within tolerance of now. A tolerance of zero disables the timestamp check.
+func Verify(payload []byte, header, secret string, tolerance time.Duration) error {
+	return verifyAt(payload, header, secret, tolerance, time.Now())
+}
+
+func verifyAt(payload []byte, header, secret string, tolerance time.Duration, now time.Time) error {
+	timestamp, signatures, err := parseHeader(header)
+	if err != nil {
+		return err
+	}
+
+	if tolerance > 0 {
+		age := now.Sub(timestamp)
+		if age > tolerance || age < -tolerance {
+			return ErrTooOld
+		}
+	}
+
+	expected := []byte(Sign(payload, secret, timestamp))
+	for _, signature := range signatures {
+		if hmac.Equal(expected, []byte(signature)) {
+			return nil
+		}
+	}
+	return ErrNoValidSignature
+}
+
+func parseHeader(header string) (time.Time, []string, error) {
+	var (
+		timestamp  time.Time
+		signatures []string
+	)
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return time.Time{}, nil, ErrInvalidHeader
+		}
+
+		switch key {
+		case "t":
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return time.Time{}, nil, ErrInvalidHeader
+			}
+			timestamp = time.Unix(seconds, 0)
+		case signatureScheme:
+			signatures = append(signatures, value)
+		}
+	}
+
+	if timestamp.IsZero() || len(signatures) == 0 {
+		return time.Time{}, nil, ErrInvalidHeader
+	}
+	return timestamp, signatures, nil
+}
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	payload  = []byte(`{"payment_intent_id":"PI-ABC123","status":"success"}`)
+	signedAt = time.Unix(1704450600, 0)
+)
+
+func TestVerify_ValidSignature(t *testing.T) {
+	header := Header(payload, []string{"sk_current"}, signedAt)
+
+	assert.NoError(t, verifyAt(payload, header, "sk_current", DefaultTolerance, signedAt.Add(time.Minute)))
+}
+
+func TestVerify_EitherSecretDuringRotation(t *testing.T) {
+	header := Header(payload, []string{"sk_new", "sk_old"}, signedAt)
+
+	assert.NoError(t, verifyAt(payload, header, "sk_new", DefaultTolerance, signedAt))
+	assert.NoError(t, verifyAt(payload, header, "sk_old", DefaultTolerance, signedAt))
+	assert.ErrorIs(t, verifyAt(payload, header, "sk_other", DefaultTolerance, signedAt), ErrNoValidSignature)
+}
+
+func TestVerify_TamperedPayload(t *testing.T) {
+	header := Header(payload, []string{"sk_current"}, signedAt)
+
+	err := verifyAt([]byte(`{"payment_intent_id":"PI-ABC123","status":"failed"}`), header, "sk_current", DefaultTolerance, signedAt)
+	assert.ErrorIs(t, err, ErrNoValidSignature)
+}
+
+func TestVerify_RejectsReplayOutsideTolerance(t *testing.T) {
+	header := Header(payload, []string{"sk_current"}, signedAt)
+
+	assert.ErrorIs(t, verifyAt(payload, header, "sk_current", DefaultTolerance, signedAt.Add(10*time.Minute)), ErrTooOld)
+	assert.NoError(t, verifyAt(payload, header, "sk_current", 0, signedAt.Add(10*time.Minute)))
+}
+
+func TestVerify_MalformedHeader(t *testing.T) {
+	for _, header := range []string{"", "t=abc,v1=00", "v1=00", "t=1704450600", "garbage"} {
+		assert.ErrorIs(t, verifyAt(payload, header, "sk_current", DefaultTolerance, signedAt), ErrInvalidHeader, header)
+	}
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule_Next(t *testing.T) {
+	// Friday 5 January 2024, 10:32:15 UTC
+	from := time.Date(2024, 1, 5, 10, 32, 15, 0, time.UTC)
+
+	cases := []struct {
+		expression string
+		want       time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 5, 10, 33, 0, 0, time.UTC)},
+		{"*/5 * * * *", time.Date(2024, 1, 5, 10, 35, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, 1, 5, 11, 0, 0, 0, time.UTC)},
+		{"15,45 9-17 * * *", time.Date(2024, 1, 5, 10, 45, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2024, 1, 6, 2, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1-5", time.Date(2024, 1, 8, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 1", time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", time.Date(2024, 1, 5, 10, 33, 45, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		schedule, err := ParseSchedule(tc.expression)
+		require.NoError(t, err, tc.expression)
+		assert.Equal(t, tc.want, schedule.Next(from), tc.expression)
+	}
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"@every 10ms",
+		"@fortnightly",
+	} {
+		_, err := ParseSchedule(expression)
+		assert.Error(t, err, "%q should not parse", expression)
+	}
+}
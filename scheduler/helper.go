@@ -0,0 +1,49 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/models"
+)
+
+// advisoryLockKey maps a job name onto the bigint key space of pg_try_advisory_lock
+func advisoryLockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("scheduler:" + name))
+	return int64(hash.Sum64())
+}
+
+// runSafely turns a panicking job into a failed run instead of crashing the process
+func runSafely(ctx context.Context, run func(ctx context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	return run(ctx)
+}
+
+func toScheduledJobRun(run *db.ScheduledJobRun) models.ScheduledJobRun {
+	info := models.ScheduledJobRun{
+		ID:        run.ID.String(),
+		JobName:   run.JobName,
+		Trigger:   run.TriggerType,
+		Status:    string(run.Status),
+		Instance:  run.Instance,
+		StartedAt: run.StartedAt,
+		Error:     run.Error.String,
+	}
+	if run.FinishedAt.Valid {
+		info.FinishedAt = &run.FinishedAt.Time
+	}
+	if run.DurationMs.Valid {
+		info.DurationMs = &run.DurationMs.Int64
+	}
+	if run.ScheduledFor.Valid {
+		info.ScheduledFor = &run.ScheduledFor.Time
+	}
+	return info
+}
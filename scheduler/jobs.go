@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+
+	"go.uber.org/zap"
+)
+
+const JobExpirePaymentIntents = "expire-payment-intents"
+
+// NewExpirePaymentIntentsJob moves pending intents past their expires_at to expired so the worker no longer settles them
+func NewExpirePaymentIntentsJob(queries *db.Queries, logger *loggermanager.Logger, cfg *models.SchedulerConfig) Job {
+	return Job{
+		Name:     JobExpirePaymentIntents,
+		Schedule: cfg.ExpirySweepSchedule,
+		Jitter:   cfg.MaxJitter,
+		Timeout:  time.Minute,
+		Run: func(ctx context.Context) error {
+			expired, err := queries.ExpirePendingPaymentIntents(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to expire payment intents: %w", err)
+			}
+
+			logger.Info("Expired pending payment intents", zap.Int64("count", expired))
+			return nil
+		},
+	}
+}
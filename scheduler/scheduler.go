@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cash-flow-financial/internal/models"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var (
+	ErrJobNotFound          = errors.New("scheduled job not found")
+	ErrJobAlreadyRegistered = errors.New("scheduled job already registered")
+	ErrJobLocked            = errors.New("scheduled job is already running on another instance")
+	ErrJobAlreadyRan        = errors.New("scheduled job already ran for this slot")
+	ErrSchedulerStarted     = errors.New("scheduler already started")
+)
+
+// Job is a unit of recurring work. Run must honour ctx cancellation, which happens on
+// Timeout or when the scheduler stops.
+type Job struct {
+	Name     string
+	Schedule string
+	Jitter   time.Duration
+	Timeout  time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type IScheduler interface {
+	Register(job Job) error
+	Start()
+	Stop()
+	Jobs(ctx context.Context) ([]models.ScheduledJob, error)
+	Trigger(ctx context.Context, name string) (*models.ScheduledJobRun, error)
+}
@@ -0,0 +1,322 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/managers/dbmanager"
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+
+	"go.uber.org/zap"
+)
+
+const defaultJobTimeout = 10 * time.Minute
+
+type Scheduler struct {
+	database *sql.DB
+	queries  *db.Queries
+	logger   *loggermanager.Logger
+	instance string
+
+	mu      sync.RWMutex
+	jobs    map[string]*registeredJob
+	order   []string
+	started bool
+
+	// runCtx outlives request contexts so manually triggered runs finish after the response is sent
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+	stop       chan struct{}
+	stopOnce   sync.Once
+	loops      sync.WaitGroup
+	runs       sync.WaitGroup
+}
+
+type registeredJob struct {
+	Job
+	schedule Schedule
+	lockKey  int64
+}
+
+func NewScheduler(dbManager dbmanager.IDBManager, queries *db.Queries, logger *loggermanager.Logger) IScheduler {
+	hostname, _ := os.Hostname()
+	runCtx, cancelRuns := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		database:   dbManager.GetDB(),
+		queries:    queries,
+		logger:     logger,
+		instance:   fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		jobs:       make(map[string]*registeredJob),
+		runCtx:     runCtx,
+		cancelRuns: cancelRuns,
+		stop:       make(chan struct{}),
+	}
+}
+
+func (s *Scheduler) Register(job Job) error {
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule for job %s: %w", job.Name, err)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = defaultJobTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrSchedulerStarted
+	}
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("%w: %s", ErrJobAlreadyRegistered, job.Name)
+	}
+
+	s.jobs[job.Name] = &registeredJob{Job: job, schedule: schedule, lockKey: advisoryLockKey(job.Name)}
+	s.order = append(s.order, job.Name)
+
+	s.logger.Info("Registered scheduled job",
+		zap.String("job", job.Name),
+		zap.String("schedule", job.Schedule),
+		zap.Duration("jitter", job.Jitter))
+	return nil
+}
+
+// Start runs every registered job on its schedule until Stop; without it jobs only run when triggered
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	for _, name := range s.order {
+		s.loops.Add(1)
+		go s.loop(s.jobs[name])
+	}
+
+	s.logger.Info("Scheduler started", zap.Int("jobs", len(s.order)), zap.String("instance", s.instance))
+}
+
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		s.logger.Info("Stopping scheduler...")
+		close(s.stop)
+		s.cancelRuns()
+		s.loops.Wait()
+		s.runs.Wait()
+		s.logger.Info("Scheduler stopped")
+	})
+}
+
+func (s *Scheduler) loop(job *registeredJob) {
+	defer s.loops.Done()
+
+	for {
+		next := job.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Error("Scheduled job has no future run time, stopping its loop", zap.String("job", job.Name))
+			return
+		}
+
+		// Jitter spreads replicas and jobs sharing a schedule so they do not all hit the lock at once
+		wait := time.Until(next) + jitter(job.Jitter)
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if _, err := s.execute(job, TriggerSchedule, false, next); err != nil {
+			if errors.Is(err, ErrJobLocked) {
+				s.logger.Debug("Scheduled job skipped, another instance holds the lock", zap.String("job", job.Name))
+				continue
+			}
+			if errors.Is(err, ErrJobAlreadyRan) {
+				s.logger.Debug("Scheduled job skipped, another instance already ran this slot",
+					zap.String("job", job.Name),
+					zap.Time("scheduled_for", next))
+				continue
+			}
+			s.logger.Error("Scheduled job could not start", zap.String("job", job.Name), zap.Error(err))
+		}
+	}
+}
+
+func (s *Scheduler) Jobs(ctx context.Context) ([]models.ScheduledJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	jobs := make([]models.ScheduledJob, 0, len(s.order))
+	for _, name := range s.order {
+		job := s.jobs[name]
+		info := models.ScheduledJob{
+			Name:      job.Name,
+			Schedule:  job.Schedule,
+			NextRunAt: job.schedule.Next(now),
+		}
+
+		lastRun, err := s.queries.GetLatestScheduledJobRun(ctx, job.Name)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get last run of job %s: %w", job.Name, err)
+		}
+		if err == nil {
+			run := toScheduledJobRun(lastRun)
+			info.LastRun = &run
+		}
+
+		jobs = append(jobs, info)
+	}
+
+	return jobs, nil
+}
+
+// Trigger starts a run immediately and returns once it holds the lock; the job itself runs in the background
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*models.ScheduledJobRun, error) {
+	s.mu.RLock()
+	job, ok := s.jobs[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+
+	s.logger.Info("Manually triggering scheduled job", zap.String("job", name))
+
+	run, err := s.execute(job, TriggerManual, true, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	info := toScheduledJobRun(run)
+	return &info, nil
+}
+
+// execute takes the job's advisory lock on a dedicated connection, since Postgres session locks
+// belong to the connection that acquired them, records the run and then runs the job. A scheduled
+// run records the slot it was due for, so a replica whose timer fires after another replica has
+// already finished that slot does not run it again; manual runs pass a zero scheduledFor.
+func (s *Scheduler) execute(job *registeredJob, trigger string, async bool, scheduledFor time.Time) (*db.ScheduledJobRun, error) {
+	conn, err := s.database.Conn(s.runCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lock connection: %w", err)
+	}
+	lockQueries := db.New(conn)
+
+	locked, err := lockQueries.TryAdvisoryLock(s.runCtx, job.lockKey)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire job lock: %w", err)
+	}
+	if !locked {
+		conn.Close()
+		return nil, ErrJobLocked
+	}
+
+	run, err := s.queries.CreateScheduledJobRun(s.runCtx, &db.CreateScheduledJobRunParams{
+		JobName:      job.Name,
+		TriggerType:  trigger,
+		Instance:     s.instance,
+		ScheduledFor: sql.NullTime{Time: scheduledFor, Valid: !scheduledFor.IsZero()},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		s.releaseLock(job, conn)
+		return nil, ErrJobAlreadyRan
+	}
+	if err != nil {
+		s.releaseLock(job, conn)
+		return nil, fmt.Errorf("failed to record job run: %w", err)
+	}
+
+	s.runs.Add(1)
+	work := func() {
+		defer s.runs.Done()
+		defer s.releaseLock(job, conn)
+		s.runJob(job, run)
+	}
+
+	if async {
+		go work()
+	} else {
+		work()
+	}
+
+	return run, nil
+}
+
+func (s *Scheduler) runJob(job *registeredJob, run *db.ScheduledJobRun) {
+	s.logger.Info("Scheduled job started",
+		zap.String("job", job.Name),
+		zap.String("run_id", run.ID.String()),
+		zap.String("trigger", run.TriggerType))
+
+	ctx, cancel := context.WithTimeout(s.runCtx, job.Timeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	err := runSafely(ctx, job.Run)
+	duration := time.Since(startedAt)
+
+	status := db.ScheduledRunStatusSucceeded
+	var errorMessage sql.NullString
+	if err != nil {
+		status = db.ScheduledRunStatusFailed
+		errorMessage = sql.NullString{String: err.Error(), Valid: true}
+		s.logger.Error("Scheduled job failed",
+			zap.String("job", job.Name),
+			zap.String("run_id", run.ID.String()),
+			zap.Duration("duration", duration),
+			zap.Error(err))
+	} else {
+		s.logger.Info("Scheduled job succeeded",
+			zap.String("job", job.Name),
+			zap.String("run_id", run.ID.String()),
+			zap.Duration("duration", duration))
+	}
+
+	// Recorded even when the run was cancelled by Stop, so the history never shows a phantom running row
+	recordCtx, recordCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer recordCancel()
+
+	if err := s.queries.FinishScheduledJobRun(recordCtx, &db.FinishScheduledJobRunParams{
+		ID:         run.ID,
+		Status:     status,
+		DurationMs: sql.NullInt64{Int64: duration.Milliseconds(), Valid: true},
+		Error:      errorMessage,
+	}); err != nil {
+		s.logger.Error("Failed to record job run result", zap.String("run_id", run.ID.String()), zap.Error(err))
+	}
+}
+
+func (s *Scheduler) releaseLock(job *registeredJob, conn *sql.Conn) {
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Closing the connection would also release the lock, but pooled connections are reused rather than closed
+	if _, err := db.New(conn).ReleaseAdvisoryLock(ctx, job.lockKey); err != nil {
+		s.logger.Error("Failed to release job lock", zap.String("job", job.Name), zap.Error(err))
+	}
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return rand.N(max)
+}
@@ -0,0 +1,94 @@
+package account
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cash-flow-financial/internal/models"
+	accountservice "cash-flow-financial/internal/services/account-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// CreateAPIKeyAPI issues an additional API key for the authenticated merchant
+// @Summary Create API Key
+// @Description Issues a new API key alongside the merchant's existing ones. The key is limited to the requested scopes, which default to and may not exceed those of the key making the request. The mode defaults to that of the key making the request; a test key can only create test keys, and live keys require a verified email. The full key is only returned in this response; store it securely.
+// @Tags Merchant
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param request body models.CreateAPIKeyRequest true "Key details"
+// @Success 201 {object} models.CreateAPIKeyResponse "API key created successfully"
+// @Failure 400 {object} models.ErrorResponse "Validation error"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the keys:write scope or a requested scope, a test key asked for a live key, or a live key was requested before the email was verified"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /account/api-keys [post]
+func (h *AccountHandler) CreateAPIKeyAPI(c echo.Context) error {
+	h.logger.Info("CreateAPIKeyAPI called")
+
+	merchantID := middleware.MerchantID(c)
+
+	var req models.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("CreateAPIKeyAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+
+	if validationErrors := h.validateCreateAPIKeyRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("CreateAPIKeyAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = middleware.Merchant(c).APIKeyScopes
+	}
+	if scope := scopeNotHeld(c, scopes); scope != "" {
+		h.logger.Warn("CreateAPIKeyAPI failed: scope not held by caller", zap.String("merchant_id", merchantID), zap.String("scope", scope))
+		return c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Status: false,
+			Error:  "API key cannot grant a scope it does not have: " + scope,
+		})
+	}
+
+	// Test keys are handed to developers and CI, so they must not be a way to obtain a live key
+	mode := req.Mode
+	if mode == "" {
+		mode = middleware.Mode(c)
+	}
+	if mode == models.ModeLive && middleware.Mode(c) != models.ModeLive {
+		h.logger.Warn("CreateAPIKeyAPI failed: test key asked for a live key", zap.String("merchant_id", merchantID))
+		return c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Status: false,
+			Error:  "a test API key cannot create live API keys",
+		})
+	}
+
+	response, err := h.accountService.CreateAPIKey(merchantID, strings.TrimSpace(req.Label), mode, scopes)
+	if err != nil {
+		if errors.Is(err, accountservice.ErrEmailNotVerified) {
+			h.logger.Warn("CreateAPIKeyAPI failed: live key for unverified email", zap.String("merchant_id", merchantID))
+			return c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Status:  false,
+				Error:   "live API keys require a verified email address",
+				Details: []string{"follow the link sent to the account email, or request a new one from POST /account/email/verification"},
+			})
+		}
+		h.logger.Error("CreateAPIKeyAPI failed: creation error", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to create API key",
+		})
+	}
+
+	h.logger.Info("CreateAPIKeyAPI successful", zap.String("merchant_id", merchantID), zap.String("api_key_id", response.Key.ID))
+	return c.JSON(http.StatusCreated, response)
+}
@@ -13,13 +13,16 @@ import (
 
 // CreateMerchantAPI creates a new merchant account and assigns an API key
 // @Summary Create Merchant Account
-// @Description Creates a new merchant account with a unique merchant ID and API key for payment processing
+// @Description Creates a new merchant account with a unique merchant ID and API key for payment processing. Requires an operator key.
 // @Tags Merchant
 // @Accept json
 // @Produce json
+// @Param X-ADMIN-KEY header string true "Operator API Key"
 // @Param request body models.CreateMerchantRequest true "Merchant creation request"
 // @Success 201 {object} models.CreateMerchantResponse "Merchant created successfully"
 // @Failure 400 {object} models.ErrorResponse "Validation error or duplicate email"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing admin key"
+// @Failure 403 {object} models.ErrorResponse "Admin access is not configured"
 // @Router /account/create-merchant [post]
 func (h *AccountHandler) CreateMerchantAPI(c echo.Context) error {
 	h.logger.Info("CreateMerchantAPI called")
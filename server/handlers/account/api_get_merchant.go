@@ -4,35 +4,40 @@ import (
 	"net/http"
 
 	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 )
 
-// GetMerchantAPI retrieves merchant details, balances, and transaction history
+// GetMerchantAPI retrieves the authenticated merchant's details, balances, and transaction history
 // @Summary Get Merchant Details
-// @Description Retrieves merchant information including balances across currencies and recent transactions
+// @Description Retrieves the authenticated merchant's information including balances across currencies and recent transactions. Only the balances and transactions of the API key's mode (test or live) are returned. merchant_id is optional; when given it must be the merchant the API key belongs to.
 // @Tags Merchant
 // @Accept json
 // @Produce json
-// @Param merchant_id query string true "Merchant ID (e.g., CASM-ABC123)"
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param merchant_id query string false "Merchant ID (e.g., CASM-ABC123)"
 // @Success 200 {object} models.GetMerchantResponse "Merchant details retrieved successfully"
-// @Failure 400 {object} models.ErrorResponse "Missing merchant_id parameter"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the balances:read scope, or merchant_id belongs to another merchant"
 // @Failure 404 {object} models.ErrorResponse "Merchant not found"
 // @Router /account/merchant [get]
 func (h *AccountHandler) GetMerchantAPI(c echo.Context) error {
-	merchantID := c.QueryParam("merchant_id")
+	merchantID := middleware.MerchantID(c)
 	h.logger.Info("GetMerchantAPI called", zap.String("merchant_id", merchantID))
 
-	if merchantID == "" {
-		h.logger.Warn("GetMerchantAPI failed: missing merchant_id parameter")
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+	if requested := c.QueryParam("merchant_id"); requested != "" && requested != merchantID {
+		h.logger.Warn("GetMerchantAPI failed: merchant_id does not match API key",
+			zap.String("merchant_id", merchantID),
+			zap.String("requested_merchant_id", requested))
+		return c.JSON(http.StatusForbidden, models.ErrorResponse{
 			Status: false,
-			Error:  "merchant_id query parameter is required",
+			Error:  "merchant_id does not match the API key",
 		})
 	}
 
-	response, err := h.accountService.GetMerchantByID(merchantID)
+	response, err := h.accountService.GetMerchantByID(merchantID, middleware.Mode(c))
 	if err != nil {
 		h.logger.Warn("GetMerchantAPI failed: merchant not found", zap.String("merchant_id", merchantID))
 		return c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -0,0 +1,39 @@
+package account
+
+import (
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ListAPIKeysAPI lists the authenticated merchant's API keys
+// @Summary List API Keys
+// @Description Lists all of the merchant's API keys, newest first, with masked values, status and when each was last used. Revoked and expired keys are included.
+// @Tags Merchant
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Success 200 {object} models.ListAPIKeysResponse "API keys retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the keys:read scope"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /account/api-keys [get]
+func (h *AccountHandler) ListAPIKeysAPI(c echo.Context) error {
+	h.logger.Info("ListAPIKeysAPI called")
+
+	merchantID := middleware.MerchantID(c)
+
+	response, err := h.accountService.ListAPIKeys(merchantID)
+	if err != nil {
+		h.logger.Error("ListAPIKeysAPI failed: listing error", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,82 @@
+package account
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	accountservice "cash-flow-financial/internal/services/account-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// RevokeAPIKeyAPI revokes one of the authenticated merchant's API keys
+// @Summary Revoke API Key
+// @Description Stops an API key from authenticating immediately. The key making the request must hold all of the key's scopes and have the same mode. A key can only be revoked while another active key of its mode has the keys:write scope; rotate it instead.
+// @Tags Merchant
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.APIKeyResponse "API key revoked successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the keys:write scope or a scope of the key being revoked, or the key being revoked has a different mode"
+// @Failure 404 {object} models.ErrorResponse "API key not found"
+// @Failure 409 {object} models.ErrorResponse "API key already revoked or expired, or no other active key of its mode can manage keys"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /account/api-keys/{id}/revoke [post]
+func (h *AccountHandler) RevokeAPIKeyAPI(c echo.Context) error {
+	keyID := c.Param("id")
+	h.logger.Info("RevokeAPIKeyAPI called", zap.String("api_key_id", keyID))
+
+	merchantID := middleware.MerchantID(c)
+
+	target, err := h.accountService.GetAPIKey(merchantID, keyID)
+	if err != nil {
+		if errors.Is(err, accountservice.ErrAPIKeyNotFound) {
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{Status: false, Error: err.Error()})
+		}
+		h.logger.Error("RevokeAPIKeyAPI failed: lookup error", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to revoke API key",
+		})
+	}
+
+	// A key cannot create or rotate keys with scopes it lacks, so it cannot revoke them either
+	if scope := scopeNotHeld(c, target.Key.Scopes); scope != "" {
+		h.logger.Warn("RevokeAPIKeyAPI failed: scope not held by caller", zap.String("merchant_id", merchantID), zap.String("scope", scope))
+		return c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Status: false,
+			Error:  "API key cannot revoke a key with a scope it does not have: " + scope,
+		})
+	}
+
+	// Otherwise a test key handed to CI could take the merchant's live keys out of service
+	if target.Key.Mode != middleware.Mode(c) {
+		h.logger.Warn("RevokeAPIKeyAPI failed: key mode differs from caller", zap.String("merchant_id", merchantID), zap.String("mode", target.Key.Mode))
+		return c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Status: false,
+			Error:  "API key can only revoke " + middleware.Mode(c) + " API keys",
+		})
+	}
+
+	response, err := h.accountService.RevokeAPIKey(merchantID, keyID)
+	if err != nil {
+		switch {
+		case errors.Is(err, accountservice.ErrAPIKeyNotFound):
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, accountservice.ErrAPIKeyNotActive), errors.Is(err, accountservice.ErrLastAPIKey):
+			return c.JSON(http.StatusConflict, models.ErrorResponse{Status: false, Error: err.Error()})
+		}
+		h.logger.Error("RevokeAPIKeyAPI failed: revocation error", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to revoke API key",
+		})
+	}
+
+	h.logger.Info("RevokeAPIKeyAPI successful", zap.String("merchant_id", merchantID), zap.String("api_key_id", keyID))
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,61 @@
+package account
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func (f *fakeAccountService) RevokeAPIKey(merchantID, keyID string) (*models.APIKeyResponse, error) {
+	f.revoked = append(f.revoked, keyID)
+	return &models.APIKeyResponse{Status: true, Key: models.APIKey{ID: keyID, Status: "revoked"}}, nil
+}
+
+func TestRevokeAPIKeyAPI_CallerLimits(t *testing.T) {
+	logger := loggermanager.NewLogger("error")
+	accounts := &fakeAccountService{
+		callers: map[string]*models.GetMerchantResponse{
+			"api_live_admin":  {MerchantID: "CASM-ABC123", Mode: models.ModeLive, APIKeyScopes: models.APIKeyScopes},
+			"api_live_narrow": {MerchantID: "CASM-ABC123", Mode: models.ModeLive, APIKeyScopes: []string{models.ScopeKeysWrite}},
+			"api_test_admin":  {MerchantID: "CASM-ABC123", Mode: models.ModeTest, APIKeyScopes: models.APIKeyScopes},
+		},
+		keys: map[string]models.APIKey{
+			"live-full": {ID: "live-full", Mode: models.ModeLive, Scopes: models.APIKeyScopes},
+			"live-keys": {ID: "live-keys", Mode: models.ModeLive, Scopes: []string{models.ScopeKeysWrite}},
+			"test-full": {ID: "test-full", Mode: models.ModeTest, Scopes: models.APIKeyScopes},
+		},
+	}
+	handler := NewAccountHandler(accounts, &models.Config{}, logger)
+
+	e := echo.New()
+	e.POST("/account/api-keys/:id/revoke", handler.RevokeAPIKeyAPI, middleware.MerchantAuth(accounts, logger))
+
+	revoke := func(callerKey, keyID string) int {
+		req := httptest.NewRequest(http.MethodPost, "/account/api-keys/"+keyID+"/revoke", nil)
+		req.Header.Set(middleware.APIKeyHeader, callerKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, revoke("api_live_admin", "missing"))
+
+	// A key cannot revoke keys with scopes it could not have created or rotated
+	assert.Equal(t, http.StatusForbidden, revoke("api_live_narrow", "live-full"))
+	assert.Equal(t, http.StatusOK, revoke("api_live_narrow", "live-keys"))
+
+	// A test key handed to CI cannot take live keys out of service, and a live key only revokes live keys
+	assert.Equal(t, http.StatusForbidden, revoke("api_test_admin", "live-full"))
+	assert.Equal(t, http.StatusForbidden, revoke("api_live_admin", "test-full"))
+	assert.Equal(t, http.StatusOK, revoke("api_test_admin", "test-full"))
+	assert.Equal(t, http.StatusOK, revoke("api_live_admin", "live-full"))
+
+	assert.Equal(t, []string{"live-keys", "test-full", "live-full"}, accounts.revoked)
+}
@@ -0,0 +1,109 @@
+package account
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"cash-flow-financial/internal/models"
+	accountservice "cash-flow-financial/internal/services/account-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// RotateAPIKeyAPI replaces one of the authenticated merchant's API keys
+// @Summary Rotate API Key
+// @Description Issues a replacement for an API key. Until the grace period ends both keys authenticate, so clients can switch over without downtime. The replacement keeps the key's scopes, so the key making the request must hold all of them and have the same mode. The full new key is only returned in this response.
+// @Tags Merchant
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param id path string true "API key ID"
+// @Param request body models.RotateAPIKeyRequest false "Rotation options"
+// @Success 200 {object} models.RotateAPIKeyResponse "API key rotated successfully"
+// @Failure 400 {object} models.ErrorResponse "Validation error"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the keys:write scope or a scope of the key being rotated, or the key being rotated has a different mode"
+// @Failure 404 {object} models.ErrorResponse "API key not found"
+// @Failure 409 {object} models.ErrorResponse "API key already revoked or expired"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /account/api-keys/{id}/rotate [post]
+func (h *AccountHandler) RotateAPIKeyAPI(c echo.Context) error {
+	keyID := c.Param("id")
+	h.logger.Info("RotateAPIKeyAPI called", zap.String("api_key_id", keyID))
+
+	merchantID := middleware.MerchantID(c)
+
+	var req models.RotateAPIKeyRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			h.logger.Warn("RotateAPIKeyAPI failed: invalid request format")
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+		}
+	}
+
+	if validationErrors := h.validateRotateAPIKeyRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("RotateAPIKeyAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	// The replacement inherits the key's scopes and is returned in full, so rotating is as good as
+	// creating a key with them
+	target, err := h.accountService.GetAPIKey(merchantID, keyID)
+	if err != nil {
+		if errors.Is(err, accountservice.ErrAPIKeyNotFound) {
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{Status: false, Error: err.Error()})
+		}
+		h.logger.Error("RotateAPIKeyAPI failed: lookup error", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to rotate API key",
+		})
+	}
+	if scope := scopeNotHeld(c, target.Key.Scopes); scope != "" {
+		h.logger.Warn("RotateAPIKeyAPI failed: scope not held by caller", zap.String("merchant_id", merchantID), zap.String("scope", scope))
+		return c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Status: false,
+			Error:  "API key cannot rotate a key with a scope it does not have: " + scope,
+		})
+	}
+
+	// Otherwise a test key handed to CI could obtain a live key by rotating one
+	if target.Key.Mode != middleware.Mode(c) {
+		h.logger.Warn("RotateAPIKeyAPI failed: key mode differs from caller", zap.String("merchant_id", merchantID), zap.String("mode", target.Key.Mode))
+		return c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Status: false,
+			Error:  "API key can only rotate " + middleware.Mode(c) + " API keys",
+		})
+	}
+
+	gracePeriod := h.config.APIKeyGracePeriod
+	if req.GracePeriodHours != nil {
+		gracePeriod = time.Duration(*req.GracePeriodHours) * time.Hour
+	}
+
+	response, err := h.accountService.RotateAPIKey(merchantID, keyID, strings.TrimSpace(req.Label), gracePeriod)
+	if err != nil {
+		switch {
+		case errors.Is(err, accountservice.ErrAPIKeyNotFound):
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, accountservice.ErrAPIKeyNotActive):
+			return c.JSON(http.StatusConflict, models.ErrorResponse{Status: false, Error: err.Error()})
+		}
+		h.logger.Error("RotateAPIKeyAPI failed: rotation error", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to rotate API key",
+		})
+	}
+
+	h.logger.Info("RotateAPIKeyAPI successful", zap.String("merchant_id", merchantID), zap.String("api_key_id", response.Key.ID))
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,88 @@
+package account
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	accountservice "cash-flow-financial/internal/services/account-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAccountService struct {
+	accountservice.IAccountService
+	callers map[string]*models.GetMerchantResponse
+	keys    map[string]models.APIKey
+	rotated []string
+	revoked []string
+}
+
+func (f *fakeAccountService) GetMerchantByAPIKey(apiKey string) (*models.GetMerchantResponse, error) {
+	caller, ok := f.callers[apiKey]
+	if !ok {
+		return nil, accountservice.ErrInvalidAPIKey
+	}
+	return caller, nil
+}
+
+func (f *fakeAccountService) GetAPIKey(merchantID, keyID string) (*models.APIKeyResponse, error) {
+	key, ok := f.keys[keyID]
+	if !ok {
+		return nil, accountservice.ErrAPIKeyNotFound
+	}
+	return &models.APIKeyResponse{Status: true, Key: key}, nil
+}
+
+func (f *fakeAccountService) RotateAPIKey(merchantID, keyID, label string, gracePeriod time.Duration) (*models.RotateAPIKeyResponse, error) {
+	f.rotated = append(f.rotated, keyID)
+	return &models.RotateAPIKeyResponse{Status: true, Key: models.APIKey{ID: keyID + "-new"}}, nil
+}
+
+func TestRotateAPIKeyAPI_CallerLimits(t *testing.T) {
+	logger := loggermanager.NewLogger("error")
+	accounts := &fakeAccountService{
+		callers: map[string]*models.GetMerchantResponse{
+			"api_live_admin":  {MerchantID: "CASM-ABC123", Mode: models.ModeLive, APIKeyScopes: models.APIKeyScopes},
+			"api_live_narrow": {MerchantID: "CASM-ABC123", Mode: models.ModeLive, APIKeyScopes: []string{models.ScopeKeysWrite}},
+			"api_test_admin":  {MerchantID: "CASM-ABC123", Mode: models.ModeTest, APIKeyScopes: models.APIKeyScopes},
+		},
+		keys: map[string]models.APIKey{
+			"live-full":  {ID: "live-full", Mode: models.ModeLive, Scopes: models.APIKeyScopes},
+			"live-keys":  {ID: "live-keys", Mode: models.ModeLive, Scopes: []string{models.ScopeKeysWrite}},
+			"test-full":  {ID: "test-full", Mode: models.ModeTest, Scopes: models.APIKeyScopes},
+			"test-other": {ID: "test-other", Mode: models.ModeTest, Scopes: []string{models.ScopeIntentsRead}},
+		},
+	}
+	handler := NewAccountHandler(accounts, &models.Config{APIKeyGracePeriod: time.Hour}, logger)
+
+	e := echo.New()
+	e.POST("/account/api-keys/:id/rotate", handler.RotateAPIKeyAPI, middleware.MerchantAuth(accounts, logger))
+
+	rotate := func(callerKey, keyID string) int {
+		req := httptest.NewRequest(http.MethodPost, "/account/api-keys/"+keyID+"/rotate", nil)
+		req.Header.Set(middleware.APIKeyHeader, callerKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, rotate("api_live_admin", "live-full"))
+	assert.Equal(t, http.StatusOK, rotate("api_live_narrow", "live-keys"))
+	assert.Equal(t, http.StatusNotFound, rotate("api_live_admin", "missing"))
+
+	// A key cannot obtain scopes it lacks by rotating a broader key
+	assert.Equal(t, http.StatusForbidden, rotate("api_live_narrow", "live-full"))
+
+	// A test key cannot obtain a live key by rotating one, and a live key only rotates live keys
+	assert.Equal(t, http.StatusOK, rotate("api_test_admin", "test-other"))
+	assert.Equal(t, http.StatusForbidden, rotate("api_test_admin", "live-keys"))
+	assert.Equal(t, http.StatusForbidden, rotate("api_live_admin", "test-full"))
+
+	assert.Equal(t, []string{"live-full", "live-keys", "test-other"}, accounts.rotated)
+}
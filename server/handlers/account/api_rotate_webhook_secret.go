@@ -0,0 +1,66 @@
+package account
+
+import (
+	"net/http"
+	"time"
+
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// RotateWebhookSecretAPI issues a new callback signing secret for the authenticated merchant
+// @Summary Rotate Webhook Secret
+// @Description Issues a new secret for signing callbacks. Until the grace period ends, callbacks carry a signature from both the new and the previous secret so receivers can switch over without dropping events.
+// @Tags Merchant
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param request body models.RotateWebhookSecretRequest false "Rotation options"
+// @Success 200 {object} models.RotateWebhookSecretResponse "Webhook secret rotated successfully"
+// @Failure 400 {object} models.ErrorResponse "Validation error"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the webhooks:write scope"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /account/webhook-secret/rotate [post]
+func (h *AccountHandler) RotateWebhookSecretAPI(c echo.Context) error {
+	h.logger.Info("RotateWebhookSecretAPI called")
+
+	merchantID := middleware.MerchantID(c)
+
+	var req models.RotateWebhookSecretRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			h.logger.Warn("RotateWebhookSecretAPI failed: invalid request format")
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+		}
+	}
+
+	if validationErrors := h.validateRotateWebhookSecretRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("RotateWebhookSecretAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	gracePeriod := h.config.Webhook.SecretGracePeriod
+	if req.GracePeriodHours != nil {
+		gracePeriod = time.Duration(*req.GracePeriodHours) * time.Hour
+	}
+
+	response, err := h.accountService.RotateWebhookSecret(merchantID, gracePeriod)
+	if err != nil {
+		h.logger.Error("RotateWebhookSecretAPI failed: rotation error", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to rotate webhook secret",
+		})
+	}
+
+	h.logger.Info("RotateWebhookSecretAPI successful", zap.String("merchant_id", merchantID))
+	return c.JSON(http.StatusOK, response)
+}
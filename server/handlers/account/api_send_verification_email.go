@@ -0,0 +1,45 @@
+package account
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	accountservice "cash-flow-financial/internal/services/account-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// SendVerificationEmailAPI mails the authenticated merchant a new verification link
+// @Summary Resend Verification Email
+// @Description Mails a new verification link to the pending email, or to the current email while it is unverified. Earlier links for the same address keep working until they expire.
+// @Tags Merchant
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Success 200 {object} models.ProfileResponse "Verification email sent"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the account:write scope"
+// @Failure 409 {object} models.ErrorResponse "Email is already verified and no change is pending"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /account/email/verification [post]
+func (h *AccountHandler) SendVerificationEmailAPI(c echo.Context) error {
+	merchantID := middleware.MerchantID(c)
+	h.logger.Info("SendVerificationEmailAPI called", zap.String("merchant_id", merchantID))
+
+	response, err := h.accountService.SendVerificationEmail(c.Request().Context(), merchantID)
+	if err != nil {
+		if errors.Is(err, accountservice.ErrEmailAlreadyVerified) {
+			return c.JSON(http.StatusConflict, models.ErrorResponse{Status: false, Error: err.Error()})
+		}
+		h.logger.Error("SendVerificationEmailAPI failed: send error", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to send verification email",
+		})
+	}
+
+	h.logger.Info("SendVerificationEmailAPI successful", zap.String("merchant_id", merchantID))
+	return c.JSON(http.StatusOK, response)
+}
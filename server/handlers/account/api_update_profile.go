@@ -0,0 +1,76 @@
+package account
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cash-flow-financial/internal/models"
+	accountservice "cash-flow-financial/internal/services/account-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// UpdateProfileAPI changes the authenticated merchant's name or email
+// @Summary Update Merchant Profile
+// @Description Changes the fields that are set. A new name applies immediately. A new email is kept as pending_email and a verification link is mailed to it; the current email stays in use until the link is followed. Sending the current email cancels a pending change.
+// @Tags Merchant
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param request body models.UpdateProfileRequest true "Profile changes"
+// @Success 200 {object} models.ProfileResponse "Profile updated successfully"
+// @Failure 400 {object} models.ErrorResponse "Validation error"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the account:write scope"
+// @Failure 409 {object} models.ErrorResponse "Email belongs to another merchant"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /account/profile [patch]
+func (h *AccountHandler) UpdateProfileAPI(c echo.Context) error {
+	merchantID := middleware.MerchantID(c)
+	h.logger.Info("UpdateProfileAPI called", zap.String("merchant_id", merchantID))
+
+	var req models.UpdateProfileRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("UpdateProfileAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		req.Name = &name
+	}
+	if req.Email != nil {
+		email := strings.TrimSpace(*req.Email)
+		req.Email = &email
+	}
+
+	if validationErrors := h.validateUpdateProfileRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("UpdateProfileAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.accountService.UpdateProfile(c.Request().Context(), merchantID, req)
+	if err != nil {
+		if errors.Is(err, accountservice.ErrDuplicateEmail) {
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Status:  false,
+				Error:   "duplicate email",
+				Details: []string{"A merchant with this email already exists"},
+			})
+		}
+		h.logger.Error("UpdateProfileAPI failed: update error", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to update profile",
+		})
+	}
+
+	h.logger.Info("UpdateProfileAPI successful", zap.String("merchant_id", merchantID))
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,57 @@
+package account
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cash-flow-financial/internal/models"
+	accountservice "cash-flow-financial/internal/services/account-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// VerifyEmailAPI confirms an email address with the token from a verification link
+// @Summary Verify Email
+// @Description Opened from the link in a verification email, so it needs no API key; the signed token identifies the merchant and address. A pending address becomes the merchant's email. Links expire after EMAIL_VERIFICATION_TTL hours and stop working once a different address is requested.
+// @Tags Merchant
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} models.ProfileResponse "Email verified successfully"
+// @Failure 400 {object} models.ErrorResponse "Missing, invalid, superseded or expired token"
+// @Failure 409 {object} models.ErrorResponse "Email was taken by another merchant in the meantime"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /account/verify-email [get]
+func (h *AccountHandler) VerifyEmailAPI(c echo.Context) error {
+	h.logger.Info("VerifyEmailAPI called")
+
+	token := strings.TrimSpace(c.QueryParam("token"))
+	if token == "" {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "token is required"})
+	}
+
+	response, err := h.accountService.VerifyEmail(c.Request().Context(), token)
+	if err != nil {
+		switch {
+		case errors.Is(err, accountservice.ErrInvalidVerificationToken), errors.Is(err, accountservice.ErrVerificationTokenExpired):
+			middleware.RecordAuthFailure(c)
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Status:  false,
+				Error:   err.Error(),
+				Details: []string{"request a new link from POST /account/email/verification"},
+			})
+		case errors.Is(err, accountservice.ErrDuplicateEmail):
+			return c.JSON(http.StatusConflict, models.ErrorResponse{Status: false, Error: "duplicate email"})
+		}
+		h.logger.Error("VerifyEmailAPI failed: verification error", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to verify email",
+		})
+	}
+
+	h.logger.Info("VerifyEmailAPI successful", zap.String("merchant_id", response.MerchantID))
+	return c.JSON(http.StatusOK, response)
+}
@@ -2,12 +2,27 @@ package account
 
 import (
 	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
+	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
 )
 
+// scopeNotHeld returns the first of scopes the calling key does not hold, or "" when it holds them
+// all. A key can only hand out scopes it holds itself, so a narrow key cannot obtain a broader one.
+func scopeNotHeld(c echo.Context, scopes []string) string {
+	for _, scope := range scopes {
+		if !middleware.HasScope(c, scope) {
+			return scope
+		}
+	}
+	return ""
+}
+
 func (h *AccountHandler) validateCreateMerchantRequest(req models.CreateMerchantRequest) []string {
 	validate := validator.New()
 	var errorMessages []string
@@ -46,3 +61,113 @@ func (h *AccountHandler) validateCreateMerchantRequest(req models.CreateMerchant
 
 	return errorMessages
 }
+
+func (h *AccountHandler) validateRotateWebhookSecretRequest(req models.RotateWebhookSecretRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "GracePeriodHours":
+				errorMessages = append(errorMessages, "grace_period_hours must be between 0 and 168")
+			}
+		}
+	}
+
+	return errorMessages
+}
+
+func (h *AccountHandler) validateCreateAPIKeyRequest(req models.CreateAPIKeyRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "Label":
+				switch fieldError.Tag() {
+				case "required":
+					errorMessages = append(errorMessages, "label is required")
+				case "max":
+					errorMessages = append(errorMessages, "label must be at most 100 characters")
+				}
+			case "Mode":
+				errorMessages = append(errorMessages, "mode must be one of: test, live")
+			}
+		}
+	}
+
+	if req.Label != "" && strings.TrimSpace(req.Label) == "" {
+		errorMessages = append(errorMessages, "label must not be blank")
+	}
+
+	for _, scope := range req.Scopes {
+		if !slices.Contains(models.APIKeyScopes, scope) {
+			errorMessages = append(errorMessages, fmt.Sprintf("unknown scope '%s', must be one of: %s",
+				scope, strings.Join(models.APIKeyScopes, ", ")))
+		}
+	}
+
+	return errorMessages
+}
+
+func (h *AccountHandler) validateRotateAPIKeyRequest(req models.RotateAPIKeyRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "Label":
+				errorMessages = append(errorMessages, "label must be at most 100 characters")
+			case "GracePeriodHours":
+				errorMessages = append(errorMessages, "grace_period_hours must be between 0 and 168")
+			}
+		}
+	}
+
+	return errorMessages
+}
+
+func (h *AccountHandler) validateUpdateProfileRequest(req models.UpdateProfileRequest) []string {
+	if req.Name == nil && req.Email == nil {
+		return []string{"name or email is required"}
+	}
+
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "Name":
+				switch fieldError.Tag() {
+				case "min":
+					errorMessages = append(errorMessages, "name must be at least 2 characters")
+				case "max":
+					errorMessages = append(errorMessages, "name must be at most 100 characters")
+				}
+			case "Email":
+				switch fieldError.Tag() {
+				case "email":
+					errorMessages = append(errorMessages, "invalid email format")
+				case "max":
+					errorMessages = append(errorMessages, "email must be at most 255 characters")
+				}
+			}
+		}
+	}
+
+	// Same rule as at signup
+	nameRegex := regexp.MustCompile(`^[a-zA-Z\s]+$`)
+	if req.Name != nil && !nameRegex.MatchString(*req.Name) {
+		errorMessages = append(errorMessages, "name can only contain letters and spaces")
+	}
+
+	return errorMessages
+}
@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	merchantservice "cash-flow-financial/internal/services/merchant-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ChangeMerchantTierAPI moves a merchant to another tier
+// @Summary Change Merchant Tier
+// @Description Sets the tier that selects the request rate limit of the merchant's API keys and dashboard users. The new limit applies from the merchant's next request.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Operator API Key"
+// @Param id path string true "Merchant ID" example(CASM-ABC123)
+// @Param request body models.ChangeMerchantTierRequest true "New tier"
+// @Success 200 {object} models.ChangeMerchantTierResponse "Merchant tier changed"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing admin key"
+// @Failure 403 {object} models.ErrorResponse "Admin access is not configured"
+// @Failure 404 {object} models.ErrorResponse "Merchant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/merchants/{id}/tier [post]
+func (h *AdminHandler) ChangeMerchantTierAPI(c echo.Context) error {
+	merchantID := c.Param("id")
+	actor := middleware.AdminName(c)
+	h.logger.Info("ChangeMerchantTierAPI called", zap.String("merchant_id", merchantID), zap.String("actor", actor))
+
+	var req models.ChangeMerchantTierRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("ChangeMerchantTierAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+
+	if validationErrors := h.validateChangeMerchantTierRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("ChangeMerchantTierAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.merchantService.ChangeTier(c.Request().Context(), merchantID, req.Tier, actor)
+	if err != nil {
+		switch {
+		case errors.Is(err, merchantservice.ErrMerchantNotFound):
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, merchantservice.ErrInvalidMerchantTier):
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	h.logger.Info("ChangeMerchantTierAPI successful",
+		zap.String("merchant_id", merchantID),
+		zap.String("actor", actor),
+		zap.String("tier", response.Merchant.Tier))
+	return c.JSON(http.StatusOK, response)
+}
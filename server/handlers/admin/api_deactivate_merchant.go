@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"cash-flow-financial/internal/db"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DeactivateMerchantAPI deactivates a merchant
+// @Summary Deactivate Merchant
+// @Description Stops every API key of the merchant from authenticating. Pending intents are not processed and expire.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Operator API Key"
+// @Param id path string true "Merchant ID" example(CASM-ABC123)
+// @Param request body models.ChangeMerchantStatusRequest true "Reason for the change"
+// @Success 200 {object} models.ChangeMerchantStatusResponse "Merchant deactivated"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing admin key"
+// @Failure 403 {object} models.ErrorResponse "Admin access is not configured"
+// @Failure 404 {object} models.ErrorResponse "Merchant not found"
+// @Failure 409 {object} models.ErrorResponse "Merchant is already inactive"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/merchants/{id}/deactivate [post]
+func (h *AdminHandler) DeactivateMerchantAPI(c echo.Context) error {
+	return h.changeMerchantStatus(c, "DeactivateMerchantAPI", db.MerchantStatusInactive)
+}
@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	merchantservice "cash-flow-financial/internal/services/merchant-service"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// GetMerchantAPI returns a merchant with its status history
+// @Summary Get Merchant
+// @Description Returns the merchant and every status change made by operators, newest first, with the reason and the admin key that made it
+// @Tags Admin
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Operator API Key"
+// @Param id path string true "Merchant ID" example(CASM-ABC123)
+// @Success 200 {object} models.AdminMerchantResponse "Merchant retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing admin key"
+// @Failure 403 {object} models.ErrorResponse "Admin access is not configured"
+// @Failure 404 {object} models.ErrorResponse "Merchant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/merchants/{id} [get]
+func (h *AdminHandler) GetMerchantAPI(c echo.Context) error {
+	merchantID := c.Param("id")
+	h.logger.Info("GetMerchantAPI called", zap.String("merchant_id", merchantID))
+
+	response, err := h.merchantService.GetMerchant(c.Request().Context(), merchantID)
+	if err != nil {
+		if errors.Is(err, merchantservice.ErrMerchantNotFound) {
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{Status: false, Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
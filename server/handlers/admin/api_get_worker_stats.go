@@ -0,0 +1,31 @@
+package admin
+
+import (
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// GetWorkerStatsAPI reports the payment worker pool size and current in-flight deliveries
+// @Summary Get Worker Stats
+// @Description Returns the payment worker pool configuration together with in-flight, processed and failed delivery counts
+// @Tags Admin
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Operator API Key"
+// @Success 200 {object} models.WorkerStatsResponse "Worker stats retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing admin key"
+// @Failure 403 {object} models.ErrorResponse "Admin access is not configured"
+// @Router /admin/worker/stats [get]
+func (h *AdminHandler) GetWorkerStatsAPI(c echo.Context) error {
+	stats := h.worker.Stats()
+	h.logger.Info("GetWorkerStatsAPI called", zap.Int64("in_flight", stats.InFlight))
+
+	return c.JSON(http.StatusOK, models.WorkerStatsResponse{
+		Status:  true,
+		Worker:  stats,
+		Message: "Worker stats retrieved successfully",
+	})
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ListMerchantsAPI lists and searches merchants
+// @Summary List Merchants
+// @Description Lists merchants, newest first, with their status, active API key count and pending intents. q matches the merchant ID, name or email, ignoring case.
+// @Tags Admin
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Operator API Key"
+// @Param q query string false "Search text" example(example.com)
+// @Param status query string false "Merchant status" Enums(active, suspended, inactive)
+// @Param limit query int false "Maximum merchants to return (1-200)" default(50)
+// @Param offset query int false "Merchants to skip" default(0)
+// @Success 200 {object} models.ListMerchantsResponse "Merchants retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing admin key"
+// @Failure 403 {object} models.ErrorResponse "Admin access is not configured"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/merchants [get]
+func (h *AdminHandler) ListMerchantsAPI(c echo.Context) error {
+	h.logger.Info("ListMerchantsAPI called")
+
+	filter, validationErrors := parseMerchantFilter(c)
+	if len(validationErrors) > 0 {
+		h.logger.Warn("ListMerchantsAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.merchantService.ListMerchants(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	h.logger.Info("ListMerchantsAPI successful", zap.Int("count", len(response.Merchants)))
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,40 @@
+package admin
+
+import (
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ListScheduledJobsAPI lists the registered background jobs with their last and next runs
+// @Summary List Scheduled Jobs
+// @Description Returns every registered scheduler job with its cron schedule, next run time and most recent run
+// @Tags Admin
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Operator API Key"
+// @Success 200 {object} models.ScheduledJobsResponse "Scheduled jobs retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing admin key"
+// @Failure 403 {object} models.ErrorResponse "Admin access is not configured"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/jobs [get]
+func (h *AdminHandler) ListScheduledJobsAPI(c echo.Context) error {
+	h.logger.Info("ListScheduledJobsAPI called")
+
+	jobs, err := h.scheduler.Jobs(c.Request().Context())
+	if err != nil {
+		h.logger.Error("ListScheduledJobsAPI failed", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.ScheduledJobsResponse{
+		Status:  true,
+		Jobs:    jobs,
+		Message: "Scheduled jobs retrieved successfully",
+	})
+}
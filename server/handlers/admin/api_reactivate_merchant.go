@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"cash-flow-financial/internal/db"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReactivateMerchantAPI reactivates a suspended or deactivated merchant
+// @Summary Reactivate Merchant
+// @Description Restores full access and queues the merchant's unexpired pending intents for processing again
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Operator API Key"
+// @Param id path string true "Merchant ID" example(CASM-ABC123)
+// @Param request body models.ChangeMerchantStatusRequest true "Reason for the change"
+// @Success 200 {object} models.ChangeMerchantStatusResponse "Merchant reactivated"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing admin key"
+// @Failure 403 {object} models.ErrorResponse "Admin access is not configured"
+// @Failure 404 {object} models.ErrorResponse "Merchant not found"
+// @Failure 409 {object} models.ErrorResponse "Merchant is already active"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/merchants/{id}/reactivate [post]
+func (h *AdminHandler) ReactivateMerchantAPI(c echo.Context) error {
+	return h.changeMerchantStatus(c, "ReactivateMerchantAPI", db.MerchantStatusActive)
+}
@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"cash-flow-financial/internal/db"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SuspendMerchantAPI suspends an active merchant
+// @Summary Suspend Merchant
+// @Description Blocks intent creation at once and pauses processing and settlement of the merchant's pending intents until it is reactivated. The merchant can still authenticate and read its account.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Operator API Key"
+// @Param id path string true "Merchant ID" example(CASM-ABC123)
+// @Param request body models.ChangeMerchantStatusRequest true "Reason for the change"
+// @Success 200 {object} models.ChangeMerchantStatusResponse "Merchant suspended"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing admin key"
+// @Failure 403 {object} models.ErrorResponse "Admin access is not configured"
+// @Failure 404 {object} models.ErrorResponse "Merchant not found"
+// @Failure 409 {object} models.ErrorResponse "Merchant is not active"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/merchants/{id}/suspend [post]
+func (h *AdminHandler) SuspendMerchantAPI(c echo.Context) error {
+	return h.changeMerchantStatus(c, "SuspendMerchantAPI", db.MerchantStatusSuspended)
+}
@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/scheduler"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// TriggerScheduledJobAPI starts a run of a scheduled job outside its schedule
+// @Summary Trigger Scheduled Job
+// @Description Starts a run of the named job immediately. The run holds the same lock as scheduled runs, so it is rejected while the job is running on any instance.
+// @Tags Admin
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Operator API Key"
+// @Param name path string true "Job name" example(expire-payment-intents)
+// @Success 202 {object} models.TriggerScheduledJobResponse "Scheduled job run started"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing admin key"
+// @Failure 403 {object} models.ErrorResponse "Admin access is not configured"
+// @Failure 404 {object} models.ErrorResponse "Scheduled job not found"
+// @Failure 409 {object} models.ErrorResponse "Scheduled job is already running"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /admin/jobs/{name}/run [post]
+func (h *AdminHandler) TriggerScheduledJobAPI(c echo.Context) error {
+	name := c.Param("name")
+	h.logger.Info("TriggerScheduledJobAPI called", zap.String("job", name))
+
+	run, err := h.scheduler.Trigger(c.Request().Context(), name)
+	if err != nil {
+		switch {
+		case errors.Is(err, scheduler.ErrJobNotFound):
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Status: false,
+				Error:  err.Error(),
+			})
+		case errors.Is(err, scheduler.ErrJobLocked):
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Status: false,
+				Error:  err.Error(),
+			})
+		}
+		h.logger.Error("TriggerScheduledJobAPI failed", zap.String("job", name), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.JSON(http.StatusAccepted, models.TriggerScheduledJobResponse{
+		Status:  true,
+		Run:     *run,
+		Message: "Scheduled job run started",
+	})
+}
@@ -0,0 +1,27 @@
+package admin
+
+import (
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	merchantservice "cash-flow-financial/internal/services/merchant-service"
+	"cash-flow-financial/scheduler"
+	"cash-flow-financial/worker"
+)
+
+type AdminHandler struct {
+	worker          worker.IWorker
+	scheduler       scheduler.IScheduler
+	merchantService merchantservice.IMerchantService
+	config          *models.Config
+	logger          *loggermanager.Logger
+}
+
+func NewAdminHandler(worker worker.IWorker, scheduler scheduler.IScheduler, merchantService merchantservice.IMerchantService, config *models.Config, logger *loggermanager.Logger) *AdminHandler {
+	return &AdminHandler{
+		worker:          worker,
+		scheduler:       scheduler,
+		merchantService: merchantService,
+		config:          config,
+		logger:          logger,
+	}
+}
@@ -0,0 +1,147 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/models"
+	merchantservice "cash-flow-financial/internal/services/merchant-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMerchantLimit = 50
+	maxMerchantLimit     = 200
+)
+
+// parseMerchantFilter reads the merchant list query parameters
+func parseMerchantFilter(c echo.Context) (models.MerchantFilter, []string) {
+	var errorMessages []string
+	filter := models.MerchantFilter{
+		Query:  c.QueryParam("q"),
+		Status: c.QueryParam("status"),
+		Limit:  defaultMerchantLimit,
+	}
+
+	if filter.Status != "" && !db.MerchantStatus(filter.Status).Valid() {
+		errorMessages = append(errorMessages, "status must be one of: active, suspended, inactive")
+	}
+
+	if len(filter.Query) > 255 {
+		errorMessages = append(errorMessages, "q must be at most 255 characters")
+	}
+
+	if value := c.QueryParam("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxMerchantLimit {
+			errorMessages = append(errorMessages, fmt.Sprintf("limit must be between 1 and %d", maxMerchantLimit))
+		}
+		filter.Limit = limit
+	}
+
+	if value := c.QueryParam("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			errorMessages = append(errorMessages, "offset must be a non-negative integer")
+		}
+		filter.Offset = offset
+	}
+
+	return filter, errorMessages
+}
+
+func (h *AdminHandler) validateChangeMerchantStatusRequest(req models.ChangeMerchantStatusRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "Reason":
+				switch fieldError.Tag() {
+				case "required":
+					errorMessages = append(errorMessages, "reason is required")
+				case "min":
+					errorMessages = append(errorMessages, "reason must be at least 3 characters")
+				case "max":
+					errorMessages = append(errorMessages, "reason must be at most 500 characters")
+				}
+			}
+		}
+	}
+
+	return errorMessages
+}
+
+func (h *AdminHandler) validateChangeMerchantTierRequest(req models.ChangeMerchantTierRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "Tier":
+				switch fieldError.Tag() {
+				case "required":
+					errorMessages = append(errorMessages, "tier is required")
+				case "oneof":
+					errorMessages = append(errorMessages, "tier must be one of: standard, growth, enterprise")
+				}
+			}
+		}
+	}
+
+	return errorMessages
+}
+
+// changeMerchantStatus serves the suspend, reactivate and deactivate endpoints, which differ only
+// in the target status. The change is recorded against the admin key's name.
+func (h *AdminHandler) changeMerchantStatus(c echo.Context, api string, status db.MerchantStatus) error {
+	merchantID := c.Param("id")
+	actor := middleware.AdminName(c)
+	h.logger.Info(api+" called", zap.String("merchant_id", merchantID), zap.String("actor", actor))
+
+	var req models.ChangeMerchantStatusRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn(api + " failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+
+	if validationErrors := h.validateChangeMerchantStatusRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn(api+" failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.merchantService.ChangeStatus(c.Request().Context(), merchantID, string(status), req.Reason, actor)
+	if err != nil {
+		switch {
+		case errors.Is(err, merchantservice.ErrMerchantNotFound):
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, merchantservice.ErrInvalidStatusTransition):
+			return c.JSON(http.StatusConflict, models.ErrorResponse{Status: false, Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	h.logger.Info(api+" successful",
+		zap.String("merchant_id", merchantID),
+		zap.String("actor", actor),
+		zap.Int("republished_intents", response.RepublishedIntents))
+	return c.JSON(http.StatusOK, response)
+}
@@ -1,10 +1,12 @@
 package checkout
 
 import (
+	"errors"
 	"net/http"
-	"strings"
 
 	"cash-flow-financial/internal/models"
+	checkoutservice "cash-flow-financial/internal/services/checkout-service"
+	"cash-flow-financial/server/middleware"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
@@ -12,7 +14,7 @@ import (
 
 // CreateIntent creates a new payment intent for processing
 // @Summary Create Payment Intent
-// @Description Creates a payment intent that will be processed asynchronously. The payment gateway charges 1% fee and only accepts ETB and USD currencies.
+// @Description Creates a payment intent that will be processed asynchronously. The payment gateway charges 1% fee and only accepts ETB and USD currencies. Intents created with a test key are processed by the sandbox, whose outcome is chosen by the account number and the amount's cents.
 // @Tags Payment
 // @Accept json
 // @Produce json
@@ -21,40 +23,14 @@ import (
 // @Success 201 {object} models.CreatePaymentIntentResponse "Payment intent created successfully"
 // @Failure 400 {object} models.ErrorResponse "Validation error"
 // @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the intents:write scope, or the merchant is suspended"
+// @Failure 409 {object} models.ErrorResponse "Nonce already used by an intent in the other mode"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /checkout/create-intent [post]
 func (h *CheckoutHandler) CreateIntent(c echo.Context) error {
 	h.logger.Info("CreateIntent called")
 
-	// Extract API key from header
-	apiKey := c.Request().Header.Get("X-API-KEY")
-	if apiKey == "" {
-		h.logger.Warn("CreateIntent failed: missing X-API-KEY header")
-		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-			Status: false,
-			Error:  "X-API-KEY header is required",
-		})
-	}
-
-	// Trim any whitespace
-	apiKey = strings.TrimSpace(apiKey)
-
-	// Validate API key and get merchant
-	merchantResponse, err := h.accountService.GetMerchantByAPIKey(apiKey)
-	if err != nil {
-		if strings.Contains(err.Error(), "invalid API key") {
-			h.logger.Warn("CreateIntent failed: invalid API key", zap.String("api_key", maskAPIKey(apiKey)))
-			return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-				Status: false,
-				Error:  "invalid API key",
-			})
-		}
-		h.logger.Error("CreateIntent failed: merchant lookup error", zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Status: false,
-			Error:  "internal server error",
-		})
-	}
+	merchantID := middleware.MerchantID(c)
 
 	// Parse request payload
 	var req models.CreatePaymentIntentRequest
@@ -77,8 +53,15 @@ func (h *CheckoutHandler) CreateIntent(c echo.Context) error {
 	}
 
 	// Create payment intent
-	response, err := h.checkoutService.CreatePaymentIntent(merchantResponse.MerchantID, req)
+	response, err := h.checkoutService.CreatePaymentIntent(merchantID, middleware.Mode(c), req)
 	if err != nil {
+		if errors.Is(err, checkoutservice.ErrNonceUsedInOtherMode) {
+			h.logger.Warn("CreateIntent failed: nonce used in the other mode", zap.String("merchant_id", merchantID), zap.String("nonce", req.Nonce))
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Status: false,
+				Error:  err.Error(),
+			})
+		}
 		h.logger.Error("CreateIntent failed: payment intent creation error", zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Status: false,
@@ -86,6 +69,6 @@ func (h *CheckoutHandler) CreateIntent(c echo.Context) error {
 		})
 	}
 
-	h.logger.Info("CreateIntent successful", zap.String("payment_intent_id", response.PaymentIntentID), zap.String("merchant_id", merchantResponse.MerchantID))
+	h.logger.Info("CreateIntent successful", zap.String("payment_intent_id", response.PaymentIntentID), zap.String("merchant_id", merchantID))
 	return c.JSON(http.StatusCreated, response)
 }
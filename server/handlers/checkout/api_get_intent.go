@@ -0,0 +1,49 @@
+package checkout
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	checkoutservice "cash-flow-financial/internal/services/checkout-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// GetIntent returns a payment intent and the merchant's acknowledgement of its callbacks
+// @Summary Get Payment Intent
+// @Description Returns the payment intent's status and callback_ack, the acknowledgement state of its callbacks: pending, acknowledged, unacknowledged (2xx without an acknowledgement body), rejected (the merchant answered {"status": false}; the callback is being retried) or failed (retries exhausted).
+// @Tags Payment
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param id path string true "Payment intent ID"
+// @Success 200 {object} models.PaymentIntentResponse "Payment intent retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the intents:read scope"
+// @Failure 404 {object} models.ErrorResponse "Payment intent not found, or created in the other mode"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /checkout/intents/{id} [get]
+func (h *CheckoutHandler) GetIntent(c echo.Context) error {
+	paymentIntentID := c.Param("id")
+	h.logger.Info("GetIntent called", zap.String("payment_intent_id", paymentIntentID))
+
+	merchantID := middleware.MerchantID(c)
+
+	response, err := h.checkoutService.GetPaymentIntent(merchantID, middleware.Mode(c), paymentIntentID)
+	if err != nil {
+		if errors.Is(err, checkoutservice.ErrPaymentIntentNotFound) {
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Status: false,
+				Error:  err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
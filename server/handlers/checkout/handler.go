@@ -1,26 +1,26 @@
 package checkout
 
 import (
+	"cash-flow-financial/internal/managers/brokermanager"
 	loggermanager "cash-flow-financial/internal/managers/loggermanager"
-	"cash-flow-financial/internal/managers/rabbitmqmanager"
 	"cash-flow-financial/internal/models"
-	accountservice "cash-flow-financial/internal/services/account-service"
+	"cash-flow-financial/internal/services/callback"
 	checkoutservice "cash-flow-financial/internal/services/checkout-service"
 )
 
 type CheckoutHandler struct {
 	checkoutService checkoutservice.ICheckoutService
-	accountService  accountservice.IAccountService
-	rabbitManager   rabbitmqmanager.IRabbitMQManager
+	publisher       brokermanager.IPublisher
+	callbackGuard   *callback.Guard
 	config          *models.Config
 	logger          *loggermanager.Logger
 }
 
-func NewCheckoutHandler(checkoutService checkoutservice.ICheckoutService, accountService accountservice.IAccountService, config *models.Config, logger *loggermanager.Logger, rabbitMgr rabbitmqmanager.IRabbitMQManager) *CheckoutHandler {
+func NewCheckoutHandler(checkoutService checkoutservice.ICheckoutService, config *models.Config, logger *loggermanager.Logger, publisher brokermanager.IPublisher) *CheckoutHandler {
 	return &CheckoutHandler{
 		checkoutService: checkoutService,
-		accountService:  accountService,
-		rabbitManager:   rabbitMgr,
+		publisher:       publisher,
+		callbackGuard:   callback.NewGuard(config),
 		config:          config,
 		logger:          logger,
 	}
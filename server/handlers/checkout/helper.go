@@ -33,10 +33,7 @@ func (h *CheckoutHandler) validateCreatePaymentIntentRequest(req models.CreatePa
 					errorMessages = append(errorMessages, "currency must be one of: ETB, USD, EUR, GBP")
 				}
 			case "CallbackURL":
-				switch fieldError.Tag() {
-				case "required":
-					errorMessages = append(errorMessages, "callback_url is required")
-				case "url":
+				if fieldError.Tag() == "url" {
 					errorMessages = append(errorMessages, "callback_url must be a valid URL")
 				}
 			case "Nonce":
@@ -52,16 +49,18 @@ func (h *CheckoutHandler) validateCreatePaymentIntentRequest(req models.CreatePa
 				if fieldError.Tag() == "max" {
 					errorMessages = append(errorMessages, "description must be at most 500 characters")
 				}
+			case "AccountNumber":
+				errorMessages = append(errorMessages, "account_number must be 9 to 15 digits")
 			}
 		}
 	}
 
-	return errorMessages
-}
-
-func maskAPIKey(apiKey string) string {
-	if len(apiKey) <= 10 {
-		return apiKey
+	// Addresses behind hostnames are only known at send time, where the guard checks them again
+	if req.CallbackURL != "" && len(errorMessages) == 0 {
+		if err := h.callbackGuard.CheckURL(req.CallbackURL); err != nil {
+			errorMessages = append(errorMessages, "callback_url: "+err.Error())
+		}
 	}
-	return apiKey[:10] + "..."
+
+	return errorMessages
 }
@@ -0,0 +1,66 @@
+package dashboard
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cash-flow-financial/internal/models"
+	userservice "cash-flow-financial/internal/services/user-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// AcceptInviteAPI activates an invited dashboard user
+// @Summary Accept Dashboard Invitation
+// @Description Sets the password of the user an invitation was sent to, using the token from the invitation link, and activates them. The user then signs in with POST /dashboard/login. Invitations expire after DASHBOARD_INVITE_TTL hours and stop working once the user is invited again.
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Param request body models.AcceptInviteRequest true "Invitation token and new password"
+// @Success 200 {object} models.UserResponse "Invitation accepted"
+// @Failure 400 {object} models.ErrorResponse "Validation error, or an invalid, used or expired invitation"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /dashboard/invites/accept [post]
+func (h *DashboardHandler) AcceptInviteAPI(c echo.Context) error {
+	h.logger.Info("AcceptInviteAPI called")
+
+	var req models.AcceptInviteRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("AcceptInviteAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	req.Name = strings.TrimSpace(req.Name)
+
+	if validationErrors := h.validateAcceptInviteRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("AcceptInviteAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.userService.AcceptInvite(c.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, userservice.ErrInvalidInvite) || errors.Is(err, userservice.ErrInviteExpired) {
+			middleware.RecordAuthFailure(c)
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Status:  false,
+				Error:   err.Error(),
+				Details: []string{"ask an owner or admin to invite you again"},
+			})
+		}
+		h.logger.Error("AcceptInviteAPI failed: accept error", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to accept invitation",
+		})
+	}
+
+	h.logger.Info("AcceptInviteAPI successful", zap.String("user_id", response.User.ID))
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,73 @@
+package dashboard
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cash-flow-financial/internal/models"
+	userservice "cash-flow-financial/internal/services/user-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ConfirmTOTPAPI enables two-factor authentication once the user proves their app has the secret
+// @Summary Confirm Two-Factor Enrollment
+// @Description Checks a code generated from the secret returned by POST /dashboard/me/totp and turns two-factor authentication on. From then on sign-in requires totp_code.
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param request body models.TOTPCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} models.UserResponse "Two-factor authentication enabled"
+// @Failure 400 {object} models.ErrorResponse "Validation error or wrong code"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing session"
+// @Failure 409 {object} models.ErrorResponse "Already enabled, or enrollment was not started"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /dashboard/me/totp/confirm [post]
+func (h *DashboardHandler) ConfirmTOTPAPI(c echo.Context) error {
+	user := middleware.DashboardUser(c)
+	h.logger.Info("ConfirmTOTPAPI called", zap.String("user_id", user.ID))
+
+	var req models.TOTPCodeRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("ConfirmTOTPAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+	req.Code = strings.TrimSpace(req.Code)
+
+	if validationErrors := h.validateTOTPCodeRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("ConfirmTOTPAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.userService.ConfirmTOTP(c.Request().Context(), user.ID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, userservice.ErrInvalidTOTPCode):
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, userservice.ErrTOTPAlreadyEnabled):
+			return c.JSON(http.StatusConflict, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, userservice.ErrTOTPNotEnrolled):
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Status:  false,
+				Error:   err.Error(),
+				Details: []string{"start with POST /dashboard/me/totp"},
+			})
+		}
+		h.logger.Error("ConfirmTOTPAPI failed: update error", zap.String("user_id", user.ID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to enable two-factor authentication",
+		})
+	}
+
+	h.logger.Info("ConfirmTOTPAPI successful", zap.String("user_id", user.ID))
+	return c.JSON(http.StatusOK, response)
+}
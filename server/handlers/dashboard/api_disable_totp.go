@@ -0,0 +1,67 @@
+package dashboard
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cash-flow-financial/internal/models"
+	userservice "cash-flow-financial/internal/services/user-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// DisableTOTPAPI turns two-factor authentication off for the signed-in user
+// @Summary Disable Two-Factor Authentication
+// @Description Requires a current code from the authenticator app, so a session token alone cannot remove the second factor.
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param request body models.TOTPCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} models.UserResponse "Two-factor authentication disabled"
+// @Failure 400 {object} models.ErrorResponse "Validation error or wrong code"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing session"
+// @Failure 409 {object} models.ErrorResponse "Two-factor authentication is not enabled"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /dashboard/me/totp/disable [post]
+func (h *DashboardHandler) DisableTOTPAPI(c echo.Context) error {
+	user := middleware.DashboardUser(c)
+	h.logger.Info("DisableTOTPAPI called", zap.String("user_id", user.ID))
+
+	var req models.TOTPCodeRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("DisableTOTPAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+	req.Code = strings.TrimSpace(req.Code)
+
+	if validationErrors := h.validateTOTPCodeRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("DisableTOTPAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.userService.DisableTOTP(c.Request().Context(), user.ID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, userservice.ErrInvalidTOTPCode):
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, userservice.ErrTOTPNotEnrolled):
+			return c.JSON(http.StatusConflict, models.ErrorResponse{Status: false, Error: err.Error()})
+		}
+		h.logger.Error("DisableTOTPAPI failed: update error", zap.String("user_id", user.ID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to disable two-factor authentication",
+		})
+	}
+
+	h.logger.Info("DisableTOTPAPI successful", zap.String("user_id", user.ID))
+	return c.JSON(http.StatusOK, response)
+}
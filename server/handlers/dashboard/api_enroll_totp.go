@@ -0,0 +1,48 @@
+package dashboard
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	userservice "cash-flow-financial/internal/services/user-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// EnrollTOTPAPI starts two-factor enrollment for the signed-in user
+// @Summary Start Two-Factor Enrollment
+// @Description Generates a TOTP secret and returns it with an otpauth URI for authenticator apps. Sign-in does not ask for a code until it is confirmed with POST /dashboard/me/totp/confirm. Calling this again before confirming replaces the secret.
+// @Tags Dashboard
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Success 200 {object} models.TOTPEnrollmentResponse "Secret generated"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing session"
+// @Failure 409 {object} models.ErrorResponse "Two-factor authentication is already enabled"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /dashboard/me/totp [post]
+func (h *DashboardHandler) EnrollTOTPAPI(c echo.Context) error {
+	user := middleware.DashboardUser(c)
+	h.logger.Info("EnrollTOTPAPI called", zap.String("user_id", user.ID))
+
+	response, err := h.userService.BeginTOTPEnrollment(c.Request().Context(), user.ID)
+	if err != nil {
+		if errors.Is(err, userservice.ErrTOTPAlreadyEnabled) {
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Status:  false,
+				Error:   err.Error(),
+				Details: []string{"disable it with POST /dashboard/me/totp/disable before enrolling again"},
+			})
+		}
+		h.logger.Error("EnrollTOTPAPI failed: enrollment error", zap.String("user_id", user.ID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to start two-factor enrollment",
+		})
+	}
+
+	h.logger.Info("EnrollTOTPAPI successful", zap.String("user_id", user.ID))
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,32 @@
+package dashboard
+
+import (
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetMeAPI returns the signed-in user and what their role allows
+// @Summary Get Current Dashboard User
+// @Description Returns the user behind the session token, the merchant they belong to and the scopes their role grants.
+// @Tags Dashboard
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Success 200 {object} models.DashboardMeResponse "Signed in"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing session"
+// @Router /dashboard/me [get]
+func (h *DashboardHandler) GetMeAPI(c echo.Context) error {
+	session := middleware.Session(c)
+
+	return c.JSON(http.StatusOK, models.DashboardMeResponse{
+		Status:       true,
+		User:         session.User,
+		MerchantID:   session.Merchant.MerchantID,
+		MerchantName: session.Merchant.Name,
+		Scopes:       session.Merchant.APIKeyScopes,
+		Message:      "Signed in",
+	})
+}
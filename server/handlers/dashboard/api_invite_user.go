@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cash-flow-financial/internal/models"
+	userservice "cash-flow-financial/internal/services/user-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// InviteUserAPI invites a member of staff to the merchant's dashboard
+// @Summary Invite Dashboard User
+// @Description Creates the user with the given role and emails them a link to set a password. Inviting an address again, while the invitation is pending or after the user was removed, sends a new link and invalidates the old one. Only owners can invite an owner, except that an API key can invite the first one while the merchant has no active owner.
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string false "Merchant API Key"
+// @Param Authorization header string false "Bearer session token"
+// @Param request body models.InviteUserRequest true "User to invite"
+// @Success 201 {object} models.InviteUserResponse "Invitation sent"
+// @Failure 400 {object} models.ErrorResponse "Validation error"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key or session"
+// @Failure 403 {object} models.ErrorResponse "Missing the users:write scope, or inviting an owner without being one"
+// @Failure 409 {object} models.ErrorResponse "The email already belongs to a dashboard user"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /dashboard/users [post]
+func (h *DashboardHandler) InviteUserAPI(c echo.Context) error {
+	merchantID := middleware.MerchantID(c)
+	h.logger.Info("InviteUserAPI called", zap.String("merchant_id", merchantID))
+
+	var req models.InviteUserRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("InviteUserAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.Name = strings.TrimSpace(req.Name)
+
+	if validationErrors := h.validateInviteUserRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("InviteUserAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.userService.InviteUser(c.Request().Context(), merchantID, middleware.DashboardUser(c), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, userservice.ErrOwnerRequired):
+			return c.JSON(http.StatusForbidden, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, userservice.ErrUserExists):
+			return c.JSON(http.StatusConflict, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, userservice.ErrInvalidRole):
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: err.Error()})
+		}
+		h.logger.Error("InviteUserAPI failed: invite error", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to invite user",
+		})
+	}
+
+	h.logger.Info("InviteUserAPI successful", zap.String("merchant_id", merchantID), zap.String("user_id", response.User.ID))
+	return c.JSON(http.StatusCreated, response)
+}
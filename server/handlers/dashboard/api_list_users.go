@@ -0,0 +1,40 @@
+package dashboard
+
+import (
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ListUsersAPI lists the merchant's dashboard users
+// @Summary List Dashboard Users
+// @Description Returns every dashboard user of the merchant, including pending invitations and removed users, oldest first. Accepts an API key or a dashboard session.
+// @Tags Dashboard
+// @Produce json
+// @Param X-API-KEY header string false "Merchant API Key"
+// @Param Authorization header string false "Bearer session token"
+// @Success 200 {object} models.ListUsersResponse "Users retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key or session"
+// @Failure 403 {object} models.ErrorResponse "Missing the users:read scope"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /dashboard/users [get]
+func (h *DashboardHandler) ListUsersAPI(c echo.Context) error {
+	merchantID := middleware.MerchantID(c)
+	h.logger.Info("ListUsersAPI called", zap.String("merchant_id", merchantID))
+
+	response, err := h.userService.ListUsers(c.Request().Context(), merchantID)
+	if err != nil {
+		h.logger.Error("ListUsersAPI failed: list error", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to list users",
+		})
+	}
+
+	h.logger.Info("ListUsersAPI successful", zap.String("merchant_id", merchantID), zap.Int("count", len(response.Users)))
+	return c.JSON(http.StatusOK, response)
+}
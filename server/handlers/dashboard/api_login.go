@@ -0,0 +1,71 @@
+package dashboard
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cash-flow-financial/internal/models"
+	userservice "cash-flow-financial/internal/services/user-service"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// LoginAPI signs a dashboard user in
+// @Summary Dashboard Login
+// @Description Checks the email and password, and the TOTP code once two-factor authentication is enabled, and returns a session token. Send it as Authorization: Bearer <session_token>; it expires after DASHBOARD_SESSION_TTL hours. A 401 with error "two-factor code is required" means the request should be repeated with totp_code. Each TOTP code is accepted once, and after DASHBOARD_LOGIN_MAX_ATTEMPTS failed sign-ins in a row the user is locked out for DASHBOARD_LOGIN_LOCKOUT minutes.
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Param request body models.LoginRequest true "Credentials"
+// @Success 200 {object} models.LoginResponse "Signed in successfully"
+// @Failure 400 {object} models.ErrorResponse "Validation error"
+// @Failure 401 {object} models.ErrorResponse "Invalid credentials, or a missing or invalid two-factor code"
+// @Failure 403 {object} models.ErrorResponse "Merchant account is inactive"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /dashboard/login [post]
+func (h *DashboardHandler) LoginAPI(c echo.Context) error {
+	h.logger.Info("LoginAPI called")
+
+	var req models.LoginRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("LoginAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.TOTPCode = strings.TrimSpace(req.TOTPCode)
+
+	if validationErrors := h.validateLoginRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("LoginAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.userService.Login(c.Request().Context(), req, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, userservice.ErrInvalidCredentials),
+			errors.Is(err, userservice.ErrTOTPRequired),
+			errors.Is(err, userservice.ErrInvalidTOTPCode):
+			return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, userservice.ErrMerchantInactive):
+			return c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Status:  false,
+				Error:   err.Error(),
+				Details: []string{"contact support to reactivate the account"},
+			})
+		}
+		h.logger.Error("LoginAPI failed: login error", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to sign in",
+		})
+	}
+
+	h.logger.Info("LoginAPI successful", zap.String("user_id", response.User.ID))
+	return c.JSON(http.StatusOK, response)
+}
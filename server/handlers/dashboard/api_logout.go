@@ -0,0 +1,37 @@
+package dashboard
+
+import (
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// LogoutAPI ends the current dashboard session
+// @Summary Dashboard Logout
+// @Description Revokes the session token the request was made with. Other sessions of the user stay signed in.
+// @Tags Dashboard
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Success 204 "Signed out"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing session"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /dashboard/logout [post]
+func (h *DashboardHandler) LogoutAPI(c echo.Context) error {
+	session := middleware.Session(c)
+	h.logger.Info("LogoutAPI called", zap.String("user_id", session.User.ID))
+
+	if err := h.userService.Logout(c.Request().Context(), session.SessionID); err != nil {
+		h.logger.Error("LogoutAPI failed: logout error", zap.String("user_id", session.User.ID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to sign out",
+		})
+	}
+
+	h.logger.Info("LogoutAPI successful", zap.String("user_id", session.User.ID))
+	return c.NoContent(http.StatusNoContent)
+}
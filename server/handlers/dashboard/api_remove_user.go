@@ -0,0 +1,58 @@
+package dashboard
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	userservice "cash-flow-financial/internal/services/user-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// RemoveUserAPI removes a user from the merchant's dashboard
+// @Summary Remove Dashboard User
+// @Description Disables the user and signs out all of their sessions, or cancels a pending invitation. The user stays in the list with status disabled and can be invited again. Only owners can remove an owner, and the merchant's last active owner cannot be removed.
+// @Tags Dashboard
+// @Produce json
+// @Param X-API-KEY header string false "Merchant API Key"
+// @Param Authorization header string false "Bearer session token"
+// @Param id path string true "User ID"
+// @Success 200 {object} models.UserResponse "User removed successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key or session"
+// @Failure 403 {object} models.ErrorResponse "Missing the users:write scope, or removing an owner without being one"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 409 {object} models.ErrorResponse "The user is the last active owner"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /dashboard/users/{id} [delete]
+func (h *DashboardHandler) RemoveUserAPI(c echo.Context) error {
+	merchantID := middleware.MerchantID(c)
+	userID := c.Param("id")
+	h.logger.Info("RemoveUserAPI called", zap.String("merchant_id", merchantID), zap.String("user_id", userID))
+
+	response, err := h.userService.RemoveUser(c.Request().Context(), merchantID, middleware.DashboardUser(c), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, userservice.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, userservice.ErrOwnerRequired):
+			return c.JSON(http.StatusForbidden, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, userservice.ErrLastOwner):
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Status:  false,
+				Error:   err.Error(),
+				Details: []string{"make another user an owner first"},
+			})
+		}
+		h.logger.Error("RemoveUserAPI failed: remove error", zap.String("merchant_id", merchantID), zap.String("user_id", userID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to remove user",
+		})
+	}
+
+	h.logger.Info("RemoveUserAPI successful", zap.String("merchant_id", merchantID), zap.String("user_id", userID))
+	return c.JSON(http.StatusOK, response)
+}
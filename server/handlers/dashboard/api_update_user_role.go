@@ -0,0 +1,80 @@
+package dashboard
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cash-flow-financial/internal/models"
+	userservice "cash-flow-financial/internal/services/user-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// UpdateUserRoleAPI changes a dashboard user's role
+// @Summary Change Dashboard User Role
+// @Description Gives the user a new role, effective from their next request. Only owners can grant or take away the owner role (API keys only while the merchant has no active owner), and the merchant's last active owner cannot be demoted.
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string false "Merchant API Key"
+// @Param Authorization header string false "Bearer session token"
+// @Param id path string true "User ID"
+// @Param request body models.UpdateUserRoleRequest true "New role"
+// @Success 200 {object} models.UserResponse "User role updated successfully"
+// @Failure 400 {object} models.ErrorResponse "Validation error"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key or session"
+// @Failure 403 {object} models.ErrorResponse "Missing the users:write scope, or changing an owner without being one"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 409 {object} models.ErrorResponse "The user is the last active owner"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /dashboard/users/{id} [patch]
+func (h *DashboardHandler) UpdateUserRoleAPI(c echo.Context) error {
+	merchantID := middleware.MerchantID(c)
+	userID := c.Param("id")
+	h.logger.Info("UpdateUserRoleAPI called", zap.String("merchant_id", merchantID), zap.String("user_id", userID))
+
+	var req models.UpdateUserRoleRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("UpdateUserRoleAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+	req.Role = strings.TrimSpace(req.Role)
+
+	if validationErrors := h.validateUpdateUserRoleRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("UpdateUserRoleAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.userService.UpdateUserRole(c.Request().Context(), merchantID, middleware.DashboardUser(c), userID, req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, userservice.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, userservice.ErrOwnerRequired):
+			return c.JSON(http.StatusForbidden, models.ErrorResponse{Status: false, Error: err.Error()})
+		case errors.Is(err, userservice.ErrLastOwner):
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Status:  false,
+				Error:   err.Error(),
+				Details: []string{"make another user an owner first"},
+			})
+		case errors.Is(err, userservice.ErrInvalidRole):
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: err.Error()})
+		}
+		h.logger.Error("UpdateUserRoleAPI failed: update error", zap.String("merchant_id", merchantID), zap.String("user_id", userID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "failed to update user role",
+		})
+	}
+
+	h.logger.Info("UpdateUserRoleAPI successful", zap.String("merchant_id", merchantID), zap.String("user_id", userID))
+	return c.JSON(http.StatusOK, response)
+}
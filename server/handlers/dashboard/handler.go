@@ -0,0 +1,21 @@
+package dashboard
+
+import (
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	userservice "cash-flow-financial/internal/services/user-service"
+)
+
+type DashboardHandler struct {
+	userService userservice.IUserService
+	config      *models.Config
+	logger      *loggermanager.Logger
+}
+
+func NewDashboardHandler(userService userservice.IUserService, config *models.Config, logger *loggermanager.Logger) *DashboardHandler {
+	return &DashboardHandler{
+		userService: userService,
+		config:      config,
+		logger:      logger,
+	}
+}
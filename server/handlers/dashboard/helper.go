@@ -0,0 +1,119 @@
+package dashboard
+
+import (
+	"cash-flow-financial/internal/models"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var roleList = strings.Join(models.DashboardRoles, ", ")
+
+func (h *DashboardHandler) validateInviteUserRequest(req models.InviteUserRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "Email":
+				switch fieldError.Tag() {
+				case "required":
+					errorMessages = append(errorMessages, "email is required")
+				case "email":
+					errorMessages = append(errorMessages, "invalid email format")
+				case "max":
+					errorMessages = append(errorMessages, "email must be at most 255 characters")
+				}
+			case "Name":
+				errorMessages = append(errorMessages, "name must be at most 100 characters")
+			case "Role":
+				errorMessages = append(errorMessages, "role must be one of: "+roleList)
+			}
+		}
+	}
+
+	return errorMessages
+}
+
+func (h *DashboardHandler) validateUpdateUserRoleRequest(req models.UpdateUserRoleRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		errorMessages = append(errorMessages, "role must be one of: "+roleList)
+	}
+
+	return errorMessages
+}
+
+func (h *DashboardHandler) validateAcceptInviteRequest(req models.AcceptInviteRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "Token":
+				errorMessages = append(errorMessages, "token is required")
+			case "Name":
+				errorMessages = append(errorMessages, "name must be at most 100 characters")
+			case "Password":
+				switch fieldError.Tag() {
+				case "required":
+					errorMessages = append(errorMessages, "password is required")
+				case "min":
+					errorMessages = append(errorMessages, "password must be at least 12 characters")
+				case "max":
+					errorMessages = append(errorMessages, "password must be at most 72 characters")
+				}
+			}
+		}
+	}
+
+	return errorMessages
+}
+
+func (h *DashboardHandler) validateLoginRequest(req models.LoginRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "Email":
+				switch fieldError.Tag() {
+				case "required":
+					errorMessages = append(errorMessages, "email is required")
+				default:
+					errorMessages = append(errorMessages, "invalid email format")
+				}
+			case "Password":
+				switch fieldError.Tag() {
+				case "required":
+					errorMessages = append(errorMessages, "password is required")
+				case "max":
+					errorMessages = append(errorMessages, "password must be at most 72 characters")
+				}
+			case "TOTPCode":
+				errorMessages = append(errorMessages, "totp_code must be 6 digits")
+			}
+		}
+	}
+
+	return errorMessages
+}
+
+func (h *DashboardHandler) validateTOTPCodeRequest(req models.TOTPCodeRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		errorMessages = append(errorMessages, "code must be 6 digits")
+	}
+
+	return errorMessages
+}
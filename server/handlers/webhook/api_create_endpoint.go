@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// CreateEndpointAPI registers a webhook endpoint for the authenticated merchant
+// @Summary Create Webhook Endpoint
+// @Description Registers a URL to receive callbacks for the listed event types. Use "*" to subscribe to every event type. Events are sent to every enabled endpoint subscribed to them unless the payment intent sets its own callback_url.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param request body models.CreateWebhookEndpointRequest true "Webhook endpoint"
+// @Success 201 {object} models.WebhookEndpointResponse "Webhook endpoint created successfully"
+// @Failure 400 {object} models.ErrorResponse "Validation error"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the webhooks:write scope"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /webhooks/endpoints [post]
+func (h *WebhookHandler) CreateEndpointAPI(c echo.Context) error {
+	h.logger.Info("CreateEndpointAPI called")
+
+	merchantID := middleware.MerchantID(c)
+
+	var req models.CreateWebhookEndpointRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("CreateEndpointAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+
+	if validationErrors := h.validateCreateWebhookEndpointRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("CreateEndpointAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.webhookService.CreateEndpoint(c.Request().Context(), merchantID, req)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	webhookservice "cash-flow-financial/internal/services/webhook-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// DeleteEndpointAPI removes a webhook endpoint
+// @Summary Delete Webhook Endpoint
+// @Description Deletes the endpoint. Deliveries already queued for it are still attempted, and past deliveries stay in the delivery log.
+// @Tags Webhooks
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param id path string true "Endpoint ID"
+// @Success 204 "Webhook endpoint deleted"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the webhooks:write scope"
+// @Failure 404 {object} models.ErrorResponse "Webhook endpoint not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /webhooks/endpoints/{id} [delete]
+func (h *WebhookHandler) DeleteEndpointAPI(c echo.Context) error {
+	endpointID := c.Param("id")
+	h.logger.Info("DeleteEndpointAPI called", zap.String("endpoint_id", endpointID))
+
+	merchantID := middleware.MerchantID(c)
+
+	if err := h.webhookService.DeleteEndpoint(c.Request().Context(), merchantID, endpointID); err != nil {
+		if errors.Is(err, webhookservice.ErrEndpointNotFound) {
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Status: false,
+				Error:  err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
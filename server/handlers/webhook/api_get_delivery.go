@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	webhookservice "cash-flow-financial/internal/services/webhook-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// GetDeliveryAPI retrieves one callback delivery with its attempts
+// @Summary Get Webhook Delivery
+// @Description Retrieves a callback delivery of the authenticated merchant, including its payload and every attempt
+// @Tags Webhooks
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} models.WebhookDeliveryResponse "Webhook delivery retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the webhooks:read scope"
+// @Failure 404 {object} models.ErrorResponse "Webhook delivery not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /webhooks/deliveries/{id} [get]
+func (h *WebhookHandler) GetDeliveryAPI(c echo.Context) error {
+	deliveryID := c.Param("id")
+	h.logger.Info("GetDeliveryAPI called", zap.String("delivery_id", deliveryID))
+
+	merchantID := middleware.MerchantID(c)
+
+	response, err := h.webhookService.GetDelivery(c.Request().Context(), merchantID, deliveryID)
+	if err != nil {
+		if errors.Is(err, webhookservice.ErrDeliveryNotFound) {
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Status: false,
+				Error:  err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"net/http"
+	"time"
+
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ListDeliveriesAPI lists the authenticated merchant's callback deliveries with their attempts
+// @Summary List Webhook Deliveries
+// @Description Lists callback deliveries, newest first, with every attempt's status code, latency and response excerpt. Filter by payment intent, status or creation time; without from, the last seven days are returned unless payment_intent_id is set.
+// @Tags Webhooks
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param payment_intent_id query string false "Payment intent ID" example(PI-ABC123DEF456)
+// @Param status query string false "Delivery status" Enums(pending, delivered, exhausted)
+// @Param from query string false "Created at or after (RFC 3339)" example(2024-01-05T00:00:00Z)
+// @Param to query string false "Created before (RFC 3339); defaults to now" example(2024-01-06T00:00:00Z)
+// @Param limit query int false "Maximum deliveries to return (1-200)" default(50)
+// @Success 200 {object} models.ListWebhookDeliveriesResponse "Webhook deliveries retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the webhooks:read scope"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /webhooks/deliveries [get]
+func (h *WebhookHandler) ListDeliveriesAPI(c echo.Context) error {
+	h.logger.Info("ListDeliveriesAPI called")
+
+	merchantID := middleware.MerchantID(c)
+
+	filter, validationErrors := parseDeliveryFilter(c, time.Now())
+	if len(validationErrors) > 0 {
+		h.logger.Warn("ListDeliveriesAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.webhookService.ListDeliveries(c.Request().Context(), merchantID, filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	h.logger.Info("ListDeliveriesAPI successful", zap.String("merchant_id", merchantID), zap.Int("count", len(response.Deliveries)))
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListEndpointsAPI returns the authenticated merchant's webhook endpoints
+// @Summary List Webhook Endpoints
+// @Description Returns every webhook endpoint registered by the merchant, enabled or not, oldest first.
+// @Tags Webhooks
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Success 200 {object} models.ListWebhookEndpointsResponse "Webhook endpoints retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the webhooks:read scope"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /webhooks/endpoints [get]
+func (h *WebhookHandler) ListEndpointsAPI(c echo.Context) error {
+	h.logger.Info("ListEndpointsAPI called")
+
+	merchantID := middleware.MerchantID(c)
+
+	response, err := h.webhookService.ListEndpoints(c.Request().Context(), merchantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
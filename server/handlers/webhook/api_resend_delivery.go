@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	webhookservice "cash-flow-financial/internal/services/webhook-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ResendDeliveryAPI queues a callback delivery to be sent again
+// @Summary Resend Webhook Delivery
+// @Description Queues a new delivery with the same payload and URL as an earlier one, in any status. The new delivery references the original through resend_of and gets a fresh retry horizon.
+// @Tags Webhooks
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param id path string true "Delivery ID"
+// @Success 202 {object} models.WebhookDeliveryResponse "Webhook delivery queued for resend"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the webhooks:write scope"
+// @Failure 404 {object} models.ErrorResponse "Webhook delivery not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /webhooks/deliveries/{id}/resend [post]
+func (h *WebhookHandler) ResendDeliveryAPI(c echo.Context) error {
+	deliveryID := c.Param("id")
+	h.logger.Info("ResendDeliveryAPI called", zap.String("delivery_id", deliveryID))
+
+	merchantID := middleware.MerchantID(c)
+
+	response, err := h.webhookService.ResendDelivery(c.Request().Context(), merchantID, deliveryID)
+	if err != nil {
+		if errors.Is(err, webhookservice.ErrDeliveryNotFound) {
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Status: false,
+				Error:  err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.JSON(http.StatusAccepted, response)
+}
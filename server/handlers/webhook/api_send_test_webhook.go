@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// SendTestWebhookAPI fires a synthetic callback at an endpoint and reports how it answered
+// @Summary Send Test Webhook
+// @Description Sends one signed callback with status "test" and payment_intent_id PI-TEST00000000 to the given URL and returns the response code, latency and response excerpt. Test events are not stored or retried.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param request body models.SendTestWebhookRequest true "Test webhook request"
+// @Success 200 {object} models.SendTestWebhookResponse "Test webhook sent; see delivered for the outcome"
+// @Failure 400 {object} models.ErrorResponse "Validation error"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the webhooks:write scope"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /webhooks/test [post]
+func (h *WebhookHandler) SendTestWebhookAPI(c echo.Context) error {
+	h.logger.Info("SendTestWebhookAPI called")
+
+	merchantID := middleware.MerchantID(c)
+
+	var req models.SendTestWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("SendTestWebhookAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+
+	if validationErrors := h.validateSendTestWebhookRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("SendTestWebhookAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.webhookService.SendTestEvent(c.Request().Context(), merchantID, req.CallbackURL)
+	if err != nil {
+		h.logger.Error("SendTestWebhookAPI failed", zap.String("merchant_id", merchantID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+
+	"cash-flow-financial/internal/models"
+	webhookservice "cash-flow-financial/internal/services/webhook-service"
+	"cash-flow-financial/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// UpdateEndpointAPI changes a webhook endpoint's URL, event types, enabled flag or description
+// @Summary Update Webhook Endpoint
+// @Description Updates only the fields present in the request. Set enabled to false to stop sending events to an endpoint without deleting it.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "Merchant API Key"
+// @Param id path string true "Endpoint ID"
+// @Param request body models.UpdateWebhookEndpointRequest true "Fields to update"
+// @Success 200 {object} models.WebhookEndpointResponse "Webhook endpoint updated successfully"
+// @Failure 400 {object} models.ErrorResponse "Validation error"
+// @Failure 401 {object} models.ErrorResponse "Invalid or missing API key"
+// @Failure 403 {object} models.ErrorResponse "API key is missing the webhooks:write scope"
+// @Failure 404 {object} models.ErrorResponse "Webhook endpoint not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /webhooks/endpoints/{id} [patch]
+func (h *WebhookHandler) UpdateEndpointAPI(c echo.Context) error {
+	endpointID := c.Param("id")
+	h.logger.Info("UpdateEndpointAPI called", zap.String("endpoint_id", endpointID))
+
+	merchantID := middleware.MerchantID(c)
+
+	var req models.UpdateWebhookEndpointRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("UpdateEndpointAPI failed: invalid request format")
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Status: false, Error: "invalid request format"})
+	}
+
+	if validationErrors := h.validateUpdateWebhookEndpointRequest(req); len(validationErrors) > 0 {
+		h.logger.Warn("UpdateEndpointAPI failed: validation errors", zap.Strings("errors", validationErrors))
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  false,
+			Error:   "validation failed",
+			Details: validationErrors,
+		})
+	}
+
+	response, err := h.webhookService.UpdateEndpoint(c.Request().Context(), merchantID, endpointID, req)
+	if err != nil {
+		if errors.Is(err, webhookservice.ErrEndpointNotFound) {
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Status: false,
+				Error:  err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status: false,
+			Error:  "internal server error",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
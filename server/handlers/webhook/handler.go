@@ -0,0 +1,24 @@
+package webhook
+
+import (
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/internal/services/callback"
+	webhookservice "cash-flow-financial/internal/services/webhook-service"
+)
+
+type WebhookHandler struct {
+	webhookService webhookservice.IWebhookService
+	callbackGuard  *callback.Guard
+	config         *models.Config
+	logger         *loggermanager.Logger
+}
+
+func NewWebhookHandler(webhookService webhookservice.IWebhookService, config *models.Config, logger *loggermanager.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		callbackGuard:  callback.NewGuard(config),
+		config:         config,
+		logger:         logger,
+	}
+}
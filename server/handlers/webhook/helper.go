@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/models"
+	"cash-flow-financial/internal/services/callback"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultDeliveryLimit  = 50
+	maxDeliveryLimit      = 200
+	defaultDeliveryWindow = 7 * 24 * time.Hour
+)
+
+// parseDeliveryFilter reads the list query parameters. Without from, the window is the last seven
+// days, or all time when a payment_intent_id is given.
+func parseDeliveryFilter(c echo.Context, now time.Time) (models.WebhookDeliveryFilter, []string) {
+	var errorMessages []string
+	filter := models.WebhookDeliveryFilter{
+		PaymentIntentID: c.QueryParam("payment_intent_id"),
+		Status:          c.QueryParam("status"),
+		To:              now,
+		Limit:           defaultDeliveryLimit,
+	}
+
+	if filter.Status != "" && !db.WebhookDeliveryStatus(filter.Status).Valid() {
+		errorMessages = append(errorMessages, "status must be one of: pending, delivered, exhausted")
+	}
+
+	if value := c.QueryParam("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errorMessages = append(errorMessages, "to must be an RFC 3339 timestamp")
+		}
+		filter.To = to
+	}
+
+	if value := c.QueryParam("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errorMessages = append(errorMessages, "from must be an RFC 3339 timestamp")
+		}
+		filter.From = from
+	} else if filter.PaymentIntentID == "" {
+		filter.From = filter.To.Add(-defaultDeliveryWindow)
+	}
+
+	if len(errorMessages) == 0 && !filter.From.Before(filter.To) {
+		errorMessages = append(errorMessages, "from must be before to")
+	}
+
+	if value := c.QueryParam("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxDeliveryLimit {
+			errorMessages = append(errorMessages, fmt.Sprintf("limit must be between 1 and %d", maxDeliveryLimit))
+		}
+		filter.Limit = limit
+	}
+
+	return filter, errorMessages
+}
+
+func (h *WebhookHandler) validateSendTestWebhookRequest(req models.SendTestWebhookRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "CallbackURL":
+				switch fieldError.Tag() {
+				case "required":
+					errorMessages = append(errorMessages, "callback_url is required")
+				case "url":
+					errorMessages = append(errorMessages, "callback_url must be a valid URL")
+				}
+			}
+		}
+	}
+
+	if len(errorMessages) == 0 {
+		if err := h.callbackGuard.CheckURL(req.CallbackURL); err != nil {
+			errorMessages = append(errorMessages, "callback_url: "+err.Error())
+		}
+	}
+
+	return errorMessages
+}
+
+func (h *WebhookHandler) validateCreateWebhookEndpointRequest(req models.CreateWebhookEndpointRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "URL":
+				switch fieldError.Tag() {
+				case "required":
+					errorMessages = append(errorMessages, "url is required")
+				case "url":
+					errorMessages = append(errorMessages, "url must be a valid URL")
+				case "max":
+					errorMessages = append(errorMessages, "url must not exceed 500 characters")
+				}
+			case "EventTypes":
+				errorMessages = append(errorMessages, "event_types must contain at least one event type")
+			case "Description":
+				errorMessages = append(errorMessages, "description must not exceed 500 characters")
+			}
+		}
+	}
+
+	if len(errorMessages) == 0 {
+		if err := h.callbackGuard.CheckURL(req.URL); err != nil {
+			errorMessages = append(errorMessages, "url: "+err.Error())
+		}
+	}
+
+	return append(errorMessages, validateEventTypes(req.EventTypes)...)
+}
+
+func (h *WebhookHandler) validateUpdateWebhookEndpointRequest(req models.UpdateWebhookEndpointRequest) []string {
+	validate := validator.New()
+	var errorMessages []string
+
+	if err := validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "URL":
+				switch fieldError.Tag() {
+				case "url":
+					errorMessages = append(errorMessages, "url must be a valid URL")
+				case "max":
+					errorMessages = append(errorMessages, "url must not exceed 500 characters")
+				}
+			case "EventTypes":
+				errorMessages = append(errorMessages, "event_types must contain at least one event type")
+			case "Description":
+				errorMessages = append(errorMessages, "description must not exceed 500 characters")
+			}
+		}
+	}
+
+	if req.URL != nil && len(errorMessages) == 0 {
+		if err := h.callbackGuard.CheckURL(*req.URL); err != nil {
+			errorMessages = append(errorMessages, "url: "+err.Error())
+		}
+	}
+
+	return append(errorMessages, validateEventTypes(req.EventTypes)...)
+}
+
+func validateEventTypes(eventTypes []string) []string {
+	var errorMessages []string
+	for _, eventType := range eventTypes {
+		if !callback.IsSubscribable(eventType) {
+			errorMessages = append(errorMessages, fmt.Sprintf("unknown event type '%s', must be one of: %s or %s",
+				eventType, strings.Join(callback.EventTypes, ", "), callback.AllEvents))
+		}
+	}
+	return errorMessages
+}
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cash-flow-financial/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFilterContext(query string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, "/webhooks/deliveries?"+query, nil)
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestParseDeliveryFilter_Defaults(t *testing.T) {
+	now := time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)
+
+	filter, errs := parseDeliveryFilter(newFilterContext(""), now)
+	assert.Empty(t, errs)
+	assert.Equal(t, now, filter.To)
+	assert.Equal(t, now.Add(-defaultDeliveryWindow), filter.From)
+	assert.Equal(t, defaultDeliveryLimit, filter.Limit)
+
+	filter, errs = parseDeliveryFilter(newFilterContext("payment_intent_id=PI-ABC123DEF456"), now)
+	assert.Empty(t, errs)
+	assert.True(t, filter.From.IsZero(), "an intent lookup should search all time")
+}
+
+func TestParseDeliveryFilter_Invalid(t *testing.T) {
+	now := time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)
+
+	_, errs := parseDeliveryFilter(newFilterContext("status=sent&limit=500&from=yesterday"), now)
+	assert.Len(t, errs, 3)
+
+	_, errs = parseDeliveryFilter(newFilterContext("from=2024-01-06T00:00:00Z&to=2024-01-05T00:00:00Z"), now)
+	assert.Equal(t, []string{"from must be before to"}, errs)
+}
+
+func TestValidateEventTypes(t *testing.T) {
+	assert.Empty(t, validateEventTypes([]string{"payment.intent.succeeded", "payment.intent.failed"}))
+	assert.Empty(t, validateEventTypes([]string{"*"}))
+	assert.Len(t, validateEventTypes([]string{"payment.intent.succeeded", "payment.created", "refund.*"}), 2)
+}
+
+func TestValidateUpdateWebhookEndpointRequest_EmptyEventTypes(t *testing.T) {
+	h := &WebhookHandler{}
+
+	assert.Empty(t, h.validateUpdateWebhookEndpointRequest(models.UpdateWebhookEndpointRequest{}))
+	assert.Equal(t, []string{"event_types must contain at least one event type"},
+		h.validateUpdateWebhookEndpointRequest(models.UpdateWebhookEndpointRequest{EventTypes: []string{}}))
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	accountservice "cash-flow-financial/internal/services/account-service"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	APIKeyHeader   = "X-API-KEY"
+	AdminKeyHeader = "X-ADMIN-KEY"
+
+	merchantContextKey = "merchant"
+	adminContextKey    = "admin"
+)
+
+// MerchantAuth resolves the X-API-KEY header to a merchant and stores it in the request context for
+// the handlers behind it. Requests without a valid key are answered with 401.
+func MerchantAuth(accountService accountservice.IAccountService, logger *loggermanager.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			apiKey := strings.TrimSpace(c.Request().Header.Get(APIKeyHeader))
+			if apiKey == "" {
+				logger.Warn("Request rejected: missing X-API-KEY header", zap.String("path", c.Path()))
+				return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+					Status: false,
+					Error:  "X-API-KEY header is required",
+				})
+			}
+
+			merchant, err := accountService.GetMerchantByAPIKey(apiKey)
+			if err != nil {
+				if errors.Is(err, accountservice.ErrInvalidAPIKey) {
+					logger.Warn("Request rejected: invalid API key", zap.String("path", c.Path()))
+					return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+						Status: false,
+						Error:  "invalid API key",
+					})
+				}
+				logger.Error("Merchant lookup failed", zap.String("path", c.Path()), zap.Error(err))
+				return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+					Status: false,
+					Error:  "internal server error",
+				})
+			}
+
+			c.Set(merchantContextKey, merchant)
+			return next(c)
+		}
+	}
+}
+
+// Merchant returns the merchant authenticated by MerchantAuth, or nil outside a merchant route
+func Merchant(c echo.Context) *models.GetMerchantResponse {
+	merchant, _ := c.Get(merchantContextKey).(*models.GetMerchantResponse)
+	return merchant
+}
+
+// MerchantID returns the ID of the merchant authenticated by MerchantAuth
+func MerchantID(c echo.Context) string {
+	if merchant := Merchant(c); merchant != nil {
+		return merchant.MerchantID
+	}
+	return ""
+}
+
+// Mode returns the mode (test or live) of the API key authenticated by MerchantAuth
+func Mode(c echo.Context) string {
+	if merchant := Merchant(c); merchant != nil {
+		return merchant.Mode
+	}
+	return ""
+}
+
+// AdminAuth requires the X-ADMIN-KEY header to match one of the configured operator keys and stores
+// the key's name for the handlers behind it. When no key is configured every request is refused, so
+// the admin routes are never open by default.
+func AdminAuth(config *models.AdminConfig, logger *loggermanager.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if len(config.APIKeys) == 0 {
+				logger.Warn("Admin request rejected: ADMIN_API_KEYS is not configured", zap.String("path", c.Path()))
+				return c.JSON(http.StatusForbidden, models.ErrorResponse{
+					Status: false,
+					Error:  "admin access is not configured",
+				})
+			}
+
+			adminKey := strings.TrimSpace(c.Request().Header.Get(AdminKeyHeader))
+			if adminKey == "" {
+				logger.Warn("Admin request rejected: missing X-ADMIN-KEY header", zap.String("path", c.Path()))
+				return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+					Status: false,
+					Error:  "X-ADMIN-KEY header is required",
+				})
+			}
+
+			admin, ok := matchAdminKey(adminKey, config.APIKeys)
+			if !ok {
+				logger.Warn("Admin request rejected: invalid admin key", zap.String("path", c.Path()), zap.String("remote_ip", c.RealIP()))
+				return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+					Status: false,
+					Error:  "invalid admin key",
+				})
+			}
+
+			c.Set(adminContextKey, admin)
+			return next(c)
+		}
+	}
+}
+
+// AdminName returns the name of the operator key authenticated by AdminAuth
+func AdminName(c echo.Context) string {
+	name, _ := c.Get(adminContextKey).(string)
+	return name
+}
+
+// matchAdminKey compares against every configured key in constant time and returns the name of
+// the matching one
+func matchAdminKey(adminKey string, keys []models.AdminKey) (string, bool) {
+	var name string
+	matched := 0
+	for _, key := range keys {
+		if subtle.ConstantTimeCompare([]byte(adminKey), []byte(key.Key)) == 1 {
+			name = key.Name
+			matched = 1
+		}
+	}
+	return name, matched == 1
+}
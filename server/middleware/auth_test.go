@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	accountservice "cash-flow-financial/internal/services/account-service"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAccountService struct {
+	accountservice.IAccountService
+	merchants map[string]*models.GetMerchantResponse
+	err       error
+}
+
+func (f *fakeAccountService) GetMerchantByAPIKey(apiKey string) (*models.GetMerchantResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	merchant, ok := f.merchants[apiKey]
+	if !ok {
+		return nil, accountservice.ErrInvalidAPIKey
+	}
+	return merchant, nil
+}
+
+func serve(mw echo.MiddlewareFunc, header, value string) (*httptest.ResponseRecorder, string) {
+	var merchantID string
+	handler := mw(func(c echo.Context) error {
+		merchantID = MerchantID(c)
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/account/merchant", nil)
+	if value != "" {
+		req.Header.Set(header, value)
+	}
+	rec := httptest.NewRecorder()
+	_ = handler(echo.New().NewContext(req, rec))
+	return rec, merchantID
+}
+
+func TestMerchantAuth(t *testing.T) {
+	logger := loggermanager.NewLogger("error")
+	accounts := &fakeAccountService{merchants: map[string]*models.GetMerchantResponse{
+		"api_abc_secret": {MerchantID: "CASM-ABC123"},
+	}}
+	mw := MerchantAuth(accounts, logger)
+
+	rec, merchantID := serve(mw, APIKeyHeader, " api_abc_secret ")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "CASM-ABC123", merchantID)
+
+	rec, _ = serve(mw, APIKeyHeader, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "X-API-KEY header is required")
+
+	rec, _ = serve(mw, APIKeyHeader, "api_abc_wrong")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid API key")
+
+	accounts.err = errors.New("connection refused")
+	rec, _ = serve(mw, APIKeyHeader, "api_abc_secret")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestAdminAuth(t *testing.T) {
+	logger := loggermanager.NewLogger("error")
+	mw := AdminAuth(&models.AdminConfig{APIKeys: []models.AdminKey{
+		{Name: "alice", Key: "ops-key-1"},
+		{Name: "bob", Key: "ops-key-2"},
+	}}, logger)
+
+	rec, _ := serve(mw, AdminKeyHeader, "ops-key-2")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var admin string
+	handler := mw(func(c echo.Context) error {
+		admin = AdminName(c)
+		return c.NoContent(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/admin/merchants", nil)
+	req.Header.Set(AdminKeyHeader, "ops-key-1")
+	_ = handler(echo.New().NewContext(req, httptest.NewRecorder()))
+	assert.Equal(t, "alice", admin)
+
+	rec, _ = serve(mw, AdminKeyHeader, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec, _ = serve(mw, AdminKeyHeader, "ops-key")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// A merchant API key is not an admin credential
+	rec, _ = serve(mw, APIKeyHeader, "ops-key-1")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Without configured keys the admin routes stay closed
+	rec, _ = serve(AdminAuth(&models.AdminConfig{}, logger), AdminKeyHeader, "ops-key-1")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/managers/ratelimitmanager"
+	"cash-flow-financial/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+)
+
+// RateLimit counts requests against the authenticated API key or dashboard user, at the limit of
+// the merchant's tier, and otherwise against the client IP. It runs after the auth middleware on
+// merchant routes, behind AuthFailureLimit, and on its own on routes that need no credentials. Every response carries the
+// RateLimit-* headers, and requests over the limit are answered with 429 and Retry-After. A store
+// error lets the request through, so an outage of a shared store does not take the API down.
+func RateLimit(store ratelimitmanager.IStore, config *models.RateLimitConfig, logger *loggermanager.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key, limit := rateLimitKey(c, config)
+
+			usage, err := store.Increment(c.Request().Context(), key, config.Window)
+			if err != nil {
+				logger.Error("Rate limit store failed, request let through", zap.String("path", c.Path()), zap.Error(err))
+				return next(c)
+			}
+
+			reset := secondsUntil(usage.ResetAt)
+			header := c.Response().Header()
+			header.Set(RateLimitLimitHeader, strconv.Itoa(limit))
+			header.Set(RateLimitRemainingHeader, strconv.Itoa(max(limit-usage.Count, 0)))
+			header.Set(RateLimitResetHeader, strconv.Itoa(reset))
+			header.Set(RateLimitPolicyHeader, fmt.Sprintf("%d;w=%d", limit, int(config.Window.Seconds())))
+
+			if usage.Count > limit {
+				logger.Warn("Request rejected: rate limit exceeded",
+					zap.String("path", c.Path()),
+					zap.String("merchant_id", MerchantID(c)),
+					zap.String("remote_ip", c.RealIP()),
+					zap.Int("limit", limit))
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(reset))
+				return c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+					Status:  false,
+					Error:   "rate limit exceeded",
+					Details: []string{fmt.Sprintf("at most %d requests are allowed every %s; retry after %d seconds", limit, config.Window, reset)},
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// authFailureContextKey marks a request that presented an unknown token without being answered 401
+const authFailureContextKey = "auth_failure"
+
+// RecordAuthFailure has AuthFailureLimit count the request as a failed authentication. Routes that
+// take a token in place of credentials, and answer an unknown one with 400, call it so the token
+// cannot be guessed without limit.
+func RecordAuthFailure(c echo.Context) {
+	c.Set(authFailureContextKey, true)
+}
+
+// AuthFailureLimit counts requests that fail authentication against the client IP, and once an IP
+// has failed as often as the IP limit allows in a window, refuses its requests with 429 before the
+// credentials are looked up. It runs ahead of the auth middleware, so missing or invalid API keys
+// and sessions, which never reach RateLimit, cannot be tried without limit. Like RateLimit it lets
+// requests through when the store fails.
+func AuthFailureLimit(store ratelimitmanager.IStore, config *models.RateLimitConfig, logger *loggermanager.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := "auth-failure:" + c.RealIP()
+
+			usage, err := store.Peek(c.Request().Context(), key)
+			if err != nil {
+				logger.Error("Rate limit store failed, request let through", zap.String("path", c.Path()), zap.Error(err))
+				return next(c)
+			}
+			if usage.Count >= config.IPLimit {
+				reset := secondsUntil(usage.ResetAt)
+				logger.Warn("Request rejected: too many failed authentications",
+					zap.String("path", c.Path()),
+					zap.String("remote_ip", c.RealIP()),
+					zap.Int("limit", config.IPLimit))
+				c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(reset))
+				return c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+					Status:  false,
+					Error:   "too many failed authentication attempts",
+					Details: []string{fmt.Sprintf("retry after %d seconds", reset)},
+				})
+			}
+
+			err = next(c)
+			failed, _ := c.Get(authFailureContextKey).(bool)
+			if failed || c.Response().Status == http.StatusUnauthorized {
+				if _, incErr := store.Increment(c.Request().Context(), key, config.Window); incErr != nil {
+					logger.Error("Rate limit store failed, authentication failure not counted", zap.String("path", c.Path()), zap.Error(incErr))
+				}
+			}
+			return err
+		}
+	}
+}
+
+// rateLimitKey picks what the request is counted against and its limit. Each API key has its own
+// count, as does each dashboard user however many sessions they hold.
+func rateLimitKey(c echo.Context, config *models.RateLimitConfig) (string, int) {
+	merchant := Merchant(c)
+	if merchant == nil {
+		return "ip:" + c.RealIP(), config.IPLimit
+	}
+
+	limit, ok := config.TierLimits[merchant.Tier]
+	if !ok {
+		limit = config.TierLimits[models.TierStandard]
+	}
+	if user := DashboardUser(c); user != nil {
+		return "user:" + user.ID, limit
+	}
+	return "key:" + merchant.APIKeyID, limit
+}
+
+// secondsUntil rounds the time left until t up to whole seconds, and is at least 1
+func secondsUntil(t time.Time) int {
+	return max(int(math.Ceil(time.Until(t).Seconds())), 1)
+}
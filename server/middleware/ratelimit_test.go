@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/managers/ratelimitmanager"
+	"cash-flow-financial/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct{}
+
+func (failingStore) Increment(ctx context.Context, key string, window time.Duration) (ratelimitmanager.Usage, error) {
+	return ratelimitmanager.Usage{}, errors.New("store unavailable")
+}
+
+func (failingStore) Peek(ctx context.Context, key string) (ratelimitmanager.Usage, error) {
+	return ratelimitmanager.Usage{}, errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	logger := loggermanager.NewLogger("error")
+	accounts := &fakeAccountService{merchants: map[string]*models.GetMerchantResponse{
+		"api_standard_one": {MerchantID: "CASM-ABC123", APIKeyID: "key-one", MaskedAPIKey: "api_...", Tier: models.TierStandard},
+		// Legacy keys all mask the same way, so they must be told apart by ID
+		"api_standard_two": {MerchantID: "CASM-ABC123", APIKeyID: "key-two", MaskedAPIKey: "api_...", Tier: models.TierStandard},
+		"api_growth":       {MerchantID: "CASM-DEF456", APIKeyID: "key-growth", MaskedAPIKey: "api_growth_...", Tier: models.TierGrowth},
+	}}
+	config := &models.RateLimitConfig{
+		Window:     time.Minute,
+		TierLimits: map[string]int{models.TierStandard: 2, models.TierGrowth: 3},
+		IPLimit:    1,
+	}
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	rateLimit := RateLimit(ratelimitmanager.NewMemoryStore(), config, logger)
+	e.POST("/checkout/create-intent", ok, MerchantAuth(accounts, logger), rateLimit)
+	e.POST("/dashboard/login", ok, rateLimit)
+
+	request := func(path, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("/checkout/create-intent", "203.0.113.7:41000", map[string]string{APIKeyHeader: "api_standard_one"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get(RateLimitLimitHeader))
+	assert.Equal(t, "1", rec.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(t, "2;w=60", rec.Header().Get(RateLimitPolicyHeader))
+	reset, err := strconv.Atoi(rec.Header().Get(RateLimitResetHeader))
+	assert.NoError(t, err)
+	assert.InDelta(t, 60, reset, 1)
+
+	assert.Equal(t, http.StatusOK, request("/checkout/create-intent", "203.0.113.7:41000", map[string]string{APIKeyHeader: "api_standard_one"}).Code)
+
+	rec = request("/checkout/create-intent", "203.0.113.7:41000", map[string]string{APIKeyHeader: "api_standard_one"})
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(t, rec.Header().Get(RateLimitResetHeader), rec.Header().Get(echo.HeaderRetryAfter))
+	assert.Contains(t, rec.Body.String(), "rate limit exceeded")
+
+	// Each key has its own count, and the tier sets the limit
+	assert.Equal(t, http.StatusOK, request("/checkout/create-intent", "203.0.113.7:41000", map[string]string{APIKeyHeader: "api_standard_two"}).Code)
+	rec = request("/checkout/create-intent", "203.0.113.7:41000", map[string]string{APIKeyHeader: "api_growth"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "3", rec.Header().Get(RateLimitLimitHeader))
+
+	// Routes without credentials are counted per client IP, which headers from the client cannot change
+	assert.Equal(t, http.StatusOK, request("/dashboard/login", "203.0.113.7:41000", nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("/dashboard/login", "203.0.113.7:41000", nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("/dashboard/login", "203.0.113.7:41000", map[string]string{
+		echo.HeaderXRealIP:       "198.51.100.1",
+		echo.HeaderXForwardedFor: "198.51.100.2",
+	}).Code)
+	assert.Equal(t, http.StatusOK, request("/dashboard/login", "203.0.113.8:41000", nil).Code)
+}
+
+func TestAuthFailureLimit(t *testing.T) {
+	logger := loggermanager.NewLogger("error")
+	accounts := &fakeAccountService{merchants: map[string]*models.GetMerchantResponse{
+		"api_valid": {MerchantID: "CASM-ABC123", APIKeyID: "key-valid", Tier: models.TierStandard},
+	}}
+	config := &models.RateLimitConfig{
+		Window:     time.Minute,
+		TierLimits: map[string]int{models.TierStandard: 100},
+		IPLimit:    2,
+	}
+	store := ratelimitmanager.NewMemoryStore()
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.GET("/account/merchant", func(c echo.Context) error { return c.NoContent(http.StatusOK) },
+		AuthFailureLimit(store, config, logger), MerchantAuth(accounts, logger), RateLimit(store, config, logger))
+
+	request := func(apiKey, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/account/merchant", nil)
+		req.RemoteAddr = remoteAddr
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeader, apiKey)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Valid keys are not counted as failures
+	for range 3 {
+		assert.Equal(t, http.StatusOK, request("api_valid", "203.0.113.7:41000").Code)
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request("api_guess_1", "203.0.113.7:41000").Code)
+	assert.Equal(t, http.StatusUnauthorized, request("", "203.0.113.7:41001").Code)
+
+	rec := request("api_guess_2", "203.0.113.7:41002")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+	assert.Equal(t, http.StatusTooManyRequests, request("api_valid", "203.0.113.7:41000").Code, "the IP is refused until the window ends")
+
+	assert.Equal(t, http.StatusOK, request("api_valid", "203.0.113.8:41000").Code)
+}
+
+func TestRateLimit_StoreFailureLetsRequestsThrough(t *testing.T) {
+	config := &models.RateLimitConfig{Window: time.Minute, IPLimit: 1}
+
+	e := echo.New()
+	e.POST("/dashboard/login", func(c echo.Context) error { return c.NoContent(http.StatusOK) }, RateLimit(failingStore{}, config, loggermanager.NewLogger("error")))
+
+	for range 3 {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/dashboard/login", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(RateLimitLimitHeader))
+	}
+}
+
+func TestAuthFailureLimit_RecordedFailures(t *testing.T) {
+	logger := loggermanager.NewLogger("error")
+	config := &models.RateLimitConfig{Window: time.Minute, IPLimit: 2}
+	store := ratelimitmanager.NewMemoryStore()
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	// Stands in for a route that takes a token in place of credentials and answers a wrong one with 400
+	e.GET("/account/verify-email", func(c echo.Context) error {
+		if c.QueryParam("token") != "valid" {
+			RecordAuthFailure(c)
+			return c.NoContent(http.StatusBadRequest)
+		}
+		return c.NoContent(http.StatusOK)
+	}, AuthFailureLimit(store, config, logger))
+
+	request := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/account/verify-email?token="+token, nil)
+		req.RemoteAddr = "203.0.113.9:41000"
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("valid"))
+	assert.Equal(t, http.StatusBadRequest, request("guess-1"))
+	assert.Equal(t, http.StatusBadRequest, request("guess-2"))
+	assert.Equal(t, http.StatusTooManyRequests, request("guess-3"))
+	assert.Equal(t, http.StatusTooManyRequests, request("valid"), "the IP is refused until the window ends")
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// RequireScope rejects requests whose API key, or signed-in user's role, lacks the scope with 403.
+// It runs after MerchantAuth, SessionAuth or MerchantOrSessionAuth.
+func RequireScope(scope string, logger *loggermanager.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !HasScope(c, scope) {
+				logger.Warn("Request rejected: missing scope",
+					zap.String("path", c.Path()),
+					zap.String("merchant_id", MerchantID(c)),
+					zap.String("scope", scope))
+				if user := DashboardUser(c); user != nil {
+					return c.JSON(http.StatusForbidden, models.ErrorResponse{
+						Status:  false,
+						Error:   "the " + user.Role + " role does not grant the required scope: " + scope,
+						Details: []string{"ask an owner or admin for a role with the " + scope + " scope"},
+					})
+				}
+				return c.JSON(http.StatusForbidden, models.ErrorResponse{
+					Status:  false,
+					Error:   "API key is missing the required scope: " + scope,
+					Details: []string{"create a key with the " + scope + " scope to call this endpoint"},
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// HasScope reports whether the authenticated API key or dashboard role carries the scope
+func HasScope(c echo.Context, scope string) bool {
+	merchant := Merchant(c)
+	return merchant != nil && slices.Contains(merchant.APIKeyScopes, scope)
+}
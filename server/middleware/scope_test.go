@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireScope(t *testing.T) {
+	logger := loggermanager.NewLogger("error")
+	accounts := &fakeAccountService{merchants: map[string]*models.GetMerchantResponse{
+		"api_storefront_secret": {MerchantID: "CASM-ABC123", APIKeyScopes: []string{models.ScopeIntentsWrite}},
+	}}
+
+	e := echo.New()
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.POST("/checkout/create-intent", ok, MerchantAuth(accounts, logger), RequireScope(models.ScopeIntentsWrite, logger))
+	e.GET("/account/merchant", ok, MerchantAuth(accounts, logger), RequireScope(models.ScopeBalancesRead, logger))
+
+	req := httptest.NewRequest(http.MethodPost, "/checkout/create-intent", nil)
+	req.Header.Set(APIKeyHeader, "api_storefront_secret")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/account/merchant", nil)
+	req.Header.Set(APIKeyHeader, "api_storefront_secret")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "balances:read")
+
+	// Authentication still comes first
+	req = httptest.NewRequest(http.MethodGet, "/account/merchant", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
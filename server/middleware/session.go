@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	accountservice "cash-flow-financial/internal/services/account-service"
+	userservice "cash-flow-financial/internal/services/user-service"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const sessionContextKey = "session"
+
+// SessionAuth resolves the dashboard session in the Authorization: Bearer header. Like MerchantAuth
+// it stores the merchant in the request context, carrying the scopes of the user's role, so
+// RequireScope and the merchant handlers work unchanged behind it.
+func SessionAuth(userService userservice.IUserService, logger *loggermanager.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := bearerToken(c.Request().Header.Get(echo.HeaderAuthorization))
+			if !ok {
+				logger.Warn("Request rejected: missing session token", zap.String("path", c.Path()))
+				return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+					Status: false,
+					Error:  "Authorization: Bearer <session_token> header is required",
+				})
+			}
+
+			principal, err := userService.Authenticate(c.Request().Context(), token)
+			if err != nil {
+				if errors.Is(err, userservice.ErrInvalidSession) {
+					logger.Warn("Request rejected: invalid session", zap.String("path", c.Path()))
+					return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+						Status:  false,
+						Error:   "invalid or expired session",
+						Details: []string{"sign in again with POST /dashboard/login"},
+					})
+				}
+				logger.Error("Session lookup failed", zap.String("path", c.Path()), zap.Error(err))
+				return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+					Status: false,
+					Error:  "internal server error",
+				})
+			}
+
+			c.Set(merchantContextKey, &principal.Merchant)
+			c.Set(sessionContextKey, principal)
+			return next(c)
+		}
+	}
+}
+
+// MerchantOrSessionAuth accepts either an API key or a dashboard session. The X-API-KEY header
+// wins when both are sent; requests with neither get MerchantAuth's error.
+func MerchantOrSessionAuth(accountService accountservice.IAccountService, userService userservice.IUserService, logger *loggermanager.Logger) echo.MiddlewareFunc {
+	merchantAuth := MerchantAuth(accountService, logger)
+	sessionAuth := SessionAuth(userService, logger)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		byAPIKey := merchantAuth(next)
+		bySession := sessionAuth(next)
+		return func(c echo.Context) error {
+			header := c.Request().Header
+			if header.Get(APIKeyHeader) != "" || header.Get(echo.HeaderAuthorization) == "" {
+				return byAPIKey(c)
+			}
+			return bySession(c)
+		}
+	}
+}
+
+// Session returns the dashboard session authenticated by SessionAuth, or nil when the request was
+// made with an API key
+func Session(c echo.Context) *models.DashboardPrincipal {
+	principal, _ := c.Get(sessionContextKey).(*models.DashboardPrincipal)
+	return principal
+}
+
+// DashboardUser returns the signed-in user, or nil when the request was made with an API key
+func DashboardUser(c echo.Context) *models.DashboardUser {
+	if principal := Session(c); principal != nil {
+		return &principal.User
+	}
+	return nil
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	token = strings.TrimSpace(token)
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
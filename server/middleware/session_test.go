@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+	userservice "cash-flow-financial/internal/services/user-service"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeUserService struct {
+	userservice.IUserService
+	sessions map[string]*models.DashboardPrincipal
+}
+
+func (f *fakeUserService) Authenticate(ctx context.Context, sessionToken string) (*models.DashboardPrincipal, error) {
+	principal, ok := f.sessions[sessionToken]
+	if !ok {
+		return nil, userservice.ErrInvalidSession
+	}
+	return principal, nil
+}
+
+func TestMerchantOrSessionAuth(t *testing.T) {
+	logger := loggermanager.NewLogger("error")
+	accounts := &fakeAccountService{merchants: map[string]*models.GetMerchantResponse{
+		"api_abc_secret": {MerchantID: "CASM-ABC123", APIKeyScopes: models.APIKeyScopes},
+	}}
+	users := &fakeUserService{sessions: map[string]*models.DashboardPrincipal{
+		"sess_finance": {
+			User:     models.DashboardUser{Email: "finance@example.com", Role: models.RoleFinance},
+			Merchant: models.GetMerchantResponse{MerchantID: "CASM-ABC123", APIKeyScopes: models.RoleScopes[models.RoleFinance]},
+		},
+	}}
+
+	var user *models.DashboardUser
+	e := echo.New()
+	ok := func(c echo.Context) error {
+		user = DashboardUser(c)
+		return c.NoContent(http.StatusOK)
+	}
+	auth := MerchantOrSessionAuth(accounts, users, logger)
+	e.GET("/account/merchant", ok, auth, RequireScope(models.ScopeBalancesRead, logger))
+	e.POST("/webhooks", ok, auth, RequireScope(models.ScopeWebhooksWrite, logger))
+
+	request := func(method, path string, headers map[string]string) *httptest.ResponseRecorder {
+		user = nil
+		req := httptest.NewRequest(method, path, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodGet, "/account/merchant", map[string]string{echo.HeaderAuthorization: "Bearer sess_finance"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "finance@example.com", user.Email)
+	}
+
+	// The role decides the scopes
+	rec = request(http.MethodPost, "/webhooks", map[string]string{echo.HeaderAuthorization: "Bearer sess_finance"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "finance role does not grant the required scope: webhooks:write")
+
+	// API keys keep working and win over a session header
+	rec = request(http.MethodPost, "/webhooks", map[string]string{
+		APIKeyHeader:             "api_abc_secret",
+		echo.HeaderAuthorization: "Bearer sess_finance",
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, user)
+
+	rec = request(http.MethodGet, "/account/merchant", map[string]string{echo.HeaderAuthorization: "Bearer sess_revoked"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid or expired session")
+
+	rec = request(http.MethodGet, "/account/merchant", map[string]string{echo.HeaderAuthorization: "Basic abc"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = request(http.MethodGet, "/account/merchant", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "X-API-KEY header is required")
+}
+
+func TestBearerToken(t *testing.T) {
+	token, ok := bearerToken("Bearer sess_abc")
+	assert.True(t, ok)
+	assert.Equal(t, "sess_abc", token)
+
+	token, ok = bearerToken("  bearer   sess_abc ")
+	assert.True(t, ok)
+	assert.Equal(t, "sess_abc", token)
+
+	for _, header := range []string{"", "Bearer", "Bearer ", "Basic sess_abc", "sess_abc"} {
+		_, ok := bearerToken(header)
+		assert.False(t, ok, header)
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// RequireActiveMerchant rejects requests from suspended merchants with 403. It runs after
+// MerchantAuth, which already refuses inactive merchants.
+func RequireActiveMerchant(logger *loggermanager.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			merchant := Merchant(c)
+			if merchant == nil || merchant.MerchantStatus != models.MerchantStatusActive {
+				logger.Warn("Request rejected: merchant is not active",
+					zap.String("path", c.Path()),
+					zap.String("merchant_id", MerchantID(c)))
+				return c.JSON(http.StatusForbidden, models.ErrorResponse{
+					Status:  false,
+					Error:   "merchant account is suspended",
+					Details: []string{"contact support to reactivate the account"},
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireVerifiedEmail rejects live-mode requests from merchants whose email is not verified with
+// 403. Test keys are let through so integration can start before the address is confirmed.
+func RequireVerifiedEmail(logger *loggermanager.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			merchant := Merchant(c)
+			if merchant == nil || (merchant.Mode != models.ModeTest && !merchant.EmailVerified) {
+				logger.Warn("Request rejected: merchant email is not verified",
+					zap.String("path", c.Path()),
+					zap.String("merchant_id", MerchantID(c)))
+				return c.JSON(http.StatusForbidden, models.ErrorResponse{
+					Status:  false,
+					Error:   "email address is not verified",
+					Details: []string{"follow the link sent to the account email, or request a new one from POST /account/email/verification"},
+				})
+			}
+			return next(c)
+		}
+	}
+}
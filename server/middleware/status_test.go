@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireActiveMerchant(t *testing.T) {
+	logger := loggermanager.NewLogger("error")
+	accounts := &fakeAccountService{merchants: map[string]*models.GetMerchantResponse{
+		"api_active_secret":    {MerchantID: "CASM-ABC123", MerchantStatus: models.MerchantStatusActive},
+		"api_suspended_secret": {MerchantID: "CASM-DEF456", MerchantStatus: models.MerchantStatusSuspended},
+	}}
+
+	e := echo.New()
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.POST("/checkout/create-intent", ok, MerchantAuth(accounts, logger), RequireActiveMerchant(logger))
+	e.GET("/account/merchant", ok, MerchantAuth(accounts, logger))
+
+	request := func(method, path, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(APIKeyHeader, apiKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/checkout/create-intent", "api_active_secret").Code)
+
+	rec := request(http.MethodPost, "/checkout/create-intent", "api_suspended_secret")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "suspended")
+
+	// A suspended merchant can still read its account
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/account/merchant", "api_suspended_secret").Code)
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	logger := loggermanager.NewLogger("error")
+	accounts := &fakeAccountService{merchants: map[string]*models.GetMerchantResponse{
+		"api_live_verified":   {MerchantID: "CASM-ABC123", Mode: models.ModeLive, EmailVerified: true},
+		"api_live_unverified": {MerchantID: "CASM-DEF456", Mode: models.ModeLive},
+		"api_test_unverified": {MerchantID: "CASM-DEF456", Mode: models.ModeTest},
+	}}
+
+	e := echo.New()
+	e.POST("/checkout/create-intent", func(c echo.Context) error { return c.NoContent(http.StatusOK) },
+		MerchantAuth(accounts, logger), RequireVerifiedEmail(logger))
+
+	request := func(apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/checkout/create-intent", nil)
+		req.Header.Set(APIKeyHeader, apiKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, request("api_live_verified").Code)
+	assert.Equal(t, http.StatusOK, request("api_test_unverified").Code)
+
+	rec := request("api_live_unverified")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "email address is not verified")
+}
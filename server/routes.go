@@ -1,8 +1,15 @@
 package server
 
 import (
+	"net/http"
+
+	"cash-flow-financial/internal/models"
 	"cash-flow-financial/server/handlers/account"
+	"cash-flow-financial/server/handlers/admin"
 	"cash-flow-financial/server/handlers/checkout"
+	"cash-flow-financial/server/handlers/dashboard"
+	"cash-flow-financial/server/handlers/webhook"
+	authmiddleware "cash-flow-financial/server/middleware"
 
 	"github.com/labstack/echo/v4"
 	echoSwagger "github.com/swaggo/echo-swagger"
@@ -15,22 +22,114 @@ func (s *Server) setupRoutes() {
 	// Swagger documentation
 	s.echo.GET("/swagger/*", echoSwagger.WrapHandler)
 
-	checkoutHandler := checkout.NewCheckoutHandler(s.ICHECKOUTSERVICE, s.IACCOUNTSERVICE, s.config, s.logger, s.IRabbitMQManager)
+	checkoutHandler := checkout.NewCheckoutHandler(s.ICHECKOUTSERVICE, s.config, s.logger, s.IBroker)
 	accountHandler := account.NewAccountHandler(s.IACCOUNTSERVICE, s.config, s.logger)
+	webhookHandler := webhook.NewWebhookHandler(s.IWEBHOOKSERVICE, s.config, s.logger)
+	adminHandler := admin.NewAdminHandler(s.IWorker, s.IScheduler, s.IMERCHANTSERVICE, s.config, s.logger)
+	dashboardHandler := dashboard.NewDashboardHandler(s.IUSERSERVICE, s.config, s.logger)
+
+	if len(s.config.Admin.APIKeys) == 0 {
+		s.logger.Warn("ADMIN_API_KEYS is not set, admin routes and merchant creation are disabled")
+	}
 
 	apiV1 := s.echo.Group("/cashflow_test/v1")
 
+	// Merchant routes resolve X-API-KEY, or a dashboard session, to the merchant they act for and
+	// require a scope of that key or of the user's role; operator routes require X-ADMIN-KEY. Both are
+	// attached per route, as group middleware would also answer unknown paths. Merchant routes are
+	// rate limited per API key or user once authenticated, and public routes per client IP. Failed
+	// authentications, sign-ins and token checks, admin keys included, are also counted per client
+	// IP, ahead of the key lookup.
+	merchantAuth := authmiddleware.MerchantOrSessionAuth(s.IACCOUNTSERVICE, s.IUSERSERVICE, s.logger)
+	sessionAuth := authmiddleware.SessionAuth(s.IUSERSERVICE, s.logger)
+	adminAuth := authmiddleware.AdminAuth(&s.config.Admin, s.logger)
+	requireScope := func(scope string) echo.MiddlewareFunc {
+		return authmiddleware.RequireScope(scope, s.logger)
+	}
+	rateLimit := authmiddleware.RateLimit(s.IRateLimitStore, &s.config.RateLimit, s.logger)
+	authFailureLimit := authmiddleware.AuthFailureLimit(s.IRateLimitStore, &s.config.RateLimit, s.logger)
+	requireActiveMerchant := authmiddleware.RequireActiveMerchant(s.logger)
+	requireVerifiedEmail := authmiddleware.RequireVerifiedEmail(s.logger)
+
 	// Checkout routes
-	apiV1.POST("/checkout/create-intent", checkoutHandler.CreateIntent)
+	apiV1.POST("/checkout/create-intent", checkoutHandler.CreateIntent, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeIntentsWrite), requireActiveMerchant, requireVerifiedEmail)
+	apiV1.GET("/checkout/intents/:id", checkoutHandler.GetIntent, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeIntentsRead))
 
 	// Account routes
-	apiV1.POST("/account/create-merchant", accountHandler.CreateMerchantAPI)
-	apiV1.GET("/account/merchant", accountHandler.GetMerchantAPI) // Requires merchant_id query param, returns merchant details, balances, and transactions
+	apiV1.POST("/account/create-merchant", accountHandler.CreateMerchantAPI, authFailureLimit, adminAuth)
+	apiV1.GET("/account/merchant", accountHandler.GetMerchantAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeBalancesRead))
+	apiV1.POST("/account/webhook-secret/rotate", accountHandler.RotateWebhookSecretAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeWebhooksWrite))
+	apiV1.POST("/account/api-keys", accountHandler.CreateAPIKeyAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeKeysWrite))
+	apiV1.GET("/account/api-keys", accountHandler.ListAPIKeysAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeKeysRead))
+	apiV1.POST("/account/api-keys/:id/revoke", accountHandler.RevokeAPIKeyAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeKeysWrite))
+	apiV1.POST("/account/api-keys/:id/rotate", accountHandler.RotateAPIKeyAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeKeysWrite))
+	apiV1.PATCH("/account/profile", accountHandler.UpdateProfileAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeAccountWrite))
+	apiV1.POST("/account/email/verification", accountHandler.SendVerificationEmailAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeAccountWrite))
+	// Opened from the emailed link, so the signed token stands in for an API key
+	apiV1.GET("/account/verify-email", accountHandler.VerifyEmailAPI, authFailureLimit, rateLimit)
+
+	// Webhook routes
+	apiV1.GET("/webhooks/deliveries", webhookHandler.ListDeliveriesAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeWebhooksRead))
+	apiV1.GET("/webhooks/deliveries/:id", webhookHandler.GetDeliveryAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeWebhooksRead))
+	apiV1.POST("/webhooks/deliveries/:id/resend", webhookHandler.ResendDeliveryAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeWebhooksWrite))
+	apiV1.POST("/webhooks/test", webhookHandler.SendTestWebhookAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeWebhooksWrite))
+	apiV1.POST("/webhooks/endpoints", webhookHandler.CreateEndpointAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeWebhooksWrite))
+	apiV1.GET("/webhooks/endpoints", webhookHandler.ListEndpointsAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeWebhooksRead))
+	apiV1.PATCH("/webhooks/endpoints/:id", webhookHandler.UpdateEndpointAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeWebhooksWrite))
+	apiV1.DELETE("/webhooks/endpoints/:id", webhookHandler.DeleteEndpointAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeWebhooksWrite))
+
+	// Dashboard routes. Sign-in and invitation acceptance are public; the /me routes act on the
+	// signed-in user, and user management also accepts an API key so the first owner can be invited.
+	apiV1.POST("/dashboard/login", dashboardHandler.LoginAPI, authFailureLimit, rateLimit)
+	apiV1.POST("/dashboard/invites/accept", dashboardHandler.AcceptInviteAPI, authFailureLimit, rateLimit)
+	apiV1.POST("/dashboard/logout", dashboardHandler.LogoutAPI, authFailureLimit, sessionAuth, rateLimit)
+	apiV1.GET("/dashboard/me", dashboardHandler.GetMeAPI, authFailureLimit, sessionAuth, rateLimit)
+	apiV1.POST("/dashboard/me/totp", dashboardHandler.EnrollTOTPAPI, authFailureLimit, sessionAuth, rateLimit)
+	apiV1.POST("/dashboard/me/totp/confirm", dashboardHandler.ConfirmTOTPAPI, authFailureLimit, sessionAuth, rateLimit)
+	apiV1.POST("/dashboard/me/totp/disable", dashboardHandler.DisableTOTPAPI, authFailureLimit, sessionAuth, rateLimit)
+	apiV1.GET("/dashboard/users", dashboardHandler.ListUsersAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeUsersRead))
+	apiV1.POST("/dashboard/users", dashboardHandler.InviteUserAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeUsersWrite))
+	apiV1.PATCH("/dashboard/users/:id", dashboardHandler.UpdateUserRoleAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeUsersWrite))
+	apiV1.DELETE("/dashboard/users/:id", dashboardHandler.RemoveUserAPI, authFailureLimit, merchantAuth, rateLimit, requireScope(models.ScopeUsersWrite))
+
+	// Admin routes (worker stats are only available when the worker runs in this process)
+	if s.IWorker != nil {
+		apiV1.GET("/admin/worker/stats", adminHandler.GetWorkerStatsAPI, authFailureLimit, adminAuth)
+	}
+	apiV1.GET("/admin/jobs", adminHandler.ListScheduledJobsAPI, authFailureLimit, adminAuth)
+	apiV1.POST("/admin/jobs/:name/run", adminHandler.TriggerScheduledJobAPI, authFailureLimit, adminAuth)
+	apiV1.GET("/admin/merchants", adminHandler.ListMerchantsAPI, authFailureLimit, adminAuth)
+	apiV1.GET("/admin/merchants/:id", adminHandler.GetMerchantAPI, authFailureLimit, adminAuth)
+	apiV1.POST("/admin/merchants/:id/suspend", adminHandler.SuspendMerchantAPI, authFailureLimit, adminAuth)
+	apiV1.POST("/admin/merchants/:id/reactivate", adminHandler.ReactivateMerchantAPI, authFailureLimit, adminAuth)
+	apiV1.POST("/admin/merchants/:id/deactivate", adminHandler.DeactivateMerchantAPI, authFailureLimit, adminAuth)
+	apiV1.POST("/admin/merchants/:id/tier", adminHandler.ChangeMerchantTierAPI, authFailureLimit, adminAuth)
 }
 
 func (s *Server) healthCheck(c echo.Context) error {
-	return c.JSON(200, map[string]interface{}{
-		"status":  "healthy",
-		"service": "cash-flow-financial",
-	})
+	response := models.HealthResponse{
+		Status:  "healthy",
+		Service: "cash-flow-financial",
+		Mode:    s.config.App.Mode,
+		Checks:  map[string]string{},
+	}
+
+	if err := s.IDBManager.IsHealthy(); err != nil {
+		response.Status = "unhealthy"
+		response.Checks["database"] = err.Error()
+	} else {
+		response.Checks["database"] = "ok"
+	}
+
+	if err := s.IBroker.HealthCheck(); err != nil {
+		response.Status = "unhealthy"
+		response.Checks["broker"] = err.Error()
+	} else {
+		response.Checks["broker"] = "ok"
+	}
+
+	if response.Status != "healthy" {
+		return c.JSON(http.StatusServiceUnavailable, response)
+	}
+	return c.JSON(http.StatusOK, response)
 }
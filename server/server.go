@@ -2,15 +2,22 @@ package server
 
 import (
 	"cash-flow-financial/docs"
+	"cash-flow-financial/internal/managers/brokermanager"
 	"cash-flow-financial/internal/managers/dbmanager"
 	logger "cash-flow-financial/internal/managers/loggermanager"
-	"cash-flow-financial/internal/managers/rabbitmqmanager"
+	"cash-flow-financial/internal/managers/ratelimitmanager"
 	"cash-flow-financial/internal/models"
 	accountservice "cash-flow-financial/internal/services/account-service"
 	checkoutservice "cash-flow-financial/internal/services/checkout-service"
+	merchantservice "cash-flow-financial/internal/services/merchant-service"
 	transactionservice "cash-flow-financial/internal/services/transaction-service"
+	userservice "cash-flow-financial/internal/services/user-service"
+	webhookservice "cash-flow-financial/internal/services/webhook-service"
+	"cash-flow-financial/scheduler"
+	"cash-flow-financial/worker"
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -21,10 +28,16 @@ import (
 
 type Server struct {
 	IDBManager          dbmanager.IDBManager
-	IRabbitMQManager    rabbitmqmanager.IRabbitMQManager
+	IBroker             brokermanager.IBroker
+	IRateLimitStore     ratelimitmanager.IStore
 	ICHECKOUTSERVICE    checkoutservice.ICheckoutService
 	IACCOUNTSERVICE     accountservice.IAccountService
 	ITRANSACTIONSERVICE transactionservice.ITransactionService
+	IWEBHOOKSERVICE     webhookservice.IWebhookService
+	IMERCHANTSERVICE    merchantservice.IMerchantService
+	IUSERSERVICE        userservice.IUserService
+	IWorker             worker.IWorker
+	IScheduler          scheduler.IScheduler
 	echo                *echo.Echo
 	config              *models.Config
 	logger              *logger.Logger
@@ -40,8 +53,9 @@ type Server struct {
 // @host localhost:3074
 // @BasePath /cashflow_test/v1
 // @schemes http
-func NewServer(cfg *models.Config, log *logger.Logger, checkoutSvc checkoutservice.ICheckoutService, accountSvc accountservice.IAccountService, transactionSvc transactionservice.ITransactionService, dbMgr dbmanager.IDBManager, rabbitMgr rabbitmqmanager.IRabbitMQManager) *Server {
+func NewServer(cfg *models.Config, log *logger.Logger, checkoutSvc checkoutservice.ICheckoutService, accountSvc accountservice.IAccountService, transactionSvc transactionservice.ITransactionService, webhookSvc webhookservice.IWebhookService, merchantSvc merchantservice.IMerchantService, userSvc userservice.IUserService, dbMgr dbmanager.IDBManager, broker brokermanager.IBroker, rateLimitStore ratelimitmanager.IStore, paymentWorker worker.IWorker, jobScheduler scheduler.IScheduler) *Server {
 	e := echo.New()
+	e.IPExtractor = ipExtractor(cfg.Server.TrustedProxies)
 
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
@@ -51,10 +65,16 @@ func NewServer(cfg *models.Config, log *logger.Logger, checkoutSvc checkoutservi
 
 	server := &Server{
 		IDBManager:          dbMgr,
-		IRabbitMQManager:    rabbitMgr,
+		IBroker:             broker,
+		IRateLimitStore:     rateLimitStore,
 		ICHECKOUTSERVICE:    checkoutSvc,
 		IACCOUNTSERVICE:     accountSvc,
 		ITRANSACTIONSERVICE: transactionSvc,
+		IWEBHOOKSERVICE:     webhookSvc,
+		IMERCHANTSERVICE:    merchantSvc,
+		IUSERSERVICE:        userSvc,
+		IWorker:             paymentWorker,
+		IScheduler:          jobScheduler,
 		echo:                e,
 		config:              cfg,
 		logger:              log,
@@ -65,6 +85,20 @@ func NewServer(cfg *models.Config, log *logger.Logger, checkoutSvc checkoutservi
 	return server
 }
 
+// ipExtractor reads the client IP, which rate limits count against, from X-Forwarded-For only when
+// the request came through a trusted proxy. Otherwise the connection's address is used, since a
+// client could put any address in the headers.
+func ipExtractor(trustedProxies []*net.IPNet) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range trustedProxies {
+		options = append(options, echo.TrustIPRange(proxy))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
 func (s *Server) Start(ctx context.Context) error {
 	address := fmt.Sprintf(":%s", s.config.Server.Port)
 	s.logger.Info("Starting server", zap.String("port", s.config.Server.Port))
@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+
+	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// recordSettlementEvents stages the success events; qtx must be bound to the settlement transaction
+func (w *Worker) recordSettlementEvents(ctx context.Context, qtx *db.Queries, paymentIntentInfo *db.GetPaymentIntentRow, transaction *db.PaymentTransaction, balance *db.MerchantBalance, creditedAmount string) error {
+	return w.recordEvents(ctx, qtx, paymentIntentInfo.PaymentIntentID, []pendingEvent{
+		{events.TypePaymentTransactionSucceeded, transactionEventData(transaction, "")},
+		{events.TypePaymentIntentSucceeded, intentEventData(paymentIntentInfo, string(db.PaymentStatusSuccess), "")},
+		{events.TypeMerchantBalanceCredited, events.MerchantBalanceData{
+			MerchantID:       paymentIntentInfo.MerchantID,
+			Mode:             string(balance.Mode),
+			PaymentIntentID:  paymentIntentInfo.PaymentIntentID,
+			Currency:         string(balance.Currency),
+			CreditedAmount:   creditedAmount,
+			FeeAmount:        transaction.FeeAmount.String,
+			AvailableBalance: balance.AvailableBalance.String,
+			TotalDeposit:     balance.TotalDeposit.String,
+			TransactionCount: balance.TotalTransactionCount.Int32,
+		}},
+	})
+}
+
+// recordFailureEvents stages the failure events; qtx must be bound to the transaction that marks the payment failed
+func (w *Worker) recordFailureEvents(ctx context.Context, qtx *db.Queries, paymentIntentInfo *db.GetPaymentIntentRow, transaction *db.PaymentTransaction, reason string) error {
+	return w.recordEvents(ctx, qtx, paymentIntentInfo.PaymentIntentID, []pendingEvent{
+		{events.TypePaymentTransactionFailed, transactionEventData(transaction, reason)},
+		{events.TypePaymentIntentFailed, intentEventData(paymentIntentInfo, string(db.PaymentStatusFailed), reason)},
+	})
+}
+
+type pendingEvent struct {
+	eventType string
+	data      interface{}
+}
+
+// recordEvents writes events in slice order, which is the order the relay publishes them in
+func (w *Worker) recordEvents(ctx context.Context, qtx *db.Queries, paymentIntentID string, pending []pendingEvent) error {
+	for _, event := range pending {
+		envelope, err := events.New(event.eventType, events.SourcePaymentWorker, paymentIntentID, event.data)
+		if err != nil {
+			return err
+		}
+		if err := events.Record(ctx, qtx, envelope); err != nil {
+			return err
+		}
+
+		w.logger.Info("Recorded domain event",
+			zap.String("event_id", envelope.ID),
+			zap.String("event_type", event.eventType),
+			zap.String("payment_intent_id", paymentIntentID))
+	}
+	return nil
+}
+
+func intentEventData(paymentIntentInfo *db.GetPaymentIntentRow, status, reason string) events.PaymentIntentData {
+	return events.PaymentIntentData{
+		PaymentIntentID: paymentIntentInfo.PaymentIntentID,
+		MerchantID:      paymentIntentInfo.MerchantID,
+		Mode:            string(paymentIntentInfo.Mode),
+		Amount:          paymentIntentInfo.Amount,
+		Currency:        string(paymentIntentInfo.Currency),
+		Status:          status,
+		FailureReason:   reason,
+	}
+}
+
+func transactionEventData(transaction *db.PaymentTransaction, reason string) events.PaymentTransactionData {
+	return events.PaymentTransactionData{
+		TransactionID:       transaction.ID.String(),
+		PaymentIntentID:     transaction.PaymentIntentID,
+		MerchantID:          transaction.MerchantID,
+		Mode:                string(transaction.Mode),
+		Amount:              transaction.Amount,
+		Currency:            string(transaction.Currency),
+		Status:              string(transaction.Status.TransactionStatus),
+		FeeAmount:           transaction.FeeAmount.String,
+		PaymentMethod:       string(transaction.PaymentMethod.PaymentMethodType),
+		ThirdPartyReference: transaction.ThirdPartyReference.String,
+		FailureReason:       reason,
+	}
+}
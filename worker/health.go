@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cash-flow-financial/internal/managers/brokermanager"
+	"cash-flow-financial/internal/managers/dbmanager"
+	"cash-flow-financial/internal/managers/loggermanager"
+	"cash-flow-financial/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// HealthServer exposes liveness for worker-only processes, which run without Echo. Pool stats are
+// left to the admin API, since this port has no authentication.
+type HealthServer struct {
+	dbManager dbmanager.IDBManager
+	broker    brokermanager.IBroker
+	config    *models.Config
+	logger    *loggermanager.Logger
+	server    *http.Server
+}
+
+func NewHealthServer(cfg *models.Config, logger *loggermanager.Logger, dbMgr dbmanager.IDBManager, broker brokermanager.IBroker) *HealthServer {
+	hs := &HealthServer{
+		dbManager: dbMgr,
+		broker:    broker,
+		config:    cfg,
+		logger:    logger,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", hs.healthCheck)
+
+	hs.server = &http.Server{
+		Addr:              fmt.Sprintf(":%s", cfg.Worker.HealthPort),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return hs
+}
+
+func (hs *HealthServer) Start(ctx context.Context) error {
+	hs.logger.Info("Starting worker health server", zap.String("port", hs.config.Worker.HealthPort))
+
+	go func() {
+		if err := hs.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			hs.logger.Error("Failed to start worker health server", zap.Error(err))
+		}
+	}()
+
+	<-ctx.Done()
+	hs.logger.Info("Shutting down worker health server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := hs.server.Shutdown(shutdownCtx); err != nil {
+		hs.logger.Error("Worker health server forced to shutdown", zap.Error(err))
+		return err
+	}
+
+	hs.logger.Info("Worker health server stopped")
+	return nil
+}
+
+func (hs *HealthServer) healthCheck(w http.ResponseWriter, r *http.Request) {
+	response := models.HealthResponse{
+		Status:  "healthy",
+		Service: "cash-flow-financial",
+		Mode:    hs.config.App.Mode,
+		Checks:  map[string]string{},
+	}
+
+	if err := hs.dbManager.IsHealthy(); err != nil {
+		response.Status = "unhealthy"
+		response.Checks["database"] = err.Error()
+	} else {
+		response.Checks["database"] = "ok"
+	}
+
+	if err := hs.broker.HealthCheck(); err != nil {
+		response.Status = "unhealthy"
+		response.Checks["broker"] = err.Error()
+	} else {
+		response.Checks["broker"] = "ok"
+	}
+
+	statusCode := http.StatusOK
+	if response.Status != "healthy" {
+		statusCode = http.StatusServiceUnavailable
+	}
+	writeJSON(w, statusCode, response)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
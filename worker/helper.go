@@ -3,10 +3,18 @@ package worker
 import (
 	"cash-flow-financial/internal/db"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"math/big"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+// statusUpdateTimeout bounds the writes that record how a payment attempt ended, which run even after
+// processing is aborted
+const statusUpdateTimeout = 10 * time.Second
+
 func generateThirdPartyReference() string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	b := make([]byte, 7)
@@ -45,3 +53,18 @@ func generateAccountNumber(paymentMethod db.PaymentMethodType) string {
 
 	return fmt.Sprintf("%s%s", prefix, string(digits))
 }
+
+// isSettlementRejection reports whether the database refused a settlement by its rules, such as a
+// constraint on the balance, so retrying cannot succeed. Other errors, like a lost connection or a
+// cancelled context, are temporary.
+func isSettlementRejection(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code.Class() {
+	case "22", "23": // data exception, integrity constraint violation
+		return true
+	}
+	return false
+}
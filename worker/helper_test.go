@@ -0,0 +1,22 @@
+package worker
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSettlementRejection(t *testing.T) {
+	checkViolation := &pq.Error{Code: "23514"}
+	assert.True(t, isSettlementRejection(fmt.Errorf("failed to increment merchant balance: %w", checkViolation)))
+	assert.True(t, isSettlementRejection(&pq.Error{Code: "22003"}), "numeric overflow")
+
+	assert.False(t, isSettlementRejection(&pq.Error{Code: "40001"}), "serialization failures are retried")
+	assert.False(t, isSettlementRejection(&pq.Error{Code: "57P01"}), "admin shutdown")
+	assert.False(t, isSettlementRejection(driver.ErrBadConn))
+	assert.False(t, isSettlementRejection(fmt.Errorf("failed to update payment intent status: %w", context.Canceled)))
+}
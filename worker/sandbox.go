@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"errors"
+	"strings"
+
+	"cash-flow-financial/internal/db"
+)
+
+// Test intents never reach a payment provider. The sandbox settles them with an outcome chosen by
+// the payer's account number, then by the amount's cents, so integrations can exercise every path
+// on demand and get the same result every time.
+const (
+	sandboxAccountSuccess  = "251900000001"
+	sandboxAccountDeclined = "251900000002"
+	sandboxAccountTimeout  = "251900000003"
+
+	sandboxCentsDeclined = "51"
+	sandboxCentsTimeout  = "52"
+
+	sandboxReferencePrefix = "SBX"
+	sandboxPaymentMethod   = db.PaymentMethodTypeTelebirr
+)
+
+var (
+	errSandboxDeclined = errors.New("payment declined by the payer's provider")
+	errSandboxTimeout  = errors.New("payment provider did not respond in time")
+)
+
+// sandboxPayment picks the method, reference and account of a test payment. Without an account
+// number the payment uses the one that always succeeds.
+func sandboxPayment(accountNumber string) (thirdPartyRef string, method db.PaymentMethodType, account string) {
+	if accountNumber == "" {
+		accountNumber = sandboxAccountSuccess
+	}
+	return sandboxReferencePrefix + generateThirdPartyReference(), sandboxPaymentMethod, accountNumber
+}
+
+// sandboxOutcome returns nil for a test payment that succeeds, or the reason it fails. The
+// magic account numbers take precedence over the amount.
+func sandboxOutcome(amount, accountNumber string) error {
+	switch accountNumber {
+	case sandboxAccountSuccess:
+		return nil
+	case sandboxAccountDeclined:
+		return errSandboxDeclined
+	case sandboxAccountTimeout:
+		return errSandboxTimeout
+	}
+
+	_, cents, _ := strings.Cut(amount, ".")
+	switch cents {
+	case sandboxCentsDeclined:
+		return errSandboxDeclined
+	case sandboxCentsTimeout:
+		return errSandboxTimeout
+	}
+	return nil
+}
+
+// isProviderFailure reports whether a failed payment was refused by the provider rather than by an
+// internal error, so its reason can be shown to the merchant. Timeouts never fail a payment.
+func isProviderFailure(err error) bool {
+	return errors.Is(err, errSandboxDeclined)
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSandboxOutcome(t *testing.T) {
+	tests := []struct {
+		amount        string
+		accountNumber string
+		want          error
+	}{
+		{"100.00", "", nil},
+		{"100.00", "251911223344", nil},
+		{"100.00", sandboxAccountSuccess, nil},
+		{"100.00", sandboxAccountDeclined, errSandboxDeclined},
+		{"100.00", sandboxAccountTimeout, errSandboxTimeout},
+		{"100.51", "", errSandboxDeclined},
+		{"100.52", "251911223344", errSandboxTimeout},
+		{"100.53", "", nil},
+		{"51.00", "", nil},
+		// The account number wins over the amount
+		{"100.51", sandboxAccountSuccess, nil},
+		{"100.51", sandboxAccountTimeout, errSandboxTimeout},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, sandboxOutcome(tt.amount, tt.accountNumber), "amount %s, account %q", tt.amount, tt.accountNumber)
+	}
+}
+
+func TestSandboxPayment(t *testing.T) {
+	ref, method, account := sandboxPayment("")
+	assert.True(t, strings.HasPrefix(ref, sandboxReferencePrefix))
+	assert.Equal(t, sandboxPaymentMethod, method)
+	assert.Equal(t, sandboxAccountSuccess, account)
+
+	_, _, account = sandboxPayment(sandboxAccountDeclined)
+	assert.Equal(t, sandboxAccountDeclined, account)
+}
@@ -4,12 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"cash-flow-financial/internal/contracts"
 	"cash-flow-financial/internal/db"
+	"cash-flow-financial/internal/events"
+	"cash-flow-financial/internal/managers/brokermanager"
+	"cash-flow-financial/internal/managers/dbmanager"
 	"cash-flow-financial/internal/managers/loggermanager"
-	"cash-flow-financial/internal/managers/rabbitmqmanager"
+	"cash-flow-financial/internal/models"
 	"cash-flow-financial/internal/services/callback"
 
 	"go.uber.org/zap"
@@ -17,128 +26,219 @@ import (
 
 type Worker struct {
 	queries      *db.Queries
-	rabbitMQ     *rabbitmqmanager.RabbitMQManager
+	dbManager    dbmanager.IDBManager
+	consumer     brokermanager.IConsumer
 	logger       *loggermanager.Logger
-	callbackSvc  callback.ICallbackService
 	queueName    string
 	exchangeName string
 	routingKey   string
+	consumerTag  string
+	concurrency  int
+	prefetch     int
+
+	shutdownTimeout time.Duration
+	started         bool
+	processCtx      context.Context
+	abortProcessing context.CancelFunc
+	consumers       sync.WaitGroup
+	stopOnce        sync.Once
+
+	inFlight  atomic.Int64
+	processed atomic.Int64
+	failed    atomic.Int64
 }
 
-func NewWorker(queries *db.Queries, rabbitMQ *rabbitmqmanager.RabbitMQManager, logger *loggermanager.Logger, callbackSvc callback.ICallbackService) IWorker {
+func NewWorker(queries *db.Queries, dbManager dbmanager.IDBManager, consumer brokermanager.IConsumer, logger *loggermanager.Logger, cfg *models.WorkerConfig) IWorker {
+	concurrency := cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	prefetch := cfg.Prefetch
+	if prefetch < 1 {
+		prefetch = concurrency
+	}
+
+	hostname, _ := os.Hostname()
+
 	return &Worker{
 		queries:      queries,
-		rabbitMQ:     rabbitMQ,
+		dbManager:    dbManager,
+		consumer:     consumer,
 		logger:       logger,
-		callbackSvc:  callbackSvc,
 		queueName:    "payment_intents_queue",
 		exchangeName: "payment_intents_exchange",
 		routingKey:   "payment.intent.created",
+		consumerTag:  fmt.Sprintf("payment-worker-%s-%d", hostname, os.Getpid()),
+		concurrency:  concurrency,
+		prefetch:     prefetch,
+
+		shutdownTimeout: cfg.ShutdownTimeout,
 	}
 }
 
 func (w *Worker) Start(ctx context.Context) error {
 	w.logger.Info("Starting payment worker...")
 
-	err := w.rabbitMQ.Channel.ExchangeDeclare(
-		w.exchangeName,
-		"topic",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		w.logger.Error("Failed to declare exchange", zap.Error(err))
-		return fmt.Errorf("failed to declare exchange: %w", err)
-	}
-
-	queue, err := w.rabbitMQ.Channel.QueueDeclare(
-		w.queueName,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		w.logger.Error("Failed to declare queue", zap.Error(err))
-		return fmt.Errorf("failed to declare queue: %w", err)
-	}
-
-	err = w.rabbitMQ.Channel.QueueBind(
-		queue.Name,
-		w.routingKey,
-		w.exchangeName,
-		false,
-		nil,
-	)
-	if err != nil {
-		w.logger.Error("Failed to bind queue", zap.Error(err))
-		return fmt.Errorf("failed to bind queue: %w", err)
-	}
-
-	msgs, err := w.rabbitMQ.Channel.Consume(
-		queue.Name,
-		"",
-		false,
-		false,
-		false,
-		false,
-		nil,
-	)
+	msgs, err := w.consumer.Consume(ctx, brokermanager.Subscription{
+		Queue:       w.queueName,
+		Exchange:    w.exchangeName,
+		RoutingKeys: []string{w.routingKey},
+		ConsumerTag: w.consumerTag,
+		Prefetch:    w.prefetch,
+	})
 	if err != nil {
 		w.logger.Error("Failed to register consumer", zap.Error(err))
 		return fmt.Errorf("failed to register consumer: %w", err)
 	}
+	w.started = true
 
-	w.logger.Info("Payment worker started successfully", zap.String("queue", queue.Name))
+	// Processing outlives the shutdown signal so in-flight payments can finish; Stop aborts it at the deadline
+	w.processCtx, w.abortProcessing = context.WithCancel(context.WithoutCancel(ctx))
 
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				w.logger.Info("Worker shutting down...")
-				return
-			case d, ok := <-msgs:
-				if !ok {
-					w.logger.Error("Message channel closed")
-					return
-				}
-
-				w.logger.Info("Received payment intent message",
-					zap.String("message_id", d.MessageId),
-					zap.String("body", string(d.Body)))
-
-				var msg PaymentIntentMessage
-				if err := json.Unmarshal(d.Body, &msg); err != nil {
-					w.logger.Error("Failed to unmarshal message", zap.Error(err), zap.String("body", string(d.Body)))
-					d.Nack(false, false)
-					continue
-				}
-
-				if err := w.ProcessPaymentIntent(ctx, msg); err != nil {
-					w.logger.Error("Failed to process payment intent", zap.Error(err), zap.Any("message", msg))
-					d.Nack(false, true)
-					continue
-				}
-
-				w.logger.Info("Successfully processed payment intent", zap.String("payment_intent_id", msg.PaymentIntentID))
-				d.Ack(false)
-			}
-		}
-	}()
+	w.logger.Info("Payment worker started successfully",
+		zap.String("queue", w.queueName),
+		zap.String("consumer_tag", w.consumerTag),
+		zap.Int("concurrency", w.concurrency),
+		zap.Int("prefetch", w.prefetch))
+
+	for i := 0; i < w.concurrency; i++ {
+		w.consumers.Add(1)
+		go w.consume(i, msgs)
+	}
 
 	return nil
 }
 
+func (w *Worker) consume(slot int, msgs <-chan brokermanager.Delivery) {
+	defer w.consumers.Done()
+
+	// msgs is closed once the consumer is cancelled and already-buffered deliveries are handed out
+	for d := range msgs {
+		w.handleDelivery(w.processCtx, slot, d)
+	}
+
+	w.logger.Info("Worker slot drained", zap.Int("slot", slot))
+}
+
+func (w *Worker) handleDelivery(ctx context.Context, slot int, d brokermanager.Delivery) {
+	w.inFlight.Add(1)
+	defer w.inFlight.Add(-1)
+
+	w.logger.Info("Received payment intent message",
+		zap.Int("slot", slot),
+		zap.String("message_id", d.MessageID),
+		zap.Bool("redelivered", d.Redelivered),
+		zap.String("body", string(d.Body)))
+
+	// Malformed or unsupported messages will never succeed, so they are dropped instead of requeued
+	msg, err := contracts.DecodePaymentIntentCreated(d.ContentType, d.Headers, d.Body)
+	if err != nil {
+		w.logger.Error("Rejecting invalid payment intent message",
+			zap.String("message_id", d.MessageID),
+			zap.String("type", d.Type),
+			zap.Error(err),
+			zap.String("body", string(d.Body)))
+		w.failed.Add(1)
+		if nackErr := d.Nack(false); nackErr != nil {
+			w.logger.Error("Failed to nack message", zap.String("message_id", d.MessageID), zap.Error(nackErr))
+		}
+		return
+	}
+
+	if err := w.ProcessPaymentIntent(ctx, msg); err != nil {
+		w.logger.Error("Failed to process payment intent", zap.Error(err), zap.Any("message", msg))
+		w.failed.Add(1)
+		if nackErr := d.Nack(true); nackErr != nil {
+			w.logger.Error("Failed to nack message", zap.String("message_id", d.MessageID), zap.Error(nackErr))
+		}
+		return
+	}
+
+	w.logger.Info("Successfully processed payment intent", zap.String("payment_intent_id", msg.PaymentIntentID))
+	w.processed.Add(1)
+	if ackErr := d.Ack(); ackErr != nil {
+		w.logger.Error("Failed to ack message", zap.String("message_id", d.MessageID), zap.Error(ackErr))
+	}
+}
+
+func (w *Worker) Stats() models.WorkerStats {
+	return models.WorkerStats{
+		Concurrency: w.concurrency,
+		Prefetch:    w.prefetch,
+		InFlight:    w.inFlight.Load(),
+		Processed:   w.processed.Load(),
+		Failed:      w.failed.Load(),
+	}
+}
+
 func (w *Worker) Stop() error {
-	w.logger.Info("Stopping payment worker...")
+	var err error
+	w.stopOnce.Do(func() {
+		err = w.shutdown()
+	})
+	return err
+}
+
+func (w *Worker) shutdown() error {
+	startedAt := time.Now()
+	w.logger.Info("Stopping payment worker...",
+		zap.String("consumer_tag", w.consumerTag),
+		zap.Int64("in_flight", w.inFlight.Load()),
+		zap.Duration("deadline", w.shutdownTimeout))
+
+	if !w.started {
+		w.logger.Info("Payment worker was never started, nothing to stop")
+		return nil
+	}
+
+	if err := w.consumer.Cancel(w.consumerTag); err != nil {
+		w.logger.Error("Failed to cancel consumer", zap.String("consumer_tag", w.consumerTag), zap.Error(err))
+	} else {
+		w.logger.Info("Consumer cancelled, no new deliveries will be received",
+			zap.Duration("elapsed", time.Since(startedAt)))
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		w.consumers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		w.logger.Info("In-flight deliveries drained",
+			zap.Int64("processed", w.processed.Load()),
+			zap.Duration("elapsed", time.Since(startedAt)))
+	case <-time.After(w.shutdownTimeout):
+		w.logger.Warn("Shutdown deadline reached, aborting in-flight deliveries",
+			zap.Int64("in_flight", w.inFlight.Load()),
+			zap.Duration("elapsed", time.Since(startedAt)))
+		w.abortProcessing()
+
+		// Aborted deliveries nack themselves with requeue; anything still unacked is requeued when the consumer closes
+		select {
+		case <-drained:
+			w.logger.Info("Aborted deliveries returned to the queue", zap.Duration("elapsed", time.Since(startedAt)))
+		case <-time.After(5 * time.Second):
+			w.logger.Warn("Deliveries still running after abort, closing consumer anyway",
+				zap.Int64("in_flight", w.inFlight.Load()))
+		}
+	}
+	w.abortProcessing()
+
+	if err := w.consumer.CloseConsumer(w.consumerTag); err != nil {
+		w.logger.Error("Failed to close worker consumer", zap.Error(err))
+		return fmt.Errorf("failed to close worker consumer: %w", err)
+	}
+
+	w.logger.Info("Payment worker stopped",
+		zap.Int64("processed", w.processed.Load()),
+		zap.Int64("failed", w.failed.Load()),
+		zap.Duration("elapsed", time.Since(startedAt)))
 	return nil
 }
 
-func (w *Worker) ProcessPaymentIntent(ctx context.Context, message PaymentIntentMessage) error {
+func (w *Worker) ProcessPaymentIntent(ctx context.Context, message *contracts.PaymentIntentCreated) error {
 	w.logger.Info("Processing payment intent", zap.String("payment_intent_id", message.PaymentIntentID))
 
 	w.logger.Info("Getting payment intent by string ID", zap.String("payment_intent_id", message.PaymentIntentID))
@@ -154,6 +254,15 @@ func (w *Worker) ProcessPaymentIntent(ctx context.Context, message PaymentIntent
 		zap.String("status", string(paymentIntentInfo.Status.PaymentStatus)),
 		zap.Bool("status_valid", paymentIntentInfo.Status.Valid))
 
+	// v1 messages carry no merchant; a v2 message that disagrees with the stored intent is not retried
+	if message.MerchantID != "" && message.MerchantID != paymentIntentInfo.MerchantID {
+		w.logger.Error("Payment intent message merchant does not match stored intent, skipping",
+			zap.String("payment_intent_id", message.PaymentIntentID),
+			zap.String("message_merchant_id", message.MerchantID),
+			zap.String("intent_merchant_id", paymentIntentInfo.MerchantID))
+		return nil
+	}
+
 	if paymentIntentInfo.Status.Valid && paymentIntentInfo.Status.PaymentStatus == db.PaymentStatusProcessing {
 		w.logger.Info("Payment intent already being processed, stopping execution",
 			zap.String("payment_intent_id", message.PaymentIntentID))
@@ -167,6 +276,22 @@ func (w *Worker) ProcessPaymentIntent(ctx context.Context, message PaymentIntent
 		return nil
 	}
 
+	merchant, err := w.queries.GetMerchantByMerchantID(ctx, paymentIntentInfo.MerchantID)
+	if err != nil {
+		w.logger.Error("Failed to get merchant UUID", zap.String("custom_merchant_id", paymentIntentInfo.MerchantID), zap.Error(err))
+		return fmt.Errorf("failed to get merchant UUID: %w", err)
+	}
+
+	// Processing and settlement pause while a merchant is suspended: the intent stays pending and is
+	// republished on reactivation. Intents of deactivated merchants are left to expire.
+	if !merchant.Status.Valid || merchant.Status.MerchantStatus != db.MerchantStatusActive {
+		w.logger.Warn("Merchant is not active, leaving payment intent pending",
+			zap.String("payment_intent_id", message.PaymentIntentID),
+			zap.String("merchant_id", paymentIntentInfo.MerchantID),
+			zap.String("merchant_status", string(merchant.Status.MerchantStatus)))
+		return nil
+	}
+
 	w.logger.Info("Payment intent is pending, changing to processing and continuing",
 		zap.String("payment_intent_id", message.PaymentIntentID))
 
@@ -185,11 +310,21 @@ func (w *Worker) ProcessPaymentIntent(ctx context.Context, message PaymentIntent
 	w.logger.Info("Creating payment transaction",
 		zap.String("payment_intent_id", message.PaymentIntentID))
 
-	thirdPartyRef := generateThirdPartyReference()
-	selectedPaymentMethod := selectRandomPaymentMethod()
-	accountNumber := generateAccountNumber(selectedPaymentMethod)
+	var thirdPartyRef, accountNumber string
+	var selectedPaymentMethod db.PaymentMethodType
+	if paymentIntentInfo.Mode == db.ApiModeTest {
+		thirdPartyRef, selectedPaymentMethod, accountNumber = sandboxPayment(paymentIntentInfo.AccountNumber.String)
+	} else {
+		thirdPartyRef = generateThirdPartyReference()
+		selectedPaymentMethod = selectRandomPaymentMethod()
+		accountNumber = paymentIntentInfo.AccountNumber.String
+		if accountNumber == "" {
+			accountNumber = generateAccountNumber(selectedPaymentMethod)
+		}
+	}
 
-	w.logger.Info("Selected payment method and generated account number",
+	w.logger.Info("Selected payment method and account number",
+		zap.String("mode", string(paymentIntentInfo.Mode)),
 		zap.String("payment_method", string(selectedPaymentMethod)),
 		zap.String("account_number", accountNumber))
 
@@ -204,6 +339,7 @@ func (w *Worker) ProcessPaymentIntent(ctx context.Context, message PaymentIntent
 		PaymentMethod:   db.NullPaymentMethodType{PaymentMethodType: selectedPaymentMethod, Valid: true},
 		FeeAmount:       sql.NullString{String: feeAmount, Valid: true},
 		AccountNumber:   sql.NullString{String: accountNumber, Valid: true},
+		Mode:            paymentIntentInfo.Mode,
 	})
 	if err != nil {
 		w.logger.Error("Failed to create payment transaction", zap.Error(err))
@@ -214,6 +350,7 @@ func (w *Worker) ProcessPaymentIntent(ctx context.Context, message PaymentIntent
 		zap.String("transaction_id", transaction.ID.String()),
 		zap.String("payment_intent_id", transaction.PaymentIntentID),
 		zap.String("merchant_id", transaction.MerchantID),
+		zap.String("mode", string(transaction.Mode)),
 		zap.String("amount", transaction.Amount),
 		zap.String("currency", string(transaction.Currency)),
 		zap.String("payment_method", string(transaction.PaymentMethod.PaymentMethodType)),
@@ -221,52 +358,41 @@ func (w *Worker) ProcessPaymentIntent(ctx context.Context, message PaymentIntent
 		zap.String("account_number", transaction.AccountNumber.String),
 		zap.String("status", string(transaction.Status.TransactionStatus)))
 
-	merchant, err := w.queries.GetMerchantByMerchantID(ctx, paymentIntentInfo.MerchantID)
-	if err != nil {
-		w.logger.Error("Failed to get merchant UUID", zap.String("custom_merchant_id", paymentIntentInfo.MerchantID), zap.Error(err))
-		return fmt.Errorf("failed to get merchant UUID: %w", err)
+	if paymentIntentInfo.Mode == db.ApiModeTest {
+		if outcome := sandboxOutcome(paymentIntentInfo.Amount, accountNumber); outcome != nil {
+			w.logger.Info("Sandbox payment did not succeed",
+				zap.String("payment_intent_id", message.PaymentIntentID),
+				zap.String("account_number", accountNumber),
+				zap.String("amount", paymentIntentInfo.Amount),
+				zap.Error(outcome))
+			// A provider that never answers has not refused the payment, so the intent is not failed;
+			// it goes back to pending and the expiry sweep ends it, as it would a payer who never paid
+			if errors.Is(outcome, errSandboxTimeout) {
+				w.releasePayment(ctx, paymentIntentInfo, transaction)
+				return nil
+			}
+			w.failPayment(ctx, paymentIntentInfo, transaction, outcome)
+			return nil
+		}
 	}
 
 	merchantBalanceBefore, err := w.queries.GetMerchantBalance(ctx, &db.GetMerchantBalanceParams{
 		MerchantID: merchant.ID,
 		Currency:   db.CurrencyType(paymentIntentInfo.Currency),
+		Mode:       paymentIntentInfo.Mode,
 	})
 	if err != nil {
 		w.logger.Warn("Could not get merchant balance before update", zap.Error(err))
 	} else {
 		w.logger.Info("=== MERCHANT BALANCE BEFORE UPDATE ===",
 			zap.String("merchant_id", paymentIntentInfo.MerchantID),
+			zap.String("mode", string(paymentIntentInfo.Mode)),
 			zap.String("currency", string(paymentIntentInfo.Currency)),
 			zap.String("available_balance", merchantBalanceBefore.AvailableBalance.String),
 			zap.String("total_deposit", merchantBalanceBefore.TotalDeposit.String),
 			zap.Int32("transaction_count", merchantBalanceBefore.TotalTransactionCount.Int32))
 	}
 
-	w.logger.Info("Updating payment transaction status",
-		zap.String("payment_transaction_id", transaction.ID.String()),
-		zap.String("third_party_ref", thirdPartyRef))
-
-	_, err = w.queries.UpdatePaymentTransactionStatus(ctx, &db.UpdatePaymentTransactionStatusParams{
-		ID:                  transaction.ID,
-		Status:              db.NullTransactionStatus{TransactionStatus: db.TransactionStatusSuccess, Valid: true},
-		ThirdPartyReference: sql.NullString{String: thirdPartyRef, Valid: true},
-		Status_2:            db.NullTransactionStatus{TransactionStatus: db.TransactionStatusPending, Valid: true},
-	})
-	if err != nil {
-		w.logger.Error("Failed to update payment transaction status", zap.Error(err))
-		return fmt.Errorf("failed to update payment transaction status: %w", err)
-	}
-
-	_, err = w.queries.UpdatePaymentIntentStatus(ctx, &db.UpdatePaymentIntentStatusParams{
-		ID:       paymentIntentInfo.ID,
-		Status:   db.NullPaymentStatus{PaymentStatus: db.PaymentStatusSuccess, Valid: true},
-		Status_2: db.NullPaymentStatus{PaymentStatus: db.PaymentStatusProcessing, Valid: true},
-	})
-	if err != nil {
-		w.logger.Error("Failed to update payment intent status", zap.Error(err))
-		return fmt.Errorf("failed to update payment intent status: %w", err)
-	}
-
 	amountFloat, _ = strconv.ParseFloat(paymentIntentInfo.Amount, 64)
 	feeFloat, _ := strconv.ParseFloat(feeAmount, 64)
 	depositAmount := amountFloat
@@ -274,56 +400,94 @@ func (w *Worker) ProcessPaymentIntent(ctx context.Context, message PaymentIntent
 	depositAmountStr := fmt.Sprintf("%.2f", depositAmount)
 	netBalanceStr := fmt.Sprintf("%.2f", netBalanceAmount)
 
-	w.logger.Info("Updating merchant balance",
-		zap.String("merchant_uuid", merchant.ID.String()),
-		zap.String("custom_merchant_id", paymentIntentInfo.MerchantID),
-		zap.String("deposit_amount", depositAmountStr),
-		zap.String("fee_amount", feeAmount),
-		zap.String("net_balance_credit", netBalanceStr),
-		zap.String("currency", string(paymentIntentInfo.Currency)))
-
 	w.logger.Info("=== MERCHANT BALANCE UPDATE START ===",
 		zap.String("merchant_uuid", merchant.ID.String()),
 		zap.String("custom_merchant_id", paymentIntentInfo.MerchantID),
+		zap.String("mode", string(paymentIntentInfo.Mode)),
 		zap.String("currency", string(paymentIntentInfo.Currency)),
 		zap.String("deposit_amount", depositAmountStr),
 		zap.String("fee_deducted", feeAmount),
 		zap.String("balance_after_fee", netBalanceStr))
 
-	merchantBalance, err := w.queries.IncrementMerchantBalance(ctx, &db.IncrementMerchantBalanceParams{
-		MerchantID: merchant.ID,
-		Currency:   db.CurrencyType(paymentIntentInfo.Currency),
-		Column3:    depositAmountStr,
-		Column4:    feeAmount,
-	})
+	// Status changes, the balance credit and their domain events commit together or not at all
+	var merchantBalance *db.MerchantBalance
+	err = w.dbManager.WithTransaction(ctx, func(tx *sql.Tx) error {
+		qtx := w.queries.WithTx(tx)
+
+		w.logger.Info("Updating payment transaction status",
+			zap.String("payment_transaction_id", transaction.ID.String()),
+			zap.String("third_party_ref", thirdPartyRef))
+
+		settledTransaction, err := qtx.UpdatePaymentTransactionStatus(ctx, &db.UpdatePaymentTransactionStatusParams{
+			ID:                  transaction.ID,
+			Status:              db.NullTransactionStatus{TransactionStatus: db.TransactionStatusSuccess, Valid: true},
+			ThirdPartyReference: sql.NullString{String: thirdPartyRef, Valid: true},
+			Status_2:            db.NullTransactionStatus{TransactionStatus: db.TransactionStatusPending, Valid: true},
+		})
+		if err != nil {
+			w.logger.Error("Failed to update payment transaction status", zap.Error(err))
+			return fmt.Errorf("failed to update payment transaction status: %w", err)
+		}
 
-	if err != nil {
-		w.logger.Error("=== MERCHANT BALANCE UPDATE FAILED ===",
-			zap.String("merchant_id", paymentIntentInfo.MerchantID),
-			zap.String("currency", string(paymentIntentInfo.Currency)),
-			zap.String("deposit_amount", depositAmountStr),
-			zap.Error(err))
-	} else {
-		w.logger.Info("=== MERCHANT BALANCE UPDATE SUCCESSFUL ===",
-			zap.String("merchant_id", paymentIntentInfo.MerchantID),
-			zap.String("currency", string(paymentIntentInfo.Currency)),
-			zap.String("new_available_balance", merchantBalance.AvailableBalance.String),
-			zap.String("new_total_deposit", merchantBalance.TotalDeposit.String),
-			zap.Int32("new_transaction_count", merchantBalance.TotalTransactionCount.Int32),
-			zap.String("deposit_amount", depositAmountStr))
+		_, err = qtx.UpdatePaymentIntentStatus(ctx, &db.UpdatePaymentIntentStatusParams{
+			ID:       paymentIntentInfo.ID,
+			Status:   db.NullPaymentStatus{PaymentStatus: db.PaymentStatusSuccess, Valid: true},
+			Status_2: db.NullPaymentStatus{PaymentStatus: db.PaymentStatusProcessing, Valid: true},
+		})
+		if err != nil {
+			w.logger.Error("Failed to update payment intent status", zap.Error(err))
+			return fmt.Errorf("failed to update payment intent status: %w", err)
+		}
 
-		if err := w.sendCallback(paymentIntentInfo, transaction, thirdPartyRef, feeAmount, depositAmount); err != nil {
-			w.logger.Warn("Failed to send callback to merchant",
-				zap.String("payment_intent_id", message.PaymentIntentID),
-				zap.String("callback_url", paymentIntentInfo.CallbackUrl),
+		merchantBalance, err = qtx.IncrementMerchantBalance(ctx, &db.IncrementMerchantBalanceParams{
+			MerchantID: merchant.ID,
+			Currency:   db.CurrencyType(paymentIntentInfo.Currency),
+			Column3:    depositAmountStr,
+			Column4:    feeAmount,
+			Mode:       paymentIntentInfo.Mode,
+		})
+		if err != nil {
+			w.logger.Error("=== MERCHANT BALANCE UPDATE FAILED ===",
+				zap.String("merchant_id", paymentIntentInfo.MerchantID),
+				zap.String("currency", string(paymentIntentInfo.Currency)),
+				zap.String("deposit_amount", depositAmountStr),
 				zap.Error(err))
-		} else {
-			w.logger.Info("Callback sent successfully to merchant",
-				zap.String("payment_intent_id", message.PaymentIntentID),
-				zap.String("callback_url", paymentIntentInfo.CallbackUrl))
+			return fmt.Errorf("failed to increment merchant balance: %w", err)
 		}
+
+		transaction = settledTransaction
+		if err := w.recordSettlementEvents(ctx, qtx, paymentIntentInfo, transaction, merchantBalance, netBalanceStr); err != nil {
+			return err
+		}
+		return w.enqueueCallback(ctx, qtx, events.TypePaymentIntentSucceeded, string(db.PaymentStatusSuccess), "", paymentIntentInfo, transaction)
+	})
+	if err != nil {
+		if !isSettlementRejection(err) {
+			// Nothing was settled, so the intent goes back to pending and the broker redelivers it
+			w.logger.Warn("Settlement rolled back, returning payment intent to pending for a retry",
+				zap.String("payment_intent_id", paymentIntentInfo.PaymentIntentID),
+				zap.String("payment_transaction_id", transaction.ID.String()),
+				zap.Error(err))
+			w.releasePayment(ctx, paymentIntentInfo, transaction)
+			return fmt.Errorf("failed to settle payment intent: %w", err)
+		}
+
+		w.logger.Warn("Settlement rejected, marking payment as failed",
+			zap.String("payment_intent_id", paymentIntentInfo.PaymentIntentID),
+			zap.String("payment_transaction_id", transaction.ID.String()),
+			zap.Error(err))
+		w.failPayment(ctx, paymentIntentInfo, transaction, err)
+		return nil
 	}
 
+	w.logger.Info("=== MERCHANT BALANCE UPDATE SUCCESSFUL ===",
+		zap.String("merchant_id", paymentIntentInfo.MerchantID),
+		zap.String("currency", string(paymentIntentInfo.Currency)),
+		zap.String("new_available_balance", merchantBalance.AvailableBalance.String),
+		zap.String("new_total_deposit", merchantBalance.TotalDeposit.String),
+		zap.Int32("new_transaction_count", merchantBalance.TotalTransactionCount.Int32),
+		zap.String("deposit_amount", depositAmountStr))
+
 	w.logger.Info("Payment processing completed successfully",
 		zap.String("payment_intent_id", message.PaymentIntentID),
 		zap.String("payment_transaction_id", transaction.ID.String()),
@@ -333,13 +497,97 @@ func (w *Worker) ProcessPaymentIntent(ctx context.Context, message PaymentIntent
 	return nil
 }
 
-func (w *Worker) sendCallback(paymentIntentInfo *db.GetPaymentIntentRow, transaction *db.PaymentTransaction, thirdPartyRef, feeAmount string, depositAmount float64) error {
-	if paymentIntentInfo.CallbackUrl == "" {
-		w.logger.Info("No callback URL provided, skipping callback",
-			zap.String("payment_intent_id", paymentIntentInfo.PaymentIntentID))
+// failPayment marks the transaction and intent failed, after a provider refusal or a settlement
+// the database rejected, so the intent is not left in processing, and records the matching failed
+// events in the same transaction. Only a provider's reason is passed on to the merchant's callback.
+func (w *Worker) failPayment(ctx context.Context, paymentIntentInfo *db.GetPaymentIntentRow, transaction *db.PaymentTransaction, cause error) {
+	callbackReason := ""
+	if isProviderFailure(cause) {
+		callbackReason = cause.Error()
+	}
+
+	// The outcome is recorded even when processing is being aborted
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusUpdateTimeout)
+	defer cancel()
+
+	err := w.dbManager.WithTransaction(ctx, func(tx *sql.Tx) error {
+		qtx := w.queries.WithTx(tx)
+
+		failedTransaction, err := qtx.UpdatePaymentTransactionStatus(ctx, &db.UpdatePaymentTransactionStatusParams{
+			ID:       transaction.ID,
+			Status:   db.NullTransactionStatus{TransactionStatus: db.TransactionStatusFailed, Valid: true},
+			Status_2: db.NullTransactionStatus{TransactionStatus: db.TransactionStatusPending, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark payment transaction failed: %w", err)
+		}
+
+		_, err = qtx.UpdatePaymentIntentStatus(ctx, &db.UpdatePaymentIntentStatusParams{
+			ID:       paymentIntentInfo.ID,
+			Status:   db.NullPaymentStatus{PaymentStatus: db.PaymentStatusFailed, Valid: true},
+			Status_2: db.NullPaymentStatus{PaymentStatus: db.PaymentStatusProcessing, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark payment intent failed: %w", err)
+		}
+
+		if err := w.recordFailureEvents(ctx, qtx, paymentIntentInfo, failedTransaction, cause.Error()); err != nil {
+			return err
+		}
+		return w.enqueueCallback(ctx, qtx, events.TypePaymentIntentFailed, string(db.PaymentStatusFailed), callbackReason, paymentIntentInfo, failedTransaction)
+	})
+	if err != nil {
+		w.logger.Error("Failed to mark payment as failed",
+			zap.String("payment_intent_id", paymentIntentInfo.PaymentIntentID),
+			zap.Error(err))
+		return
+	}
+
+	w.logger.Info("Payment marked as failed", zap.String("payment_intent_id", paymentIntentInfo.PaymentIntentID))
+}
+
+// releasePayment abandons a settlement attempt that failed for a temporary reason: the attempt's
+// transaction is marked failed and the intent goes back to pending, so a redelivery processes it
+// again. No events or callbacks are recorded since the payment has not failed.
+func (w *Worker) releasePayment(ctx context.Context, paymentIntentInfo *db.GetPaymentIntentRow, transaction *db.PaymentTransaction) {
+	// The context may be the reason the settlement failed
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusUpdateTimeout)
+	defer cancel()
+
+	err := w.dbManager.WithTransaction(ctx, func(tx *sql.Tx) error {
+		qtx := w.queries.WithTx(tx)
+
+		_, err := qtx.UpdatePaymentTransactionStatus(ctx, &db.UpdatePaymentTransactionStatusParams{
+			ID:       transaction.ID,
+			Status:   db.NullTransactionStatus{TransactionStatus: db.TransactionStatusFailed, Valid: true},
+			Status_2: db.NullTransactionStatus{TransactionStatus: db.TransactionStatusPending, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark payment transaction failed: %w", err)
+		}
+
+		_, err = qtx.UpdatePaymentIntentStatus(ctx, &db.UpdatePaymentIntentStatusParams{
+			ID:       paymentIntentInfo.ID,
+			Status:   db.NullPaymentStatus{PaymentStatus: db.PaymentStatusPending, Valid: true},
+			Status_2: db.NullPaymentStatus{PaymentStatus: db.PaymentStatusProcessing, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to return payment intent to pending: %w", err)
+		}
 		return nil
+	})
+	if err != nil {
+		w.logger.Error("Failed to return payment intent to pending",
+			zap.String("payment_intent_id", paymentIntentInfo.PaymentIntentID),
+			zap.Error(err))
+		return
 	}
 
+	w.logger.Info("Payment intent returned to pending", zap.String("payment_intent_id", paymentIntentInfo.PaymentIntentID))
+}
+
+// enqueueCallback stores the merchant callbacks for eventType; qtx must be bound to the transaction that changes the payment status
+func (w *Worker) enqueueCallback(ctx context.Context, qtx *db.Queries, eventType, status, failureReason string, paymentIntentInfo *db.GetPaymentIntentRow, transaction *db.PaymentTransaction) error {
 	var metadata map[string]interface{}
 	if paymentIntentInfo.Metadata.Valid {
 		if err := json.Unmarshal(paymentIntentInfo.Metadata.RawMessage, &metadata); err != nil {
@@ -355,20 +603,41 @@ func (w *Worker) sendCallback(paymentIntentInfo *db.GetPaymentIntentRow, transac
 		processedAt = transaction.ProcessedAt.Time.Format("2006-01-02T15:04:05Z07:00")
 	}
 
+	amount, _ := strconv.ParseFloat(paymentIntentInfo.Amount, 64)
+
 	callbackReq := callback.CallbackRequest{
 		PaymentIntentID:     paymentIntentInfo.PaymentIntentID,
 		MerchantID:          paymentIntentInfo.MerchantID,
-		Amount:              depositAmount,
+		Mode:                string(paymentIntentInfo.Mode),
+		Amount:              amount,
 		Currency:            string(paymentIntentInfo.Currency),
-		Status:              "success",
+		Status:              status,
+		FailureReason:       failureReason,
 		AccountNumber:       transaction.AccountNumber.String,
 		PaymentMethod:       string(transaction.PaymentMethod.PaymentMethodType),
-		ThirdPartyReference: thirdPartyRef,
-		FeeAmount:           feeAmount,
+		ThirdPartyReference: transaction.ThirdPartyReference.String,
+		FeeAmount:           transaction.FeeAmount.String,
 		ProcessedAt:         processedAt,
 		Nonce:               paymentIntentInfo.Nonce,
 		Metadata:            metadata,
 	}
 
-	return w.callbackSvc.SendCallback(paymentIntentInfo.CallbackUrl, callbackReq)
+	deliveries, err := callback.Enqueue(ctx, qtx, eventType, paymentIntentInfo.CallbackUrl.String, callbackReq)
+	if err != nil {
+		return err
+	}
+
+	if len(deliveries) == 0 {
+		w.logger.Info("No callback URL or subscribed webhook endpoint, skipping callback",
+			zap.String("payment_intent_id", paymentIntentInfo.PaymentIntentID),
+			zap.String("event_type", eventType))
+	}
+	for _, delivery := range deliveries {
+		w.logger.Info("Callback queued for delivery",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.String("payment_intent_id", paymentIntentInfo.PaymentIntentID),
+			zap.String("event_type", eventType),
+			zap.String("callback_url", delivery.Url))
+	}
+	return nil
 }